@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"pull-request-review/internal/domain/model"
+	"pull-request-review/internal/infrastructure/archive"
+)
+
+func runExport(ctx context.Context, env *environment, args []string) error {
+	flags := flag.NewFlagSet("export", flag.ExitOnError)
+	file := flags.String("file", "", "archive file (.json, .ndjson or .jsonl), stdout by default")
+	formatName := flags.String("format", "", "archive format, taken from the file extension by default")
+	_ = flags.Parse(args)
+
+	format, err := archiveFormat(*file, *formatName)
+	if err != nil {
+		return err
+	}
+
+	var out io.Writer = os.Stdout
+	if *file != "" {
+		f, err := os.Create(*file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		out = f
+	}
+
+	writer, err := archive.NewWriter(out, format)
+	if err != nil {
+		return err
+	}
+
+	counts, err := newServices(env).archive.Export(ctx, writer)
+	if err != nil {
+		return err
+	}
+
+	printArchiveCounts("exported", counts)
+	return nil
+}
+
+func runRestore(ctx context.Context, env *environment, args []string) error {
+	flags := flag.NewFlagSet("restore", flag.ExitOnError)
+	file := flags.String("file", "", "archive file (.json, .ndjson or .jsonl)")
+	formatName := flags.String("format", "", "archive format, taken from the file extension by default")
+	_ = flags.Parse(args)
+
+	if *file == "" {
+		return errors.New("-file is required")
+	}
+
+	format, err := archiveFormat(*file, *formatName)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Open(*file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	reader, err := archive.NewReader(f, format)
+	if err != nil {
+		return err
+	}
+
+	counts, err := newServices(env).archive.Restore(ctx, reader)
+	if err != nil {
+		return fmt.Errorf("restore rolled back: %w", err)
+	}
+
+	printArchiveCounts("restored", counts)
+	return nil
+}
+
+// archiveFormat prefers an explicit format; stdout exports default to NDJSON.
+func archiveFormat(file string, formatName string) (archive.Format, error) {
+	switch {
+	case formatName != "":
+		return archive.ParseFormat(formatName)
+	case file != "":
+		return archive.FormatFromPath(file)
+	default:
+		return archive.FormatNDJSON, nil
+	}
+}
+
+// printArchiveCounts goes to stderr so it never mixes with an archive written to stdout.
+func printArchiveCounts(verb string, counts map[model.ArchiveRecordKind]int) {
+	if len(counts) == 0 {
+		return
+	}
+
+	kinds := make([]string, 0, len(counts))
+	for kind := range counts {
+		kinds = append(kinds, string(kind))
+	}
+	sort.Strings(kinds)
+
+	fmt.Fprintf(os.Stderr, "%s:\n", verb)
+	for _, kind := range kinds {
+		fmt.Fprintf(os.Stderr, "  %-18s %d\n", kind, counts[model.ArchiveRecordKind(kind)])
+	}
+}
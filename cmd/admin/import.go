@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"pull-request-review/internal/domain/model"
+	"pull-request-review/internal/domain/rules"
+	"pull-request-review/internal/infrastructure/roster"
+)
+
+func runImport(ctx context.Context, env *environment, args []string) error {
+	flags := flag.NewFlagSet("import", flag.ExitOnError)
+	file := flags.String("file", "", "roster file (.yaml, .yml or .csv)")
+	formatName := flags.String("format", "", "roster format, taken from the file extension by default")
+	dryRun := flags.Bool("dry-run", false, "print the changes without applying them")
+	_ = flags.Parse(args)
+
+	if *file == "" {
+		return errors.New("-file is required")
+	}
+
+	format, err := roster.FormatFromPath(*file)
+	if *formatName != "" {
+		format, err = roster.ParseFormat(*formatName)
+	}
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Open(*file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	parsed, err := roster.Parse(f, format)
+	if err != nil {
+		return describeImportError(err)
+	}
+
+	plan, err := newServices(env).imports.Import(ctx, parsed, *dryRun)
+	if err != nil {
+		return describeImportError(err)
+	}
+
+	printImportPlan(plan)
+	switch {
+	case plan.IsEmpty():
+		fmt.Println("nothing to import")
+	case *dryRun:
+		fmt.Printf("dry run: %d changes not applied\n", len(plan.Changes))
+	default:
+		fmt.Printf("applied %d changes\n", len(plan.Changes))
+	}
+
+	return nil
+}
+
+func printImportPlan(plan *model.ImportPlan) {
+	for _, change := range plan.Changes {
+		line := fmt.Sprintf("%-6s %-10s %s", change.Action, change.Subject, change.Key)
+		if len(change.Details) > 0 {
+			line += " (" + strings.Join(change.Details, ", ") + ")"
+		}
+		fmt.Println(line)
+	}
+}
+
+func describeImportError(err error) error {
+	var importErr *rules.ImportValidationError
+	if !errors.As(err, &importErr) {
+		return err
+	}
+
+	return fmt.Errorf("%w:\n  %s", err, strings.Join(importErr.Problems, "\n  "))
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+
+	"pull-request-review/config"
+	"pull-request-review/internal/app"
+	"pull-request-review/internal/infrastructure/adapters/logger"
+)
+
+type command struct {
+	description string
+	run         func(ctx context.Context, env *environment, args []string) error
+}
+
+var commands = map[string]command{
+	"migrate":     {description: "apply (up), roll back (down) or list (status) schema migrations", run: runMigrate},
+	"create-team": {description: "create a team, optionally with members and a parent team", run: runCreateTeam},
+	"set-active":  {description: "activate or deactivate a user", run: runSetActive},
+	"reassign":    {description: "replace a reviewer on a pull request", run: runReassign},
+	"merge":       {description: "mark a pull request as merged", run: runMerge},
+	"stats":       {description: "print review statistics", run: runStats},
+	"import":      {description: "import teams and users from a YAML or CSV roster", run: runImport},
+	"export":      {description: "export all data to a JSON or NDJSON archive", run: runExport},
+	"restore":     {description: "restore an archive into an empty database", run: runRestore},
+}
+
+// environment is what every subcommand gets: the loaded configuration, a logger and the
+// storage of the configured driver.
+type environment struct {
+	cfg     *config.Config
+	log     logger.Logger
+	storage *app.Storage
+}
+
+func main() {
+	os.Exit(run())
+}
+
+func run() int {
+	if len(os.Args) < 2 {
+		usage()
+		return 2
+	}
+
+	cmd, ok := commands[os.Args[1]]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n", os.Args[1])
+		usage()
+		return 2
+	}
+
+	log := logger.NewZerologLogger()
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Error(err, "Failed to load configuration")
+		return 1
+	}
+
+	// the in-memory store would start empty and be gone once the command exits
+	if cfg.Database.Driver == config.DriverMemory {
+		fmt.Fprintf(os.Stderr, "%s: the %s driver keeps no data between runs, configure postgres or sqlite\n",
+			os.Args[1], config.DriverMemory)
+		return 1
+	}
+
+	// the schema only changes through the migrate command
+	dbCfg := cfg.Database
+	dbCfg.MigrateOnStartup = false
+
+	ctx := context.Background()
+	storage, err := app.OpenStorage(ctx, dbCfg, log)
+	if err != nil {
+		log.Error(err, "Failed to open storage")
+		return 1
+	}
+	defer storage.Close()
+
+	err = cmd.run(ctx, &environment{cfg: cfg, log: log, storage: storage}, os.Args[2:])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", os.Args[1], err)
+		return 1
+	}
+
+	return 0
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: admin <command> [flags]")
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "commands:")
+
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "  %-12s %s\n", name, commands[name].description)
+	}
+}
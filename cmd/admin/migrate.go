@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+
+	"pull-request-review/config"
+	"pull-request-review/db/migrations"
+	"pull-request-review/internal/infrastructure/database"
+)
+
+func runMigrate(ctx context.Context, env *environment, args []string) error {
+	flags := flag.NewFlagSet("migrate", flag.ExitOnError)
+	path := flags.String("path", "", "directory with the migration files, the embedded ones by default")
+	steps := flags.Int("steps", 1, "number of migrations to roll back with down")
+	_ = flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		return errors.New("expected up, down or status")
+	}
+
+	if env.storage.SQLite != nil {
+		return runSQLiteMigrate(ctx, env.storage.SQLite, *path, flags.Arg(0))
+	}
+
+	var source fs.FS = migrations.FS
+	if *path != "" {
+		source = os.DirFS(*path)
+	}
+	migrator := database.NewMigrator(env.storage.Postgres, source, env.log)
+
+	switch flags.Arg(0) {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("applied %d migrations\n", applied)
+	case "down":
+		if *steps < 1 {
+			return errors.New("-steps must be positive")
+		}
+		rolledBack, err := migrator.Down(ctx, *steps)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("rolled back %d migrations\n", rolledBack)
+	case "status":
+		return printMigrationStatus(ctx, migrator)
+	default:
+		return fmt.Errorf("unknown migrate command %q, expected up, down or status", flags.Arg(0))
+	}
+
+	return nil
+}
+
+// runSQLiteMigrate applies the SQLite migrations. SQLiteDatabase only migrates up, so down and
+// status are refused rather than run against the Postgres schema.
+func runSQLiteMigrate(ctx context.Context, db *database.SQLiteDatabase, path, command string) error {
+	if command != "up" {
+		return fmt.Errorf("migrate %s is not supported for %s, only up is", command, config.DriverSQLite)
+	}
+
+	var source fs.FS = migrations.SQLiteFS
+	if path != "" {
+		source = os.DirFS(path)
+	}
+
+	applied, err := db.Migrate(ctx, source)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("applied %d migrations\n", applied)
+
+	return nil
+}
+
+func printMigrationStatus(ctx context.Context, migrator *database.Migrator) error {
+	statuses, dirty, err := migrator.Status(ctx)
+	if err != nil {
+		return err
+	}
+
+	pending := 0
+	for _, status := range statuses {
+		state := "applied"
+		if !status.Applied {
+			state = "pending"
+			pending++
+		}
+		fmt.Printf("%03d  %-8s %s\n", status.Version, state, status.Name)
+	}
+
+	fmt.Printf("%d pending migrations\n", pending)
+	if dirty {
+		fmt.Println("database is dirty: the last migration failed halfway and needs a manual fix")
+	}
+
+	return nil
+}
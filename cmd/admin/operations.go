@@ -0,0 +1,172 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"pull-request-review/internal/domain/model"
+)
+
+func runCreateTeam(ctx context.Context, env *environment, args []string) error {
+	flags := flag.NewFlagSet("create-team", flag.ExitOnError)
+	name := flags.String("name", "", "team name")
+	parent := flags.String("parent", "", "parent team name")
+	actor := flags.String("as", "", "user id to act as, needed to nest the team under a parent")
+	var members []model.User
+	flags.Func("member", "team member as user_id:username, can be repeated", func(value string) error {
+		member, err := parseMember(value)
+		if err != nil {
+			return err
+		}
+		members = append(members, member)
+		return nil
+	})
+	_ = flags.Parse(args)
+
+	if *name == "" {
+		return errors.New("-name is required")
+	}
+
+	ctx, err := withActor(ctx, *actor)
+	if err != nil {
+		return err
+	}
+
+	err = newServices(env).team.CreateTeamWithMembers(
+		ctx, *name, members, model.TeamCreateOptions{ParentTeamName: *parent},
+	)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("created team %s with %d members\n", *name, len(members))
+	return nil
+}
+
+func runSetActive(ctx context.Context, env *environment, args []string) error {
+	flags := flag.NewFlagSet("set-active", flag.ExitOnError)
+	userID := flags.String("user", "", "user id")
+	active := flags.Bool("active", true, "whether the user takes reviews")
+	_ = flags.Parse(args)
+
+	ID, err := parseUserID("-user", *userID)
+	if err != nil {
+		return err
+	}
+
+	user, err := newServices(env).user.SetActive(ctx, ID, *active)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("user %s (%s) is_active=%t\n", uuid.UUID(user.ID), user.Username, user.IsActive)
+	return nil
+}
+
+func runReassign(ctx context.Context, env *environment, args []string) error {
+	flags := flag.NewFlagSet("reassign", flag.ExitOnError)
+	prID := flags.String("pr", "", "pull request id")
+	reviewerID := flags.String("reviewer", "", "reviewer to replace")
+	replacementID := flags.String("with", "", "reviewer to replace with, a random one by default")
+	actor := flags.String("as", "", "user id to act as, the replaced reviewer by default")
+	_ = flags.Parse(args)
+
+	pullRequestID, err := uuid.Parse(*prID)
+	if err != nil {
+		return fmt.Errorf("invalid -pr: %w", err)
+	}
+	oldReviewerID, err := parseUserID("-reviewer", *reviewerID)
+	if err != nil {
+		return err
+	}
+	replacement := model.UserID(uuid.Nil)
+	if *replacementID != "" {
+		replacement, err = parseUserID("-with", *replacementID)
+		if err != nil {
+			return err
+		}
+	}
+
+	if *actor == "" {
+		*actor = *reviewerID
+	}
+	ctx, err = withActor(ctx, *actor)
+	if err != nil {
+		return err
+	}
+
+	pr, newReviewerID, err := newServices(env).pr.ReassignPullRequest(
+		ctx, model.PullRequestID(pullRequestID), oldReviewerID, replacement,
+	)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf(
+		"pull request %s (%s): %s replaced by %s\n",
+		uuid.UUID(pr.PullRequestID), pr.Name, uuid.UUID(oldReviewerID), uuid.UUID(newReviewerID),
+	)
+	return nil
+}
+
+func runMerge(ctx context.Context, env *environment, args []string) error {
+	flags := flag.NewFlagSet("merge", flag.ExitOnError)
+	prID := flags.String("pr", "", "pull request id")
+	_ = flags.Parse(args)
+
+	pullRequestID, err := uuid.Parse(*prID)
+	if err != nil {
+		return fmt.Errorf("invalid -pr: %w", err)
+	}
+
+	pr, err := newServices(env).pr.MergePullRequest(ctx, model.PullRequestID(pullRequestID))
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("pull request %s (%s) is %s\n", uuid.UUID(pr.PullRequestID), pr.Name, pr.Status)
+	return nil
+}
+
+func runStats(ctx context.Context, env *environment, args []string) error {
+	flags := flag.NewFlagSet("stats", flag.ExitOnError)
+	_ = flags.Parse(args)
+
+	stats, err := newServices(env).statistics.GetStatistics(ctx)
+	if err != nil {
+		return err
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(stats)
+}
+
+func parseUserID(flagName string, value string) (model.UserID, error) {
+	ID, err := uuid.Parse(value)
+	if err != nil {
+		return model.UserID(uuid.Nil), fmt.Errorf("invalid %s: %w", flagName, err)
+	}
+	return model.UserID(ID), nil
+}
+
+func parseMember(value string) (model.User, error) {
+	userID, username, ok := strings.Cut(value, ":")
+	if !ok || username == "" {
+		return model.User{}, errors.New("expected user_id:username")
+	}
+
+	ID, err := parseUserID("-member", userID)
+	if err != nil {
+		return model.User{}, err
+	}
+
+	return model.User{ID: ID, Username: username, IsActive: true}, nil
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"pull-request-review/internal/domain/auth"
+	"pull-request-review/internal/domain/model"
+	"pull-request-review/internal/service"
+)
+
+// services wires the service layer the same way internal/app does for the HTTP server.
+type services struct {
+	team       *service.TeamService
+	user       *service.UserService
+	pr         *service.PullRequestService
+	statistics *service.StatisticsService
+	imports    *service.ImportService
+	archive    *service.ArchiveService
+}
+
+func newServices(env *environment) *services {
+	repos := env.storage.Repositories
+	teamRepo := repos.Team
+	userRepo := repos.User
+	prRepo := repos.PullRequest
+	reviewAssignmentRepo := repos.ReviewAssignment
+	teamRoleRepo := repos.TeamRole
+	auditRepo := repos.MembershipAudit
+	membershipRepo := repos.TeamMembership
+	tx := repos.Tx
+
+	strategy, scoringWeights := env.cfg.Service.Strategy()
+	prService := service.NewPullRequestService(
+		prRepo,
+		userRepo,
+		reviewAssignmentRepo,
+		membershipRepo,
+		teamRepo,
+		teamRoleRepo,
+		tx,
+		env.log,
+		service.PullRequestServiceOptions{
+			MaxReviewers:   env.cfg.Service.MaxReviewersCount,
+			MaxBatchSize:   env.cfg.Service.MaxBatchSize,
+			RoutingRules:   env.cfg.Service.Rules(),
+			Strategy:       strategy,
+			ScoringWeights: scoringWeights,
+		},
+	)
+
+	return &services{
+		team: service.NewTeamService(
+			teamRepo, userRepo, membershipRepo, teamRoleRepo, auditRepo, prService, tx, env.log,
+		),
+		user: service.NewUserService(
+			userRepo, teamRepo, membershipRepo, teamRoleRepo, auditRepo, prService, tx, env.log,
+		),
+		pr:         prService,
+		statistics: service.NewStatisticsService(reviewAssignmentRepo, prRepo, env.log),
+		imports:    service.NewImportService(teamRepo, userRepo, membershipRepo, tx, env.log),
+		archive: service.NewArchiveService(
+			teamRepo, userRepo, membershipRepo, teamRoleRepo, prRepo, reviewAssignmentRepo, tx, env.log,
+		),
+	}
+}
+
+// withActor makes the operator act as the given user, for operations guarded by team roles.
+func withActor(ctx context.Context, actor string) (context.Context, error) {
+	if actor == "" {
+		return ctx, nil
+	}
+
+	actorID, err := uuid.Parse(actor)
+	if err != nil {
+		return nil, fmt.Errorf("invalid -as user id: %w", err)
+	}
+
+	return auth.WithActor(ctx, model.UserID(actorID)), nil
+}
@@ -7,7 +7,6 @@ import (
 	"pull-request-review/config"
 	"pull-request-review/internal/app"
 	"pull-request-review/internal/infrastructure/adapters/logger"
-	"pull-request-review/internal/infrastructure/database"
 )
 
 func main() {
@@ -24,13 +23,12 @@ func main() {
 		logger.F("port", cfg.Server.Port),
 	)
 
-	ctx := context.Background()
-	db := database.NewDatabase(cfg.Database, log)
-	if err := db.Connect(ctx); err != nil {
-		log.Error(err, "Failed to connect to database")
+	storage, err := app.OpenStorage(context.Background(), cfg.Database, log)
+	if err != nil {
+		log.Error(err, "Failed to open storage")
 		os.Exit(1)
 	}
-	defer db.Close()
+	defer storage.Close()
 
-	app.Run(cfg, db, log)
+	app.Run(cfg, storage, log)
 }
\ No newline at end of file
@@ -0,0 +1,9 @@
+CREATE TABLE team_roles (
+    team_id UUID NOT NULL REFERENCES teams(team_id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    role TEXT NOT NULL CHECK (role IN ('member', 'maintainer', 'admin')),
+    granted_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (team_id, user_id)
+);
+
+CREATE INDEX idx_team_roles_user_id ON team_roles(user_id);
@@ -0,0 +1,12 @@
+CREATE TABLE idempotency_keys (
+    idempotency_key TEXT NOT NULL,
+    route TEXT NOT NULL,
+    request_hash TEXT NOT NULL,
+    status_code INTEGER,
+    response_body BYTEA,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    completed_at TIMESTAMPTZ,
+    PRIMARY KEY (idempotency_key, route)
+);
+
+CREATE INDEX idx_idempotency_keys_created_at ON idempotency_keys(created_at);
@@ -0,0 +1,3 @@
+DELETE FROM users WHERE team_id IS NULL;
+
+ALTER TABLE users ALTER COLUMN team_id SET NOT NULL;
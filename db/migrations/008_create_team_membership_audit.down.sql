@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_team_membership_audit_user_id;
+
+DROP TABLE IF EXISTS team_membership_audit;
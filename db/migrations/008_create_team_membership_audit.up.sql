@@ -0,0 +1,11 @@
+CREATE TABLE team_membership_audit (
+    audit_id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    from_team_id UUID REFERENCES teams(team_id) ON DELETE SET NULL,
+    to_team_id UUID REFERENCES teams(team_id) ON DELETE SET NULL,
+    actor_id UUID,
+    reason TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_team_membership_audit_user_id ON team_membership_audit(user_id);
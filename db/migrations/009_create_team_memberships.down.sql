@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_team_memberships_user_id;
+
+DROP TABLE IF EXISTS team_memberships;
@@ -0,0 +1,14 @@
+CREATE TABLE team_memberships (
+    team_id UUID NOT NULL REFERENCES teams(team_id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    weight DOUBLE PRECISION NOT NULL DEFAULT 1 CHECK (weight > 0),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (team_id, user_id)
+);
+
+CREATE INDEX idx_team_memberships_user_id ON team_memberships(user_id);
+
+INSERT INTO team_memberships (team_id, user_id, created_at)
+SELECT team_id, user_id, created_at
+FROM users
+WHERE team_id IS NOT NULL;
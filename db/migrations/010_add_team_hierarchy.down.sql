@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_teams_parent_team_id;
+
+ALTER TABLE teams DROP COLUMN IF EXISTS reviewer_fallback;
+ALTER TABLE teams DROP COLUMN IF EXISTS max_reviewers;
+ALTER TABLE teams DROP COLUMN IF EXISTS parent_team_id;
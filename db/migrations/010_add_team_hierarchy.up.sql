@@ -0,0 +1,5 @@
+ALTER TABLE teams ADD COLUMN parent_team_id UUID REFERENCES teams(team_id) ON DELETE SET NULL;
+ALTER TABLE teams ADD COLUMN max_reviewers INT CHECK (max_reviewers > 0);
+ALTER TABLE teams ADD COLUMN reviewer_fallback BOOLEAN;
+
+CREATE INDEX idx_teams_parent_team_id ON teams(parent_team_id);
@@ -0,0 +1,3 @@
+-- version is bumped by every change to the pull request or its reviewers, concurrent changes
+-- that started from the same version lose the race and are retried
+ALTER TABLE pull_requests ADD COLUMN version BIGINT NOT NULL DEFAULT 1;
@@ -0,0 +1,11 @@
+-- a reviewer may decline the same pull request more than once when they are assigned again,
+-- so declines are a log rather than keyed by pull request and reviewer
+CREATE TABLE review_declines (
+    pull_request_id UUID NOT NULL REFERENCES pull_requests(pull_request_id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    reason TEXT NOT NULL,
+    declined_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_review_declines_pull_request_id ON review_declines(pull_request_id);
+CREATE INDEX idx_review_declines_user_id ON review_declines(user_id);
@@ -0,0 +1,12 @@
+-- enum values cannot be dropped, the type is recreated without DRAFT
+UPDATE pull_requests SET status = 'OPEN' WHERE status = 'DRAFT';
+
+ALTER TYPE pull_request_status RENAME TO pull_request_status_old;
+CREATE TYPE pull_request_status AS ENUM ('OPEN', 'MERGED');
+
+ALTER TABLE pull_requests ALTER COLUMN status DROP DEFAULT;
+ALTER TABLE pull_requests
+    ALTER COLUMN status TYPE pull_request_status USING status::text::pull_request_status;
+ALTER TABLE pull_requests ALTER COLUMN status SET DEFAULT 'OPEN';
+
+DROP TYPE pull_request_status_old;
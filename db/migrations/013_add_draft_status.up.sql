@@ -0,0 +1,2 @@
+-- adding an enum value inside a transaction needs PostgreSQL 12 or later
+ALTER TYPE pull_request_status ADD VALUE IF NOT EXISTS 'DRAFT' BEFORE 'OPEN';
@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS idx_pull_requests_labels;
+DROP INDEX IF EXISTS idx_pull_requests_repository;
+
+ALTER TABLE pull_requests DROP COLUMN IF EXISTS labels;
+ALTER TABLE pull_requests DROP COLUMN IF EXISTS description;
+ALTER TABLE pull_requests DROP COLUMN IF EXISTS url;
+ALTER TABLE pull_requests DROP COLUMN IF EXISTS target_branch;
+ALTER TABLE pull_requests DROP COLUMN IF EXISTS source_branch;
+ALTER TABLE pull_requests DROP COLUMN IF EXISTS repository;
@@ -0,0 +1,10 @@
+ALTER TABLE pull_requests ADD COLUMN repository TEXT NOT NULL DEFAULT '';
+ALTER TABLE pull_requests ADD COLUMN source_branch TEXT NOT NULL DEFAULT '';
+ALTER TABLE pull_requests ADD COLUMN target_branch TEXT NOT NULL DEFAULT '';
+ALTER TABLE pull_requests ADD COLUMN url TEXT NOT NULL DEFAULT '';
+ALTER TABLE pull_requests ADD COLUMN description TEXT NOT NULL DEFAULT '';
+ALTER TABLE pull_requests ADD COLUMN labels TEXT[] NOT NULL DEFAULT '{}';
+
+-- reviews are listed by repository and label
+CREATE INDEX idx_pull_requests_repository ON pull_requests(repository);
+CREATE INDEX idx_pull_requests_labels ON pull_requests USING GIN (labels);
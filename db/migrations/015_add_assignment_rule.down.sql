@@ -0,0 +1 @@
+ALTER TABLE review_assignments DROP COLUMN IF EXISTS rule;
@@ -0,0 +1,2 @@
+-- kept so that a draft is routed by the paths it was created with once it is marked ready
+ALTER TABLE pull_requests ADD COLUMN changed_paths TEXT[] NOT NULL DEFAULT '{}';
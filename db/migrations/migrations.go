@@ -0,0 +1,16 @@
+// Package migrations embeds the SQL schema migrations, so the binaries can apply them without the source tree.
+package migrations
+
+import (
+	"embed"
+	"io/fs"
+)
+
+//go:embed *.sql
+var FS embed.FS
+
+//go:embed sqlite/*.sql
+var sqliteFS embed.FS
+
+// SQLiteFS holds the same schema translated for SQLite.
+var SQLiteFS, _ = fs.Sub(sqliteFS, "sqlite")
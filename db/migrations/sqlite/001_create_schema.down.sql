@@ -0,0 +1,8 @@
+DROP TABLE IF EXISTS team_memberships;
+DROP TABLE IF EXISTS team_membership_audit;
+DROP TABLE IF EXISTS idempotency_keys;
+DROP TABLE IF EXISTS team_roles;
+DROP TABLE IF EXISTS review_assignments;
+DROP TABLE IF EXISTS pull_requests;
+DROP TABLE IF EXISTS users;
+DROP TABLE IF EXISTS teams;
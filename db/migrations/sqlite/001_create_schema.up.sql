@@ -0,0 +1,89 @@
+CREATE TABLE teams (
+    team_id TEXT PRIMARY KEY,
+    name TEXT NOT NULL,
+    parent_team_id TEXT REFERENCES teams(team_id) ON DELETE SET NULL,
+    max_reviewers INTEGER CHECK (max_reviewers > 0),
+    reviewer_fallback BOOLEAN,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_teams_parent_team_id ON teams(parent_team_id);
+
+CREATE TABLE users (
+    user_id TEXT PRIMARY KEY,
+    username TEXT NOT NULL,
+    team_id TEXT REFERENCES teams(team_id) ON DELETE CASCADE,
+    is_active BOOLEAN NOT NULL DEFAULT 1,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_users_team_id ON users(team_id);
+CREATE INDEX idx_users_is_active ON users(is_active);
+
+-- SQLite has no enum types, the CHECK keeps the same set of statuses as pull_request_status
+CREATE TABLE pull_requests (
+    pull_request_id TEXT PRIMARY KEY,
+    name TEXT NOT NULL,
+    author_id TEXT REFERENCES users(user_id) ON DELETE CASCADE,
+    status TEXT NOT NULL DEFAULT 'OPEN' CHECK (status IN ('OPEN', 'MERGED')),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    merged_at TIMESTAMP
+);
+
+CREATE INDEX idx_pull_requests_author_id ON pull_requests(author_id);
+CREATE INDEX idx_pull_requests_status ON pull_requests(status);
+
+CREATE TABLE review_assignments (
+    pull_request_id TEXT NOT NULL REFERENCES pull_requests(pull_request_id) ON DELETE CASCADE,
+    user_id TEXT NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    assigned_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (pull_request_id, user_id)
+);
+
+CREATE INDEX idx_reviewer_assignments_user_id ON review_assignments(user_id);
+
+CREATE TABLE team_roles (
+    team_id TEXT NOT NULL REFERENCES teams(team_id) ON DELETE CASCADE,
+    user_id TEXT NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    role TEXT NOT NULL CHECK (role IN ('member', 'maintainer', 'admin')),
+    granted_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (team_id, user_id)
+);
+
+CREATE INDEX idx_team_roles_user_id ON team_roles(user_id);
+
+CREATE TABLE idempotency_keys (
+    idempotency_key TEXT NOT NULL,
+    route TEXT NOT NULL,
+    request_hash TEXT NOT NULL,
+    status_code INTEGER,
+    response_body BLOB,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    completed_at TIMESTAMP,
+    PRIMARY KEY (idempotency_key, route)
+);
+
+CREATE INDEX idx_idempotency_keys_created_at ON idempotency_keys(created_at);
+
+CREATE TABLE team_membership_audit (
+    audit_id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    from_team_id TEXT REFERENCES teams(team_id) ON DELETE SET NULL,
+    to_team_id TEXT REFERENCES teams(team_id) ON DELETE SET NULL,
+    actor_id TEXT,
+    reason TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_team_membership_audit_user_id ON team_membership_audit(user_id);
+
+CREATE TABLE team_memberships (
+    team_id TEXT NOT NULL REFERENCES teams(team_id) ON DELETE CASCADE,
+    user_id TEXT NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    weight REAL NOT NULL DEFAULT 1 CHECK (weight > 0),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (team_id, user_id)
+);
+
+CREATE INDEX idx_team_memberships_user_id ON team_memberships(user_id);
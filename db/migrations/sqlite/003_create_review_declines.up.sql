@@ -0,0 +1,9 @@
+CREATE TABLE review_declines (
+    pull_request_id TEXT NOT NULL REFERENCES pull_requests(pull_request_id) ON DELETE CASCADE,
+    user_id TEXT NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    reason TEXT NOT NULL,
+    declined_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_review_declines_pull_request_id ON review_declines(pull_request_id);
+CREATE INDEX idx_review_declines_user_id ON review_declines(user_id);
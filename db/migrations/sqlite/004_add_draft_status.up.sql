@@ -0,0 +1,21 @@
+-- SQLite cannot change a CHECK constraint, so pull_requests is rebuilt with DRAFT allowed.
+-- The migrator turns foreign keys off meanwhile, dropping the old table must not cascade.
+CREATE TABLE pull_requests_new (
+    pull_request_id TEXT PRIMARY KEY,
+    name TEXT NOT NULL,
+    author_id TEXT REFERENCES users(user_id) ON DELETE CASCADE,
+    status TEXT NOT NULL DEFAULT 'OPEN' CHECK (status IN ('DRAFT', 'OPEN', 'MERGED')),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    merged_at TIMESTAMP,
+    version INTEGER NOT NULL DEFAULT 1
+);
+
+INSERT INTO pull_requests_new (pull_request_id, name, author_id, status, created_at, merged_at, version)
+SELECT pull_request_id, name, author_id, status, created_at, merged_at, version
+FROM pull_requests;
+
+DROP TABLE pull_requests;
+ALTER TABLE pull_requests_new RENAME TO pull_requests;
+
+CREATE INDEX idx_pull_requests_author_id ON pull_requests(author_id);
+CREATE INDEX idx_pull_requests_status ON pull_requests(status);
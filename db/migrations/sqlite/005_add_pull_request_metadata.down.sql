@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_pull_requests_repository;
+
+ALTER TABLE pull_requests DROP COLUMN labels;
+ALTER TABLE pull_requests DROP COLUMN description;
+ALTER TABLE pull_requests DROP COLUMN url;
+ALTER TABLE pull_requests DROP COLUMN target_branch;
+ALTER TABLE pull_requests DROP COLUMN source_branch;
+ALTER TABLE pull_requests DROP COLUMN repository;
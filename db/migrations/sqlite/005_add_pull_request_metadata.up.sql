@@ -0,0 +1,9 @@
+ALTER TABLE pull_requests ADD COLUMN repository TEXT NOT NULL DEFAULT '';
+ALTER TABLE pull_requests ADD COLUMN source_branch TEXT NOT NULL DEFAULT '';
+ALTER TABLE pull_requests ADD COLUMN target_branch TEXT NOT NULL DEFAULT '';
+ALTER TABLE pull_requests ADD COLUMN url TEXT NOT NULL DEFAULT '';
+ALTER TABLE pull_requests ADD COLUMN description TEXT NOT NULL DEFAULT '';
+-- SQLite has no arrays, labels are kept as a JSON array of strings
+ALTER TABLE pull_requests ADD COLUMN labels TEXT NOT NULL DEFAULT '[]';
+
+CREATE INDEX idx_pull_requests_repository ON pull_requests(repository);
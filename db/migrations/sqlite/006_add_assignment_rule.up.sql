@@ -0,0 +1,2 @@
+-- the routing rule that picked the reviewer, empty when the reviewer came from the author's team
+ALTER TABLE review_assignments ADD COLUMN rule TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,2 @@
+-- a JSON array of strings like labels, kept so that drafts are routed by them once marked ready
+ALTER TABLE pull_requests ADD COLUMN changed_paths TEXT NOT NULL DEFAULT '[]';
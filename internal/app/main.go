@@ -1,14 +1,15 @@
 package app
 
 import (
+	"context"
+
 	"pull-request-review/config"
 	"pull-request-review/internal/delivery/http/handlers"
 	"pull-request-review/internal/infrastructure/adapters/logger"
 	"pull-request-review/internal/infrastructure/adapters/router"
-	"pull-request-review/internal/infrastructure/database"
+	"pull-request-review/internal/infrastructure/http/middleware"
 	"pull-request-review/internal/infrastructure/http/route"
 	"pull-request-review/internal/infrastructure/http/server"
-	"pull-request-review/internal/infrastructure/repository"
 	"pull-request-review/internal/service"
 )
 
@@ -16,37 +17,68 @@ type Application struct {
 	router router.Router
 }
 
-func Run(cfg *config.Config, db *database.Database, appLogger logger.Logger) {
-	app := initializeApp(db, cfg, appLogger)
+func Run(cfg *config.Config, storage *Storage, appLogger logger.Logger) {
+	app := initializeApp(storage, cfg, appLogger)
 
 	srv := server.NewServer(app.router, cfg.Server, appLogger)
 
+	purgeCtx, stopPurge := context.WithCancel(context.Background())
+	defer stopPurge()
+	go middleware.PurgeIdempotencyKeys(
+		purgeCtx, storage.Repositories.Idempotency,
+		middleware.IdempotencyOptions{KeyTTL: cfg.Idempotency.KeyTTL, LockTimeout: cfg.Idempotency.LockTimeout},
+		cfg.Idempotency.PurgeInterval, appLogger,
+	)
+
 	srv.Start()
 	srv.WaitForShutdown()
 }
 
-func initializeApp(db *database.Database, cfg *config.Config, appLogger logger.Logger) *Application {
-	teamRepo := repository.NewTeamRepository(db)
-	userRepo := repository.NewUserRepository(db)
-	prRepo := repository.NewPullRequestRepositoryPgx(db)
-	reviewAssignmentRepo := repository.NewReviewAssignmentRepository(db)
+func initializeApp(storage *Storage, cfg *config.Config, appLogger logger.Logger) *Application {
+	repos := storage.Repositories
+	teamRepo := repos.Team
+	userRepo := repos.User
+	prRepo := repos.PullRequest
+	reviewAssignmentRepo := repos.ReviewAssignment
+	teamRoleRepo := repos.TeamRole
+	idempotencyRepo := repos.Idempotency
+	auditRepo := repos.MembershipAudit
+	membershipRepo := repos.TeamMembership
+	tx := repos.Tx
 
-	teamService := service.NewTeamService(teamRepo, userRepo, appLogger)
-	userService := service.NewUserService(userRepo, teamRepo, appLogger)
+	strategy, scoringWeights := cfg.Service.Strategy()
 	prService := service.NewPullRequestService(
 		prRepo,
 		userRepo,
 		reviewAssignmentRepo,
+		membershipRepo,
+		teamRepo,
+		teamRoleRepo,
+		tx,
 		appLogger,
-		cfg.Service.MaxReviewersCount,
+		service.PullRequestServiceOptions{
+			MaxReviewers:   cfg.Service.MaxReviewersCount,
+			MaxBatchSize:   cfg.Service.MaxBatchSize,
+			RoutingRules:   cfg.Service.Rules(),
+			Strategy:       strategy,
+			ScoringWeights: scoringWeights,
+		},
+	)
+	teamService := service.NewTeamService(
+		teamRepo, userRepo, membershipRepo, teamRoleRepo, auditRepo, prService, tx, appLogger,
+	)
+	userService := service.NewUserService(
+		userRepo, teamRepo, membershipRepo, teamRoleRepo, auditRepo, prService, tx, appLogger,
 	)
 	statisticsService := service.NewStatisticsService(reviewAssignmentRepo, prRepo, appLogger)
+	importService := service.NewImportService(teamRepo, userRepo, membershipRepo, tx, appLogger)
 
 	teamHandler := handlers.NewTeamHandler(teamService)
 	userHandler := handlers.NewUserHandler(userService, prService)
 	prHandler := handlers.NewPullRequestHandler(prService)
-	healthHandler := handlers.NewHealthHandler(db)
+	healthHandler := handlers.NewHealthHandler(storage.Health)
 	statsHandler := handlers.NewStatisticsHandler(statisticsService)
+	adminHandler := handlers.NewAdminHandler(importService)
 
 	r := router.NewGinRouter()
 	route.SetupRoutes(
@@ -56,9 +88,11 @@ func initializeApp(db *database.Database, cfg *config.Config, appLogger logger.L
 			PullRequestHandler: prHandler,
 			StatisticsHandler:  statsHandler,
 			HealthHandler:      healthHandler,
+			AdminHandler:       adminHandler,
 		},
+		idempotencyRepo,
 		appLogger,
-		cfg.Server.RequestTimeout,
+		cfg,
 	)
 
 	return &Application{
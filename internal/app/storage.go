@@ -0,0 +1,92 @@
+package app
+
+import (
+	"context"
+	"fmt"
+
+	"pull-request-review/config"
+	"pull-request-review/db/migrations"
+	"pull-request-review/internal/delivery/http/handlers"
+	"pull-request-review/internal/domain/ports/repository"
+	"pull-request-review/internal/infrastructure/adapters/logger"
+	"pull-request-review/internal/infrastructure/database"
+	pgxrepository "pull-request-review/internal/infrastructure/repository"
+	"pull-request-review/internal/infrastructure/repository/memory"
+	"pull-request-review/internal/infrastructure/repository/sqlite"
+)
+
+// Storage is the repository backend selected by DatabaseConfig.Driver.
+type Storage struct {
+	Repositories *repository.Repositories
+	Health       handlers.Pinger
+	// Postgres and SQLite hold the connection of the selected driver and are nil for the
+	// others. The admin CLI manages the schema through them.
+	Postgres *database.Database
+	SQLite   *database.SQLiteDatabase
+	close    func()
+}
+
+// OpenStorage connects the configured backend. For Postgres and SQLite it also applies pending
+// migrations when MigrateOnStartup is set.
+func OpenStorage(ctx context.Context, cfg config.DatabaseConfig, log logger.Logger) (*Storage, error) {
+	switch cfg.Driver {
+	case config.DriverMemory:
+		log.Warn("Using the in-memory storage, data is lost on restart")
+		store := memory.NewStore()
+		return &Storage{
+			Repositories: memory.NewRepositories(store),
+			Health:       store,
+			close:        func() {},
+		}, nil
+	case config.DriverPostgres, "":
+		db := database.NewDatabase(cfg, log)
+		if err := db.Connect(ctx); err != nil {
+			return nil, err
+		}
+
+		if cfg.MigrateOnStartup {
+			migrator := database.NewMigrator(db, migrations.FS, log)
+			if _, err := migrator.Up(ctx); err != nil {
+				db.Close()
+				return nil, fmt.Errorf("failed to apply migrations: %w", err)
+			}
+		}
+
+		return NewPostgresStorage(db), nil
+	case config.DriverSQLite:
+		db := database.NewSQLiteDatabase(cfg, log)
+		if err := db.Connect(ctx); err != nil {
+			return nil, err
+		}
+
+		if cfg.MigrateOnStartup {
+			if _, err := db.Migrate(ctx, migrations.SQLiteFS); err != nil {
+				db.Close()
+				return nil, fmt.Errorf("failed to apply migrations: %w", err)
+			}
+		}
+
+		return &Storage{
+			Repositories: sqlite.NewRepositories(db),
+			Health:       db,
+			SQLite:       db,
+			close:        db.Close,
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown database driver %q", cfg.Driver)
+	}
+}
+
+// NewPostgresStorage wraps an already connected database.
+func NewPostgresStorage(db *database.Database) *Storage {
+	return &Storage{
+		Repositories: pgxrepository.NewRepositories(db),
+		Health:       db,
+		Postgres:     db,
+		close:        db.Close,
+	}
+}
+
+func (s *Storage) Close() {
+	s.close()
+}
@@ -3,4 +3,10 @@ package dto
 type ErrorDetail struct {
 	Code    string `json:"code"`
 	Message string `json:"message"`
+	Details any    `json:"details,omitempty"`
+}
+
+type MemberConflictDTO struct {
+	UserID   string `json:"user_id"`
+	TeamName string `json:"team_name"`
 }
\ No newline at end of file
@@ -0,0 +1,60 @@
+package dto
+
+import "pull-request-review/internal/domain/model"
+
+type ImportChangeDTO struct {
+	Action  string   `json:"action"`
+	Subject string   `json:"subject"`
+	Key     string   `json:"key"`
+	Details []string `json:"details,omitempty"`
+}
+
+type ImportSummaryDTO struct {
+	TeamsCreated       int `json:"teams_created"`
+	TeamsUpdated       int `json:"teams_updated"`
+	UsersCreated       int `json:"users_created"`
+	UsersUpdated       int `json:"users_updated"`
+	MembershipsCreated int `json:"memberships_created"`
+	MembershipsUpdated int `json:"memberships_updated"`
+}
+
+func ImportChangesToDTOs(changes []model.ImportChange) []ImportChangeDTO {
+	dtos := make([]ImportChangeDTO, len(changes))
+	for i, change := range changes {
+		dtos[i] = ImportChangeDTO{
+			Action:  string(change.Action),
+			Subject: string(change.Subject),
+			Key:     change.Key,
+			Details: change.Details,
+		}
+	}
+	return dtos
+}
+
+func ImportPlanToSummary(plan *model.ImportPlan) ImportSummaryDTO {
+	var summary ImportSummaryDTO
+	for _, change := range plan.Changes {
+		created := change.Action == model.ImportActionCreate
+		switch change.Subject {
+		case model.ImportSubjectTeam:
+			if created {
+				summary.TeamsCreated++
+			} else {
+				summary.TeamsUpdated++
+			}
+		case model.ImportSubjectUser:
+			if created {
+				summary.UsersCreated++
+			} else {
+				summary.UsersUpdated++
+			}
+		case model.ImportSubjectMembership:
+			if created {
+				summary.MembershipsCreated++
+			} else {
+				summary.MembershipsUpdated++
+			}
+		}
+	}
+	return summary
+}
@@ -14,22 +14,46 @@ type PullRequestDTO struct {
 	AssignedReviewers []string `json:"assigned_reviewers"`
 	CreatedAt         *string  `json:"createdAt,omitempty"`
 	MergedAt          *string  `json:"mergedAt,omitempty"`
+	PullRequestMetadataDTO
+	// RoutedBy maps the reviewers picked by a routing rule to the name of the rule.
+	RoutedBy map[string]string `json:"routed_by,omitempty"`
+}
+
+// PullRequestMetadataDTO is the descriptive part of a pull request shared by requests and responses.
+type PullRequestMetadataDTO struct {
+	Repository   string   `json:"repository,omitempty"`
+	SourceBranch string   `json:"source_branch,omitempty"`
+	TargetBranch string   `json:"target_branch,omitempty"`
+	URL          string   `json:"url,omitempty"`
+	Description  string   `json:"description,omitempty"`
+	Labels       []string `json:"labels,omitempty"`
+}
+
+// BatchCreateItemDTO carries the created pull request or the error that kept it from being created.
+type BatchCreateItemDTO struct {
+	PullRequestID string          `json:"pull_request_id"`
+	Created       bool            `json:"created"`
+	PullRequest   *PullRequestDTO `json:"pr,omitempty"`
+	Error         *ErrorDetail    `json:"error,omitempty"`
 }
 
 type PullRequestShortDTO struct {
-	PullRequestID   string `json:"pull_request_id"`
-	PullRequestName string `json:"pull_request_name"`
-	AuthorID        string `json:"author_id"`
-	Status          string `json:"status"`
+	PullRequestID   string   `json:"pull_request_id"`
+	PullRequestName string   `json:"pull_request_name"`
+	AuthorID        string   `json:"author_id"`
+	Status          string   `json:"status"`
+	Repository      string   `json:"repository,omitempty"`
+	Labels          []string `json:"labels,omitempty"`
 }
 
 func PullRequestToDTO(pr *model.PullRequest, reviewerIDs []string) PullRequestDTO {
 	dto := PullRequestDTO{
-		PullRequestID:     uuid.UUID(pr.PullRequestID).String(),
-		PullRequestName:   pr.Name,
-		AuthorID:          uuid.UUID(pr.AuthorID).String(),
-		Status:            string(pr.Status),
-		AssignedReviewers: reviewerIDs,
+		PullRequestID:          uuid.UUID(pr.PullRequestID).String(),
+		PullRequestName:        pr.Name,
+		AuthorID:               uuid.UUID(pr.AuthorID).String(),
+		Status:                 string(pr.Status),
+		AssignedReviewers:      reviewerIDs,
+		PullRequestMetadataDTO: PullRequestMetadataToDTO(pr.PullRequestMetadata),
 	}
 
 	if !pr.CreatedAt.IsZero() {
@@ -45,6 +69,17 @@ func PullRequestToDTO(pr *model.PullRequest, reviewerIDs []string) PullRequestDT
 	return dto
 }
 
+// AssignmentsToRoutedBy maps the reviewers of the assignments made by a routing rule to the rule.
+func AssignmentsToRoutedBy(assignments []model.ReviewAssignment) map[string]string {
+	routedBy := make(map[string]string)
+	for _, assignment := range assignments {
+		if assignment.Rule != "" {
+			routedBy[uuid.UUID(assignment.ReviewerID).String()] = assignment.Rule
+		}
+	}
+	return routedBy
+}
+
 func PullRequestsToShortDTOs(prs []model.PullRequest) []PullRequestShortDTO {
 	dtos := make([]PullRequestShortDTO, len(prs))
 	for i, pr := range prs {
@@ -59,5 +94,63 @@ func PullRequestToShortDTO(pr *model.PullRequest) PullRequestShortDTO {
 		PullRequestName: pr.Name,
 		AuthorID:        uuid.UUID(pr.AuthorID).String(),
 		Status:          string(pr.Status),
+		Repository:      pr.Repository,
+		Labels:          pr.Labels,
+	}
+}
+
+func PullRequestMetadataToDTO(metadata model.PullRequestMetadata) PullRequestMetadataDTO {
+	return PullRequestMetadataDTO{
+		Repository:   metadata.Repository,
+		SourceBranch: metadata.SourceBranch,
+		TargetBranch: metadata.TargetBranch,
+		URL:          metadata.URL,
+		Description:  metadata.Description,
+		Labels:       metadata.Labels,
 	}
+}
+
+func PullRequestMetadataFromDTO(metadata PullRequestMetadataDTO) model.PullRequestMetadata {
+	return model.PullRequestMetadata{
+		Repository:   metadata.Repository,
+		SourceBranch: metadata.SourceBranch,
+		TargetBranch: metadata.TargetBranch,
+		URL:          metadata.URL,
+		Description:  metadata.Description,
+		Labels:       metadata.Labels,
+	}
+}
+
+// ReviewerSuggestionDTO is a candidate reviewer with the parts of its score and why they are what they are.
+type ReviewerSuggestionDTO struct {
+	UserID          string   `json:"user_id"`
+	Username        string   `json:"username"`
+	Score           float64  `json:"score"`
+	Expertise       float64  `json:"expertise"`
+	Availability    float64  `json:"availability"`
+	ReviewsOfAuthor int      `json:"reviews_of_author"`
+	LastReviewedAt  *string  `json:"last_reviewed_at,omitempty"`
+	OpenReviews     int      `json:"open_reviews"`
+	Reasons         []string `json:"reasons"`
+}
+
+func ReviewerSuggestionsToDTOs(suggestions []model.ReviewerSuggestion) []ReviewerSuggestionDTO {
+	dtos := make([]ReviewerSuggestionDTO, len(suggestions))
+	for i, suggestion := range suggestions {
+		dtos[i] = ReviewerSuggestionDTO{
+			UserID:          uuid.UUID(suggestion.User.ID).String(),
+			Username:        suggestion.User.Username,
+			Score:           suggestion.Score,
+			Expertise:       suggestion.Expertise,
+			Availability:    suggestion.Availability,
+			ReviewsOfAuthor: suggestion.History.Reviews,
+			OpenReviews:     suggestion.OpenReviews,
+			Reasons:         suggestion.Reasons,
+		}
+		if !suggestion.History.LastReviewedAt.IsZero() {
+			lastReviewedAt := suggestion.History.LastReviewedAt.Format(time.RFC3339)
+			dtos[i].LastReviewedAt = &lastReviewedAt
+		}
+	}
+	return dtos
 }
\ No newline at end of file
@@ -1,14 +1,55 @@
 package dto
 
 type TeamRequest struct {
-	TeamName string       `json:"team_name"`
-	Members  []TeamMember `json:"members"`
+	TeamName       string       `json:"team_name"`
+	ParentTeamName string       `json:"parent_team_name"`
+	Members        []TeamMember `json:"members"`
+	Mode           string       `json:"mode"`
+	ReviewPolicy   string       `json:"review_policy"`
 }
 
 type TeamMember struct {
-	UserID   string `json:"user_id"`
-	Username string `json:"username"`
-	IsActive bool   `json:"is_active"`
+	UserID   string  `json:"user_id"`
+	Username string  `json:"username"`
+	IsActive bool    `json:"is_active"`
+	Weight   float64 `json:"weight,omitempty"`
+}
+
+type AddMemberRequest struct {
+	TeamName string  `json:"team_name"`
+	UserID   string  `json:"user_id"`
+	Username string  `json:"username"`
+	IsActive bool    `json:"is_active"`
+	Weight   float64 `json:"weight,omitempty"`
+}
+
+type RemoveMemberRequest struct {
+	TeamName     string `json:"team_name"`
+	UserID       string `json:"user_id"`
+	ReviewPolicy string `json:"review_policy"`
+}
+
+type RenameTeamRequest struct {
+	TeamName    string `json:"team_name"`
+	NewTeamName string `json:"new_team_name"`
+}
+
+type SetParentTeamRequest struct {
+	TeamName       string `json:"team_name"`
+	ParentTeamName string `json:"parent_team_name"`
+}
+
+type UpdateTeamSettingsRequest struct {
+	TeamName         string `json:"team_name"`
+	MaxReviewers     *int   `json:"max_reviewers"`
+	ReviewerFallback *bool  `json:"reviewer_fallback"`
+}
+
+type MoveTeamRequest struct {
+	UserID       string `json:"user_id"`
+	FromTeamName string `json:"from_team_name"`
+	TeamName     string `json:"team_name"`
+	ReviewPolicy string `json:"review_policy"`
 }
 
 type SetActiveRequest struct {
@@ -20,13 +61,57 @@ type CreatePRRequest struct {
 	PullRequestID   string `json:"pull_request_id"`
 	PullRequestName string `json:"pull_request_name"`
 	AuthorID        string `json:"author_id"`
+	Draft           bool   `json:"draft,omitempty"`
+	PullRequestMetadataDTO
+	// ChangedPaths route the pull request to reviewers together with its labels, a draft's once it is ready.
+	ChangedPaths []string `json:"changed_paths,omitempty"`
+}
+
+type BatchCreatePRRequest struct {
+	PullRequests []CreatePRRequest `json:"pull_requests"`
 }
 
 type MergePRRequest struct {
 	PullRequestID string `json:"pull_request_id"`
 }
 
+// UpdatePRRequest replaces the metadata of a pull request; omitted fields are cleared.
+type UpdatePRRequest struct {
+	PullRequestID string `json:"pull_request_id"`
+	PullRequestMetadataDTO
+}
+
+type MarkReadyRequest struct {
+	PullRequestID string `json:"pull_request_id"`
+}
+
+type ReviewerRequest struct {
+	PullRequestID string `json:"pull_request_id"`
+	UserID        string `json:"user_id"`
+}
+
 type ReassignRequest struct {
 	PullRequestID string `json:"pull_request_id"`
 	OldUserID     string `json:"old_user_id"`
-}
\ No newline at end of file
+	NewUserID     string `json:"new_user_id,omitempty"`
+}
+
+type DeclineRequest struct {
+	PullRequestID string `json:"pull_request_id"`
+	Reason        string `json:"reason"`
+}
+
+type DeactivateTeamRequest struct {
+	TeamName string `json:"team_name"`
+}
+
+type GrantRoleRequest struct {
+	TeamName string `json:"team_name"`
+	UserID   string `json:"user_id"`
+	Role     string `json:"role"`
+}
+
+type RevokeRoleRequest struct {
+	TeamName string `json:"team_name"`
+	UserID   string `json:"user_id"`
+}
@@ -4,6 +4,10 @@ type TeamResponse struct {
 	Team TeamDTO `json:"team"`
 }
 
+type TeamTreeResponse struct {
+	Teams []TeamNodeDTO `json:"teams"`
+}
+
 type UserResponse struct {
 	User UserDTO `json:"user"`
 }
@@ -12,16 +16,48 @@ type PullRequestResponse struct {
 	PullRequest PullRequestDTO `json:"pr"`
 }
 
+// BatchCreateResponse lists the outcome of every pull request of the batch in request order.
+type BatchCreateResponse struct {
+	Created int                  `json:"created"`
+	Failed  int                  `json:"failed"`
+	Results []BatchCreateItemDTO `json:"results"`
+}
+
 type ReassignResponse struct {
 	PullRequest PullRequestDTO `json:"pr"`
 	ReplacedBy  string         `json:"replaced_by"`
 }
 
+// DeclineResponse leaves out replaced_by when nobody could take the declined review over.
+type DeclineResponse struct {
+	PullRequest PullRequestDTO `json:"pr"`
+	ReplacedBy  string         `json:"replaced_by,omitempty"`
+}
+
+// SuggestReviewersResponse lists the candidates best first.
+type SuggestReviewersResponse struct {
+	PullRequestID string                  `json:"pull_request_id"`
+	Candidates    []ReviewerSuggestionDTO `json:"candidates"`
+}
+
 type UserReviewsResponse struct {
 	UserID       string                `json:"user_id"`
 	PullRequests []PullRequestShortDTO `json:"pull_requests"`
 }
 
+type TeamRoleResponse struct {
+	TeamName string `json:"team_name"`
+	UserID   string `json:"user_id"`
+	Role     string `json:"role"`
+}
+
+type ImportResponse struct {
+	DryRun  bool              `json:"dry_run"`
+	Applied bool              `json:"applied"`
+	Summary ImportSummaryDTO  `json:"summary"`
+	Changes []ImportChangeDTO `json:"changes"`
+}
+
 type ErrorResponse struct {
 	Error ErrorDetail `json:"error"`
 }
\ No newline at end of file
@@ -62,4 +62,71 @@ func TeamMembersToUsers(members []TeamMember) ([]model.User, error) {
 		users[i] = user
 	}
 	return users, nil
-}
\ No newline at end of file
+}
+
+func TeamMembersToWeights(members []TeamMember) (map[model.UserID]float64, error) {
+	weights := make(map[model.UserID]float64)
+	for _, member := range members {
+		if member.Weight == 0 {
+			continue
+		}
+
+		userUUID, err := uuid.Parse(member.UserID)
+		if err != nil {
+			return nil, err
+		}
+		weights[model.UserID(userUUID)] = member.Weight
+	}
+	return weights, nil
+}
+
+// ReviewPolicyFromString falls back to reassigning open reviews when no policy was requested.
+func ReviewPolicyFromString(policy string) model.ReviewPolicy {
+	if policy == "" {
+		return model.ReviewPolicyReassign
+	}
+	return model.ReviewPolicy(policy)
+}
+
+func MemberConflictsToDTOs(conflicts []model.MemberConflict) []MemberConflictDTO {
+	dtos := make([]MemberConflictDTO, len(conflicts))
+	for i, conflict := range conflicts {
+		dtos[i] = MemberConflictDTO{
+			UserID:   uuid.UUID(conflict.UserID).String(),
+			TeamName: conflict.TeamName,
+		}
+	}
+	return dtos
+}
+
+type TeamSettingsDTO struct {
+	MaxReviewers     *int  `json:"max_reviewers"`
+	ReviewerFallback *bool `json:"reviewer_fallback"`
+}
+
+type TeamNodeDTO struct {
+	TeamName          string          `json:"team_name"`
+	Settings          TeamSettingsDTO `json:"settings"`
+	EffectiveSettings TeamSettingsDTO `json:"effective_settings"`
+	Children          []TeamNodeDTO   `json:"children"`
+}
+
+func TeamSettingsToDTO(settings model.TeamSettings) TeamSettingsDTO {
+	return TeamSettingsDTO{
+		MaxReviewers:     settings.MaxReviewers,
+		ReviewerFallback: settings.ReviewerFallback,
+	}
+}
+
+func TeamNodesToDTOs(nodes []*model.TeamNode) []TeamNodeDTO {
+	dtos := make([]TeamNodeDTO, len(nodes))
+	for i, node := range nodes {
+		dtos[i] = TeamNodeDTO{
+			TeamName:          node.Team.Name,
+			Settings:          TeamSettingsToDTO(node.Team.Settings),
+			EffectiveSettings: TeamSettingsToDTO(node.EffectiveSettings),
+			Children:          TeamNodesToDTOs(node.Children),
+		}
+	}
+	return dtos
+}
@@ -6,17 +6,33 @@ import (
 )
 
 type UserDTO struct {
-	UserID   string `json:"user_id"`
-	Username string `json:"username"`
-	TeamName string `json:"team_name"`
-	IsActive bool   `json:"is_active"`
+	UserID   string              `json:"user_id"`
+	Username string              `json:"username"`
+	TeamName string              `json:"team_name"`
+	Teams    []TeamMembershipDTO `json:"teams"`
+	IsActive bool                `json:"is_active"`
 }
 
-func UserToDTO(user *model.User, teamName string) UserDTO {
+type TeamMembershipDTO struct {
+	TeamName string  `json:"team_name"`
+	Weight   float64 `json:"weight"`
+}
+
+// UserToDTO reports the primary team in team_name and every membership in teams.
+func UserToDTO(user *model.User, teamName string, memberships []model.TeamMembership) UserDTO {
+	teams := make([]TeamMembershipDTO, len(memberships))
+	for i, membership := range memberships {
+		teams[i] = TeamMembershipDTO{
+			TeamName: membership.TeamName,
+			Weight:   membership.Weight,
+		}
+	}
+
 	return UserDTO{
 		UserID:   uuid.UUID(user.ID).String(),
 		Username: user.Username,
 		TeamName: teamName,
+		Teams:    teams,
 		IsActive: user.IsActive,
 	}
 }
\ No newline at end of file
@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"pull-request-review/internal/delivery/http/dto"
+	"pull-request-review/internal/domain/ports/service"
+	"pull-request-review/internal/infrastructure/roster"
+)
+
+const maxImportSize = 10 << 20
+
+type AdminHandler struct {
+	importService service.ImportService
+}
+
+func NewAdminHandler(importService service.ImportService) *AdminHandler {
+	return &AdminHandler{
+		importService: importService,
+	}
+}
+
+// Import handles POST /admin/import
+func (h *AdminHandler) Import(w http.ResponseWriter, r *http.Request) {
+	formatName := r.URL.Query().Get("format")
+	if formatName == "" {
+		formatName = r.Header.Get("Content-Type")
+	}
+
+	format, err := roster.ParseFormat(formatName)
+	if err != nil {
+		WriteError(w, &ValidationError{Message: "format must be yaml or csv"})
+		return
+	}
+
+	dryRun := false
+	if raw := r.URL.Query().Get("dry_run"); raw != "" {
+		dryRun, err = strconv.ParseBool(raw)
+		if err != nil {
+			WriteError(w, &ValidationError{Message: "invalid dry_run value"})
+			return
+		}
+	}
+
+	parsed, err := roster.Parse(http.MaxBytesReader(w, r.Body, maxImportSize), format)
+	if err != nil {
+		WriteError(w, err)
+		return
+	}
+
+	plan, err := h.importService.Import(r.Context(), parsed, dryRun)
+	if err != nil {
+		WriteError(w, err)
+		return
+	}
+
+	response := dto.ImportResponse{
+		DryRun:  dryRun,
+		Applied: !dryRun && !plan.IsEmpty(),
+		Summary: dto.ImportPlanToSummary(plan),
+		Changes: dto.ImportChangesToDTOs(plan.Changes),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		return
+	}
+}
@@ -24,10 +24,54 @@ func getErrorCode(err error) string {
 		return "PR_EXISTS"
 	case errors.Is(err, rules.ErrPullRequestMerged):
 		return "PR_MERGED"
+	case errors.Is(err, rules.ErrPullRequestDraft):
+		return "PR_DRAFT"
+	case errors.Is(err, rules.ErrPullRequestNotDraft):
+		return "PR_NOT_DRAFT"
 	case errors.Is(err, rules.ErrNotAssigned):
 		return "NOT_ASSIGNED"
 	case errors.Is(err, rules.ErrNoCandidates):
 		return "NO_CANDIDATE"
+	case errors.Is(err, rules.ErrForbidden):
+		return "FORBIDDEN"
+	case errors.Is(err, rules.ErrInvalidRole):
+		return "INVALID_ROLE"
+	case errors.Is(err, rules.ErrUserExists):
+		return "USER_EXISTS"
+	case errors.Is(err, rules.ErrInvalidReviewPolicy):
+		return "INVALID_REVIEW_POLICY"
+	case errors.Is(err, rules.ErrMemberConflict):
+		return "MEMBER_CONFLICT"
+	case errors.Is(err, rules.ErrInvalidConflictMode):
+		return "INVALID_MODE"
+	case errors.Is(err, rules.ErrInvalidWeight):
+		return "INVALID_WEIGHT"
+	case errors.Is(err, rules.ErrTeamCycle):
+		return "TEAM_CYCLE"
+	case errors.Is(err, rules.ErrInvalidTeamSettings):
+		return "INVALID_SETTINGS"
+	case errors.Is(err, rules.ErrInvalidImport):
+		return "INVALID_IMPORT"
+	case errors.Is(err, rules.ErrConcurrentModification):
+		return "CONCURRENT_MODIFICATION"
+	case errors.Is(err, rules.ErrPreconditionFailed):
+		return "PRECONDITION_FAILED"
+	case errors.Is(err, rules.ErrInvalidMetadata):
+		return "INVALID_METADATA"
+	case errors.Is(err, rules.ErrBatchTooLarge):
+		return "BATCH_TOO_LARGE"
+	case errors.Is(err, rules.ErrAlreadyAssigned):
+		return "ALREADY_ASSIGNED"
+	case errors.Is(err, rules.ErrReviewerIsAuthor):
+		return "REVIEWER_IS_AUTHOR"
+	case errors.Is(err, rules.ErrReviewerInactive):
+		return "REVIEWER_INACTIVE"
+	case errors.Is(err, rules.ErrReviewerNotInTeam):
+		return "REVIEWER_NOT_IN_TEAM"
+	case errors.Is(err, rules.ErrTooManyReviewers):
+		return "TOO_MANY_REVIEWERS"
+	case errors.Is(err, rules.ErrReviewerDeclined):
+		return "REVIEWER_DECLINED"
 	case errors.Is(err, rules.ErrNotFound),
 		errors.Is(err, rules.ErrTeamNotFound),
 		errors.Is(err, rules.ErrUserNotFound),
@@ -45,8 +89,34 @@ func getHTTPStatus(err error) int {
 	case errors.Is(err, rules.ErrPullRequestExists):
 		return http.StatusConflict
 	case errors.Is(err, rules.ErrPullRequestMerged),
+		errors.Is(err, rules.ErrPullRequestDraft),
+		errors.Is(err, rules.ErrPullRequestNotDraft),
 		errors.Is(err, rules.ErrNotAssigned),
-		errors.Is(err, rules.ErrNoCandidates):
+		errors.Is(err, rules.ErrNoCandidates),
+		errors.Is(err, rules.ErrAlreadyAssigned),
+		errors.Is(err, rules.ErrReviewerIsAuthor),
+		errors.Is(err, rules.ErrReviewerInactive),
+		errors.Is(err, rules.ErrReviewerNotInTeam),
+		errors.Is(err, rules.ErrTooManyReviewers),
+		errors.Is(err, rules.ErrReviewerDeclined):
+		return http.StatusConflict
+	case errors.Is(err, rules.ErrForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, rules.ErrPreconditionFailed):
+		return http.StatusPreconditionFailed
+	case errors.Is(err, rules.ErrInvalidRole),
+		errors.Is(err, rules.ErrInvalidReviewPolicy),
+		errors.Is(err, rules.ErrInvalidConflictMode),
+		errors.Is(err, rules.ErrInvalidWeight),
+		errors.Is(err, rules.ErrTeamCycle),
+		errors.Is(err, rules.ErrInvalidTeamSettings),
+		errors.Is(err, rules.ErrInvalidImport),
+		errors.Is(err, rules.ErrBatchTooLarge),
+		errors.Is(err, rules.ErrInvalidMetadata):
+		return http.StatusBadRequest
+	case errors.Is(err, rules.ErrUserExists),
+		errors.Is(err, rules.ErrMemberConflict),
+		errors.Is(err, rules.ErrConcurrentModification):
 		return http.StatusConflict
 	case errors.Is(err, rules.ErrNotFound),
 		errors.Is(err, rules.ErrTeamNotFound),
@@ -58,18 +128,33 @@ func getHTTPStatus(err error) int {
 	}
 }
 
-func WriteError(w http.ResponseWriter, err error) {
-	code := getErrorCode(err)
-	status := getHTTPStatus(err)
+func getErrorDetails(err error) any {
+	var conflictErr *rules.MemberConflictError
+	if errors.As(err, &conflictErr) {
+		return dto.MemberConflictsToDTOs(conflictErr.Conflicts)
+	}
 
+	var importErr *rules.ImportValidationError
+	if errors.As(err, &importErr) {
+		return importErr.Problems
+	}
+	return nil
+}
+
+func errorDetail(err error) dto.ErrorDetail {
+	return dto.ErrorDetail{
+		Code:    getErrorCode(err),
+		Message: err.Error(),
+		Details: getErrorDetails(err),
+	}
+}
+
+func WriteError(w http.ResponseWriter, err error) {
 	response := dto.ErrorResponse{
-		Error: dto.ErrorDetail{
-			Code:    code,
-			Message: err.Error(),
-		},
+		Error: errorDetail(err),
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(status)
+	w.WriteHeader(getHTTPStatus(err))
 	json.NewEncoder(w).Encode(response)
 }
\ No newline at end of file
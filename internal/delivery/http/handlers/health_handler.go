@@ -1,17 +1,21 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
-
-	"pull-request-review/internal/infrastructure/database"
 )
 
+// Pinger is the storage backend as seen by the health check.
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
 type HealthHandler struct {
-	db *database.Database
+	db Pinger
 }
 
-func NewHealthHandler(db *database.Database) *HealthHandler {
+func NewHealthHandler(db Pinger) *HealthHandler {
 	return &HealthHandler{
 		db: db,
 	}
@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"pull-request-review/internal/domain/model"
+	"pull-request-review/internal/domain/precondition"
+)
+
+const (
+	ETagHeader    = "ETag"
+	IfMatchHeader = "If-Match"
+)
+
+// setETag exposes the pull request version, so the client can send it back in If-Match.
+func setETag(w http.ResponseWriter, pr *model.PullRequest) {
+	w.Header().Set(ETagHeader, strconv.Quote(strconv.FormatInt(pr.Version, 10)))
+}
+
+// withIfMatch carries the versions listed in If-Match into the context. "*" and a missing
+// header put no limit; weak or foreign tags never match, as If-Match compares strongly.
+func withIfMatch(ctx context.Context, r *http.Request) context.Context {
+	header := strings.TrimSpace(r.Header.Get(IfMatchHeader))
+	if header == "" || header == "*" {
+		return ctx
+	}
+
+	var versions []int64
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if len(tag) < 2 || tag[0] != '"' || tag[len(tag)-1] != '"' {
+			continue
+		}
+		version, err := strconv.ParseInt(tag[1:len(tag)-1], 10, 64)
+		if err != nil {
+			continue
+		}
+		versions = append(versions, version)
+	}
+
+	return precondition.WithVersions(ctx, versions)
+}
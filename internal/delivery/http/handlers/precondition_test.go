@@ -0,0 +1,128 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"pull-request-review/internal/delivery/http/dto"
+	"pull-request-review/internal/delivery/http/handlers"
+	"pull-request-review/internal/domain/model"
+	"pull-request-review/internal/domain/ports/service"
+	"pull-request-review/internal/domain/precondition"
+)
+
+// versionedService keeps one pull request at a fixed version and merges it only when the
+// versions expected in the context allow it.
+type versionedService struct {
+	service.PullRequestService
+	pr *model.PullRequest
+}
+
+func (s *versionedService) GetPullRequest(context.Context, model.PullRequestID) (*model.PullRequest, error) {
+	return s.pr, nil
+}
+
+func (s *versionedService) GetPullRequestReviewers(context.Context, model.PullRequestID) ([]model.UserID, error) {
+	return nil, nil
+}
+
+func (s *versionedService) GetReviewAssignments(context.Context, model.PullRequestID) (
+	[]model.ReviewAssignment, error,
+) {
+	return nil, nil
+}
+
+func (s *versionedService) MergePullRequest(ctx context.Context, _ model.PullRequestID) (*model.PullRequest, error) {
+	if err := precondition.CheckVersion(ctx, s.pr.Version); err != nil {
+		return nil, err
+	}
+	merged := *s.pr
+	merged.Status = model.PRStatusMerged
+	merged.Version++
+	return &merged, nil
+}
+
+func newVersionedHandler() (*handlers.PullRequestHandler, *model.PullRequest) {
+	pr := &model.PullRequest{
+		PullRequestID: model.PullRequestID(uuid.New()), Name: "versioned", AuthorID: model.UserID(uuid.New()),
+		Status: model.PRStatusOpen, Version: 3,
+	}
+	return handlers.NewPullRequestHandler(&versionedService{pr: pr}), pr
+}
+
+func TestETag(t *testing.T) {
+	t.Run("get", func(t *testing.T) {
+		handler, pr := newVersionedHandler()
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(
+			http.MethodGet, "/pullRequest/get?pull_request_id="+uuid.UUID(pr.PullRequestID).String(), nil,
+		)
+		handler.GetPullRequest(w, r)
+
+		if w.Code != http.StatusOK || w.Header().Get(handlers.ETagHeader) != `"3"` {
+			t.Fatalf("status %d, etag %q", w.Code, w.Header().Get(handlers.ETagHeader))
+		}
+	})
+
+	t.Run("merge carries the new version", func(t *testing.T) {
+		handler, pr := newVersionedHandler()
+		w := httptest.NewRecorder()
+		handler.MergePullRequest(w, newMergeRequest(pr, ""))
+
+		if w.Code != http.StatusOK || w.Header().Get(handlers.ETagHeader) != `"4"` {
+			t.Fatalf("status %d, etag %q", w.Code, w.Header().Get(handlers.ETagHeader))
+		}
+	})
+}
+
+func TestIfMatch(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		ifMatch string
+		want    int
+	}{
+		{"missing header skips the check", "", http.StatusOK},
+		{"current version", `"3"`, http.StatusOK},
+		{"current version in a list", `"2", "3"`, http.StatusOK},
+		{"any version", "*", http.StatusOK},
+		{"stale version", `"2"`, http.StatusPreconditionFailed},
+		{"weak tag never matches", `W/"3"`, http.StatusPreconditionFailed},
+		{"foreign tag never matches", `"abc"`, http.StatusPreconditionFailed},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			handler, pr := newVersionedHandler()
+			w := httptest.NewRecorder()
+			handler.MergePullRequest(w, newMergeRequest(pr, tc.ifMatch))
+
+			if w.Code != tc.want {
+				t.Fatalf("status %d, want %d: %s", w.Code, tc.want, w.Body)
+			}
+			if tc.want != http.StatusPreconditionFailed {
+				return
+			}
+			var response dto.ErrorResponse
+			if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+				t.Fatalf("decode error: %v", err)
+			}
+			if response.Error.Code != "PRECONDITION_FAILED" {
+				t.Fatalf("unexpected error code %q", response.Error.Code)
+			}
+		})
+	}
+}
+
+// newMergeRequest asks to merge pr, with ifMatch in If-Match unless it is empty.
+func newMergeRequest(pr *model.PullRequest, ifMatch string) *http.Request {
+	body := `{"pull_request_id": "` + uuid.UUID(pr.PullRequestID).String() + `"}`
+	r := httptest.NewRequest(http.MethodPost, "/pullRequest/merge", strings.NewReader(body))
+	if ifMatch != "" {
+		r.Header.Set(handlers.IfMatchHeader, ifMatch)
+	}
+	return r
+}
@@ -1,8 +1,10 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/google/uuid"
@@ -61,10 +63,12 @@ func (h *PullRequestHandler) CreatePullRequest(w http.ResponseWriter, r *http.Re
 	authorID := model.UserID(authorUUID)
 
 	pr := &model.PullRequest{
-		PullRequestID: prID,
-		Name:          req.PullRequestName,
-		AuthorID:      authorID,
-		Status:        model.PRStatusOpen,
+		PullRequestID:       prID,
+		Name:                req.PullRequestName,
+		AuthorID:            authorID,
+		Status:              createdStatus(req.Draft),
+		PullRequestMetadata: dto.PullRequestMetadataFromDTO(req.PullRequestMetadataDTO),
+		ChangedPaths:        req.ChangedPaths,
 	}
 
 	createdPR, reviewerIDs, err := h.pullRequestService.CreatePullRequest(r.Context(), pr)
@@ -78,10 +82,18 @@ func (h *PullRequestHandler) CreatePullRequest(w http.ResponseWriter, r *http.Re
 		reviewerIDStrings[i] = uuid.UUID(id).String()
 	}
 
+	routedBy, err := h.routedBy(r.Context(), createdPR.PullRequestID)
+	if err != nil {
+		WriteError(w, err)
+		return
+	}
+
 	response := dto.PullRequestResponse{
 		PullRequest: dto.PullRequestToDTO(createdPR, reviewerIDStrings),
 	}
+	response.PullRequest.RoutedBy = routedBy
 
+	setETag(w, createdPR)
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
 	err = json.NewEncoder(w).Encode(response)
@@ -90,6 +102,181 @@ func (h *PullRequestHandler) CreatePullRequest(w http.ResponseWriter, r *http.Re
 	}
 }
 
+// BatchCreatePullRequests handles POST /pullRequest/batchCreate. Every pull request gets its own
+// result; malformed items are reported without reaching the service.
+func (h *PullRequestHandler) BatchCreatePullRequests(w http.ResponseWriter, r *http.Request) {
+	var req dto.BatchCreatePRRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, err)
+		return
+	}
+
+	if len(req.PullRequests) == 0 {
+		WriteError(w, &ValidationError{Message: "pull_requests is required"})
+		return
+	}
+
+	results := make([]dto.BatchCreateItemDTO, len(req.PullRequests))
+	var pullRequests []*model.PullRequest
+	var positions []int
+	for i, item := range req.PullRequests {
+		results[i].PullRequestID = item.PullRequestID
+
+		pr, message := parseBatchItem(item)
+		if pr == nil {
+			results[i].Error = &dto.ErrorDetail{Code: "INVALID_REQUEST", Message: message}
+			continue
+		}
+		pullRequests = append(pullRequests, pr)
+		positions = append(positions, i)
+	}
+
+	if len(pullRequests) > 0 {
+		created, err := h.pullRequestService.BatchCreatePullRequests(r.Context(), pullRequests)
+		if err != nil {
+			WriteError(w, err)
+			return
+		}
+
+		for j, result := range created {
+			item := &results[positions[j]]
+			if result.Err != nil {
+				detail := errorDetail(result.Err)
+				item.Error = &detail
+				continue
+			}
+
+			reviewerIDStrings := make([]string, len(result.ReviewerIDs))
+			for k, id := range result.ReviewerIDs {
+				reviewerIDStrings[k] = uuid.UUID(id).String()
+			}
+			pr := dto.PullRequestToDTO(result.PullRequest, reviewerIDStrings)
+			item.Created = true
+			item.PullRequest = &pr
+		}
+	}
+
+	response := dto.BatchCreateResponse{Results: results}
+	for _, result := range results {
+		if result.Created {
+			response.Created++
+		} else {
+			response.Failed++
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	err := json.NewEncoder(w).Encode(response)
+	if err != nil {
+		return
+	}
+}
+
+// parseBatchItem validates one item of a batch like CreatePullRequest validates its request,
+// returning what is wrong with it instead of the pull request.
+func parseBatchItem(item dto.CreatePRRequest) (*model.PullRequest, string) {
+	if strings.TrimSpace(item.PullRequestID) == "" {
+		return nil, "pull_request_id is required"
+	}
+	if strings.TrimSpace(item.PullRequestName) == "" {
+		return nil, "pull_request_name is required"
+	}
+	if strings.TrimSpace(item.AuthorID) == "" {
+		return nil, "author_id is required"
+	}
+
+	prUUID, err := uuid.Parse(item.PullRequestID)
+	if err != nil {
+		return nil, "invalid pull_request_id format"
+	}
+
+	authorUUID, err := uuid.Parse(item.AuthorID)
+	if err != nil {
+		return nil, "invalid author_id format"
+	}
+
+	return &model.PullRequest{
+		PullRequestID:       model.PullRequestID(prUUID),
+		Name:                item.PullRequestName,
+		AuthorID:            model.UserID(authorUUID),
+		Status:              createdStatus(item.Draft),
+		PullRequestMetadata: dto.PullRequestMetadataFromDTO(item.PullRequestMetadataDTO),
+		ChangedPaths:        item.ChangedPaths,
+	}, ""
+}
+
+// routedBy tells which routing rule picked which reviewer of the pull request.
+func (h *PullRequestHandler) routedBy(ctx context.Context, ID model.PullRequestID) (map[string]string, error) {
+	assignments, err := h.pullRequestService.GetReviewAssignments(ctx, ID)
+	if err != nil {
+		return nil, err
+	}
+	return dto.AssignmentsToRoutedBy(assignments), nil
+}
+
+// createdStatus is the status a pull request is created with, drafts wait for markReady.
+func createdStatus(draft bool) model.PullRequestStatus {
+	if draft {
+		return model.PRStatusDraft
+	}
+	return model.PRStatusOpen
+}
+
+// GetPullRequest handles GET /pullRequest/get
+func (h *PullRequestHandler) GetPullRequest(w http.ResponseWriter, r *http.Request) {
+	prIDStr := r.URL.Query().Get("pull_request_id")
+
+	if strings.TrimSpace(prIDStr) == "" {
+		WriteError(w, &ValidationError{Message: "pull_request_id query parameter is required"})
+		return
+	}
+
+	prUUID, err := uuid.Parse(prIDStr)
+	if err != nil {
+		WriteError(w, &ValidationError{Message: "invalid pull_request_id format"})
+		return
+	}
+	prID := model.PullRequestID(prUUID)
+
+	pr, err := h.pullRequestService.GetPullRequest(r.Context(), prID)
+	if err != nil {
+		WriteError(w, err)
+		return
+	}
+
+	reviewerIDs, err := h.pullRequestService.GetPullRequestReviewers(r.Context(), prID)
+	if err != nil {
+		WriteError(w, err)
+		return
+	}
+
+	reviewerIDStrings := make([]string, len(reviewerIDs))
+	for i, id := range reviewerIDs {
+		reviewerIDStrings[i] = uuid.UUID(id).String()
+	}
+
+	routedBy, err := h.routedBy(r.Context(), prID)
+	if err != nil {
+		WriteError(w, err)
+		return
+	}
+
+	response := dto.PullRequestResponse{
+		PullRequest: dto.PullRequestToDTO(pr, reviewerIDStrings),
+	}
+	response.PullRequest.RoutedBy = routedBy
+
+	setETag(w, pr)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		return
+	}
+}
+
 // MergePullRequest handles POST /pullRequest/merge
 func (h *PullRequestHandler) MergePullRequest(w http.ResponseWriter, r *http.Request) {
 	var req dto.MergePRRequest
@@ -111,7 +298,7 @@ func (h *PullRequestHandler) MergePullRequest(w http.ResponseWriter, r *http.Req
 	}
 	prID := model.PullRequestID(prUUID)
 
-	mergedPR, err := h.pullRequestService.MergePullRequest(r.Context(), prID)
+	mergedPR, err := h.pullRequestService.MergePullRequest(withIfMatch(r.Context(), r), prID)
 	if err != nil {
 		WriteError(w, err)
 		return
@@ -132,6 +319,156 @@ func (h *PullRequestHandler) MergePullRequest(w http.ResponseWriter, r *http.Req
 		PullRequest: dto.PullRequestToDTO(mergedPR, reviewerIDStrings),
 	}
 
+	setETag(w, mergedPR)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		return
+	}
+}
+
+// SuggestReviewers handles GET /pullRequest/suggestReviewers
+func (h *PullRequestHandler) SuggestReviewers(w http.ResponseWriter, r *http.Request) {
+	prIDStr := r.URL.Query().Get("pull_request_id")
+
+	if strings.TrimSpace(prIDStr) == "" {
+		WriteError(w, &ValidationError{Message: "pull_request_id query parameter is required"})
+		return
+	}
+
+	prUUID, err := uuid.Parse(prIDStr)
+	if err != nil {
+		WriteError(w, &ValidationError{Message: "invalid pull_request_id format"})
+		return
+	}
+
+	limit := 0
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		limit, err = strconv.Atoi(limitStr)
+		if err != nil || limit < 1 {
+			WriteError(w, &ValidationError{Message: "limit must be a positive integer"})
+			return
+		}
+	}
+
+	suggestions, err := h.pullRequestService.SuggestReviewers(r.Context(), model.PullRequestID(prUUID), limit)
+	if err != nil {
+		WriteError(w, err)
+		return
+	}
+
+	response := dto.SuggestReviewersResponse{
+		PullRequestID: prIDStr,
+		Candidates:    dto.ReviewerSuggestionsToDTOs(suggestions),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		return
+	}
+}
+
+// UpdatePullRequest handles POST /pullRequest/update
+func (h *PullRequestHandler) UpdatePullRequest(w http.ResponseWriter, r *http.Request) {
+	var req dto.UpdatePRRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, err)
+		return
+	}
+
+	if strings.TrimSpace(req.PullRequestID) == "" {
+		WriteError(w, &ValidationError{Message: "pull_request_id is required"})
+		return
+	}
+
+	prUUID, err := uuid.Parse(req.PullRequestID)
+	if err != nil {
+		WriteError(w, &ValidationError{Message: "invalid pull_request_id format"})
+		return
+	}
+	prID := model.PullRequestID(prUUID)
+
+	updatedPR, err := h.pullRequestService.UpdatePullRequest(
+		withIfMatch(r.Context(), r), prID, dto.PullRequestMetadataFromDTO(req.PullRequestMetadataDTO),
+	)
+	if err != nil {
+		WriteError(w, err)
+		return
+	}
+
+	reviewerIDs, err := h.pullRequestService.GetPullRequestReviewers(r.Context(), prID)
+	if err != nil {
+		WriteError(w, err)
+		return
+	}
+
+	reviewerIDStrings := make([]string, len(reviewerIDs))
+	for i, id := range reviewerIDs {
+		reviewerIDStrings[i] = uuid.UUID(id).String()
+	}
+
+	response := dto.PullRequestResponse{
+		PullRequest: dto.PullRequestToDTO(updatedPR, reviewerIDStrings),
+	}
+
+	setETag(w, updatedPR)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		return
+	}
+}
+
+// MarkReady handles POST /pullRequest/markReady
+func (h *PullRequestHandler) MarkReady(w http.ResponseWriter, r *http.Request) {
+	var req dto.MarkReadyRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, err)
+		return
+	}
+
+	if strings.TrimSpace(req.PullRequestID) == "" {
+		WriteError(w, &ValidationError{Message: "pull_request_id is required"})
+		return
+	}
+
+	prUUID, err := uuid.Parse(req.PullRequestID)
+	if err != nil {
+		WriteError(w, &ValidationError{Message: "invalid pull_request_id format"})
+		return
+	}
+
+	readyPR, reviewerIDs, err := h.pullRequestService.MarkReady(
+		withIfMatch(r.Context(), r), model.PullRequestID(prUUID),
+	)
+	if err != nil {
+		WriteError(w, err)
+		return
+	}
+
+	reviewerIDStrings := make([]string, len(reviewerIDs))
+	for i, id := range reviewerIDs {
+		reviewerIDStrings[i] = uuid.UUID(id).String()
+	}
+
+	routedBy, err := h.routedBy(r.Context(), readyPR.PullRequestID)
+	if err != nil {
+		WriteError(w, err)
+		return
+	}
+
+	response := dto.PullRequestResponse{
+		PullRequest: dto.PullRequestToDTO(readyPR, reviewerIDStrings),
+	}
+	response.PullRequest.RoutedBy = routedBy
+
+	setETag(w, readyPR)
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	err = json.NewEncoder(w).Encode(response)
@@ -172,7 +509,20 @@ func (h *PullRequestHandler) ReassignReviewer(w http.ResponseWriter, r *http.Req
 	}
 	oldUserID := model.UserID(oldUserUUID)
 
-	updatedPR, newReviewerID, err := h.pullRequestService.ReassignPullRequest(r.Context(), prID, oldUserID)
+	// without new_user_id the replacement is drawn at random
+	newUserID := model.UserID(uuid.Nil)
+	if strings.TrimSpace(req.NewUserID) != "" {
+		newUserUUID, err := uuid.Parse(req.NewUserID)
+		if err != nil {
+			WriteError(w, &ValidationError{Message: "invalid new_user_id format"})
+			return
+		}
+		newUserID = model.UserID(newUserUUID)
+	}
+
+	updatedPR, newReviewerID, err := h.pullRequestService.ReassignPullRequest(
+		withIfMatch(r.Context(), r), prID, oldUserID, newUserID,
+	)
 	if err != nil {
 		WriteError(w, err)
 		return
@@ -194,6 +544,139 @@ func (h *PullRequestHandler) ReassignReviewer(w http.ResponseWriter, r *http.Req
 		ReplacedBy:  uuid.UUID(newReviewerID).String(),
 	}
 
+	setETag(w, updatedPR)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		return
+	}
+}
+
+// DeclineReview handles POST /pullRequest/decline, called by the reviewer who declines
+func (h *PullRequestHandler) DeclineReview(w http.ResponseWriter, r *http.Request) {
+	var req dto.DeclineRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, err)
+		return
+	}
+
+	if strings.TrimSpace(req.PullRequestID) == "" {
+		WriteError(w, &ValidationError{Message: "pull_request_id is required"})
+		return
+	}
+	if strings.TrimSpace(req.Reason) == "" {
+		WriteError(w, &ValidationError{Message: "reason is required"})
+		return
+	}
+
+	prUUID, err := uuid.Parse(req.PullRequestID)
+	if err != nil {
+		WriteError(w, &ValidationError{Message: "invalid pull_request_id format"})
+		return
+	}
+	prID := model.PullRequestID(prUUID)
+
+	updatedPR, newReviewerID, err := h.pullRequestService.DeclineReview(
+		withIfMatch(r.Context(), r), prID, strings.TrimSpace(req.Reason),
+	)
+	if err != nil {
+		WriteError(w, err)
+		return
+	}
+
+	reviewerIDs, err := h.pullRequestService.GetPullRequestReviewers(r.Context(), prID)
+	if err != nil {
+		WriteError(w, err)
+		return
+	}
+
+	reviewerIDStrings := make([]string, len(reviewerIDs))
+	for i, id := range reviewerIDs {
+		reviewerIDStrings[i] = uuid.UUID(id).String()
+	}
+
+	response := dto.DeclineResponse{PullRequest: dto.PullRequestToDTO(updatedPR, reviewerIDStrings)}
+	if uuid.UUID(newReviewerID) != uuid.Nil {
+		response.ReplacedBy = uuid.UUID(newReviewerID).String()
+	}
+
+	setETag(w, updatedPR)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		return
+	}
+}
+
+// AddReviewer handles POST /pullRequest/addReviewer
+func (h *PullRequestHandler) AddReviewer(w http.ResponseWriter, r *http.Request) {
+	h.changeReviewer(w, r, h.pullRequestService.AddReviewer)
+}
+
+// RemoveReviewer handles POST /pullRequest/removeReviewer
+func (h *PullRequestHandler) RemoveReviewer(w http.ResponseWriter, r *http.Request) {
+	h.changeReviewer(w, r, h.pullRequestService.RemoveReviewer)
+}
+
+func (h *PullRequestHandler) changeReviewer(
+	w http.ResponseWriter,
+	r *http.Request,
+	change func(ctx context.Context, ID model.PullRequestID, reviewerID model.UserID) (*model.PullRequest, error),
+) {
+	var req dto.ReviewerRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, err)
+		return
+	}
+
+	if strings.TrimSpace(req.PullRequestID) == "" {
+		WriteError(w, &ValidationError{Message: "pull_request_id is required"})
+		return
+	}
+	if strings.TrimSpace(req.UserID) == "" {
+		WriteError(w, &ValidationError{Message: "user_id is required"})
+		return
+	}
+
+	prUUID, err := uuid.Parse(req.PullRequestID)
+	if err != nil {
+		WriteError(w, &ValidationError{Message: "invalid pull_request_id format"})
+		return
+	}
+	prID := model.PullRequestID(prUUID)
+
+	reviewerUUID, err := uuid.Parse(req.UserID)
+	if err != nil {
+		WriteError(w, &ValidationError{Message: "invalid user_id format"})
+		return
+	}
+
+	updatedPR, err := change(withIfMatch(r.Context(), r), prID, model.UserID(reviewerUUID))
+	if err != nil {
+		WriteError(w, err)
+		return
+	}
+
+	reviewerIDs, err := h.pullRequestService.GetPullRequestReviewers(r.Context(), prID)
+	if err != nil {
+		WriteError(w, err)
+		return
+	}
+
+	reviewerIDStrings := make([]string, len(reviewerIDs))
+	for i, id := range reviewerIDs {
+		reviewerIDStrings[i] = uuid.UUID(id).String()
+	}
+
+	response := dto.PullRequestResponse{
+		PullRequest: dto.PullRequestToDTO(updatedPR, reviewerIDStrings),
+	}
+
+	setETag(w, updatedPR)
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	err = json.NewEncoder(w).Encode(response)
@@ -6,7 +6,10 @@ import (
 	"net/http"
 	"strings"
 
+	"github.com/google/uuid"
+
 	"pull-request-review/internal/delivery/http/dto"
+	"pull-request-review/internal/domain/model"
 	"pull-request-review/internal/domain/ports/service"
 )
 
@@ -56,7 +59,20 @@ func (h *TeamHandler) AddTeam(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = h.teamService.CreateTeamWithMembers(r.Context(), req.TeamName, members)
+	weights, err := dto.TeamMembersToWeights(req.Members)
+	if err != nil {
+		WriteError(w, &ValidationError{Message: "invalid user_id format in members"})
+		return
+	}
+
+	opts := model.TeamCreateOptions{
+		ParentTeamName: req.ParentTeamName,
+		Weights:        weights,
+		ConflictMode:   model.MemberConflictMode(req.Mode),
+		ReviewPolicy:   model.ReviewPolicy(req.ReviewPolicy),
+	}
+
+	err = h.teamService.CreateTeamWithMembers(r.Context(), req.TeamName, members, opts)
 	if err != nil {
 		WriteError(w, err)
 		return
@@ -105,4 +121,346 @@ func (h *TeamHandler) GetTeam(w http.ResponseWriter, r *http.Request) {
 	if err != nil {
 		return
 	}
-}
\ No newline at end of file
+}
+
+// AddMember handles POST /team/addMember
+func (h *TeamHandler) AddMember(w http.ResponseWriter, r *http.Request) {
+	var req dto.AddMemberRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, err)
+		return
+	}
+
+	if strings.TrimSpace(req.TeamName) == "" {
+		WriteError(w, &ValidationError{Message: "team_name is required"})
+		return
+	}
+	if strings.TrimSpace(req.UserID) == "" {
+		WriteError(w, &ValidationError{Message: "user_id is required"})
+		return
+	}
+	if strings.TrimSpace(req.Username) == "" {
+		WriteError(w, &ValidationError{Message: "username is required"})
+		return
+	}
+
+	member, err := dto.TeamMemberToUser(
+		dto.TeamMember{
+			UserID:   req.UserID,
+			Username: req.Username,
+			IsActive: req.IsActive,
+		},
+	)
+	if err != nil {
+		WriteError(w, &ValidationError{Message: "invalid user_id format"})
+		return
+	}
+
+	team, users, err := h.teamService.AddMember(r.Context(), req.TeamName, member, req.Weight)
+	if err != nil {
+		WriteError(w, err)
+		return
+	}
+
+	response := dto.TeamResponse{
+		Team: dto.TeamToDTO(team, users),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		return
+	}
+}
+
+// RemoveMember handles POST /team/removeMember
+func (h *TeamHandler) RemoveMember(w http.ResponseWriter, r *http.Request) {
+	var req dto.RemoveMemberRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, err)
+		return
+	}
+
+	if strings.TrimSpace(req.TeamName) == "" {
+		WriteError(w, &ValidationError{Message: "team_name is required"})
+		return
+	}
+	if strings.TrimSpace(req.UserID) == "" {
+		WriteError(w, &ValidationError{Message: "user_id is required"})
+		return
+	}
+
+	userUUID, err := uuid.Parse(req.UserID)
+	if err != nil {
+		WriteError(w, &ValidationError{Message: "invalid user_id format"})
+		return
+	}
+
+	team, users, err := h.teamService.RemoveMember(
+		r.Context(), req.TeamName, model.UserID(userUUID), dto.ReviewPolicyFromString(req.ReviewPolicy),
+	)
+	if err != nil {
+		WriteError(w, err)
+		return
+	}
+
+	response := dto.TeamResponse{
+		Team: dto.TeamToDTO(team, users),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		return
+	}
+}
+
+// RenameTeam handles POST /team/rename
+func (h *TeamHandler) RenameTeam(w http.ResponseWriter, r *http.Request) {
+	var req dto.RenameTeamRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, err)
+		return
+	}
+
+	if strings.TrimSpace(req.TeamName) == "" {
+		WriteError(w, &ValidationError{Message: "team_name is required"})
+		return
+	}
+	if strings.TrimSpace(req.NewTeamName) == "" {
+		WriteError(w, &ValidationError{Message: "new_team_name is required"})
+		return
+	}
+
+	team, users, err := h.teamService.RenameTeam(r.Context(), req.TeamName, req.NewTeamName)
+	if err != nil {
+		WriteError(w, err)
+		return
+	}
+
+	response := dto.TeamResponse{
+		Team: dto.TeamToDTO(team, users),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		return
+	}
+}
+
+// DeactivateTeam handles POST /team/deactivate
+func (h *TeamHandler) DeactivateTeam(w http.ResponseWriter, r *http.Request) {
+	var req dto.DeactivateTeamRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, err)
+		return
+	}
+
+	if strings.TrimSpace(req.TeamName) == "" {
+		WriteError(w, &ValidationError{Message: "team_name is required"})
+		return
+	}
+
+	team, _, err := h.teamService.GetTeamWithMembers(r.Context(), req.TeamName)
+	if err != nil {
+		WriteError(w, err)
+		return
+	}
+
+	err = h.teamService.BulkDeactivateTeam(r.Context(), team.TeamID)
+	if err != nil {
+		WriteError(w, err)
+		return
+	}
+
+	team, users, err := h.teamService.GetTeamWithMembers(r.Context(), req.TeamName)
+	if err != nil {
+		WriteError(w, err)
+		return
+	}
+
+	response := dto.TeamResponse{
+		Team: dto.TeamToDTO(team, users),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		return
+	}
+}
+
+// GrantRole handles POST /team/grantRole
+func (h *TeamHandler) GrantRole(w http.ResponseWriter, r *http.Request) {
+	var req dto.GrantRoleRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, err)
+		return
+	}
+
+	if strings.TrimSpace(req.TeamName) == "" {
+		WriteError(w, &ValidationError{Message: "team_name is required"})
+		return
+	}
+	if strings.TrimSpace(req.UserID) == "" {
+		WriteError(w, &ValidationError{Message: "user_id is required"})
+		return
+	}
+
+	userUUID, err := uuid.Parse(req.UserID)
+	if err != nil {
+		WriteError(w, &ValidationError{Message: "invalid user_id format"})
+		return
+	}
+	role := model.TeamRole(req.Role)
+
+	team, err := h.teamService.GrantRole(r.Context(), req.TeamName, model.UserID(userUUID), role)
+	if err != nil {
+		WriteError(w, err)
+		return
+	}
+
+	response := dto.TeamRoleResponse{
+		TeamName: team.Name,
+		UserID:   userUUID.String(),
+		Role:     string(role),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		return
+	}
+}
+
+// RevokeRole handles POST /team/revokeRole
+func (h *TeamHandler) RevokeRole(w http.ResponseWriter, r *http.Request) {
+	var req dto.RevokeRoleRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, err)
+		return
+	}
+
+	if strings.TrimSpace(req.TeamName) == "" {
+		WriteError(w, &ValidationError{Message: "team_name is required"})
+		return
+	}
+	if strings.TrimSpace(req.UserID) == "" {
+		WriteError(w, &ValidationError{Message: "user_id is required"})
+		return
+	}
+
+	userUUID, err := uuid.Parse(req.UserID)
+	if err != nil {
+		WriteError(w, &ValidationError{Message: "invalid user_id format"})
+		return
+	}
+
+	team, err := h.teamService.RevokeRole(r.Context(), req.TeamName, model.UserID(userUUID))
+	if err != nil {
+		WriteError(w, err)
+		return
+	}
+
+	response := dto.TeamRoleResponse{
+		TeamName: team.Name,
+		UserID:   userUUID.String(),
+		Role:     string(model.TeamRoleMember),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		return
+	}
+}
+
+// GetTree handles GET /team/tree
+func (h *TeamHandler) GetTree(w http.ResponseWriter, r *http.Request) {
+	teamName := strings.TrimSpace(r.URL.Query().Get("team_name"))
+
+	h.writeTeamTree(w, r, teamName)
+}
+
+// SetParent handles POST /team/setParent
+func (h *TeamHandler) SetParent(w http.ResponseWriter, r *http.Request) {
+	var req dto.SetParentTeamRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, err)
+		return
+	}
+
+	if strings.TrimSpace(req.TeamName) == "" {
+		WriteError(w, &ValidationError{Message: "team_name is required"})
+		return
+	}
+
+	team, err := h.teamService.SetParentTeam(r.Context(), req.TeamName, strings.TrimSpace(req.ParentTeamName))
+	if err != nil {
+		WriteError(w, err)
+		return
+	}
+
+	h.writeTeamTree(w, r, team.Name)
+}
+
+// UpdateSettings handles POST /team/updateSettings
+func (h *TeamHandler) UpdateSettings(w http.ResponseWriter, r *http.Request) {
+	var req dto.UpdateTeamSettingsRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, err)
+		return
+	}
+
+	if strings.TrimSpace(req.TeamName) == "" {
+		WriteError(w, &ValidationError{Message: "team_name is required"})
+		return
+	}
+
+	settings := model.TeamSettings{
+		MaxReviewers:     req.MaxReviewers,
+		ReviewerFallback: req.ReviewerFallback,
+	}
+
+	team, err := h.teamService.UpdateSettings(r.Context(), req.TeamName, settings)
+	if err != nil {
+		WriteError(w, err)
+		return
+	}
+
+	h.writeTeamTree(w, r, team.Name)
+}
+
+func (h *TeamHandler) writeTeamTree(w http.ResponseWriter, r *http.Request, teamName string) {
+	nodes, err := h.teamService.GetTeamTree(r.Context(), teamName)
+	if err != nil {
+		WriteError(w, err)
+		return
+	}
+
+	response := dto.TeamTreeResponse{
+		Teams: dto.TeamNodesToDTOs(nodes),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		return
+	}
+}
@@ -57,8 +57,71 @@ func (h *UserHandler) SetIsActive(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	teams, err := h.userService.GetUserTeams(r.Context(), userID)
+	if err != nil {
+		WriteError(w, err)
+		return
+	}
+
+	response := dto.UserResponse{
+		User: dto.UserToDTO(user, teamName, teams),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		return
+	}
+}
+
+// MoveTeam handles POST /users/moveTeam
+func (h *UserHandler) MoveTeam(w http.ResponseWriter, r *http.Request) {
+	var req dto.MoveTeamRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, err)
+		return
+	}
+
+	if strings.TrimSpace(req.UserID) == "" {
+		WriteError(w, &ValidationError{Message: "user_id is required"})
+		return
+	}
+	if strings.TrimSpace(req.TeamName) == "" {
+		WriteError(w, &ValidationError{Message: "team_name is required"})
+		return
+	}
+
+	userUUID, err := uuid.Parse(req.UserID)
+	if err != nil {
+		WriteError(w, &ValidationError{Message: "invalid user_id format"})
+		return
+	}
+	userID := model.UserID(userUUID)
+
+	_, err = h.userService.MoveToTeam(
+		r.Context(), userID, req.FromTeamName, req.TeamName, dto.ReviewPolicyFromString(req.ReviewPolicy),
+	)
+	if err != nil {
+		WriteError(w, err)
+		return
+	}
+
+	user, teamName, err := h.userService.GetUserWithTeamName(r.Context(), userID)
+	if err != nil {
+		WriteError(w, err)
+		return
+	}
+
+	teams, err := h.userService.GetUserTeams(r.Context(), userID)
+	if err != nil {
+		WriteError(w, err)
+		return
+	}
+
 	response := dto.UserResponse{
-		User: dto.UserToDTO(user, teamName),
+		User: dto.UserToDTO(user, teamName, teams),
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -85,7 +148,12 @@ func (h *UserHandler) GetReviews(w http.ResponseWriter, r *http.Request) {
 	}
 	userID := model.UserID(userUUID)
 
-	pullRequests, err := h.pullRequestService.GetUserReviews(r.Context(), userID)
+	filter := model.ReviewFilter{
+		Label:      strings.TrimSpace(r.URL.Query().Get("label")),
+		Repository: strings.TrimSpace(r.URL.Query().Get("repository")),
+	}
+
+	pullRequests, err := h.pullRequestService.GetUserReviews(r.Context(), userID, filter)
 	if err != nil {
 		WriteError(w, err)
 		return
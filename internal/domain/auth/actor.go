@@ -0,0 +1,18 @@
+package auth
+
+import (
+	"context"
+
+	"pull-request-review/internal/domain/model"
+)
+
+type actorKey struct{}
+
+func WithActor(ctx context.Context, actorID model.UserID) context.Context {
+	return context.WithValue(ctx, actorKey{}, actorID)
+}
+
+func ActorFromContext(ctx context.Context) (model.UserID, bool) {
+	actorID, ok := ctx.Value(actorKey{}).(model.UserID)
+	return actorID, ok
+}
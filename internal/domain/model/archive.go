@@ -0,0 +1,38 @@
+package model
+
+import "time"
+
+const (
+	ArchiveFormat  = "pull-request-review-archive"
+	ArchiveVersion = 1
+)
+
+type ArchiveHeader struct {
+	Format    string
+	Version   int
+	CreatedAt time.Time
+}
+
+type ArchiveRecordKind string
+
+const (
+	ArchiveRecordTeam             ArchiveRecordKind = "team"
+	ArchiveRecordUser             ArchiveRecordKind = "user"
+	ArchiveRecordMembership       ArchiveRecordKind = "membership"
+	ArchiveRecordTeamRole         ArchiveRecordKind = "team_role"
+	ArchiveRecordPullRequest      ArchiveRecordKind = "pull_request"
+	ArchiveRecordReviewAssignment ArchiveRecordKind = "review_assignment"
+	ArchiveRecordReviewDecline    ArchiveRecordKind = "review_decline"
+)
+
+// ArchiveRecord is one entry of an export. Exactly the field matching Kind is set.
+type ArchiveRecord struct {
+	Kind             ArchiveRecordKind
+	Team             *Team
+	User             *User
+	Membership       *TeamMembership
+	TeamRole         *TeamRoleGrant
+	PullRequest      *PullRequest
+	ReviewAssignment *ReviewAssignment
+	ReviewDecline    *ReviewDecline
+}
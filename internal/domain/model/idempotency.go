@@ -0,0 +1,19 @@
+package model
+
+import (
+	"time"
+)
+
+type IdempotencyRecord struct {
+	Key          string    `db:"idempotency_key"`
+	Route        string    `db:"route"`
+	RequestHash  string    `db:"request_hash"`
+	StatusCode   int       `db:"status_code"`
+	ResponseBody []byte    `db:"response_body"`
+	CreatedAt    time.Time `db:"created_at"`
+	CompletedAt  time.Time `db:"completed_at"`
+}
+
+func (r *IdempotencyRecord) IsCompleted() bool {
+	return !r.CompletedAt.IsZero()
+}
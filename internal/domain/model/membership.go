@@ -0,0 +1,68 @@
+package model
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+const DefaultMembershipWeight = 1.0
+
+// TeamMembership links a user to one of their teams. Weight is the relative share of reviews
+// the user gets in that team, so people split between squads can take fewer reviews in each.
+type TeamMembership struct {
+	TeamID    TeamID    `db:"team_id"`
+	UserID    UserID    `db:"user_id"`
+	TeamName  string    `db:"name"`
+	Weight    float64   `db:"weight"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+// MemberConflictMode tells /team/add what to do with members that already belong to another team.
+type MemberConflictMode string
+
+const (
+	MemberConflictReject MemberConflictMode = "reject"
+	MemberConflictMove   MemberConflictMode = "move"
+	MemberConflictMulti  MemberConflictMode = "multi"
+)
+
+func (m MemberConflictMode) IsValid() bool {
+	switch m {
+	case MemberConflictReject, MemberConflictMove, MemberConflictMulti:
+		return true
+	default:
+		return false
+	}
+}
+
+type MemberConflict struct {
+	UserID   UserID
+	TeamID   TeamID
+	TeamName string
+}
+
+type MembershipChangeReason string
+
+const (
+	MembershipChangeTeamAdd      MembershipChangeReason = "team_add"
+	MembershipChangeAddMember    MembershipChangeReason = "add_member"
+	MembershipChangeRemoveMember MembershipChangeReason = "remove_member"
+	MembershipChangeMoveTeam     MembershipChangeReason = "move_team"
+)
+
+type MembershipChange struct {
+	ID         uuid.UUID              `db:"audit_id"`
+	UserID     UserID                 `db:"user_id"`
+	FromTeamID TeamID                 `db:"from_team_id"`
+	ToTeamID   TeamID                 `db:"to_team_id"`
+	ActorID    UserID                 `db:"actor_id"`
+	Reason     MembershipChangeReason `db:"reason"`
+	CreatedAt  time.Time              `db:"created_at"`
+}
+
+type TeamCreateOptions struct {
+	ParentTeamName string
+	Weights        map[UserID]float64
+	ConflictMode   MemberConflictMode
+	ReviewPolicy   ReviewPolicy
+}
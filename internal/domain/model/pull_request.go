@@ -8,11 +8,17 @@ import (
 type PullRequestID uuid.UUID
 type PullRequestStatus string
 
+// A draft pull request gets its reviewers only once it is marked ready, and becomes open then.
 const (
+	PRStatusDraft  PullRequestStatus = "DRAFT"
 	PRStatusOpen   PullRequestStatus = "OPEN"
 	PRStatusMerged PullRequestStatus = "MERGED"
 )
 
+// InitialPullRequestVersion is the version of a new pull request. Every change to its status
+// or reviewers bumps the version by one.
+const InitialPullRequestVersion int64 = 1
+
 type PullRequest struct {
 	PullRequestID PullRequestID     `db:"pull_request_id"`
 	Name          string            `db:"name"`
@@ -20,4 +26,34 @@ type PullRequest struct {
 	Status        PullRequestStatus `db:"status"`
 	CreatedAt     time.Time         `db:"created_at"`
 	MergedAt      time.Time         `db:"merged_at"`
+	Version       int64             `db:"version"`
+	PullRequestMetadata
+	// ChangedPaths are the files the change touches, as given when the pull request was created.
+	// They route reviewers, a draft's once it is marked ready.
+	ChangedPaths []string `db:"changed_paths"`
+}
+
+// PullRequestMetadata tells which change a pull request is about. Every field is optional,
+// pull requests created before it was introduced have none.
+type PullRequestMetadata struct {
+	Repository   string   `db:"repository"`
+	SourceBranch string   `db:"source_branch"`
+	TargetBranch string   `db:"target_branch"`
+	URL          string   `db:"url"`
+	Description  string   `db:"description"`
+	Labels       []string `db:"labels"`
+}
+
+// ReviewFilter narrows down the reviews listed for a user. Empty fields match everything.
+type ReviewFilter struct {
+	Label      string
+	Repository string
+}
+
+// BatchCreateResult is the outcome of one pull request of a batch create. Err tells why
+// the pull request was skipped, ReviewerIDs who was assigned to it otherwise.
+type BatchCreateResult struct {
+	PullRequest *PullRequest
+	ReviewerIDs []UserID
+	Err         error
 }
\ No newline at end of file
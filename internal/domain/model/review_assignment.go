@@ -8,4 +8,32 @@ type ReviewAssignment struct {
 	PullRequestID PullRequestID `db:"pull_request_id"`
 	ReviewerID    UserID        `db:"reviewer_id"`
 	AssignedAt    time.Time     `db:"assigned_at"`
+	// Rule names the routing rule that picked the reviewer, it is empty for the author's team.
+	Rule string `db:"rule"`
+}
+
+// ReviewDecline records a reviewer turning down a review they were assigned.
+type ReviewDecline struct {
+	PullRequestID PullRequestID `db:"pull_request_id"`
+	ReviewerID    UserID        `db:"user_id"`
+	Reason        string        `db:"reason"`
+	DeclinedAt    time.Time     `db:"declined_at"`
+}
+
+// ReviewPolicy decides what happens to the open reviews of a user who leaves a team.
+type ReviewPolicy string
+
+const (
+	ReviewPolicyKeep     ReviewPolicy = "keep"
+	ReviewPolicyReassign ReviewPolicy = "reassign"
+	ReviewPolicyUnassign ReviewPolicy = "unassign"
+)
+
+func (p ReviewPolicy) IsValid() bool {
+	switch p {
+	case ReviewPolicyKeep, ReviewPolicyReassign, ReviewPolicyUnassign:
+		return true
+	default:
+		return false
+	}
 }
\ No newline at end of file
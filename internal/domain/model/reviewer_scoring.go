@@ -0,0 +1,52 @@
+package model
+
+import "time"
+
+// ReviewerStrategy decides how reviewers are picked among the candidates of a team.
+type ReviewerStrategy string
+
+const (
+	// ReviewerStrategyRandom draws reviewers at random by membership weight. It is the default.
+	ReviewerStrategyRandom ReviewerStrategy = "random"
+	// ReviewerStrategyExpertise takes the best scored candidates, see ScoringWeights.
+	ReviewerStrategyExpertise ReviewerStrategy = "expertise"
+)
+
+func (s ReviewerStrategy) IsValid() bool {
+	switch s {
+	case "", ReviewerStrategyRandom, ReviewerStrategyExpertise:
+		return true
+	default:
+		return false
+	}
+}
+
+// ScoringWeights mix the parts of a candidate's score: how well the candidate knows the author's
+// pull requests, how few open reviews the candidate has, and chance, so that the same people are
+// not always on top.
+type ScoringWeights struct {
+	Expertise  float64
+	Load       float64
+	Randomness float64
+}
+
+// DefaultScoringWeights are used when no weight is configured.
+var DefaultScoringWeights = ScoringWeights{Expertise: 0.6, Load: 0.3, Randomness: 0.1}
+
+// ReviewHistory is what a reviewer has reviewed of one author, merged pull requests included.
+type ReviewHistory struct {
+	Reviews        int
+	LastReviewedAt time.Time
+}
+
+// ReviewerSuggestion is a scored candidate reviewer. Expertise and Availability are the parts
+// of the score between 0 and 1, Reasons explain them in words.
+type ReviewerSuggestion struct {
+	User         User
+	Score        float64
+	Expertise    float64
+	Availability float64
+	History      ReviewHistory
+	OpenReviews  int
+	Reasons      []string
+}
@@ -0,0 +1,58 @@
+package model
+
+// Roster is an import file: the teams, their place in the hierarchy and their members.
+type Roster struct {
+	Teams []RosterTeam
+}
+
+// RosterTeam lists a team and its members. An empty ParentTeamName keeps the current parent
+// of an existing team.
+type RosterTeam struct {
+	Name           string
+	ParentTeamName string
+	Members        []RosterMember
+}
+
+// RosterMember is one membership line of the roster. A zero Weight keeps the current
+// weight of an existing membership and means the default weight for a new one.
+type RosterMember struct {
+	UserID   UserID
+	Username string
+	IsActive bool
+	Weight   float64
+}
+
+type ImportAction string
+
+const (
+	ImportActionCreate ImportAction = "create"
+	ImportActionUpdate ImportAction = "update"
+)
+
+type ImportSubject string
+
+const (
+	ImportSubjectTeam       ImportSubject = "team"
+	ImportSubjectUser       ImportSubject = "user"
+	ImportSubjectMembership ImportSubject = "membership"
+)
+
+// ImportChange is one line of the dry-run diff.
+type ImportChange struct {
+	Action  ImportAction
+	Subject ImportSubject
+	Key     string
+	Details []string
+}
+
+// ImportPlan is what applying a roster would write. Teams are ordered so that parents come first.
+type ImportPlan struct {
+	Teams       []Team
+	Users       []User
+	Memberships []TeamMembership
+	Changes     []ImportChange
+}
+
+func (p *ImportPlan) IsEmpty() bool {
+	return len(p.Changes) == 0
+}
@@ -0,0 +1,47 @@
+package model
+
+import (
+	"path"
+	"slices"
+	"strings"
+)
+
+// RoutingRule sends the pull requests carrying Label, or changing a path that matches
+// PathPattern, to Reviewers reviewers drawn from the listed users and the active members
+// of the listed teams. Either condition is enough when both are set.
+type RoutingRule struct {
+	Name        string
+	Label       string
+	PathPattern string
+	UserIDs     []UserID
+	TeamNames   []string
+	Reviewers   int
+}
+
+// Matches reports whether a pull request with the labels, changing the paths, falls under the rule.
+func (r RoutingRule) Matches(labels []string, paths []string) bool {
+	if r.Label != "" && slices.Contains(labels, r.Label) {
+		return true
+	}
+	if r.PathPattern == "" {
+		return false
+	}
+	return slices.ContainsFunc(paths, func(p string) bool { return MatchPath(r.PathPattern, p) })
+}
+
+// MatchPath matches a changed path against a routing pattern. A pattern ending in "/**" matches
+// everything below that directory, a pattern without a slash matches the file name in any
+// directory, and any other pattern matches the whole path with the syntax of path.Match.
+func MatchPath(pattern string, name string) bool {
+	name = strings.TrimPrefix(path.Clean("/"+name), "/")
+
+	if dir, ok := strings.CutSuffix(pattern, "/**"); ok {
+		return name == dir || strings.HasPrefix(name, dir+"/")
+	}
+	if !strings.Contains(pattern, "/") {
+		name = path.Base(name)
+	}
+
+	matched, _ := path.Match(pattern, name)
+	return matched
+}
@@ -8,7 +8,37 @@ import (
 type TeamID uuid.UUID
 
 type Team struct {
-	TeamID    TeamID    `db:"id"`
-	Name      string    `db:"name"`
-	CreatedAt time.Time `db:"created_at"`
+	TeamID       TeamID       `db:"id"`
+	Name         string       `db:"name"`
+	ParentTeamID uuid.UUID    `db:"parent_team_id"`
+	Settings     TeamSettings `db:"-"`
+	CreatedAt    time.Time    `db:"created_at"`
+}
+
+// TeamSettings holds per-team overrides. Unset fields are inherited from the parent team.
+type TeamSettings struct {
+	MaxReviewers     *int  `db:"max_reviewers"`
+	ReviewerFallback *bool `db:"reviewer_fallback"`
+}
+
+// Inherit fills the fields left unset with the values of the parent settings.
+func (s TeamSettings) Inherit(parent TeamSettings) TeamSettings {
+	if s.MaxReviewers == nil {
+		s.MaxReviewers = parent.MaxReviewers
+	}
+	if s.ReviewerFallback == nil {
+		s.ReviewerFallback = parent.ReviewerFallback
+	}
+	return s
+}
+
+// FallbackEnabled reports whether reviewer selection may walk up to parent teams, which is the default.
+func (s TeamSettings) FallbackEnabled() bool {
+	return s.ReviewerFallback == nil || *s.ReviewerFallback
+}
+
+type TeamNode struct {
+	Team              Team
+	EffectiveSettings TeamSettings
+	Children          []*TeamNode
 }
\ No newline at end of file
@@ -0,0 +1,35 @@
+package model
+
+import "time"
+
+type TeamRole string
+
+const (
+	TeamRoleMember     TeamRole = "member"
+	TeamRoleMaintainer TeamRole = "maintainer"
+	TeamRoleAdmin      TeamRole = "admin"
+)
+
+var teamRoleRanks = map[TeamRole]int{
+	TeamRoleMember:     1,
+	TeamRoleMaintainer: 2,
+	TeamRoleAdmin:      3,
+}
+
+func (r TeamRole) IsValid() bool {
+	_, ok := teamRoleRanks[r]
+	return ok
+}
+
+// Includes reports whether r grants at least the permissions of other.
+func (r TeamRole) Includes(other TeamRole) bool {
+	return teamRoleRanks[r] >= teamRoleRanks[other]
+}
+
+// TeamRoleGrant is an explicit role of a user in a team.
+type TeamRoleGrant struct {
+	TeamID    TeamID    `db:"team_id"`
+	UserID    UserID    `db:"user_id"`
+	Role      TeamRole  `db:"role"`
+	GrantedAt time.Time `db:"granted_at"`
+}
@@ -0,0 +1,18 @@
+package archive
+
+import "pull-request-review/internal/domain/model"
+
+// Writer receives an export record by record, so it never has to hold the whole state.
+// Records of one kind arrive together.
+type Writer interface {
+	WriteHeader(header model.ArchiveHeader) error
+	Write(record *model.ArchiveRecord) error
+	Close() error
+}
+
+// Reader yields the records of an archive in the order they were written. Next returns
+// io.EOF after the last record.
+type Reader interface {
+	ReadHeader() (model.ArchiveHeader, error)
+	Next() (*model.ArchiveRecord, error)
+}
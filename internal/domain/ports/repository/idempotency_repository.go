@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"pull-request-review/internal/domain/model"
+)
+
+type IdempotencyRepository interface {
+	Reserve(ctx context.Context, record *model.IdempotencyRecord) (bool, error)
+	Get(ctx context.Context, key string, route string) (*model.IdempotencyRecord, error)
+	Complete(ctx context.Context, key string, route string, statusCode int, responseBody []byte) error
+	Delete(ctx context.Context, key string, route string) error
+	// DeleteCreatedBefore removes the keys created before the given time and returns how many.
+	DeleteCreatedBefore(ctx context.Context, before time.Time) (int64, error)
+}
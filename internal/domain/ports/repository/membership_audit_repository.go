@@ -0,0 +1,11 @@
+package repository
+
+import (
+	"context"
+
+	"pull-request-review/internal/domain/model"
+)
+
+type MembershipAuditRepository interface {
+	Record(ctx context.Context, change *model.MembershipChange) error
+}
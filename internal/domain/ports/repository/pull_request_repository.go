@@ -9,9 +9,14 @@ import (
 
 type PullRequestRepository interface {
 	Create(ctx context.Context, pullRequest *model.PullRequest) error
+	CreateBatch(ctx context.Context, pullRequests []model.PullRequest) error
 	GetByID(ctx context.Context, ID model.PullRequestID) (*model.PullRequest, error)
 	Exists(ctx context.Context, ID model.PullRequestID) (bool, error)
+	GetExistingIDs(ctx context.Context, IDs []model.PullRequestID) ([]model.PullRequestID, error)
 	UpdateStatus(ctx context.Context, ID model.PullRequestID, status model.PullRequestStatus, mergedAt time.Time) error
-	GetByReviewer(ctx context.Context, ID model.UserID) ([]model.PullRequest, error)
+	BumpVersion(ctx context.Context, ID model.PullRequestID, version int64) error
+	UpdateMetadata(ctx context.Context, ID model.PullRequestID, metadata model.PullRequestMetadata) error
+	GetByReviewer(ctx context.Context, ID model.UserID, filter model.ReviewFilter) ([]model.PullRequest, error)
 	GetPullRequestCountsByStatus(ctx context.Context) (map[string]int, error)
+	StreamAll(ctx context.Context, fn func(pullRequest *model.PullRequest) error) error
 }
\ No newline at end of file
@@ -0,0 +1,14 @@
+package repository
+
+// Repositories bundles every repository the application uses, so a storage backend is swapped as a whole.
+type Repositories struct {
+	Team             TeamRepository
+	User             UserRepository
+	PullRequest      PullRequestRepository
+	ReviewAssignment ReviewAssignmentRepository
+	TeamRole         TeamRoleRepository
+	TeamMembership   TeamMembershipRepository
+	MembershipAudit  MembershipAuditRepository
+	Idempotency      IdempotencyRepository
+	Tx               TxManager
+}
@@ -9,11 +9,25 @@ import (
 type ReviewAssignmentRepository interface {
 	AssignReviewer(ctx context.Context, pullRequestID model.PullRequestID, reviewerID model.UserID) error
 	AssignReviewers(ctx context.Context, pullRequestID model.PullRequestID, reviewerIDs []model.UserID) error
+	RemoveReviewer(ctx context.Context, pullRequestID model.PullRequestID, reviewerID model.UserID) error
 	GetByReviewer(ctx context.Context, pullRequestID model.PullRequestID) ([]model.PullRequest, error)
 	Exists(ctx context.Context, pullRequestID model.PullRequestID, reviewerID model.UserID) (bool, error)
 	GetReviewers(ctx context.Context, pullRequestID model.PullRequestID) ([]model.User, error)
+	GetAssignments(ctx context.Context, pullRequestID model.PullRequestID) ([]model.ReviewAssignment, error)
 	ReplaceReviewer(
 		ctx context.Context, pullRequestID model.PullRequestID, oldReviewerID model.UserID, newReviewerID model.UserID,
 	) error
 	GetAssignmentCounts(ctx context.Context) (map[string]int, error)
+	GetTeamAssignmentCounts(ctx context.Context) (map[string]int, error)
+	GetOpenReviewCounts(ctx context.Context, reviewerIDs []model.UserID) (map[model.UserID]int, error)
+	GetReviewHistory(ctx context.Context, authorID model.UserID, reviewerIDs []model.UserID) (
+		map[model.UserID]model.ReviewHistory, error,
+	)
+	Insert(ctx context.Context, assignment *model.ReviewAssignment) error
+	InsertBatch(ctx context.Context, assignments []model.ReviewAssignment) error
+	StreamAll(ctx context.Context, fn func(assignment *model.ReviewAssignment) error) error
+	RecordDecline(ctx context.Context, decline *model.ReviewDecline) error
+	GetDecliners(ctx context.Context, pullRequestID model.PullRequestID) ([]model.UserID, error)
+	GetDeclineCounts(ctx context.Context) (map[string]int, error)
+	StreamDeclines(ctx context.Context, fn func(decline *model.ReviewDecline) error) error
 }
\ No newline at end of file
@@ -0,0 +1,16 @@
+package repository
+
+import (
+	"context"
+
+	"pull-request-review/internal/domain/model"
+)
+
+type TeamMembershipRepository interface {
+	Add(ctx context.Context, membership *model.TeamMembership) error
+	Remove(ctx context.Context, teamID model.TeamID, userID model.UserID) error
+	Exists(ctx context.Context, teamID model.TeamID, userID model.UserID) (bool, error)
+	GetByUser(ctx context.Context, userID model.UserID) ([]model.TeamMembership, error)
+	GetByTeam(ctx context.Context, teamID model.TeamID) ([]model.TeamMembership, error)
+	StreamAll(ctx context.Context, fn func(membership *model.TeamMembership) error) error
+}
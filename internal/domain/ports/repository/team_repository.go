@@ -15,5 +15,12 @@ type TeamRepository interface {
 	Exists(ctx context.Context, ID model.TeamID) (bool, error)
 	GetMembers(ctx context.Context, ID model.TeamID) ([]*model.User, error)
 	BulkDeactivateTeam(ctx context.Context, ID model.TeamID) error
+	Lock(ctx context.Context, ID model.TeamID) error
+	LockName(ctx context.Context, name string) error
 	CreateWithMembers(ctx context.Context, team *model.Team, members []model.User) error
+	GetAll(ctx context.Context) ([]model.Team, error)
+	GetAncestors(ctx context.Context, ID model.TeamID) ([]model.Team, error)
+	GetDescendants(ctx context.Context, ID model.TeamID) ([]model.Team, error)
+	ApplyImport(ctx context.Context, plan *model.ImportPlan) error
+	StreamAll(ctx context.Context, fn func(team *model.Team) error) error
 }
\ No newline at end of file
@@ -0,0 +1,14 @@
+package repository
+
+import (
+	"context"
+
+	"pull-request-review/internal/domain/model"
+)
+
+type TeamRoleRepository interface {
+	SetRole(ctx context.Context, teamID model.TeamID, userID model.UserID, role model.TeamRole) error
+	DeleteRole(ctx context.Context, teamID model.TeamID, userID model.UserID) error
+	GetRole(ctx context.Context, teamID model.TeamID, userID model.UserID) (model.TeamRole, error)
+	StreamAll(ctx context.Context, fn func(grant *model.TeamRoleGrant) error) error
+}
@@ -0,0 +1,15 @@
+package repository
+
+import (
+	"context"
+)
+
+// TxManager runs several repository calls as one unit of work. Repositories called with
+// the context passed to fn take part in the transaction, and a WithinTx call made inside
+// another one joins it instead of starting its own.
+type TxManager interface {
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+	// WithinReadOnlyTx runs fn over a snapshot of the data as of the start of the transaction,
+	// unaffected by what other callers commit meanwhile. fn must not write.
+	WithinReadOnlyTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
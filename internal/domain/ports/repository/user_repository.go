@@ -12,9 +12,11 @@ type UserRepository interface {
 	Upsert(ctx context.Context, user *model.User) error
 	UpdateActivity(ctx context.Context, ID model.UserID, isActive bool) error
 	GetByID(ctx context.Context, ID model.UserID) (*model.User, error)
+	GetByIDs(ctx context.Context, IDs []model.UserID) ([]model.User, error)
 	GetByTeam(ctx context.Context, teamID model.TeamID) ([]model.User, error)
 	Exists(ctx context.Context, ID model.UserID) (bool, error)
 	GetActiveByTeamExcluding(ctx context.Context, teamID model.TeamID, excludedUserIDs []model.UserID) (
 		[]model.User, error,
 	)
+	StreamAll(ctx context.Context, fn func(user *model.User) error) error
 }
\ No newline at end of file
@@ -0,0 +1,12 @@
+package service
+
+import (
+	"context"
+	"pull-request-review/internal/domain/model"
+	"pull-request-review/internal/domain/ports/archive"
+)
+
+type ArchiveService interface {
+	Export(ctx context.Context, writer archive.Writer) (map[model.ArchiveRecordKind]int, error)
+	Restore(ctx context.Context, reader archive.Reader) (map[model.ArchiveRecordKind]int, error)
+}
@@ -0,0 +1,10 @@
+package service
+
+import (
+	"context"
+	"pull-request-review/internal/domain/model"
+)
+
+type ImportService interface {
+	Import(ctx context.Context, roster *model.Roster, dryRun bool) (*model.ImportPlan, error)
+}
@@ -7,11 +7,22 @@ import (
 
 type PullRequestService interface {
 	CreatePullRequest(ctx context.Context, pullRequest *model.PullRequest) (*model.PullRequest, []model.UserID, error)
+	BatchCreatePullRequests(ctx context.Context, pullRequests []*model.PullRequest) ([]model.BatchCreateResult, error)
 	GetPullRequest(ctx context.Context, ID model.PullRequestID) (*model.PullRequest, error)
 	GetPullRequestReviewers(ctx context.Context, ID model.PullRequestID) ([]model.UserID, error)
-	GetUserReviews(ctx context.Context, userID model.UserID) ([]model.PullRequest, error)
-	ReassignPullRequest(ctx context.Context, ID model.PullRequestID, oldReviewerID model.UserID) (
+	GetReviewAssignments(ctx context.Context, ID model.PullRequestID) ([]model.ReviewAssignment, error)
+	SuggestReviewers(ctx context.Context, ID model.PullRequestID, limit int) ([]model.ReviewerSuggestion, error)
+	GetUserReviews(ctx context.Context, userID model.UserID, filter model.ReviewFilter) ([]model.PullRequest, error)
+	UpdatePullRequest(
+		ctx context.Context, ID model.PullRequestID, metadata model.PullRequestMetadata,
+	) (*model.PullRequest, error)
+	ReassignPullRequest(ctx context.Context, ID model.PullRequestID, oldReviewerID, newReviewerID model.UserID) (
 		*model.PullRequest, model.UserID, error,
 	)
+	DeclineReview(ctx context.Context, ID model.PullRequestID, reason string) (*model.PullRequest, model.UserID, error)
+	AddReviewer(ctx context.Context, ID model.PullRequestID, reviewerID model.UserID) (*model.PullRequest, error)
+	RemoveReviewer(ctx context.Context, ID model.PullRequestID, reviewerID model.UserID) (*model.PullRequest, error)
+	MarkReady(ctx context.Context, ID model.PullRequestID) (*model.PullRequest, []model.UserID, error)
 	MergePullRequest(ctx context.Context, ID model.PullRequestID) (*model.PullRequest, error)
+	ReleaseReviews(ctx context.Context, reviewerID model.UserID, teamID model.TeamID, policy model.ReviewPolicy) error
 }
\ No newline at end of file
@@ -8,7 +8,21 @@ import (
 type TeamService interface {
 	CreateTeam(ctx context.Context, team *model.Team) error
 	GetTeam(ctx context.Context, ID model.TeamID) (*model.Team, []model.User, error)
-	CreateTeamWithMembers(ctx context.Context, teamName string, members []model.User) error
+	CreateTeamWithMembers(
+		ctx context.Context, teamName string, members []model.User, opts model.TeamCreateOptions,
+	) error
 	GetTeamWithMembers(ctx context.Context, teamName string) (*model.Team, []model.User, error)
 	BulkDeactivateTeam(ctx context.Context, ID model.TeamID) error
+	AddMember(ctx context.Context, teamName string, member model.User, weight float64) (
+		*model.Team, []model.User, error,
+	)
+	RemoveMember(ctx context.Context, teamName string, userID model.UserID, policy model.ReviewPolicy) (
+		*model.Team, []model.User, error,
+	)
+	RenameTeam(ctx context.Context, teamName string, newName string) (*model.Team, []model.User, error)
+	GrantRole(ctx context.Context, teamName string, userID model.UserID, role model.TeamRole) (*model.Team, error)
+	RevokeRole(ctx context.Context, teamName string, userID model.UserID) (*model.Team, error)
+	SetParentTeam(ctx context.Context, teamName string, parentTeamName string) (*model.Team, error)
+	UpdateSettings(ctx context.Context, teamName string, settings model.TeamSettings) (*model.Team, error)
+	GetTeamTree(ctx context.Context, teamName string) ([]*model.TeamNode, error)
 }
\ No newline at end of file
@@ -9,4 +9,8 @@ type UserService interface {
 	GetUser(ctx context.Context, ID model.UserID) (*model.User, error)
 	GetUserWithTeamName(ctx context.Context, ID model.UserID) (*model.User, string, error)
 	SetActive(ctx context.Context, ID model.UserID, active bool) (*model.User, error)
+	GetUserTeams(ctx context.Context, ID model.UserID) ([]model.TeamMembership, error)
+	MoveToTeam(
+		ctx context.Context, ID model.UserID, fromTeamName string, teamName string, policy model.ReviewPolicy,
+	) (*model.User, error)
 }
\ No newline at end of file
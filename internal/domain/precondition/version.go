@@ -0,0 +1,29 @@
+package precondition
+
+import (
+	"context"
+	"slices"
+
+	"pull-request-review/internal/domain/rules"
+)
+
+type versionsKey struct{}
+
+// WithVersions limits the mutation in ctx to a pull request that is still at one of the
+// given versions, as the client saw it. An empty list matches nothing.
+func WithVersions(ctx context.Context, versions []int64) context.Context {
+	if versions == nil {
+		versions = []int64{}
+	}
+	return context.WithValue(ctx, versionsKey{}, versions)
+}
+
+// CheckVersion fails with rules.ErrPreconditionFailed when ctx expects other versions.
+// Without an expectation any version passes.
+func CheckVersion(ctx context.Context, version int64) error {
+	versions, ok := ctx.Value(versionsKey{}).([]int64)
+	if !ok || slices.Contains(versions, version) {
+		return nil
+	}
+	return rules.ErrPreconditionFailed
+}
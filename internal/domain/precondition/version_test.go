@@ -0,0 +1,29 @@
+package precondition_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"pull-request-review/internal/domain/precondition"
+	"pull-request-review/internal/domain/rules"
+)
+
+func TestCheckVersion(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		ctx  context.Context
+		want error
+	}{
+		{"no expectation", context.Background(), nil},
+		{"matching version", precondition.WithVersions(context.Background(), []int64{2, 3}), nil},
+		{"stale version", precondition.WithVersions(context.Background(), []int64{2}), rules.ErrPreconditionFailed},
+		{"no version matches", precondition.WithVersions(context.Background(), nil), rules.ErrPreconditionFailed},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := precondition.CheckVersion(tc.ctx, 3); !errors.Is(err, tc.want) {
+				t.Fatalf("expected %v, got %v", tc.want, err)
+			}
+		})
+	}
+}
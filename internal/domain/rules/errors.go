@@ -1,16 +1,70 @@
 package rules
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+
+	"pull-request-review/internal/domain/model"
+)
 
 var (
-	ErrNotFound            = errors.New("not found")
-	ErrUserExists          = errors.New("user already exists")
-	ErrTeamExists          = errors.New("team already exists")
-	ErrPullRequestExists   = errors.New("pull request already exists")
-	ErrPullRequestMerged   = errors.New("pull request merged")
-	ErrNotAssigned         = errors.New("review not assigned to pull request")
-	ErrNoCandidates        = errors.New("no active users to replace reviewer")
-	ErrTeamNotFound        = errors.New("team not found")
-	ErrUserNotFound        = errors.New("user not found")
-	ErrPullRequestNotFound = errors.New("pull request not found")
-)
\ No newline at end of file
+	ErrNotFound               = errors.New("not found")
+	ErrUserExists             = errors.New("user already exists")
+	ErrTeamExists             = errors.New("team already exists")
+	ErrPullRequestExists      = errors.New("pull request already exists")
+	ErrPullRequestMerged      = errors.New("pull request merged")
+	ErrNotAssigned            = errors.New("review not assigned to pull request")
+	ErrNoCandidates           = errors.New("no active users to replace reviewer")
+	ErrTeamNotFound           = errors.New("team not found")
+	ErrUserNotFound           = errors.New("user not found")
+	ErrPullRequestNotFound    = errors.New("pull request not found")
+	ErrForbidden              = errors.New("forbidden")
+	ErrInvalidRole            = errors.New("invalid team role")
+	ErrInvalidReviewPolicy    = errors.New("invalid review policy")
+	ErrMemberConflict         = errors.New("members already belong to another team")
+	ErrInvalidConflictMode    = errors.New("invalid member conflict mode")
+	ErrInvalidWeight          = errors.New("membership weight must be positive")
+	ErrTeamCycle              = errors.New("team cannot be nested under itself or its descendants")
+	ErrInvalidTeamSettings    = errors.New("invalid team settings")
+	ErrInvalidImport          = errors.New("invalid import file")
+	ErrUnsupportedArchive     = errors.New("unsupported archive format or version")
+	ErrRestoreTargetNotEmpty  = errors.New("restore target database is not empty")
+	ErrConcurrentModification = errors.New("pull request was changed concurrently")
+	ErrPreconditionFailed     = errors.New("pull request version does not match If-Match")
+	ErrBatchTooLarge          = errors.New("too many pull requests in one batch")
+	ErrAlreadyAssigned        = errors.New("reviewer already assigned to pull request")
+	ErrReviewerIsAuthor       = errors.New("author cannot review their own pull request")
+	ErrReviewerInactive       = errors.New("reviewer is not active")
+	ErrReviewerNotInTeam      = errors.New("reviewer is not a member of the author's team")
+	ErrTooManyReviewers       = errors.New("pull request already has the maximum number of reviewers")
+	ErrReviewerDeclined       = errors.New("reviewer declined the pull request")
+	ErrPullRequestDraft       = errors.New("pull request is a draft")
+	ErrPullRequestNotDraft    = errors.New("pull request is not a draft")
+	ErrInvalidMetadata        = errors.New("invalid pull request metadata")
+)
+
+// MemberConflictError lists the members that could not join a team because they belong to another one.
+type MemberConflictError struct {
+	Conflicts []model.MemberConflict
+}
+
+func (e *MemberConflictError) Error() string {
+	return fmt.Sprintf("%d members already belong to another team", len(e.Conflicts))
+}
+
+func (e *MemberConflictError) Unwrap() error {
+	return ErrMemberConflict
+}
+
+// ImportValidationError collects every problem found in an import file, so it can be fixed in one go.
+type ImportValidationError struct {
+	Problems []string
+}
+
+func (e *ImportValidationError) Error() string {
+	return fmt.Sprintf("import file has %d problems", len(e.Problems))
+}
+
+func (e *ImportValidationError) Unwrap() error {
+	return ErrInvalidImport
+}
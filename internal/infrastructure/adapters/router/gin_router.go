@@ -3,6 +3,7 @@ package router
 import (
 	"github.com/gin-gonic/gin"
 	"net/http"
+	"sync/atomic"
 )
 
 type GinRouter struct {
@@ -31,16 +32,55 @@ func httpHandlerAdapter(handler http.Handler) gin.HandlerFunc {
 
 func middlewareAdapter(middleware func(http.Handler) http.Handler) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		var reached atomic.Bool
 		terminal := http.HandlerFunc(
 			func(w http.ResponseWriter, r *http.Request) {
+				reached.Store(true)
+				c.Request = r
+
+				// handlers further down the chain must write through the writer the middleware handed over
+				if w != http.ResponseWriter(c.Writer) {
+					original := c.Writer
+					c.Writer = &ginWriterAdapter{ResponseWriter: original, writer: w}
+					defer func() {
+						c.Writer = original
+					}()
+				}
+
 				c.Next()
 			},
 		)
 		wrapped := middleware(terminal)
 		wrapped.ServeHTTP(c.Writer, c.Request)
+
+		// middleware that answered on its own must stop the rest of the chain
+		if !reached.Load() {
+			c.Abort()
+		}
 	}
 }
 
+type ginWriterAdapter struct {
+	gin.ResponseWriter
+	writer http.ResponseWriter
+}
+
+func (w *ginWriterAdapter) Header() http.Header {
+	return w.writer.Header()
+}
+
+func (w *ginWriterAdapter) WriteHeader(code int) {
+	w.writer.WriteHeader(code)
+}
+
+func (w *ginWriterAdapter) Write(data []byte) (int, error) {
+	return w.writer.Write(data)
+}
+
+func (w *ginWriterAdapter) WriteString(s string) (int, error) {
+	return w.writer.Write([]byte(s))
+}
+
 func (r *GinRouter) Handle(method, path string, handler http.Handler) {
 	g := r.activeGroup()
 	g.Handle(method, path, httpHandlerAdapter(handler))
@@ -0,0 +1,55 @@
+package archive
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"pull-request-review/internal/domain/ports/archive"
+)
+
+type Format string
+
+const (
+	FormatJSON   Format = "json"
+	FormatNDJSON Format = "ndjson"
+)
+
+// ParseFormat accepts a format name or a file extension.
+func ParseFormat(value string) (Format, error) {
+	switch strings.TrimPrefix(strings.ToLower(strings.TrimSpace(value)), ".") {
+	case "json":
+		return FormatJSON, nil
+	case "ndjson", "jsonl":
+		return FormatNDJSON, nil
+	default:
+		return "", fmt.Errorf("unsupported archive format %q", value)
+	}
+}
+
+func FormatFromPath(path string) (Format, error) {
+	return ParseFormat(filepath.Ext(path))
+}
+
+func NewWriter(w io.Writer, format Format) (archive.Writer, error) {
+	switch format {
+	case FormatJSON:
+		return newJSONWriter(w), nil
+	case FormatNDJSON:
+		return newNDJSONWriter(w), nil
+	default:
+		return nil, fmt.Errorf("unsupported archive format %q", format)
+	}
+}
+
+func NewReader(r io.Reader, format Format) (archive.Reader, error) {
+	switch format {
+	case FormatJSON:
+		return newJSONReader(r), nil
+	case FormatNDJSON:
+		return newNDJSONReader(r), nil
+	default:
+		return nil, fmt.Errorf("unsupported archive format %q", format)
+	}
+}
@@ -0,0 +1,205 @@
+package archive
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"pull-request-review/internal/domain/model"
+)
+
+// A JSON archive is a single object: the header fields followed by one array per record kind.
+// Both sides walk it incrementally, so neither has to hold the whole archive in memory.
+var jsonSections = map[model.ArchiveRecordKind]string{
+	model.ArchiveRecordTeam:             "teams",
+	model.ArchiveRecordUser:             "users",
+	model.ArchiveRecordMembership:       "memberships",
+	model.ArchiveRecordTeamRole:         "team_roles",
+	model.ArchiveRecordPullRequest:      "pull_requests",
+	model.ArchiveRecordReviewAssignment: "review_assignments",
+	model.ArchiveRecordReviewDecline:    "review_declines",
+}
+
+type jsonWriter struct {
+	buf     *bufio.Writer
+	section model.ArchiveRecordKind
+	empty   bool
+}
+
+func newJSONWriter(w io.Writer) *jsonWriter {
+	return &jsonWriter{buf: bufio.NewWriter(w)}
+}
+
+func (w *jsonWriter) WriteHeader(header model.ArchiveHeader) error {
+	data, err := json.Marshal(headerRecord(header))
+	if err != nil {
+		return err
+	}
+
+	// the header object is left open so the sections become its siblings
+	_, err = w.buf.Write(data[:len(data)-1])
+	return err
+}
+
+func (w *jsonWriter) Write(record *model.ArchiveRecord) error {
+	encoded, err := encodeRecord(record)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(encoded)
+	if err != nil {
+		return err
+	}
+
+	if record.Kind != w.section {
+		if w.section != "" {
+			w.buf.WriteString("]")
+		}
+		fmt.Fprintf(w.buf, ",%q:[", jsonSections[record.Kind])
+		w.section = record.Kind
+		w.empty = true
+	}
+	if !w.empty {
+		w.buf.WriteString(",")
+	}
+	w.empty = false
+
+	_, err = w.buf.Write(data)
+	return err
+}
+
+func (w *jsonWriter) Close() error {
+	if w.section != "" {
+		w.buf.WriteString("]")
+	}
+	w.buf.WriteString("}\n")
+	return w.buf.Flush()
+}
+
+type jsonReader struct {
+	dec     *json.Decoder
+	section model.ArchiveRecordKind
+	inArray bool
+	done    bool
+}
+
+func newJSONReader(r io.Reader) *jsonReader {
+	return &jsonReader{dec: json.NewDecoder(bufio.NewReader(r))}
+}
+
+// ReadHeader consumes the header fields, which precede every section.
+func (r *jsonReader) ReadHeader() (model.ArchiveHeader, error) {
+	err := r.expectDelim('{')
+	if err != nil {
+		return model.ArchiveHeader{}, err
+	}
+
+	var header model.ArchiveHeader
+	for r.dec.More() {
+		key, err := r.readKey()
+		if err != nil {
+			return model.ArchiveHeader{}, err
+		}
+
+		switch key {
+		case "format":
+			err = r.dec.Decode(&header.Format)
+		case "version":
+			err = r.dec.Decode(&header.Version)
+		case "created_at":
+			err = r.dec.Decode(&header.CreatedAt)
+		default:
+			err = r.openSection(key)
+			if err != nil {
+				return model.ArchiveHeader{}, err
+			}
+			return header, nil
+		}
+		if err != nil {
+			return model.ArchiveHeader{}, fmt.Errorf("reading archive header: %w", err)
+		}
+	}
+
+	return header, r.closeObject()
+}
+
+func (r *jsonReader) Next() (*model.ArchiveRecord, error) {
+	for !r.done {
+		if r.inArray {
+			if r.dec.More() {
+				var data json.RawMessage
+				err := r.dec.Decode(&data)
+				if err != nil {
+					return nil, err
+				}
+				return decodeRecord(r.section, data)
+			}
+
+			err := r.expectDelim(']')
+			if err != nil {
+				return nil, err
+			}
+			r.inArray = false
+		}
+
+		if !r.dec.More() {
+			err := r.closeObject()
+			if err != nil {
+				return nil, err
+			}
+			break
+		}
+
+		key, err := r.readKey()
+		if err != nil {
+			return nil, err
+		}
+		err = r.openSection(key)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return nil, io.EOF
+}
+
+func (r *jsonReader) openSection(key string) error {
+	for kind, section := range jsonSections {
+		if section == key {
+			r.section = kind
+			r.inArray = true
+			return r.expectDelim('[')
+		}
+	}
+	return fmt.Errorf("unknown archive section %q", key)
+}
+
+func (r *jsonReader) closeObject() error {
+	r.done = true
+	return r.expectDelim('}')
+}
+
+func (r *jsonReader) readKey() (string, error) {
+	token, err := r.dec.Token()
+	if err != nil {
+		return "", err
+	}
+	key, ok := token.(string)
+	if !ok {
+		return "", errors.New("malformed archive: expected an object key")
+	}
+	return key, nil
+}
+
+func (r *jsonReader) expectDelim(delim json.Delim) error {
+	token, err := r.dec.Token()
+	if err != nil {
+		return err
+	}
+	if token != delim {
+		return fmt.Errorf("malformed archive: expected %q", delim)
+	}
+	return nil
+}
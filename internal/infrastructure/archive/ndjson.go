@@ -0,0 +1,89 @@
+package archive
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"pull-request-review/internal/domain/model"
+)
+
+const ndjsonHeaderType = "header"
+
+// ndjsonLine wraps every line of an NDJSON archive: the header first, then one record per line.
+type ndjsonLine struct {
+	Type string          `json:"type"`
+	Data json.RawMessage `json:"data"`
+}
+
+type ndjsonWriter struct {
+	buf *bufio.Writer
+	enc *json.Encoder
+}
+
+func newNDJSONWriter(w io.Writer) *ndjsonWriter {
+	buf := bufio.NewWriter(w)
+	return &ndjsonWriter{buf: buf, enc: json.NewEncoder(buf)}
+}
+
+func (w *ndjsonWriter) WriteHeader(header model.ArchiveHeader) error {
+	return w.writeLine(ndjsonHeaderType, headerRecord(header))
+}
+
+func (w *ndjsonWriter) Write(record *model.ArchiveRecord) error {
+	encoded, err := encodeRecord(record)
+	if err != nil {
+		return err
+	}
+	return w.writeLine(string(record.Kind), encoded)
+}
+
+func (w *ndjsonWriter) writeLine(lineType string, value any) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return w.enc.Encode(ndjsonLine{Type: lineType, Data: data})
+}
+
+func (w *ndjsonWriter) Close() error {
+	return w.buf.Flush()
+}
+
+type ndjsonReader struct {
+	dec *json.Decoder
+}
+
+func newNDJSONReader(r io.Reader) *ndjsonReader {
+	return &ndjsonReader{dec: json.NewDecoder(bufio.NewReader(r))}
+}
+
+func (r *ndjsonReader) ReadHeader() (model.ArchiveHeader, error) {
+	var line ndjsonLine
+	err := r.dec.Decode(&line)
+	if err != nil {
+		return model.ArchiveHeader{}, fmt.Errorf("reading archive header: %w", err)
+	}
+	if line.Type != ndjsonHeaderType {
+		return model.ArchiveHeader{}, errors.New("archive does not start with a header")
+	}
+
+	var header headerRecord
+	err = json.Unmarshal(line.Data, &header)
+	if err != nil {
+		return model.ArchiveHeader{}, fmt.Errorf("reading archive header: %w", err)
+	}
+
+	return model.ArchiveHeader(header), nil
+}
+
+func (r *ndjsonReader) Next() (*model.ArchiveRecord, error) {
+	var line ndjsonLine
+	err := r.dec.Decode(&line)
+	if err != nil {
+		return nil, err
+	}
+	return decodeRecord(model.ArchiveRecordKind(line.Type), line.Data)
+}
@@ -0,0 +1,285 @@
+package archive
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"pull-request-review/internal/domain/model"
+)
+
+type headerRecord struct {
+	Format    string    `json:"format"`
+	Version   int       `json:"version"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type teamRecord struct {
+	TeamID           uuid.UUID  `json:"team_id"`
+	Name             string     `json:"name"`
+	ParentTeamID     *uuid.UUID `json:"parent_team_id,omitempty"`
+	MaxReviewers     *int       `json:"max_reviewers,omitempty"`
+	ReviewerFallback *bool      `json:"reviewer_fallback,omitempty"`
+	CreatedAt        time.Time  `json:"created_at"`
+}
+
+type userRecord struct {
+	UserID    uuid.UUID  `json:"user_id"`
+	Username  string     `json:"username"`
+	TeamID    *uuid.UUID `json:"team_id,omitempty"`
+	IsActive  bool       `json:"is_active"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+type membershipRecord struct {
+	TeamID    uuid.UUID `json:"team_id"`
+	UserID    uuid.UUID `json:"user_id"`
+	Weight    float64   `json:"weight"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type teamRoleRecord struct {
+	TeamID    uuid.UUID `json:"team_id"`
+	UserID    uuid.UUID `json:"user_id"`
+	Role      string    `json:"role"`
+	GrantedAt time.Time `json:"granted_at"`
+}
+
+type pullRequestRecord struct {
+	PullRequestID uuid.UUID  `json:"pull_request_id"`
+	Name          string     `json:"name"`
+	AuthorID      uuid.UUID  `json:"author_id"`
+	Status        string     `json:"status"`
+	CreatedAt     time.Time  `json:"created_at"`
+	MergedAt      *time.Time `json:"merged_at,omitempty"`
+	// Version is missing in archives written before pull requests were versioned.
+	Version int64 `json:"version,omitempty"`
+	// The metadata fields are missing in archives written before pull requests carried them.
+	Repository   string   `json:"repository,omitempty"`
+	SourceBranch string   `json:"source_branch,omitempty"`
+	TargetBranch string   `json:"target_branch,omitempty"`
+	URL          string   `json:"url,omitempty"`
+	Description  string   `json:"description,omitempty"`
+	Labels       []string `json:"labels,omitempty"`
+	ChangedPaths []string `json:"changed_paths,omitempty"`
+}
+
+type reviewAssignmentRecord struct {
+	PullRequestID uuid.UUID `json:"pull_request_id"`
+	ReviewerID    uuid.UUID `json:"reviewer_id"`
+	AssignedAt    time.Time `json:"assigned_at"`
+	Rule          string    `json:"rule,omitempty"`
+}
+
+type reviewDeclineRecord struct {
+	PullRequestID uuid.UUID `json:"pull_request_id"`
+	ReviewerID    uuid.UUID `json:"reviewer_id"`
+	Reason        string    `json:"reason"`
+	DeclinedAt    time.Time `json:"declined_at"`
+}
+
+func optionalUUID(id uuid.UUID) *uuid.UUID {
+	if id == uuid.Nil {
+		return nil
+	}
+	return &id
+}
+
+func uuidOrNil(id *uuid.UUID) uuid.UUID {
+	if id == nil {
+		return uuid.Nil
+	}
+	return *id
+}
+
+func encodeRecord(record *model.ArchiveRecord) (any, error) {
+	switch record.Kind {
+	case model.ArchiveRecordTeam:
+		team := record.Team
+		return teamRecord{
+			TeamID:           uuid.UUID(team.TeamID),
+			Name:             team.Name,
+			ParentTeamID:     optionalUUID(team.ParentTeamID),
+			MaxReviewers:     team.Settings.MaxReviewers,
+			ReviewerFallback: team.Settings.ReviewerFallback,
+			CreatedAt:        team.CreatedAt,
+		}, nil
+	case model.ArchiveRecordUser:
+		user := record.User
+		return userRecord{
+			UserID:    uuid.UUID(user.ID),
+			Username:  user.Username,
+			TeamID:    optionalUUID(user.TeamID),
+			IsActive:  user.IsActive,
+			CreatedAt: user.CreatedAt,
+			UpdatedAt: user.UpdatedAt,
+		}, nil
+	case model.ArchiveRecordMembership:
+		membership := record.Membership
+		return membershipRecord{
+			TeamID:    uuid.UUID(membership.TeamID),
+			UserID:    uuid.UUID(membership.UserID),
+			Weight:    membership.Weight,
+			CreatedAt: membership.CreatedAt,
+		}, nil
+	case model.ArchiveRecordTeamRole:
+		grant := record.TeamRole
+		return teamRoleRecord{
+			TeamID:    uuid.UUID(grant.TeamID),
+			UserID:    uuid.UUID(grant.UserID),
+			Role:      string(grant.Role),
+			GrantedAt: grant.GrantedAt,
+		}, nil
+	case model.ArchiveRecordPullRequest:
+		pr := record.PullRequest
+		encoded := pullRequestRecord{
+			PullRequestID: uuid.UUID(pr.PullRequestID),
+			Name:          pr.Name,
+			AuthorID:      uuid.UUID(pr.AuthorID),
+			Status:        string(pr.Status),
+			CreatedAt:     pr.CreatedAt,
+			Version:       pr.Version,
+			Repository:    pr.Repository,
+			SourceBranch:  pr.SourceBranch,
+			TargetBranch:  pr.TargetBranch,
+			URL:           pr.URL,
+			Description:   pr.Description,
+			Labels:        pr.Labels,
+			ChangedPaths:  pr.ChangedPaths,
+		}
+		if !pr.MergedAt.IsZero() {
+			mergedAt := pr.MergedAt
+			encoded.MergedAt = &mergedAt
+		}
+		return encoded, nil
+	case model.ArchiveRecordReviewAssignment:
+		assignment := record.ReviewAssignment
+		return reviewAssignmentRecord{
+			PullRequestID: uuid.UUID(assignment.PullRequestID),
+			ReviewerID:    uuid.UUID(assignment.ReviewerID),
+			AssignedAt:    assignment.AssignedAt,
+			Rule:          assignment.Rule,
+		}, nil
+	case model.ArchiveRecordReviewDecline:
+		decline := record.ReviewDecline
+		return reviewDeclineRecord{
+			PullRequestID: uuid.UUID(decline.PullRequestID),
+			ReviewerID:    uuid.UUID(decline.ReviewerID),
+			Reason:        decline.Reason,
+			DeclinedAt:    decline.DeclinedAt,
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown archive record kind %q", record.Kind)
+	}
+}
+
+func decodeRecord(kind model.ArchiveRecordKind, data json.RawMessage) (*model.ArchiveRecord, error) {
+	record := &model.ArchiveRecord{Kind: kind}
+
+	switch kind {
+	case model.ArchiveRecordTeam:
+		var decoded teamRecord
+		if err := json.Unmarshal(data, &decoded); err != nil {
+			return nil, err
+		}
+		record.Team = &model.Team{
+			TeamID:       model.TeamID(decoded.TeamID),
+			Name:         decoded.Name,
+			ParentTeamID: uuidOrNil(decoded.ParentTeamID),
+			Settings: model.TeamSettings{
+				MaxReviewers:     decoded.MaxReviewers,
+				ReviewerFallback: decoded.ReviewerFallback,
+			},
+			CreatedAt: decoded.CreatedAt,
+		}
+	case model.ArchiveRecordUser:
+		var decoded userRecord
+		if err := json.Unmarshal(data, &decoded); err != nil {
+			return nil, err
+		}
+		record.User = &model.User{
+			ID:        model.UserID(decoded.UserID),
+			Username:  decoded.Username,
+			TeamID:    uuidOrNil(decoded.TeamID),
+			IsActive:  decoded.IsActive,
+			CreatedAt: decoded.CreatedAt,
+			UpdatedAt: decoded.UpdatedAt,
+		}
+	case model.ArchiveRecordMembership:
+		var decoded membershipRecord
+		if err := json.Unmarshal(data, &decoded); err != nil {
+			return nil, err
+		}
+		record.Membership = &model.TeamMembership{
+			TeamID:    model.TeamID(decoded.TeamID),
+			UserID:    model.UserID(decoded.UserID),
+			Weight:    decoded.Weight,
+			CreatedAt: decoded.CreatedAt,
+		}
+	case model.ArchiveRecordTeamRole:
+		var decoded teamRoleRecord
+		if err := json.Unmarshal(data, &decoded); err != nil {
+			return nil, err
+		}
+		record.TeamRole = &model.TeamRoleGrant{
+			TeamID:    model.TeamID(decoded.TeamID),
+			UserID:    model.UserID(decoded.UserID),
+			Role:      model.TeamRole(decoded.Role),
+			GrantedAt: decoded.GrantedAt,
+		}
+	case model.ArchiveRecordPullRequest:
+		var decoded pullRequestRecord
+		if err := json.Unmarshal(data, &decoded); err != nil {
+			return nil, err
+		}
+		record.PullRequest = &model.PullRequest{
+			PullRequestID: model.PullRequestID(decoded.PullRequestID),
+			Name:          decoded.Name,
+			AuthorID:      model.UserID(decoded.AuthorID),
+			Status:        model.PullRequestStatus(decoded.Status),
+			CreatedAt:     decoded.CreatedAt,
+			Version:       max(decoded.Version, model.InitialPullRequestVersion),
+			PullRequestMetadata: model.PullRequestMetadata{
+				Repository:   decoded.Repository,
+				SourceBranch: decoded.SourceBranch,
+				TargetBranch: decoded.TargetBranch,
+				URL:          decoded.URL,
+				Description:  decoded.Description,
+				Labels:       decoded.Labels,
+			},
+			ChangedPaths: decoded.ChangedPaths,
+		}
+		if decoded.MergedAt != nil {
+			record.PullRequest.MergedAt = *decoded.MergedAt
+		}
+	case model.ArchiveRecordReviewAssignment:
+		var decoded reviewAssignmentRecord
+		if err := json.Unmarshal(data, &decoded); err != nil {
+			return nil, err
+		}
+		record.ReviewAssignment = &model.ReviewAssignment{
+			PullRequestID: model.PullRequestID(decoded.PullRequestID),
+			ReviewerID:    model.UserID(decoded.ReviewerID),
+			AssignedAt:    decoded.AssignedAt,
+			Rule:          decoded.Rule,
+		}
+	case model.ArchiveRecordReviewDecline:
+		var decoded reviewDeclineRecord
+		if err := json.Unmarshal(data, &decoded); err != nil {
+			return nil, err
+		}
+		record.ReviewDecline = &model.ReviewDecline{
+			PullRequestID: model.PullRequestID(decoded.PullRequestID),
+			ReviewerID:    model.UserID(decoded.ReviewerID),
+			Reason:        decoded.Reason,
+			DeclinedAt:    decoded.DeclinedAt,
+		}
+	default:
+		return nil, fmt.Errorf("unknown archive record kind %q", kind)
+	}
+
+	return record, nil
+}
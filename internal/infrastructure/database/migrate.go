@@ -0,0 +1,299 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"pull-request-review/internal/infrastructure/adapters/logger"
+)
+
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Migrator applies the NNN_name.up.sql / NNN_name.down.sql files from source, usually the
+// embedded migrations.FS. It keeps its state in the same schema_migrations table as the
+// migrate/migrate tool, so the two can be mixed.
+type Migrator struct {
+	db     *Database
+	source fs.FS
+	logger logger.Logger
+}
+
+func NewMigrator(db *Database, source fs.FS, log logger.Logger) *Migrator {
+	return &Migrator{
+		db:     db,
+		source: source,
+		logger: log,
+	}
+}
+
+// migrationLockKey is the advisory lock that keeps concurrently starting instances from
+// migrating the same database at once.
+const migrationLockKey int64 = 7_245_300_118
+
+type MigrationStatus struct {
+	Version int64
+	Name    string
+	Applied bool
+}
+
+// Up applies every pending migration and returns how many were applied.
+func (m *Migrator) Up(ctx context.Context) (int, error) {
+	migrations, err := loadMigrations(m.source)
+	if err != nil {
+		return 0, err
+	}
+
+	applied := 0
+	err = m.withLock(ctx, func(conn *pgxpool.Conn) error {
+		current, err := currentVersion(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for _, migration := range migrations {
+			if migration.Version <= current {
+				continue
+			}
+
+			err = apply(ctx, conn, migration.Up, migration.Version)
+			if err != nil {
+				return fmt.Errorf("migration %d_%s: %w", migration.Version, migration.Name, err)
+			}
+			m.logger.Info(fmt.Sprintf("Applied migration %d_%s", migration.Version, migration.Name))
+			applied++
+		}
+
+		return nil
+	})
+
+	return applied, err
+}
+
+// Down rolls back the last steps applied migrations and returns how many were rolled back.
+func (m *Migrator) Down(ctx context.Context, steps int) (int, error) {
+	migrations, err := loadMigrations(m.source)
+	if err != nil {
+		return 0, err
+	}
+
+	rolledBack := 0
+	err = m.withLock(ctx, func(conn *pgxpool.Conn) error {
+		current, err := currentVersion(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for i := len(migrations) - 1; i >= 0 && rolledBack < steps; i-- {
+			migration := migrations[i]
+			if migration.Version > current {
+				continue
+			}
+
+			var previous int64
+			if i > 0 {
+				previous = migrations[i-1].Version
+			}
+
+			err = apply(ctx, conn, migration.Down, previous)
+			if err != nil {
+				return fmt.Errorf("migration %d_%s: %w", migration.Version, migration.Name, err)
+			}
+			m.logger.Info(fmt.Sprintf("Rolled back migration %d_%s", migration.Version, migration.Name))
+			rolledBack++
+		}
+
+		return nil
+	})
+
+	return rolledBack, err
+}
+
+// Status lists every known migration and whether it is applied. dirty reports a migration
+// that failed halfway under migrate/migrate and needs a manual fix.
+func (m *Migrator) Status(ctx context.Context) (statuses []MigrationStatus, dirty bool, err error) {
+	migrations, err := loadMigrations(m.source)
+	if err != nil {
+		return nil, false, err
+	}
+
+	conn, err := m.db.GetPool().Acquire(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	defer conn.Release()
+
+	version, dirty, err := readVersion(ctx, conn)
+	if err != nil {
+		return nil, false, err
+	}
+
+	statuses = make([]MigrationStatus, 0, len(migrations))
+	for _, migration := range migrations {
+		statuses = append(
+			statuses, MigrationStatus{
+				Version: migration.Version,
+				Name:    migration.Name,
+				Applied: migration.Version <= version,
+			},
+		)
+	}
+
+	return statuses, dirty, nil
+}
+
+// withLock runs fn on a single connection holding the migration advisory lock. The lock is
+// session level, so everything under it has to go through that connection.
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *pgxpool.Conn) error) error {
+	conn, err := m.db.GetPool().Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+
+	_, err = conn.Exec(ctx, `SELECT pg_advisory_lock($1)`, migrationLockKey)
+	if err != nil {
+		return fmt.Errorf("failed to acquire migration lock: %w", err)
+	}
+	defer func() {
+		_, unlockErr := conn.Exec(context.WithoutCancel(ctx), `SELECT pg_advisory_unlock($1)`, migrationLockKey)
+		if unlockErr != nil {
+			m.logger.Error(unlockErr, "Failed to release migration lock")
+		}
+	}()
+
+	return fn(conn)
+}
+
+// apply runs the migration and records the new version in one transaction. Version 0 means
+// nothing is applied, which migrate/migrate stores as an empty table.
+func apply(ctx context.Context, conn *pgxpool.Conn, sql string, version int64) error {
+	tx, err := conn.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, sql)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, `DELETE FROM schema_migrations`)
+	if err != nil {
+		return err
+	}
+
+	if version > 0 {
+		_, err = tx.Exec(ctx, `INSERT INTO schema_migrations (version, dirty) VALUES ($1, false)`, version)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+func currentVersion(ctx context.Context, conn *pgxpool.Conn) (int64, error) {
+	version, dirty, err := readVersion(ctx, conn)
+	if err != nil {
+		return 0, err
+	}
+	if dirty {
+		return 0, fmt.Errorf("database is dirty at version %d, fix it manually before migrating", version)
+	}
+	return version, nil
+}
+
+func readVersion(ctx context.Context, conn *pgxpool.Conn) (version int64, dirty bool, err error) {
+	_, err = conn.Exec(
+		ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (version BIGINT NOT NULL PRIMARY KEY, dirty BOOLEAN NOT NULL)`,
+	)
+	if err != nil {
+		return 0, false, err
+	}
+
+	err = conn.QueryRow(ctx, `SELECT version, dirty FROM schema_migrations LIMIT 1`).Scan(&version, &dirty)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+
+	return version, dirty, nil
+}
+
+// loadMigrations pairs the up and down files found in source and orders them by version.
+func loadMigrations(source fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(source, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int64]*Migration)
+	for _, entry := range entries {
+		fileName := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(fileName, ".sql") {
+			continue
+		}
+
+		base := strings.TrimSuffix(fileName, ".sql")
+		direction := base[strings.LastIndex(base, ".")+1:]
+		base = strings.TrimSuffix(base, "."+direction)
+
+		versionPart, name, ok := strings.Cut(base, "_")
+		if !ok || (direction != "up" && direction != "down") {
+			return nil, fmt.Errorf("unexpected migration file name %q", fileName)
+		}
+		version, err := strconv.ParseInt(versionPart, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("unexpected migration file name %q", fileName)
+		}
+
+		data, err := fs.ReadFile(source, fileName)
+		if err != nil {
+			return nil, err
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: name}
+			byVersion[version] = migration
+		}
+		if migration.Name != name {
+			return nil, fmt.Errorf("migrations %q and %q share version %d", migration.Name, name, version)
+		}
+		script := &migration.Down
+		if direction == "up" {
+			script = &migration.Up
+		}
+		if *script != "" {
+			return nil, fmt.Errorf("migration %d_%s has more than one %s file", version, name, direction)
+		}
+		*script = string(data)
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == "" || migration.Down == "" {
+			return nil, fmt.Errorf("migration %d_%s is missing its up or down file", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+
+	return migrations, nil
+}
@@ -0,0 +1,86 @@
+package database
+
+import (
+	"io/fs"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"pull-request-review/db/migrations"
+)
+
+func TestLoadMigrations(t *testing.T) {
+	file := func(data string) *fstest.MapFile { return &fstest.MapFile{Data: []byte(data)} }
+
+	t.Run("pairs and orders by version", func(t *testing.T) {
+		source := fstest.MapFS{
+			"010_tenth.up.sql":    file("up 10"),
+			"010_tenth.down.sql":  file("down 10"),
+			"2_second.down.sql":   file("down 2"),
+			"2_second.up.sql":     file("up 2"),
+			"001_first.up.sql":    file("up 1"),
+			"001_first.down.sql":  file("down 1"),
+			"README.md":           file("not a migration"),
+			"sqlite/001_x.up.sql": file("in a subdirectory"),
+		}
+
+		got, err := loadMigrations(source)
+		if err != nil {
+			t.Fatalf("load migrations: %v", err)
+		}
+		want := []Migration{
+			{Version: 1, Name: "first", Up: "up 1", Down: "down 1"},
+			{Version: 2, Name: "second", Up: "up 2", Down: "down 2"},
+			{Version: 10, Name: "tenth", Up: "up 10", Down: "down 10"},
+		}
+		if len(got) != len(want) {
+			t.Fatalf("loaded %+v, want %+v", got, want)
+		}
+		for i := range want {
+			if got[i] != want[i] {
+				t.Fatalf("loaded %+v, want %+v", got, want)
+			}
+		}
+	})
+
+	for _, tc := range []struct {
+		name   string
+		source fstest.MapFS
+		want   string
+	}{
+		{"missing down file", fstest.MapFS{"001_first.up.sql": file("up")}, "missing its up or down file"},
+		{"missing up file", fstest.MapFS{"001_first.down.sql": file("down")}, "missing its up or down file"},
+		{"empty up file", fstest.MapFS{"001_first.up.sql": file(""), "001_first.down.sql": file("down")},
+			"missing its up or down file"},
+		{"no direction", fstest.MapFS{"001_first.sql": file("up")}, `unexpected migration file name "001_first.sql"`},
+		{"unknown direction", fstest.MapFS{"001_first.sideways.sql": file("up")}, "unexpected migration file name"},
+		{"no name", fstest.MapFS{"001.up.sql": file("up")}, `unexpected migration file name "001.up.sql"`},
+		{"version is not a number", fstest.MapFS{"one_first.up.sql": file("up")}, "unexpected migration file name"},
+		{"version used twice", fstest.MapFS{"001_first.up.sql": file("up"), "001_other.up.sql": file("up")},
+			`migrations "first" and "other" share version 1`},
+		{"same file twice", fstest.MapFS{"1_first.up.sql": file("up"), "001_first.up.sql": file("up")},
+			"migration 1_first has more than one up file"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := loadMigrations(tc.source)
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Fatalf("expected an error containing %q, got %v", tc.want, err)
+			}
+		})
+	}
+
+	// the shipped migrations have to load and number their versions without gaps
+	for name, source := range map[string]fs.FS{"postgres": migrations.FS, "sqlite": migrations.SQLiteFS} {
+		t.Run("shipped "+name+" migrations", func(t *testing.T) {
+			loaded, err := loadMigrations(source)
+			if err != nil || len(loaded) == 0 {
+				t.Fatalf("load migrations: %d, %v", len(loaded), err)
+			}
+			for i, migration := range loaded {
+				if migration.Version != int64(i+1) {
+					t.Fatalf("migration %d_%s should have version %d", migration.Version, migration.Name, i+1)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,218 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"strings"
+
+	_ "modernc.org/sqlite"
+
+	"pull-request-review/config"
+	"pull-request-review/internal/infrastructure/adapters/logger"
+)
+
+// SQLiteDatabase is the single-file backend for small installations and local development.
+type SQLiteDatabase struct {
+	db     *sql.DB
+	cfg    config.DatabaseConfig
+	logger logger.Logger
+}
+
+func NewSQLiteDatabase(cfg config.DatabaseConfig, log logger.Logger) *SQLiteDatabase {
+	return &SQLiteDatabase{
+		cfg:    cfg,
+		logger: log,
+	}
+}
+
+// Connect opens the database file from the URL setting, ":memory:" works too. SQLite allows
+// one writer at a time, so everything goes through a single connection: transactions never
+// fail on a busy database and an in-memory database is shared by every repository.
+// Timestamps are written in SQLite's own format, which sorts correctly as text.
+func (d *SQLiteDatabase) Connect(ctx context.Context) error {
+	dsn := d.cfg.URL
+	separator := "?"
+	if strings.Contains(dsn, "?") {
+		separator = "&"
+	}
+	dsn += separator + "_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)&_time_format=sqlite"
+
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return fmt.Errorf("failed to open sqlite database: %w", err)
+	}
+	db.SetMaxOpenConns(1)
+	db.SetConnMaxLifetime(0)
+	db.SetConnMaxIdleTime(0)
+
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return fmt.Errorf("failed to ping sqlite database: %w", err)
+	}
+
+	d.db = db
+	d.logger.Info("SQLite database opened")
+
+	return nil
+}
+
+func (d *SQLiteDatabase) Ping(ctx context.Context) error {
+	if d.db == nil {
+		return fmt.Errorf("database is not opened")
+	}
+	return d.db.PingContext(ctx)
+}
+
+func (d *SQLiteDatabase) Close() {
+	if d.db != nil {
+		d.logger.Info("Closing sqlite database")
+		d.db.Close()
+	}
+}
+
+func (d *SQLiteDatabase) GetDB() *sql.DB {
+	return d.db
+}
+
+// Migrate applies the pending migrations from source, usually migrations.SQLiteFS. The
+// version is kept in schema_migrations like the Postgres Migrator does.
+func (d *SQLiteDatabase) Migrate(ctx context.Context, source fs.FS) (int, error) {
+	migrations, err := loadMigrations(source)
+	if err != nil {
+		return 0, err
+	}
+
+	_, err = d.db.ExecContext(
+		ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (version INTEGER NOT NULL PRIMARY KEY, dirty BOOLEAN NOT NULL)`,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	var current int64
+	err = d.db.QueryRowContext(ctx, `SELECT version FROM schema_migrations LIMIT 1`).Scan(&current)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return 0, err
+	}
+
+	applied := 0
+	for _, migration := range migrations {
+		if migration.Version <= current {
+			continue
+		}
+
+		err = d.apply(ctx, migration)
+		if err != nil {
+			return applied, fmt.Errorf("migration %d_%s: %w", migration.Version, migration.Name, err)
+		}
+		d.logger.Info(fmt.Sprintf("Applied migration %d_%s", migration.Version, migration.Name))
+		applied++
+	}
+
+	return applied, nil
+}
+
+// apply runs a migration with foreign keys off, the way SQLite documents for rebuilding a
+// table: dropping the old table would otherwise cascade to the rows that reference it. The
+// keys are checked before the migration commits instead. The pragma has no effect inside a
+// transaction, so it is set around it, on the single connection.
+func (d *SQLiteDatabase) apply(ctx context.Context, migration Migration) error {
+	_, err := d.db.ExecContext(ctx, `PRAGMA foreign_keys = OFF`)
+	if err != nil {
+		return err
+	}
+	defer d.db.ExecContext(context.WithoutCancel(ctx), `PRAGMA foreign_keys = ON`)
+
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, migration.Up)
+	if err != nil {
+		return err
+	}
+
+	violations, err := tx.QueryContext(ctx, `PRAGMA foreign_key_check`)
+	if err != nil {
+		return err
+	}
+	violated := violations.Next()
+	violations.Close()
+	if err := violations.Err(); err != nil {
+		return err
+	}
+	if violated {
+		return errors.New("migration leaves rows with broken foreign keys")
+	}
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM schema_migrations`)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `INSERT INTO schema_migrations (version, dirty) VALUES (?, false)`, migration.Version)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// SQLiteQuerier is the database/sql counterpart of Querier.
+type SQLiteQuerier interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+type sqliteTxKey struct{}
+
+// Querier returns the transaction started by WithinTx for ctx, or the database outside of one.
+// With a single connection, a statement that bypassed the transaction would wait for it forever.
+func (d *SQLiteDatabase) Querier(ctx context.Context) SQLiteQuerier {
+	if tx, ok := ctx.Value(sqliteTxKey{}).(*sql.Tx); ok {
+		return tx
+	}
+	return d.db
+}
+
+// WithinTx runs fn in a transaction that is committed when fn succeeds and rolled back
+// otherwise. Inside another transaction fn simply joins it, only the outermost call commits.
+func (d *SQLiteDatabase) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return d.withinTx(ctx, nil, fn)
+}
+
+// WithinReadOnlyTx runs fn in a read only transaction. The transaction holds the only
+// connection, so nobody can write until it ends and every statement sees the same data.
+func (d *SQLiteDatabase) WithinReadOnlyTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return d.withinTx(ctx, &sql.TxOptions{ReadOnly: true}, fn)
+}
+
+func (d *SQLiteDatabase) withinTx(ctx context.Context, opts *sql.TxOptions, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(sqliteTxKey{}).(*sql.Tx); ok {
+		return fn(ctx)
+	}
+
+	tx, err := d.db.BeginTx(ctx, opts)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	if err := fn(context.WithValue(ctx, sqliteTxKey{}, tx)); err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil && !errors.Is(rollbackErr, sql.ErrTxDone) {
+			d.logger.Error(rollbackErr, "failed to roll back transaction")
+		}
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
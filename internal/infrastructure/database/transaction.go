@@ -0,0 +1,65 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Querier is what repositories run their statements on: the pool, or the transaction
+// the context carries.
+type Querier interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	CopyFrom(ctx context.Context, table pgx.Identifier, columns []string, rows pgx.CopyFromSource) (int64, error)
+}
+
+type txKey struct{}
+
+// Querier returns the transaction started by WithinTx for ctx, or the pool outside of one.
+func (d *Database) Querier(ctx context.Context) Querier {
+	if tx, ok := ctx.Value(txKey{}).(pgx.Tx); ok {
+		return tx
+	}
+	return d.pool
+}
+
+// WithinTx runs fn in a transaction that is committed when fn succeeds and rolled back
+// otherwise. Inside another transaction fn simply joins it, only the outermost call commits.
+func (d *Database) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return d.withinTx(ctx, pgx.TxOptions{}, fn)
+}
+
+// WithinReadOnlyTx runs fn in a read only REPEATABLE READ transaction, so every statement sees
+// the same snapshot. Inside another transaction fn joins it and sees what that one sees.
+func (d *Database) WithinReadOnlyTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return d.withinTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly}, fn)
+}
+
+func (d *Database) withinTx(ctx context.Context, opts pgx.TxOptions, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(pgx.Tx); ok {
+		return fn(ctx)
+	}
+
+	tx, err := d.pool.BeginTx(ctx, opts)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	if err := fn(context.WithValue(ctx, txKey{}, tx)); err != nil {
+		if rollbackErr := tx.Rollback(ctx); rollbackErr != nil && !errors.Is(rollbackErr, pgx.ErrTxClosed) {
+			d.logger.Error(rollbackErr, "failed to roll back transaction")
+		}
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+
+	"github.com/google/uuid"
+
+	"pull-request-review/internal/domain/auth"
+	"pull-request-review/internal/domain/model"
+)
+
+const (
+	ActorHeader      = "X-User-ID"
+	ProxyTokenHeader = "X-Proxy-Token"
+)
+
+// Actor puts the caller identified by the X-User-ID header into the request context.
+// The service does not authenticate users itself, the header is set by the authenticating proxy
+// in front of it. With a proxy token configured the header is only believed on requests carrying
+// that token in X-Proxy-Token. With no token every header is believed, which is only safe when
+// nothing but the proxy can reach the service. Other requests are served anonymously.
+func Actor(proxyToken string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				provided := r.Header.Get(ProxyTokenHeader)
+				trusted := proxyToken == "" || subtle.ConstantTimeCompare([]byte(provided), []byte(proxyToken)) == 1
+
+				actorUUID, err := uuid.Parse(r.Header.Get(ActorHeader))
+				if err == nil && trusted {
+					r = r.WithContext(auth.WithActor(r.Context(), model.UserID(actorUUID)))
+				}
+
+				next.ServeHTTP(w, r)
+			},
+		)
+	}
+}
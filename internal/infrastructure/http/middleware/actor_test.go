@@ -0,0 +1,59 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"pull-request-review/internal/domain/auth"
+	"pull-request-review/internal/domain/model"
+	"pull-request-review/internal/infrastructure/http/middleware"
+)
+
+func TestActor(t *testing.T) {
+	userID := uuid.New()
+
+	for _, tc := range []struct {
+		name       string
+		proxyToken string
+		headers    map[string]string
+		want       bool
+	}{
+		{"no proxy token trusts the header", "", map[string]string{middleware.ActorHeader: userID.String()}, true},
+		{"no header is anonymous", "", nil, false},
+		{"invalid header is anonymous", "", map[string]string{middleware.ActorHeader: "someone"}, false},
+		{
+			"matching proxy token", "secret",
+			map[string]string{middleware.ActorHeader: userID.String(), middleware.ProxyTokenHeader: "secret"}, true,
+		},
+		{
+			"wrong proxy token", "secret",
+			map[string]string{middleware.ActorHeader: userID.String(), middleware.ProxyTokenHeader: "guess"}, false,
+		},
+		{"missing proxy token", "secret", map[string]string{middleware.ActorHeader: userID.String()}, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var (
+				actorID model.UserID
+				found   bool
+			)
+			handler := middleware.Actor(tc.proxyToken)(
+				http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					actorID, found = auth.ActorFromContext(r.Context())
+				}),
+			)
+
+			r := httptest.NewRequest(http.MethodGet, "/team/get", nil)
+			for name, value := range tc.headers {
+				r.Header.Set(name, value)
+			}
+			handler.ServeHTTP(httptest.NewRecorder(), r)
+
+			if found != tc.want || (found && actorID != model.UserID(userID)) {
+				t.Fatalf("actor %v, found %v, want found %v", actorID, found, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,31 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+)
+
+const AdminTokenHeader = "X-Admin-Token"
+
+// AdminToken guards operator endpoints with a shared token. With no token configured
+// every request is refused.
+func AdminToken(token string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				provided := r.Header.Get(AdminTokenHeader)
+				if token == "" || subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+					w.Header().Set("Content-Type", "application/json")
+					w.WriteHeader(http.StatusForbidden)
+					_, err := w.Write([]byte(`{"error":{"code":"FORBIDDEN","message":"admin token required"}}`))
+					if err != nil {
+						return
+					}
+					return
+				}
+
+				next.ServeHTTP(w, r)
+			},
+		)
+	}
+}
@@ -0,0 +1,230 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"pull-request-review/internal/domain/model"
+	"pull-request-review/internal/domain/ports/repository"
+	"pull-request-review/internal/domain/rules"
+	"pull-request-review/internal/infrastructure/adapters/logger"
+)
+
+const (
+	IdempotencyKeyHeader      = "Idempotency-Key"
+	IdempotentReplayedHeader  = "Idempotent-Replayed"
+	maxIdempotencyKeyLength   = 255
+	idempotencyReserveRetries = 1
+)
+
+type IdempotencyOptions struct {
+	// KeyTTL is how long a completed response is replayed for the same key.
+	KeyTTL time.Duration
+	// LockTimeout is how long an unfinished request holds its key before a retry may take it over.
+	LockTimeout time.Duration
+}
+
+// Idempotency replays the stored response when a request is retried with the same Idempotency-Key.
+// The response is recorded once the handler finishes, even if the client has already given up on it.
+func Idempotency(
+	repo repository.IdempotencyRepository, opts IdempotencyOptions, log logger.Logger,
+) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				key := r.Header.Get(IdempotencyKeyHeader)
+				if key == "" {
+					next.ServeHTTP(w, r)
+					return
+				}
+				if len(key) > maxIdempotencyKeyLength {
+					writeIdempotencyError(w, http.StatusBadRequest, "INVALID_IDEMPOTENCY_KEY", "idempotency key is too long")
+					return
+				}
+
+				body, err := io.ReadAll(r.Body)
+				if err != nil {
+					writeIdempotencyError(w, http.StatusBadRequest, "INVALID_REQUEST", "cannot read request body")
+					return
+				}
+				r.Body = io.NopCloser(bytes.NewReader(body))
+
+				record := &model.IdempotencyRecord{
+					Key:         key,
+					Route:       r.Method + " " + r.URL.Path,
+					RequestHash: hashRequest(r.Header.Get(ActorHeader), body),
+					CreatedAt:   time.Now(),
+				}
+
+				reserved, existing, err := reserveIdempotencyKey(r.Context(), repo, record, opts)
+				if err != nil {
+					log.Error(err, "Failed to reserve idempotency key", logger.F("route", record.Route))
+					writeIdempotencyError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "internal server error")
+					return
+				}
+
+				if !reserved {
+					replayIdempotentResponse(w, record, existing)
+					return
+				}
+
+				recorder := &recordingWriter{ResponseWriter: w, statusCode: http.StatusOK}
+				// the client may be gone by now, the outcome still has to be saved for its retry
+				storeCtx := context.WithoutCancel(r.Context())
+				finished := false
+				defer func() {
+					if finished && recorder.statusCode < http.StatusInternalServerError {
+						err := repo.Complete(storeCtx, record.Key, record.Route, recorder.statusCode, recorder.body.Bytes())
+						if err != nil {
+							log.Error(err, "Failed to store idempotent response", logger.F("route", record.Route))
+						}
+						return
+					}
+
+					// failed or panicked requests are not replayed, the retry runs them again
+					err := repo.Delete(storeCtx, record.Key, record.Route)
+					if err != nil {
+						log.Error(err, "Failed to release idempotency key", logger.F("route", record.Route))
+					}
+				}()
+
+				next.ServeHTTP(recorder, r)
+				finished = true
+			},
+		)
+	}
+}
+
+// PurgeIdempotencyKeys deletes the keys created more than KeyTTL ago, every interval until ctx
+// is done. Those are no longer replayed, and nothing else removes the completed ones. Without a
+// KeyTTL responses are replayed forever and nothing is purged.
+func PurgeIdempotencyKeys(
+	ctx context.Context, repo repository.IdempotencyRepository, opts IdempotencyOptions, interval time.Duration,
+	log logger.Logger,
+) {
+	if opts.KeyTTL <= 0 || interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		deleted, err := repo.DeleteCreatedBefore(ctx, time.Now().Add(-opts.KeyTTL))
+		if err != nil {
+			log.Error(err, "Failed to purge idempotency keys")
+			continue
+		}
+		if deleted > 0 {
+			log.Info("Purged idempotency keys", logger.F("deleted", deleted))
+		}
+	}
+}
+
+func reserveIdempotencyKey(
+	ctx context.Context, repo repository.IdempotencyRepository, record *model.IdempotencyRecord,
+	opts IdempotencyOptions,
+) (bool, *model.IdempotencyRecord, error) {
+	for attempt := 0; ; attempt++ {
+		reserved, err := repo.Reserve(ctx, record)
+		if err != nil || reserved {
+			return reserved, nil, err
+		}
+
+		existing, err := repo.Get(ctx, record.Key, record.Route)
+		if errors.Is(err, rules.ErrNotFound) && attempt < idempotencyReserveRetries {
+			continue
+		}
+		if err != nil {
+			return false, nil, err
+		}
+
+		if !isIdempotencyRecordExpired(existing, opts) || attempt >= idempotencyReserveRetries {
+			return false, existing, nil
+		}
+
+		err = repo.Delete(ctx, record.Key, record.Route)
+		if err != nil {
+			return false, nil, err
+		}
+	}
+}
+
+func isIdempotencyRecordExpired(record *model.IdempotencyRecord, opts IdempotencyOptions) bool {
+	if record.IsCompleted() {
+		return opts.KeyTTL > 0 && time.Since(record.CompletedAt) > opts.KeyTTL
+	}
+	return opts.LockTimeout > 0 && time.Since(record.CreatedAt) > opts.LockTimeout
+}
+
+func replayIdempotentResponse(w http.ResponseWriter, record *model.IdempotencyRecord, existing *model.IdempotencyRecord) {
+	if existing.RequestHash != record.RequestHash {
+		writeIdempotencyError(
+			w, http.StatusUnprocessableEntity, "IDEMPOTENCY_KEY_REUSED",
+			"idempotency key was already used with a different request",
+		)
+		return
+	}
+
+	if !existing.IsCompleted() {
+		writeIdempotencyError(
+			w, http.StatusConflict, "IDEMPOTENCY_IN_PROGRESS", "request with this idempotency key is in progress",
+		)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set(IdempotentReplayedHeader, "true")
+	w.WriteHeader(existing.StatusCode)
+	_, err := w.Write(existing.ResponseBody)
+	if err != nil {
+		return
+	}
+}
+
+// hashRequest fingerprints the caller together with the payload, so a key reused
+// by somebody else is rejected instead of leaking the original response.
+func hashRequest(actor string, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(actor))
+	h.Write([]byte{0})
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func writeIdempotencyError(w http.ResponseWriter, status int, code string, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_, err := w.Write([]byte(`{"error":{"code":"` + code + `","message":"` + message + `"}}`))
+	if err != nil {
+		return
+	}
+}
+
+type recordingWriter struct {
+	http.ResponseWriter
+	statusCode int
+	body       bytes.Buffer
+}
+
+func (rw *recordingWriter) WriteHeader(code int) {
+	rw.statusCode = code
+	rw.ResponseWriter.WriteHeader(code)
+}
+
+func (rw *recordingWriter) Write(b []byte) (int, error) {
+	rw.body.Write(b)
+	return rw.ResponseWriter.Write(b)
+}
@@ -0,0 +1,160 @@
+package middleware_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"pull-request-review/internal/domain/model"
+	"pull-request-review/internal/domain/ports/repository"
+	"pull-request-review/internal/domain/rules"
+	"pull-request-review/internal/infrastructure/adapters/logger"
+	"pull-request-review/internal/infrastructure/http/middleware"
+	"pull-request-review/internal/infrastructure/repository/memory"
+)
+
+func TestIdempotency(t *testing.T) {
+	options := middleware.IdempotencyOptions{KeyTTL: time.Hour, LockTimeout: time.Minute}
+
+	// serve runs handler behind the middleware and counts how often it is reached
+	serve := func(repo repository.IdempotencyRepository, handler http.HandlerFunc) (http.Handler, *atomic.Int32) {
+		var calls atomic.Int32
+		return middleware.Idempotency(repo, options, logger.NewZerologLogger())(
+			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls.Add(1)
+				handler(w, r)
+			}),
+		), &calls
+	}
+	send := func(handler http.Handler, key string, body string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodPost, "/pullRequest/create", strings.NewReader(body))
+		r.Header.Set(middleware.IdempotencyKeyHeader, key)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w
+	}
+	created := func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"id":1}`))
+	}
+
+	t.Run("replay", func(t *testing.T) {
+		handler, calls := serve(memory.NewRepositories(memory.NewStore()).Idempotency, created)
+		first := send(handler, "key", `{"name":"a"}`)
+		second := send(handler, "key", `{"name":"a"}`)
+		if calls.Load() != 1 {
+			t.Fatalf("the handler ran %d times", calls.Load())
+		}
+		if second.Code != http.StatusCreated || second.Body.String() != first.Body.String() ||
+			second.Header().Get(middleware.IdempotentReplayedHeader) != "true" {
+			t.Fatalf("unexpected replay %d %q %v", second.Code, second.Body.String(), second.Header())
+		}
+	})
+
+	t.Run("key reused with another request", func(t *testing.T) {
+		handler, calls := serve(memory.NewRepositories(memory.NewStore()).Idempotency, created)
+		send(handler, "key", `{"name":"a"}`)
+		w := send(handler, "key", `{"name":"b"}`)
+		if w.Code != http.StatusUnprocessableEntity || !strings.Contains(w.Body.String(), "IDEMPOTENCY_KEY_REUSED") {
+			t.Fatalf("got %d %q", w.Code, w.Body.String())
+		}
+		if calls.Load() != 1 {
+			t.Fatalf("the handler ran %d times", calls.Load())
+		}
+	})
+
+	t.Run("request in progress", func(t *testing.T) {
+		started, release := make(chan struct{}), make(chan struct{})
+		handler, calls := serve(
+			memory.NewRepositories(memory.NewStore()).Idempotency,
+			func(w http.ResponseWriter, r *http.Request) {
+				close(started)
+				<-release
+				created(w, r)
+			},
+		)
+
+		done := make(chan *httptest.ResponseRecorder)
+		go func() { done <- send(handler, "key", `{"name":"a"}`) }()
+		<-started
+		w := send(handler, "key", `{"name":"a"}`)
+		close(release)
+		if first := <-done; first.Code != http.StatusCreated {
+			t.Fatalf("first request got %d", first.Code)
+		}
+		if w.Code != http.StatusConflict || !strings.Contains(w.Body.String(), "IDEMPOTENCY_IN_PROGRESS") {
+			t.Fatalf("got %d %q", w.Code, w.Body.String())
+		}
+		if calls.Load() != 1 {
+			t.Fatalf("the handler ran %d times", calls.Load())
+		}
+	})
+
+	t.Run("server errors release the key", func(t *testing.T) {
+		var failed atomic.Bool
+		handler, calls := serve(
+			memory.NewRepositories(memory.NewStore()).Idempotency,
+			func(w http.ResponseWriter, r *http.Request) {
+				if failed.CompareAndSwap(false, true) {
+					w.WriteHeader(http.StatusInternalServerError)
+					return
+				}
+				created(w, r)
+			},
+		)
+
+		if w := send(handler, "key", `{"name":"a"}`); w.Code != http.StatusInternalServerError {
+			t.Fatalf("first request got %d", w.Code)
+		}
+		w := send(handler, "key", `{"name":"a"}`)
+		if w.Code != http.StatusCreated || w.Header().Get(middleware.IdempotentReplayedHeader) != "" {
+			t.Fatalf("the retry should run again, got %d %v", w.Code, w.Header())
+		}
+		if calls.Load() != 2 {
+			t.Fatalf("the handler ran %d times", calls.Load())
+		}
+	})
+}
+
+func TestPurgeIdempotencyKeys(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	repo := memory.NewRepositories(memory.NewStore()).Idempotency
+	for key, createdAt := range map[string]time.Time{"old": time.Now().Add(-2 * time.Hour), "fresh": time.Now()} {
+		record := &model.IdempotencyRecord{Key: key, Route: "POST /team/add", RequestHash: "hash", CreatedAt: createdAt}
+		if _, err := repo.Reserve(ctx, record); err != nil {
+			t.Fatalf("reserve: %v", err)
+		}
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		middleware.PurgeIdempotencyKeys(
+			ctx, repo, middleware.IdempotencyOptions{KeyTTL: time.Hour}, time.Millisecond, logger.NewZerologLogger(),
+		)
+		close(stopped)
+	}()
+
+	deadline := time.After(time.Second)
+	for {
+		_, err := repo.Get(ctx, "old", "POST /team/add")
+		if errors.Is(err, rules.ErrNotFound) {
+			break
+		}
+		select {
+		case <-deadline:
+			t.Fatalf("the old key was not purged: %v", err)
+		case <-time.After(time.Millisecond):
+		}
+	}
+	if _, err := repo.Get(ctx, "fresh", "POST /team/add"); err != nil {
+		t.Fatalf("the fresh key should be kept: %v", err)
+	}
+
+	cancel()
+	<-stopped
+}
@@ -0,0 +1,163 @@
+package middleware
+
+import (
+	"context"
+	"crypto/subtle"
+	"math"
+	"net"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"pull-request-review/internal/infrastructure/adapters/logger"
+)
+
+const APITokenHeader = "X-API-Token"
+
+type RateLimitRule struct {
+	RequestsPerSecond float64
+	Burst             int
+}
+
+// RateLimitStore keeps token buckets for rate limited clients. Allow consumes a token from
+// the bucket identified by key and returns how long the client has to wait when it is empty.
+type RateLimitStore interface {
+	Allow(ctx context.Context, key string, rule RateLimitRule) (bool, time.Duration, error)
+}
+
+type RateLimitOptions struct {
+	Default RateLimitRule
+	Routes  map[string]RateLimitRule
+	// APITokens are the tokens whose clients get a bucket of their own. Any other token is
+	// made up by the client, so its requests are limited by address like anonymous ones.
+	APITokens []string
+}
+
+func RateLimit(store RateLimitStore, opts RateLimitOptions, log logger.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				key := clientKey(r, opts.APITokens)
+				rule := opts.Default
+				if routeRule, ok := opts.Routes[r.URL.Path]; ok {
+					rule = routeRule
+					key += "|" + r.URL.Path
+				}
+
+				if rule.RequestsPerSecond <= 0 {
+					next.ServeHTTP(w, r)
+					return
+				}
+
+				allowed, retryAfter, err := store.Allow(r.Context(), key, rule)
+				if err != nil {
+					log.Error(err, "Rate limit store failed, letting request through")
+					next.ServeHTTP(w, r)
+					return
+				}
+
+				if !allowed {
+					w.Header().Set("Content-Type", "application/json")
+					w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+					w.WriteHeader(http.StatusTooManyRequests)
+					_, err := w.Write([]byte(`{"error":{"code":"RATE_LIMITED","message":"rate limit exceeded"}}`))
+					if err != nil {
+						return
+					}
+					return
+				}
+
+				next.ServeHTTP(w, r)
+			},
+		)
+	}
+}
+
+// clientKey identifies the client by its API token when it is one of tokens, and by the
+// remote address otherwise. A client could dodge the limit with a fresh token per request if
+// unknown tokens got buckets too.
+func clientKey(r *http.Request, tokens []string) string {
+	token := r.Header.Get(APITokenHeader)
+	if auth := r.Header.Get("Authorization"); token == "" && strings.HasPrefix(auth, "Bearer ") {
+		token = strings.TrimPrefix(auth, "Bearer ")
+	}
+	if token != "" && slices.ContainsFunc(tokens, func(known string) bool {
+		return subtle.ConstantTimeCompare([]byte(token), []byte(known)) == 1
+	}) {
+		return "token:" + token
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return "ip:" + host
+}
+
+type tokenBucket struct {
+	tokens   float64
+	updated  time.Time
+	lastSeen time.Time
+}
+
+type MemoryRateLimitStore struct {
+	mu          sync.Mutex
+	buckets     map[string]*tokenBucket
+	idleTimeout time.Duration
+	lastCleanup time.Time
+	now         func() time.Time
+}
+
+func NewMemoryRateLimitStore() *MemoryRateLimitStore {
+	return &MemoryRateLimitStore{
+		buckets:     make(map[string]*tokenBucket),
+		idleTimeout: 10 * time.Minute,
+		now:         time.Now,
+	}
+}
+
+func (s *MemoryRateLimitStore) Allow(_ context.Context, key string, rule RateLimitRule) (bool, time.Duration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.cleanup(now)
+
+	burst := float64(max(rule.Burst, 1))
+	bucket, ok := s.buckets[key]
+	if !ok {
+		bucket = &tokenBucket{tokens: burst, updated: now}
+		s.buckets[key] = bucket
+	}
+
+	elapsed := now.Sub(bucket.updated).Seconds()
+	bucket.tokens = math.Min(burst, bucket.tokens+elapsed*rule.RequestsPerSecond)
+	bucket.updated = now
+	bucket.lastSeen = now
+
+	if bucket.tokens < 1 {
+		wait := (1 - bucket.tokens) / rule.RequestsPerSecond
+		return false, time.Duration(wait * float64(time.Second)), nil
+	}
+
+	bucket.tokens--
+	return true, 0, nil
+}
+
+// cleanup drops buckets of clients that have not been seen for a while,
+// so the map does not grow with every address that ever called the service.
+func (s *MemoryRateLimitStore) cleanup(now time.Time) {
+	if now.Sub(s.lastCleanup) < s.idleTimeout {
+		return
+	}
+	s.lastCleanup = now
+
+	for key, bucket := range s.buckets {
+		if now.Sub(bucket.lastSeen) >= s.idleTimeout {
+			delete(s.buckets, key)
+		}
+	}
+}
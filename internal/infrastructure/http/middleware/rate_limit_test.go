@@ -0,0 +1,133 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"pull-request-review/internal/infrastructure/adapters/logger"
+)
+
+// fakeClock is a store clock that only moves when the test advances it.
+type fakeClock struct{ now time.Time }
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func (c *fakeClock) Advance(d time.Duration) { c.now = c.now.Add(d) }
+
+func newTestStore() (*MemoryRateLimitStore, *fakeClock) {
+	clock := &fakeClock{now: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)}
+	store := NewMemoryRateLimitStore()
+	store.now = clock.Now
+	return store, clock
+}
+
+func TestMemoryRateLimitStoreRefill(t *testing.T) {
+	ctx := context.Background()
+	store, clock := newTestStore()
+	rule := RateLimitRule{RequestsPerSecond: 2, Burst: 3}
+
+	for i := range rule.Burst {
+		if allowed, _, _ := store.Allow(ctx, "client", rule); !allowed {
+			t.Fatalf("request %d within the burst was refused", i)
+		}
+	}
+	allowed, retryAfter, err := store.Allow(ctx, "client", rule)
+	if err != nil || allowed || retryAfter != 500*time.Millisecond {
+		t.Fatalf("empty bucket: allowed %v, retry after %v, %v", allowed, retryAfter, err)
+	}
+	if allowed, _, _ := store.Allow(ctx, "other", rule); !allowed {
+		t.Fatalf("another client should have a bucket of its own")
+	}
+
+	// half a second brings one token back, and no more than the burst ever builds up
+	clock.Advance(500 * time.Millisecond)
+	if allowed, _, _ := store.Allow(ctx, "client", rule); !allowed {
+		t.Fatalf("refilled token was refused")
+	}
+	if allowed, _, _ := store.Allow(ctx, "client", rule); allowed {
+		t.Fatalf("only one token should have been refilled")
+	}
+	clock.Advance(time.Hour)
+	for i := range rule.Burst {
+		if allowed, _, _ := store.Allow(ctx, "client", rule); !allowed {
+			t.Fatalf("request %d after a long pause was refused", i)
+		}
+	}
+	if allowed, _, _ := store.Allow(ctx, "client", rule); allowed {
+		t.Fatalf("the bucket should not hold more than the burst")
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	store, clock := newTestStore()
+	handler := RateLimit(
+		store, RateLimitOptions{
+			Default:   RateLimitRule{RequestsPerSecond: 10, Burst: 2},
+			Routes:    map[string]RateLimitRule{"/pullRequest/create": {RequestsPerSecond: 0.5, Burst: 1}},
+			APITokens: []string{"known"},
+		},
+		logger.NewZerologLogger(),
+	)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) }))
+
+	send := func(path, address string, headers map[string]string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodPost, path, nil)
+		r.RemoteAddr = address + ":1234"
+		for name, value := range headers {
+			r.Header.Set(name, value)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w
+	}
+
+	t.Run("429 with Retry-After", func(t *testing.T) {
+		for range 2 {
+			if w := send("/team/get", "10.0.0.1", nil); w.Code != http.StatusOK {
+				t.Fatalf("request within the burst got %d", w.Code)
+			}
+		}
+		w := send("/team/get", "10.0.0.1", nil)
+		if w.Code != http.StatusTooManyRequests || w.Header().Get("Retry-After") != "1" {
+			t.Fatalf("got %d, Retry-After %q", w.Code, w.Header().Get("Retry-After"))
+		}
+	})
+
+	t.Run("route override", func(t *testing.T) {
+		if w := send("/pullRequest/create", "10.0.0.2", nil); w.Code != http.StatusOK {
+			t.Fatalf("first create got %d", w.Code)
+		}
+		w := send("/pullRequest/create", "10.0.0.2", nil)
+		if w.Code != http.StatusTooManyRequests || w.Header().Get("Retry-After") != "2" {
+			t.Fatalf("got %d, Retry-After %q", w.Code, w.Header().Get("Retry-After"))
+		}
+		// the route has a bucket of its own, the default one is untouched
+		if w := send("/team/get", "10.0.0.2", nil); w.Code != http.StatusOK {
+			t.Fatalf("other routes should not share the route bucket, got %d", w.Code)
+		}
+		clock.Advance(2 * time.Second)
+		if w := send("/pullRequest/create", "10.0.0.2", nil); w.Code != http.StatusOK {
+			t.Fatalf("create after the refill got %d", w.Code)
+		}
+	})
+
+	t.Run("only known tokens get a bucket", func(t *testing.T) {
+		for i, headers := range []map[string]string{
+			{APITokenHeader: "made-up-1"},
+			{APITokenHeader: "made-up-2"},
+			{"Authorization": "Bearer made-up-3"},
+		} {
+			w := send("/team/get", "10.0.0.3", headers)
+			if want := []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests}[i]; w.Code != want {
+				t.Fatalf("request %d with an unknown token got %d, want %d", i, w.Code, want)
+			}
+		}
+		for _, headers := range []map[string]string{{APITokenHeader: "known"}, {"Authorization": "Bearer known"}} {
+			if w := send("/team/get", "10.0.0.3", headers); w.Code != http.StatusOK {
+				t.Fatalf("a known token should not share the address bucket, got %d", w.Code)
+			}
+		}
+	})
+}
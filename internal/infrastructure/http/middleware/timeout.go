@@ -1,37 +1,170 @@
 package middleware
 
 import (
+	"bytes"
 	"context"
 	"net/http"
+	"sync"
 	"time"
+
+	"pull-request-review/internal/infrastructure/adapters/logger"
 )
 
-func Timeout(timeout time.Duration) func(http.Handler) http.Handler {
+// Timeout cancels the request context once the route budget is spent and answers 408.
+// The handler writes into a buffer, so whatever it produces after the deadline is dropped
+// instead of racing with the timeout response.
+func Timeout(
+	defaultTimeout time.Duration, routeTimeouts map[string]time.Duration, log logger.Logger,
+) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(
 			func(w http.ResponseWriter, r *http.Request) {
+				timeout := defaultTimeout
+				if routeTimeout, ok := routeTimeouts[r.URL.Path]; ok {
+					timeout = routeTimeout
+				}
+
 				ctx, cancel := context.WithTimeout(r.Context(), timeout)
 				defer cancel()
 
 				r = r.WithContext(ctx)
 
+				tw := &timeoutWriter{ctx: ctx, header: make(http.Header)}
 				done := make(chan struct{})
+				panicChan := make(chan any, 1)
 				go func() {
-					next.ServeHTTP(w, r)
+					defer func() {
+						if p := recover(); p != nil {
+							panicChan <- p
+						}
+					}()
+					next.ServeHTTP(tw, r)
 					close(done)
 				}()
 
 				select {
+				case p := <-panicChan:
+					panic(p)
 				case <-done:
-					return
+					// the handler may have returned past the deadline, with some of its writes refused
+					if tw.refusedWrites() {
+						writeTimeoutResponse(w)
+					} else {
+						tw.flushTo(w)
+					}
 				case <-ctx.Done():
-					w.WriteHeader(http.StatusRequestTimeout)
-					_, err := w.Write([]byte(`{"error":{"code":"TIMEOUT","message":"request timeout"}}`))
-					if err != nil {
-						return
+					tw.expire()
+					writeTimeoutResponse(w)
+
+					// the handler still owns the request, wait for it to notice the cancellation
+					deadline := time.Now()
+					select {
+					case p := <-panicChan:
+						panic(p)
+					case <-done:
 					}
+					log.Warn(
+						"Request handler finished after timeout, response discarded",
+						logger.F("method", r.Method),
+						logger.F("path", r.URL.Path),
+						logger.F("timeout", timeout.String()),
+						logger.F("overrun", time.Since(deadline).String()),
+					)
 				}
 			},
 		)
 	}
+}
+
+func writeTimeoutResponse(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusRequestTimeout)
+	_, err := w.Write([]byte(`{"error":{"code":"TIMEOUT","message":"request timeout"}}`))
+	if err == nil {
+		if flusher, ok := w.(http.Flusher); ok {
+			flusher.Flush()
+		}
+	}
+}
+
+// timeoutWriter buffers the response of the handler. Once the request context is done it
+// refuses writes, also before the Timeout middleware itself has noticed the deadline.
+type timeoutWriter struct {
+	ctx         context.Context
+	mu          sync.Mutex
+	header      http.Header
+	body        bytes.Buffer
+	code        int
+	wroteHeader bool
+	timedOut    bool
+}
+
+func (tw *timeoutWriter) Header() http.Header {
+	return tw.header
+}
+
+func (tw *timeoutWriter) WriteHeader(code int) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	if tw.expired() || tw.wroteHeader {
+		return
+	}
+	tw.wroteHeader = true
+	tw.code = code
+}
+
+func (tw *timeoutWriter) Write(b []byte) (int, error) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	if tw.expired() {
+		return 0, http.ErrHandlerTimeout
+	}
+	if !tw.wroteHeader {
+		tw.wroteHeader = true
+		tw.code = http.StatusOK
+	}
+	return tw.body.Write(b)
+}
+
+// expired reports whether writes are refused. Callers hold mu.
+func (tw *timeoutWriter) expired() bool {
+	if tw.ctx.Err() != nil {
+		tw.timedOut = true
+	}
+	return tw.timedOut
+}
+
+func (tw *timeoutWriter) refusedWrites() bool {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	return tw.timedOut
+}
+
+func (tw *timeoutWriter) expire() {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	tw.timedOut = true
+}
+
+func (tw *timeoutWriter) flushTo(w http.ResponseWriter) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	dst := w.Header()
+	for key, values := range tw.header {
+		dst[key] = values
+	}
+
+	if !tw.wroteHeader {
+		tw.code = http.StatusOK
+	}
+	w.WriteHeader(tw.code)
+	_, err := w.Write(tw.body.Bytes())
+	if err != nil {
+		return
+	}
 }
\ No newline at end of file
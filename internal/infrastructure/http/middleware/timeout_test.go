@@ -0,0 +1,118 @@
+package middleware_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"pull-request-review/internal/infrastructure/adapters/logger"
+	"pull-request-review/internal/infrastructure/http/middleware"
+)
+
+func serveWithTimeout(
+	timeout time.Duration, routeTimeouts map[string]time.Duration, path string, handler http.HandlerFunc,
+) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	middleware.Timeout(timeout, routeTimeouts, logger.NewZerologLogger())(handler).ServeHTTP(
+		w, httptest.NewRequest(http.MethodGet, path, nil),
+	)
+	return w
+}
+
+func TestTimeout(t *testing.T) {
+	t.Run("response is buffered until the handler returns", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		wrote, release := make(chan struct{}), make(chan struct{})
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			middleware.Timeout(time.Minute, nil, logger.NewZerologLogger())(
+				http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					w.Header().Set("X-Handler", "yes")
+					w.WriteHeader(http.StatusCreated)
+					_, _ = w.Write([]byte(`{"a":`))
+					close(wrote)
+					<-release
+					_, _ = w.Write([]byte(`1}`))
+				}),
+			).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/team/get", nil))
+		}()
+
+		<-wrote
+		if w.Body.Len() != 0 || w.Header().Get("X-Handler") != "" {
+			t.Fatalf("the response reached the client before the handler returned: %q", w.Body.String())
+		}
+		close(release)
+		<-done
+
+		if w.Code != http.StatusCreated || w.Body.String() != `{"a":1}` || w.Header().Get("X-Handler") != "yes" {
+			t.Fatalf("unexpected response %d %q %v", w.Code, w.Body.String(), w.Header())
+		}
+	})
+
+	t.Run("late writes are discarded", func(t *testing.T) {
+		lateErr := make(chan error, 1)
+		w := serveWithTimeout(
+			10*time.Millisecond, nil, "/team/get", func(w http.ResponseWriter, r *http.Request) {
+				<-r.Context().Done()
+				w.WriteHeader(http.StatusCreated)
+				_, err := w.Write([]byte("late"))
+				lateErr <- err
+			},
+		)
+
+		if err := <-lateErr; !errors.Is(err, http.ErrHandlerTimeout) {
+			t.Fatalf("a write after the timeout should fail with %v, got %v", http.ErrHandlerTimeout, err)
+		}
+		if w.Code != http.StatusRequestTimeout || !strings.Contains(w.Body.String(), `"TIMEOUT"`) ||
+			strings.Contains(w.Body.String(), "late") {
+			t.Fatalf("unexpected response %d %q", w.Code, w.Body.String())
+		}
+	})
+
+	for _, tc := range []struct {
+		name    string
+		timeout time.Duration
+		handler http.HandlerFunc
+	}{
+		{"panic is propagated", time.Minute, func(http.ResponseWriter, *http.Request) { panic("boom") }},
+		{"panic after the timeout is propagated", 10 * time.Millisecond, func(w http.ResponseWriter, r *http.Request) {
+			<-r.Context().Done()
+			panic("boom")
+		}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			defer func() {
+				if p := recover(); p != "boom" {
+					t.Fatalf("expected the handler panic, got %v", p)
+				}
+			}()
+			serveWithTimeout(tc.timeout, nil, "/team/get", tc.handler)
+		})
+	}
+
+	routeTimeouts := map[string]time.Duration{"/admin/import": time.Hour}
+	for _, tc := range []struct {
+		path string
+		want time.Duration
+	}{
+		{"/admin/import", time.Hour},
+		{"/team/get", time.Minute},
+	} {
+		t.Run("timeout of "+tc.path, func(t *testing.T) {
+			var remaining time.Duration
+			w := serveWithTimeout(time.Minute, routeTimeouts, tc.path, func(w http.ResponseWriter, r *http.Request) {
+				deadline, ok := r.Context().Deadline()
+				if ok {
+					remaining = time.Until(deadline)
+				}
+			})
+			if w.Code != http.StatusOK || remaining > tc.want || remaining < tc.want-time.Second {
+				t.Fatalf("got %d with %v left, want %v", w.Code, remaining, tc.want)
+			}
+		})
+	}
+}
@@ -2,9 +2,10 @@ package route
 
 import (
 	"net/http"
-	"time"
 
+	"pull-request-review/config"
 	"pull-request-review/internal/delivery/http/handlers"
+	"pull-request-review/internal/domain/ports/repository"
 	"pull-request-review/internal/infrastructure/adapters/logger"
 	"pull-request-review/internal/infrastructure/adapters/router"
 	"pull-request-review/internal/infrastructure/http/middleware"
@@ -16,29 +17,95 @@ type Handlers struct {
 	PullRequestHandler *handlers.PullRequestHandler
 	StatisticsHandler  *handlers.StatisticsHandler
 	HealthHandler      *handlers.HealthHandler
+	AdminHandler       *handlers.AdminHandler
 }
 
-func SetupRoutes(r router.Router, handlers *Handlers, logger logger.Logger, requestTimeout time.Duration) {
+func SetupRoutes(
+	r router.Router,
+	handlers *Handlers,
+	idempotencyRepo repository.IdempotencyRepository,
+	logger logger.Logger,
+	cfg *config.Config,
+) {
 	r.Use(
 		middleware.Recovery(logger),
 		middleware.Logger(logger),
-		middleware.Timeout(requestTimeout),
+	)
+	if cfg.RateLimit.Enabled {
+		r.Use(middleware.RateLimit(middleware.NewMemoryRateLimitStore(), rateLimitOptions(cfg.RateLimit), logger))
+	}
+	if cfg.Auth.ProxyToken == "" {
+		logger.Warn("no proxy token configured, X-User-ID is trusted as sent")
+	}
+	r.Use(
+		middleware.Actor(cfg.Auth.ProxyToken),
+		middleware.Timeout(cfg.Server.RequestTimeout, cfg.Server.RouteTimeouts, logger),
+	)
+
+	idempotent := middleware.Idempotency(
+		idempotencyRepo,
+		middleware.IdempotencyOptions{
+			KeyTTL:      cfg.Idempotency.KeyTTL,
+			LockTimeout: cfg.Idempotency.LockTimeout,
+		},
+		logger,
 	)
 
 	r.GET("/health", http.HandlerFunc(handlers.HealthHandler.Check))
 
 	teamGroup := r.Group("/team")
-	teamGroup.POST("/add", http.HandlerFunc(handlers.TeamHandler.AddTeam))
+	teamGroup.POST("/add", idempotent(http.HandlerFunc(handlers.TeamHandler.AddTeam)))
 	teamGroup.GET("/get", http.HandlerFunc(handlers.TeamHandler.GetTeam))
+	teamGroup.POST("/addMember", http.HandlerFunc(handlers.TeamHandler.AddMember))
+	teamGroup.POST("/removeMember", http.HandlerFunc(handlers.TeamHandler.RemoveMember))
+	teamGroup.POST("/rename", http.HandlerFunc(handlers.TeamHandler.RenameTeam))
+	teamGroup.POST("/deactivate", http.HandlerFunc(handlers.TeamHandler.DeactivateTeam))
+	teamGroup.POST("/grantRole", http.HandlerFunc(handlers.TeamHandler.GrantRole))
+	teamGroup.POST("/revokeRole", http.HandlerFunc(handlers.TeamHandler.RevokeRole))
+	teamGroup.GET("/tree", http.HandlerFunc(handlers.TeamHandler.GetTree))
+	teamGroup.POST("/setParent", http.HandlerFunc(handlers.TeamHandler.SetParent))
+	teamGroup.POST("/updateSettings", http.HandlerFunc(handlers.TeamHandler.UpdateSettings))
 
 	userGroup := r.Group("/users")
 	userGroup.POST("/setIsActive", http.HandlerFunc(handlers.UserHandler.SetIsActive))
+	userGroup.POST("/moveTeam", http.HandlerFunc(handlers.UserHandler.MoveTeam))
 	userGroup.GET("/getReview", http.HandlerFunc(handlers.UserHandler.GetReviews))
 
 	prGroup := r.Group("/pullRequest")
-	prGroup.POST("/create", http.HandlerFunc(handlers.PullRequestHandler.CreatePullRequest))
-	prGroup.POST("/merge", http.HandlerFunc(handlers.PullRequestHandler.MergePullRequest))
-	prGroup.POST("/reassign", http.HandlerFunc(handlers.PullRequestHandler.ReassignReviewer))
+	prGroup.POST("/create", idempotent(http.HandlerFunc(handlers.PullRequestHandler.CreatePullRequest)))
+	prGroup.POST("/batchCreate", idempotent(http.HandlerFunc(handlers.PullRequestHandler.BatchCreatePullRequests)))
+	prGroup.GET("/get", http.HandlerFunc(handlers.PullRequestHandler.GetPullRequest))
+	prGroup.GET("/suggestReviewers", http.HandlerFunc(handlers.PullRequestHandler.SuggestReviewers))
+	prGroup.POST("/update", idempotent(http.HandlerFunc(handlers.PullRequestHandler.UpdatePullRequest)))
+	prGroup.POST("/markReady", idempotent(http.HandlerFunc(handlers.PullRequestHandler.MarkReady)))
+	prGroup.POST("/merge", idempotent(http.HandlerFunc(handlers.PullRequestHandler.MergePullRequest)))
+	prGroup.POST("/reassign", idempotent(http.HandlerFunc(handlers.PullRequestHandler.ReassignReviewer)))
+	prGroup.POST("/decline", idempotent(http.HandlerFunc(handlers.PullRequestHandler.DeclineReview)))
+	prGroup.POST("/addReviewer", idempotent(http.HandlerFunc(handlers.PullRequestHandler.AddReviewer)))
+	prGroup.POST("/removeReviewer", idempotent(http.HandlerFunc(handlers.PullRequestHandler.RemoveReviewer)))
 
 	r.GET("/statistics", http.HandlerFunc(handlers.StatisticsHandler.GetStatistics))
-}
\ No newline at end of file
+
+	adminGroup := r.Group("/admin")
+	adminGroup.Use(middleware.AdminToken(cfg.Admin.Token))
+	adminGroup.POST("/import", http.HandlerFunc(handlers.AdminHandler.Import))
+}
+
+func rateLimitOptions(cfg config.RateLimitConfig) middleware.RateLimitOptions {
+	routes := make(map[string]middleware.RateLimitRule, len(cfg.Routes))
+	for path, rule := range cfg.Routes {
+		routes[path] = middleware.RateLimitRule{
+			RequestsPerSecond: rule.RequestsPerSecond,
+			Burst:             rule.Burst,
+		}
+	}
+
+	return middleware.RateLimitOptions{
+		Default: middleware.RateLimitRule{
+			RequestsPerSecond: cfg.RequestsPerSecond,
+			Burst:             cfg.Burst,
+		},
+		Routes:    routes,
+		APITokens: cfg.APITokens,
+	}
+}
@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"pull-request-review/config"
+	"pull-request-review/db/migrations"
+	"pull-request-review/internal/domain/ports/repository"
+	"pull-request-review/internal/infrastructure/adapters/logger"
+	"pull-request-review/internal/infrastructure/database"
+	"pull-request-review/internal/infrastructure/repository/repositorytest"
+)
+
+// TestContract runs against the database in DATABASE_URL and is skipped without one.
+func TestContract(t *testing.T) {
+	url := os.Getenv("DATABASE_URL")
+	if url == "" {
+		t.Skip("DATABASE_URL is not set")
+	}
+
+	ctx := context.Background()
+	log := logger.NewZerologLogger()
+	db := database.NewDatabase(config.DatabaseConfig{URL: url, MaxConns: 4}, log)
+	if err := db.Connect(ctx); err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	t.Cleanup(db.Close)
+
+	if _, err := database.NewMigrator(db, migrations.FS, log).Up(ctx); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	repositorytest.Run(t, func(t *testing.T) *repository.Repositories {
+		return NewRepositories(db)
+	})
+}
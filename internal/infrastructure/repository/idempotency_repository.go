@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"github.com/jackc/pgx/v5"
+	"pull-request-review/internal/domain/model"
+	"pull-request-review/internal/domain/ports/repository"
+	"pull-request-review/internal/domain/rules"
+	"pull-request-review/internal/infrastructure/database"
+	"time"
+)
+
+type IdempotencyRepositoryPgx struct {
+	database *database.Database
+}
+
+func NewIdempotencyRepository(database *database.Database) repository.IdempotencyRepository {
+	return &IdempotencyRepositoryPgx{database: database}
+}
+
+func (r *IdempotencyRepositoryPgx) Reserve(ctx context.Context, record *model.IdempotencyRecord) (bool, error) {
+	query := `
+INSERT INTO idempotency_keys (idempotency_key, route, request_hash, created_at)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (idempotency_key, route) DO NOTHING
+`
+
+	result, err := r.database.Querier(ctx).Exec(ctx, query, record.Key, record.Route, record.RequestHash, record.CreatedAt)
+	if err != nil {
+		return false, err
+	}
+
+	return result.RowsAffected() == 1, nil
+}
+
+func (r *IdempotencyRepositoryPgx) Get(ctx context.Context, key string, route string) (
+	*model.IdempotencyRecord, error,
+) {
+	query := `
+SELECT idempotency_key, route, request_hash, status_code, response_body, created_at, completed_at
+FROM idempotency_keys
+WHERE idempotency_key = $1 AND route = $2
+`
+
+	var record model.IdempotencyRecord
+	var statusCode *int
+	var completedAt *time.Time
+	err := r.database.Querier(ctx).QueryRow(ctx, query, key, route).Scan(
+		&record.Key,
+		&record.Route,
+		&record.RequestHash,
+		&statusCode,
+		&record.ResponseBody,
+		&record.CreatedAt,
+		&completedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, rules.ErrNotFound
+		}
+		return nil, err
+	}
+
+	if statusCode != nil {
+		record.StatusCode = *statusCode
+	}
+	if completedAt != nil {
+		record.CompletedAt = *completedAt
+	}
+
+	return &record, nil
+}
+
+func (r *IdempotencyRepositoryPgx) Complete(
+	ctx context.Context, key string, route string, statusCode int, responseBody []byte,
+) error {
+	query := `
+UPDATE idempotency_keys
+SET status_code = $1, response_body = $2, completed_at = now()
+WHERE idempotency_key = $3 AND route = $4
+`
+
+	result, err := r.database.Querier(ctx).Exec(ctx, query, statusCode, responseBody, key, route)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return rules.ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *IdempotencyRepositoryPgx) Delete(ctx context.Context, key string, route string) error {
+	query := `
+DELETE FROM idempotency_keys
+WHERE idempotency_key = $1 AND route = $2
+`
+
+	_, err := r.database.Querier(ctx).Exec(ctx, query, key, route)
+	return err
+}
+
+func (r *IdempotencyRepositoryPgx) DeleteCreatedBefore(ctx context.Context, before time.Time) (int64, error) {
+	query := `
+DELETE FROM idempotency_keys
+WHERE created_at < $1
+`
+
+	result, err := r.database.Querier(ctx).Exec(ctx, query, before)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected(), nil
+}
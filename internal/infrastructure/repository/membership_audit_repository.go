@@ -0,0 +1,36 @@
+package repository
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"pull-request-review/internal/domain/model"
+	"pull-request-review/internal/domain/ports/repository"
+	"pull-request-review/internal/infrastructure/database"
+)
+
+type MembershipAuditRepositoryPgx struct {
+	database *database.Database
+}
+
+func NewMembershipAuditRepository(database *database.Database) repository.MembershipAuditRepository {
+	return &MembershipAuditRepositoryPgx{database: database}
+}
+
+func (r *MembershipAuditRepositoryPgx) Record(ctx context.Context, change *model.MembershipChange) error {
+	query := `
+INSERT INTO team_membership_audit (audit_id, user_id, from_team_id, to_team_id, actor_id, reason, created_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+`
+
+	_, err := r.database.Querier(ctx).Exec(
+		ctx, query,
+		change.ID,
+		change.UserID,
+		nullableUUID(uuid.UUID(change.FromTeamID)),
+		nullableUUID(uuid.UUID(change.ToTeamID)),
+		nullableUUID(uuid.UUID(change.ActorID)),
+		change.Reason,
+		change.CreatedAt,
+	)
+	return err
+}
@@ -0,0 +1,87 @@
+package memory
+
+import (
+	"bytes"
+	"context"
+	"time"
+
+	"pull-request-review/internal/domain/model"
+	"pull-request-review/internal/domain/ports/repository"
+	"pull-request-review/internal/domain/rules"
+)
+
+type IdempotencyRepositoryMemory struct {
+	store *Store
+}
+
+func NewIdempotencyRepository(store *Store) repository.IdempotencyRepository {
+	return &IdempotencyRepositoryMemory{store: store}
+}
+
+func (r *IdempotencyRepositoryMemory) Reserve(ctx context.Context, record *model.IdempotencyRecord) (bool, error) {
+	defer r.store.lock(ctx)()
+
+	key := idempotencyKey{key: record.Key, route: record.Route}
+	if _, ok := r.store.idempotency[key]; ok {
+		return false, nil
+	}
+
+	r.store.idempotency[key] = model.IdempotencyRecord{
+		Key:         record.Key,
+		Route:       record.Route,
+		RequestHash: record.RequestHash,
+		CreatedAt:   record.CreatedAt,
+	}
+	return true, nil
+}
+
+func (r *IdempotencyRepositoryMemory) Get(ctx context.Context, key string, route string) (
+	*model.IdempotencyRecord, error,
+) {
+	defer r.store.rlock(ctx)()
+
+	record, ok := r.store.idempotency[idempotencyKey{key: key, route: route}]
+	if !ok {
+		return nil, rules.ErrNotFound
+	}
+	record.ResponseBody = bytes.Clone(record.ResponseBody)
+	return &record, nil
+}
+
+func (r *IdempotencyRepositoryMemory) Complete(
+	ctx context.Context, key string, route string, statusCode int, responseBody []byte,
+) error {
+	defer r.store.lock(ctx)()
+
+	storeKey := idempotencyKey{key: key, route: route}
+	record, ok := r.store.idempotency[storeKey]
+	if !ok {
+		return rules.ErrNotFound
+	}
+
+	record.StatusCode = statusCode
+	record.ResponseBody = bytes.Clone(responseBody)
+	record.CompletedAt = time.Now()
+	r.store.idempotency[storeKey] = record
+	return nil
+}
+
+func (r *IdempotencyRepositoryMemory) Delete(ctx context.Context, key string, route string) error {
+	defer r.store.lock(ctx)()
+
+	delete(r.store.idempotency, idempotencyKey{key: key, route: route})
+	return nil
+}
+
+func (r *IdempotencyRepositoryMemory) DeleteCreatedBefore(ctx context.Context, before time.Time) (int64, error) {
+	defer r.store.lock(ctx)()
+
+	var deleted int64
+	for key, record := range r.store.idempotency {
+		if record.CreatedAt.Before(before) {
+			delete(r.store.idempotency, key)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
@@ -0,0 +1,27 @@
+package memory
+
+import (
+	"context"
+
+	"pull-request-review/internal/domain/model"
+	"pull-request-review/internal/domain/ports/repository"
+)
+
+type MembershipAuditRepositoryMemory struct {
+	store *Store
+}
+
+func NewMembershipAuditRepository(store *Store) repository.MembershipAuditRepository {
+	return &MembershipAuditRepositoryMemory{store: store}
+}
+
+func (r *MembershipAuditRepositoryMemory) Record(ctx context.Context, change *model.MembershipChange) error {
+	defer r.store.lock(ctx)()
+
+	if _, ok := r.store.users[change.UserID]; !ok {
+		return errForeignKeyViolated
+	}
+
+	r.store.audit = append(r.store.audit, *change)
+	return nil
+}
@@ -0,0 +1,14 @@
+package memory
+
+import (
+	"testing"
+
+	"pull-request-review/internal/domain/ports/repository"
+	"pull-request-review/internal/infrastructure/repository/repositorytest"
+)
+
+func TestContract(t *testing.T) {
+	repositorytest.Run(t, func(t *testing.T) *repository.Repositories {
+		return NewRepositories(NewStore())
+	})
+}
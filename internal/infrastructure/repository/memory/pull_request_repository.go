@@ -0,0 +1,207 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"time"
+
+	"pull-request-review/internal/domain/model"
+	"pull-request-review/internal/domain/ports/repository"
+	"pull-request-review/internal/domain/rules"
+)
+
+type PullRequestRepositoryMemory struct {
+	store *Store
+}
+
+func NewPullRequestRepository(store *Store) repository.PullRequestRepository {
+	return &PullRequestRepositoryMemory{store: store}
+}
+
+func (r *PullRequestRepositoryMemory) Create(ctx context.Context, pullRequest *model.PullRequest) error {
+	defer r.store.lock(ctx)()
+
+	if _, ok := r.store.pullRequests[pullRequest.PullRequestID]; ok {
+		return nil
+	}
+	if _, ok := r.store.users[pullRequest.AuthorID]; !ok {
+		return errForeignKeyViolated
+	}
+
+	r.store.pullRequests[pullRequest.PullRequestID] = clonePullRequest(*pullRequest)
+	return nil
+}
+
+// CreateBatch stores all pull requests or none. Unlike Create it fails on an existing ID,
+// the caller is expected to have filtered those out.
+func (r *PullRequestRepositoryMemory) CreateBatch(ctx context.Context, pullRequests []model.PullRequest) error {
+	defer r.store.lock(ctx)()
+
+	seen := make(map[model.PullRequestID]bool, len(pullRequests))
+	for _, pr := range pullRequests {
+		if _, ok := r.store.pullRequests[pr.PullRequestID]; ok || seen[pr.PullRequestID] {
+			return errDuplicateKey
+		}
+		if _, ok := r.store.users[pr.AuthorID]; !ok {
+			return errForeignKeyViolated
+		}
+		seen[pr.PullRequestID] = true
+	}
+
+	for _, pr := range pullRequests {
+		r.store.pullRequests[pr.PullRequestID] = clonePullRequest(pr)
+	}
+	return nil
+}
+
+func (r *PullRequestRepositoryMemory) GetByID(ctx context.Context, ID model.PullRequestID) (
+	*model.PullRequest, error,
+) {
+	defer r.store.rlock(ctx)()
+
+	pr, ok := r.store.pullRequests[ID]
+	if !ok {
+		return nil, rules.ErrNotFound
+	}
+	pr = clonePullRequest(pr)
+	return &pr, nil
+}
+
+func (r *PullRequestRepositoryMemory) Exists(ctx context.Context, ID model.PullRequestID) (bool, error) {
+	defer r.store.rlock(ctx)()
+
+	_, ok := r.store.pullRequests[ID]
+	return ok, nil
+}
+
+func (r *PullRequestRepositoryMemory) GetExistingIDs(
+	ctx context.Context, IDs []model.PullRequestID,
+) ([]model.PullRequestID, error) {
+	defer r.store.rlock(ctx)()
+
+	var existing []model.PullRequestID
+	for _, ID := range IDs {
+		if _, ok := r.store.pullRequests[ID]; ok {
+			existing = append(existing, ID)
+		}
+	}
+	return existing, nil
+}
+
+func (r *PullRequestRepositoryMemory) UpdateStatus(
+	ctx context.Context, ID model.PullRequestID, status model.PullRequestStatus, mergedAt time.Time,
+) error {
+	defer r.store.lock(ctx)()
+
+	pr, ok := r.store.pullRequests[ID]
+	if !ok {
+		return rules.ErrPullRequestNotFound
+	}
+
+	pr.Status = status
+	pr.MergedAt = mergedAt
+	r.store.pullRequests[ID] = pr
+	return nil
+}
+
+func (r *PullRequestRepositoryMemory) BumpVersion(ctx context.Context, ID model.PullRequestID, version int64) error {
+	defer r.store.lock(ctx)()
+
+	pr, ok := r.store.pullRequests[ID]
+	if !ok || pr.Version != version {
+		return rules.ErrConcurrentModification
+	}
+
+	pr.Version++
+	r.store.pullRequests[ID] = pr
+	return nil
+}
+
+func (r *PullRequestRepositoryMemory) UpdateMetadata(
+	ctx context.Context, ID model.PullRequestID, metadata model.PullRequestMetadata,
+) error {
+	defer r.store.lock(ctx)()
+
+	pr, ok := r.store.pullRequests[ID]
+	if !ok {
+		return rules.ErrPullRequestNotFound
+	}
+
+	pr.PullRequestMetadata = metadata
+	r.store.pullRequests[ID] = clonePullRequest(pr)
+	return nil
+}
+
+// GetByReviewer lists the pull requests the user reviews, narrowed down by filter.
+func (r *PullRequestRepositoryMemory) GetByReviewer(
+	ctx context.Context, ID model.UserID, filter model.ReviewFilter,
+) ([]model.PullRequest, error) {
+	defer r.store.rlock(ctx)()
+
+	var pullRequests []model.PullRequest
+	for key := range r.store.assignments {
+		if key.reviewerID != ID {
+			continue
+		}
+		pr, ok := r.store.pullRequests[key.pullRequestID]
+		if !ok {
+			continue
+		}
+		if filter.Repository != "" && pr.Repository != filter.Repository {
+			continue
+		}
+		if filter.Label != "" && !slices.Contains(pr.Labels, filter.Label) {
+			continue
+		}
+		pullRequests = append(pullRequests, clonePullRequest(pr))
+	}
+	sortPullRequests(pullRequests)
+	return pullRequests, nil
+}
+
+func (r *PullRequestRepositoryMemory) GetPullRequestCountsByStatus(ctx context.Context) (map[string]int, error) {
+	defer r.store.rlock(ctx)()
+
+	counts := make(map[string]int)
+	for _, pr := range r.store.pullRequests {
+		counts[string(pr.Status)]++
+	}
+	return counts, nil
+}
+
+func (r *PullRequestRepositoryMemory) StreamAll(
+	ctx context.Context, fn func(pullRequest *model.PullRequest) error,
+) error {
+	unlock := r.store.rlock(ctx)
+	pullRequests := make([]model.PullRequest, 0, len(r.store.pullRequests))
+	for _, pr := range r.store.pullRequests {
+		pullRequests = append(pullRequests, clonePullRequest(pr))
+	}
+	unlock()
+
+	sortPullRequests(pullRequests)
+	for _, pr := range pullRequests {
+		if err := fn(&pr); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func sortPullRequests(pullRequests []model.PullRequest) {
+	sort.Slice(pullRequests, func(i, j int) bool {
+		if !pullRequests[i].CreatedAt.Equal(pullRequests[j].CreatedAt) {
+			return pullRequests[i].CreatedAt.Before(pullRequests[j].CreatedAt)
+		}
+		return compareIDs(pullRequests[i].PullRequestID, pullRequests[j].PullRequestID) < 0
+	})
+}
+
+// clonePullRequest copies the labels and the changed paths, the only stored parts of a pull
+// request that are not values.
+func clonePullRequest(pr model.PullRequest) model.PullRequest {
+	pr.Labels = slices.Clone(pr.Labels)
+	pr.ChangedPaths = slices.Clone(pr.ChangedPaths)
+	return pr
+}
@@ -0,0 +1,393 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+
+	"pull-request-review/internal/domain/model"
+	"pull-request-review/internal/domain/ports/repository"
+	"pull-request-review/internal/domain/rules"
+)
+
+type ReviewAssignmentRepositoryMemory struct {
+	store *Store
+}
+
+func NewReviewAssignmentRepository(store *Store) repository.ReviewAssignmentRepository {
+	return &ReviewAssignmentRepositoryMemory{store: store}
+}
+
+func (r *ReviewAssignmentRepositoryMemory) AssignReviewer(
+	ctx context.Context, pullRequestID model.PullRequestID, reviewerID model.UserID,
+) error {
+	return r.AssignReviewers(ctx, pullRequestID, []model.UserID{reviewerID})
+}
+
+func (r *ReviewAssignmentRepositoryMemory) AssignReviewers(
+	ctx context.Context, pullRequestID model.PullRequestID, reviewerIDs []model.UserID,
+) error {
+	defer r.store.lock(ctx)()
+
+	seen := make(map[model.UserID]bool, len(reviewerIDs))
+	for _, reviewerID := range reviewerIDs {
+		err := r.checkInsert(pullRequestID, reviewerID)
+		if err != nil {
+			return err
+		}
+		if seen[reviewerID] {
+			return errDuplicateKey
+		}
+		seen[reviewerID] = true
+	}
+
+	now := time.Now()
+	for _, reviewerID := range reviewerIDs {
+		r.store.assignments[assignmentKey{pullRequestID: pullRequestID, reviewerID: reviewerID}] = model.ReviewAssignment{
+			PullRequestID: pullRequestID,
+			ReviewerID:    reviewerID,
+			AssignedAt:    now,
+		}
+	}
+	return nil
+}
+
+// checkInsert mirrors the primary and foreign keys of review_assignments. The caller holds the lock.
+func (r *ReviewAssignmentRepositoryMemory) checkInsert(pullRequestID model.PullRequestID, reviewerID model.UserID) error {
+	if _, ok := r.store.assignments[assignmentKey{pullRequestID: pullRequestID, reviewerID: reviewerID}]; ok {
+		return errDuplicateKey
+	}
+	if _, ok := r.store.pullRequests[pullRequestID]; !ok {
+		return errForeignKeyViolated
+	}
+	if _, ok := r.store.users[reviewerID]; !ok {
+		return errForeignKeyViolated
+	}
+	return nil
+}
+
+func (r *ReviewAssignmentRepositoryMemory) RemoveReviewer(
+	ctx context.Context, pullRequestID model.PullRequestID, reviewerID model.UserID,
+) error {
+	defer r.store.lock(ctx)()
+
+	key := assignmentKey{pullRequestID: pullRequestID, reviewerID: reviewerID}
+	if _, ok := r.store.assignments[key]; !ok {
+		return rules.ErrNotAssigned
+	}
+
+	delete(r.store.assignments, key)
+	return nil
+}
+
+// GetByReviewer takes the reviewer's id despite the parameter type, like the pgx implementation.
+func (r *ReviewAssignmentRepositoryMemory) GetByReviewer(
+	ctx context.Context, pullRequestID model.PullRequestID,
+) ([]model.PullRequest, error) {
+	return NewPullRequestRepository(r.store).GetByReviewer(ctx, model.UserID(pullRequestID), model.ReviewFilter{})
+}
+
+func (r *ReviewAssignmentRepositoryMemory) Exists(
+	ctx context.Context, pullRequestID model.PullRequestID, reviewerID model.UserID,
+) (bool, error) {
+	defer r.store.rlock(ctx)()
+
+	_, ok := r.store.assignments[assignmentKey{pullRequestID: pullRequestID, reviewerID: reviewerID}]
+	return ok, nil
+}
+
+func (r *ReviewAssignmentRepositoryMemory) GetReviewers(ctx context.Context, pullRequestID model.PullRequestID) (
+	[]model.User, error,
+) {
+	defer r.store.rlock(ctx)()
+
+	var users []model.User
+	for key := range r.store.assignments {
+		if key.pullRequestID != pullRequestID {
+			continue
+		}
+		if user, ok := r.store.users[key.reviewerID]; ok {
+			users = append(users, user)
+		}
+	}
+	sortUsers(users)
+	return users, nil
+}
+
+func (r *ReviewAssignmentRepositoryMemory) ReplaceReviewer(
+	ctx context.Context, pullRequestID model.PullRequestID, oldReviewerID model.UserID, newReviewerID model.UserID,
+) error {
+	defer r.store.lock(ctx)()
+
+	oldKey := assignmentKey{pullRequestID: pullRequestID, reviewerID: oldReviewerID}
+	if _, ok := r.store.assignments[oldKey]; !ok {
+		return rules.ErrNotAssigned
+	}
+	if oldReviewerID != newReviewerID {
+		err := r.checkInsert(pullRequestID, newReviewerID)
+		if err != nil {
+			return err
+		}
+	}
+
+	delete(r.store.assignments, oldKey)
+	r.store.assignments[assignmentKey{pullRequestID: pullRequestID, reviewerID: newReviewerID}] = model.ReviewAssignment{
+		PullRequestID: pullRequestID,
+		ReviewerID:    newReviewerID,
+		AssignedAt:    time.Now(),
+	}
+	return nil
+}
+
+func (r *ReviewAssignmentRepositoryMemory) GetAssignmentCounts(ctx context.Context) (map[string]int, error) {
+	defer r.store.rlock(ctx)()
+
+	counts := make(map[string]int)
+	for key := range r.store.assignments {
+		counts[uuid.UUID(key.reviewerID).String()]++
+	}
+	return counts, nil
+}
+
+// GetTeamAssignmentCounts counts the assignments of each team's members, rolled up
+// so that a team also includes everything below it. Keys are team names.
+func (r *ReviewAssignmentRepositoryMemory) GetTeamAssignmentCounts(ctx context.Context) (map[string]int, error) {
+	defer r.store.rlock(ctx)()
+
+	// every team counts for itself and for each of its ancestors
+	rollUp := make(map[model.TeamID][]string)
+	for ID, team := range r.store.teams {
+		names := []string{team.Name}
+		for parent, ok := r.store.teams[model.TeamID(team.ParentTeamID)]; ok && len(names) <= len(r.store.teams); {
+			names = append(names, parent.Name)
+			parent, ok = r.store.teams[model.TeamID(parent.ParentTeamID)]
+		}
+		rollUp[ID] = names
+	}
+
+	userTeams := make(map[model.UserID][]string)
+	for key := range r.store.memberships {
+		userTeams[key.userID] = append(userTeams[key.userID], rollUp[key.teamID]...)
+	}
+
+	counted := make(map[string]map[assignmentKey]bool)
+	for key := range r.store.assignments {
+		for _, name := range userTeams[key.reviewerID] {
+			if counted[name] == nil {
+				counted[name] = make(map[assignmentKey]bool)
+			}
+			counted[name][key] = true
+		}
+	}
+
+	counts := make(map[string]int, len(counted))
+	for name, assignments := range counted {
+		counts[name] = len(assignments)
+	}
+	return counts, nil
+}
+
+// GetOpenReviewCounts counts how many open pull requests each of the reviewers has to review.
+// Reviewers without any are left out.
+func (r *ReviewAssignmentRepositoryMemory) GetOpenReviewCounts(
+	ctx context.Context, reviewerIDs []model.UserID,
+) (map[model.UserID]int, error) {
+	defer r.store.rlock(ctx)()
+
+	wanted := make(map[model.UserID]bool, len(reviewerIDs))
+	for _, ID := range reviewerIDs {
+		wanted[ID] = true
+	}
+
+	counts := make(map[model.UserID]int)
+	for key := range r.store.assignments {
+		if wanted[key.reviewerID] && r.store.pullRequests[key.pullRequestID].Status == model.PRStatusOpen {
+			counts[key.reviewerID]++
+		}
+	}
+	return counts, nil
+}
+
+// GetReviewHistory counts the assignments of each reviewer to pull requests of the author and
+// tells when the last one was made. Reviewers who never reviewed the author are left out.
+func (r *ReviewAssignmentRepositoryMemory) GetReviewHistory(
+	ctx context.Context, authorID model.UserID, reviewerIDs []model.UserID,
+) (map[model.UserID]model.ReviewHistory, error) {
+	defer r.store.rlock(ctx)()
+
+	wanted := make(map[model.UserID]bool, len(reviewerIDs))
+	for _, ID := range reviewerIDs {
+		wanted[ID] = true
+	}
+
+	history := make(map[model.UserID]model.ReviewHistory)
+	for key, assignment := range r.store.assignments {
+		if !wanted[key.reviewerID] || r.store.pullRequests[key.pullRequestID].AuthorID != authorID {
+			continue
+		}
+
+		reviews := history[key.reviewerID]
+		reviews.Reviews++
+		if assignment.AssignedAt.After(reviews.LastReviewedAt) {
+			reviews.LastReviewedAt = assignment.AssignedAt
+		}
+		history[key.reviewerID] = reviews
+	}
+	return history, nil
+}
+
+// Insert stores an assignment as is, keeping its original assignment time.
+func (r *ReviewAssignmentRepositoryMemory) Insert(ctx context.Context, assignment *model.ReviewAssignment) error {
+	defer r.store.lock(ctx)()
+
+	err := r.checkInsert(assignment.PullRequestID, assignment.ReviewerID)
+	if err != nil {
+		return err
+	}
+
+	key := assignmentKey{pullRequestID: assignment.PullRequestID, reviewerID: assignment.ReviewerID}
+	r.store.assignments[key] = *assignment
+	return nil
+}
+
+// InsertBatch stores all assignments or none, keeping their assignment times.
+func (r *ReviewAssignmentRepositoryMemory) InsertBatch(
+	ctx context.Context, assignments []model.ReviewAssignment,
+) error {
+	defer r.store.lock(ctx)()
+
+	seen := make(map[assignmentKey]bool, len(assignments))
+	for _, assignment := range assignments {
+		err := r.checkInsert(assignment.PullRequestID, assignment.ReviewerID)
+		if err != nil {
+			return err
+		}
+
+		key := assignmentKey{pullRequestID: assignment.PullRequestID, reviewerID: assignment.ReviewerID}
+		if seen[key] {
+			return errDuplicateKey
+		}
+		seen[key] = true
+	}
+
+	for _, assignment := range assignments {
+		key := assignmentKey{pullRequestID: assignment.PullRequestID, reviewerID: assignment.ReviewerID}
+		r.store.assignments[key] = assignment
+	}
+	return nil
+}
+
+func (r *ReviewAssignmentRepositoryMemory) StreamAll(
+	ctx context.Context, fn func(assignment *model.ReviewAssignment) error,
+) error {
+	unlock := r.store.rlock(ctx)
+	assignments := make([]model.ReviewAssignment, 0, len(r.store.assignments))
+	for _, assignment := range r.store.assignments {
+		assignments = append(assignments, assignment)
+	}
+	unlock()
+
+	sort.Slice(assignments, func(i, j int) bool {
+		a, b := assignments[i], assignments[j]
+		if !a.AssignedAt.Equal(b.AssignedAt) {
+			return a.AssignedAt.Before(b.AssignedAt)
+		}
+		if a.PullRequestID != b.PullRequestID {
+			return compareIDs(a.PullRequestID, b.PullRequestID) < 0
+		}
+		return compareIDs(a.ReviewerID, b.ReviewerID) < 0
+	})
+	for _, assignment := range assignments {
+		if err := fn(&assignment); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetAssignments lists the assignments of the pull request, telling which rule picked each reviewer.
+func (r *ReviewAssignmentRepositoryMemory) GetAssignments(
+	ctx context.Context, pullRequestID model.PullRequestID,
+) ([]model.ReviewAssignment, error) {
+	defer r.store.rlock(ctx)()
+
+	var assignments []model.ReviewAssignment
+	for key, assignment := range r.store.assignments {
+		if key.pullRequestID == pullRequestID {
+			assignments = append(assignments, assignment)
+		}
+	}
+
+	sort.Slice(assignments, func(i, j int) bool {
+		a, b := assignments[i], assignments[j]
+		if !a.AssignedAt.Equal(b.AssignedAt) {
+			return a.AssignedAt.Before(b.AssignedAt)
+		}
+		return compareIDs(a.ReviewerID, b.ReviewerID) < 0
+	})
+	return assignments, nil
+}
+
+func (r *ReviewAssignmentRepositoryMemory) RecordDecline(ctx context.Context, decline *model.ReviewDecline) error {
+	defer r.store.lock(ctx)()
+
+	if _, ok := r.store.pullRequests[decline.PullRequestID]; !ok {
+		return errForeignKeyViolated
+	}
+	if _, ok := r.store.users[decline.ReviewerID]; !ok {
+		return errForeignKeyViolated
+	}
+
+	r.store.declines = append(r.store.declines, *decline)
+	return nil
+}
+
+// StreamDeclines calls fn for every decline, oldest first.
+func (r *ReviewAssignmentRepositoryMemory) StreamDeclines(
+	ctx context.Context, fn func(decline *model.ReviewDecline) error,
+) error {
+	unlock := r.store.rlock(ctx)
+	declines := slices.Clone(r.store.declines)
+	unlock()
+
+	sort.SliceStable(declines, func(i, j int) bool {
+		return declines[i].DeclinedAt.Before(declines[j].DeclinedAt)
+	})
+	for _, decline := range declines {
+		if err := fn(&decline); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetDecliners lists everybody who declined the pull request, each once.
+func (r *ReviewAssignmentRepositoryMemory) GetDecliners(
+	ctx context.Context, pullRequestID model.PullRequestID,
+) ([]model.UserID, error) {
+	defer r.store.rlock(ctx)()
+
+	var decliners []model.UserID
+	seen := make(map[model.UserID]bool)
+	for _, decline := range r.store.declines {
+		if decline.PullRequestID != pullRequestID || seen[decline.ReviewerID] {
+			continue
+		}
+		seen[decline.ReviewerID] = true
+		decliners = append(decliners, decline.ReviewerID)
+	}
+	return decliners, nil
+}
+
+func (r *ReviewAssignmentRepositoryMemory) GetDeclineCounts(ctx context.Context) (map[string]int, error) {
+	defer r.store.rlock(ctx)()
+
+	counts := make(map[string]int)
+	for _, decline := range r.store.declines {
+		counts[uuid.UUID(decline.ReviewerID).String()]++
+	}
+	return counts, nil
+}
@@ -0,0 +1,114 @@
+package memory
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"sort"
+	"sync"
+
+	"github.com/google/uuid"
+
+	"pull-request-review/internal/domain/model"
+	"pull-request-review/internal/domain/ports/repository"
+)
+
+var (
+	errDuplicateKey       = errors.New("duplicate key")
+	errForeignKeyViolated = errors.New("referenced row does not exist")
+)
+
+type membershipKey struct {
+	teamID model.TeamID
+	userID model.UserID
+}
+
+type assignmentKey struct {
+	pullRequestID model.PullRequestID
+	reviewerID    model.UserID
+}
+
+type idempotencyKey struct {
+	key   string
+	route string
+}
+
+// Store keeps every table in memory behind one lock, so the repositories built on it see
+// each other's writes the way they would through a shared database. Rows are stored and
+// returned by value, callers never share memory with the store.
+type Store struct {
+	mu           sync.RWMutex
+	teams        map[model.TeamID]model.Team
+	users        map[model.UserID]model.User
+	memberships  map[membershipKey]model.TeamMembership
+	teamRoles    map[membershipKey]model.TeamRoleGrant
+	pullRequests map[model.PullRequestID]model.PullRequest
+	assignments  map[assignmentKey]model.ReviewAssignment
+	idempotency  map[idempotencyKey]model.IdempotencyRecord
+	audit        []model.MembershipChange
+	declines     []model.ReviewDecline
+}
+
+func NewStore() *Store {
+	return &Store{
+		teams:        make(map[model.TeamID]model.Team),
+		users:        make(map[model.UserID]model.User),
+		memberships:  make(map[membershipKey]model.TeamMembership),
+		teamRoles:    make(map[membershipKey]model.TeamRoleGrant),
+		pullRequests: make(map[model.PullRequestID]model.PullRequest),
+		assignments:  make(map[assignmentKey]model.ReviewAssignment),
+		idempotency:  make(map[idempotencyKey]model.IdempotencyRecord),
+	}
+}
+
+// Ping lets the store stand in for the database in health checks.
+func (s *Store) Ping(ctx context.Context) error {
+	return ctx.Err()
+}
+
+func NewRepositories(store *Store) *repository.Repositories {
+	return &repository.Repositories{
+		Team:             NewTeamRepository(store),
+		User:             NewUserRepository(store),
+		PullRequest:      NewPullRequestRepository(store),
+		ReviewAssignment: NewReviewAssignmentRepository(store),
+		TeamRole:         NewTeamRoleRepository(store),
+		TeamMembership:   NewTeamMembershipRepository(store),
+		MembershipAudit:  NewMembershipAuditRepository(store),
+		Idempotency:      NewIdempotencyRepository(store),
+		Tx:               store,
+	}
+}
+
+// teamMembers returns the users with a membership in teamID. The caller holds the lock.
+func (s *Store) teamMembers(teamID model.TeamID) []model.User {
+	var users []model.User
+	for key := range s.memberships {
+		if key.teamID != teamID {
+			continue
+		}
+		if user, ok := s.users[key.userID]; ok {
+			users = append(users, user)
+		}
+	}
+	sortUsers(users)
+	return users
+}
+
+func (s *Store) teamExists(ID uuid.UUID) bool {
+	_, ok := s.teams[model.TeamID(ID)]
+	return ok
+}
+
+func sortUsers(users []model.User) {
+	sort.Slice(users, func(i, j int) bool {
+		if !users[i].CreatedAt.Equal(users[j].CreatedAt) {
+			return users[i].CreatedAt.Before(users[j].CreatedAt)
+		}
+		return compareIDs(users[i].ID, users[j].ID) < 0
+	})
+}
+
+func compareIDs[T ~[16]byte](a, b T) int {
+	return bytes.Compare(a[:], b[:])
+}
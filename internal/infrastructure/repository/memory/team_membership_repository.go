@@ -0,0 +1,115 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"pull-request-review/internal/domain/model"
+	"pull-request-review/internal/domain/ports/repository"
+	"pull-request-review/internal/domain/rules"
+)
+
+type TeamMembershipRepositoryMemory struct {
+	store *Store
+}
+
+func NewTeamMembershipRepository(store *Store) repository.TeamMembershipRepository {
+	return &TeamMembershipRepositoryMemory{store: store}
+}
+
+func (r *TeamMembershipRepositoryMemory) Add(ctx context.Context, membership *model.TeamMembership) error {
+	defer r.store.lock(ctx)()
+
+	if _, ok := r.store.teams[membership.TeamID]; !ok {
+		return errForeignKeyViolated
+	}
+	if _, ok := r.store.users[membership.UserID]; !ok {
+		return errForeignKeyViolated
+	}
+
+	key := membershipKey{teamID: membership.TeamID, userID: membership.UserID}
+	added := *membership
+	if stored, ok := r.store.memberships[key]; ok {
+		stored.Weight = membership.Weight
+		added = stored
+	}
+	added.TeamName = ""
+	r.store.memberships[key] = added
+	return nil
+}
+
+func (r *TeamMembershipRepositoryMemory) Remove(ctx context.Context, teamID model.TeamID, userID model.UserID) error {
+	defer r.store.lock(ctx)()
+
+	key := membershipKey{teamID: teamID, userID: userID}
+	if _, ok := r.store.memberships[key]; !ok {
+		return rules.ErrNotFound
+	}
+
+	delete(r.store.memberships, key)
+	return nil
+}
+
+func (r *TeamMembershipRepositoryMemory) Exists(ctx context.Context, teamID model.TeamID, userID model.UserID) (
+	bool, error,
+) {
+	defer r.store.rlock(ctx)()
+
+	_, ok := r.store.memberships[membershipKey{teamID: teamID, userID: userID}]
+	return ok, nil
+}
+
+func (r *TeamMembershipRepositoryMemory) GetByUser(ctx context.Context, userID model.UserID) (
+	[]model.TeamMembership, error,
+) {
+	defer r.store.rlock(ctx)()
+
+	return r.collect(func(key membershipKey) bool { return key.userID == userID }), nil
+}
+
+func (r *TeamMembershipRepositoryMemory) GetByTeam(ctx context.Context, teamID model.TeamID) (
+	[]model.TeamMembership, error,
+) {
+	defer r.store.rlock(ctx)()
+
+	return r.collect(func(key membershipKey) bool { return key.teamID == teamID }), nil
+}
+
+func (r *TeamMembershipRepositoryMemory) StreamAll(
+	ctx context.Context, fn func(membership *model.TeamMembership) error,
+) error {
+	unlock := r.store.rlock(ctx)
+	memberships := r.collect(func(membershipKey) bool { return true })
+	unlock()
+
+	for _, membership := range memberships {
+		if err := fn(&membership); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// collect returns the matching memberships with their team names, oldest first. The caller holds the lock.
+func (r *TeamMembershipRepositoryMemory) collect(match func(key membershipKey) bool) []model.TeamMembership {
+	var memberships []model.TeamMembership
+	for key, membership := range r.store.memberships {
+		if !match(key) {
+			continue
+		}
+		membership.TeamName = r.store.teams[key.teamID].Name
+		memberships = append(memberships, membership)
+	}
+
+	sort.Slice(memberships, func(i, j int) bool {
+		a, b := memberships[i], memberships[j]
+		if !a.CreatedAt.Equal(b.CreatedAt) {
+			return a.CreatedAt.Before(b.CreatedAt)
+		}
+		if a.TeamName != b.TeamName {
+			return a.TeamName < b.TeamName
+		}
+		return compareIDs(a.UserID, b.UserID) < 0
+	})
+	return memberships
+}
@@ -0,0 +1,312 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+
+	"pull-request-review/internal/domain/model"
+	"pull-request-review/internal/domain/ports/repository"
+	"pull-request-review/internal/domain/rules"
+)
+
+type TeamRepositoryMemory struct {
+	store *Store
+}
+
+func NewTeamRepository(store *Store) repository.TeamRepository {
+	return &TeamRepositoryMemory{store: store}
+}
+
+func (r *TeamRepositoryMemory) Create(ctx context.Context, team *model.Team) error {
+	defer r.store.lock(ctx)()
+
+	if _, ok := r.store.teams[team.TeamID]; ok {
+		return nil
+	}
+	if team.ParentTeamID != uuid.Nil && !r.store.teamExists(team.ParentTeamID) {
+		return errForeignKeyViolated
+	}
+
+	r.store.teams[team.TeamID] = *team
+	return nil
+}
+
+func (r *TeamRepositoryMemory) Update(ctx context.Context, team *model.Team) error {
+	defer r.store.lock(ctx)()
+
+	stored, ok := r.store.teams[team.TeamID]
+	if !ok {
+		return nil
+	}
+	if team.ParentTeamID != uuid.Nil && !r.store.teamExists(team.ParentTeamID) {
+		return errForeignKeyViolated
+	}
+
+	stored.Name = team.Name
+	stored.ParentTeamID = team.ParentTeamID
+	stored.Settings = team.Settings
+	r.store.teams[team.TeamID] = stored
+	return nil
+}
+
+func (r *TeamRepositoryMemory) GetByID(ctx context.Context, ID model.TeamID) (*model.Team, error) {
+	defer r.store.rlock(ctx)()
+
+	team, ok := r.store.teams[ID]
+	if !ok {
+		return nil, rules.ErrTeamNotFound
+	}
+	return &team, nil
+}
+
+func (r *TeamRepositoryMemory) GetByName(ctx context.Context, name string) (*model.Team, error) {
+	defer r.store.rlock(ctx)()
+
+	team, ok := r.findByName(name)
+	if !ok {
+		return nil, rules.ErrTeamNotFound
+	}
+	return &team, nil
+}
+
+func (r *TeamRepositoryMemory) ExistsByName(ctx context.Context, name string) (bool, error) {
+	defer r.store.rlock(ctx)()
+
+	_, ok := r.findByName(name)
+	return ok, nil
+}
+
+func (r *TeamRepositoryMemory) findByName(name string) (model.Team, bool) {
+	for _, team := range r.store.teams {
+		if team.Name == name {
+			return team, true
+		}
+	}
+	return model.Team{}, false
+}
+
+func (r *TeamRepositoryMemory) Exists(ctx context.Context, ID model.TeamID) (bool, error) {
+	defer r.store.rlock(ctx)()
+
+	_, ok := r.store.teams[ID]
+	return ok, nil
+}
+
+func (r *TeamRepositoryMemory) GetMembers(ctx context.Context, ID model.TeamID) ([]*model.User, error) {
+	defer r.store.rlock(ctx)()
+
+	var users []*model.User
+	for _, user := range r.store.teamMembers(ID) {
+		users = append(users, &user)
+	}
+	return users, nil
+}
+
+func (r *TeamRepositoryMemory) BulkDeactivateTeam(ctx context.Context, ID model.TeamID) error {
+	defer r.store.lock(ctx)()
+
+	now := time.Now()
+	for _, user := range r.store.teamMembers(ID) {
+		if !user.IsActive {
+			continue
+		}
+		user.IsActive = false
+		user.UpdatedAt = now
+		r.store.users[user.ID] = user
+	}
+	return nil
+}
+
+// Lock only checks that the team exists: a transaction already holds the store lock.
+func (r *TeamRepositoryMemory) Lock(ctx context.Context, ID model.TeamID) error {
+	defer r.store.rlock(ctx)()
+
+	if !r.store.teamExists(uuid.UUID(ID)) {
+		return rules.ErrTeamNotFound
+	}
+	return nil
+}
+
+// LockName has nothing to do: a transaction already holds the store lock.
+func (r *TeamRepositoryMemory) LockName(context.Context, string) error {
+	return nil
+}
+
+func (r *TeamRepositoryMemory) CreateWithMembers(ctx context.Context, team *model.Team, members []model.User) error {
+	defer r.store.lock(ctx)()
+
+	if _, ok := r.store.teams[team.TeamID]; ok {
+		return errDuplicateKey
+	}
+	if team.ParentTeamID != uuid.Nil && !r.store.teamExists(team.ParentTeamID) {
+		return errForeignKeyViolated
+	}
+	for _, member := range members {
+		if member.TeamID != uuid.Nil && member.TeamID != uuid.UUID(team.TeamID) && !r.store.teamExists(member.TeamID) {
+			return errForeignKeyViolated
+		}
+	}
+
+	r.store.teams[team.TeamID] = *team
+	for _, member := range members {
+		if stored, ok := r.store.users[member.ID]; ok {
+			member.CreatedAt = stored.CreatedAt
+		}
+		r.store.users[member.ID] = member
+
+		key := membershipKey{teamID: team.TeamID, userID: member.ID}
+		if _, ok := r.store.memberships[key]; !ok {
+			r.store.memberships[key] = model.TeamMembership{
+				TeamID:    team.TeamID,
+				UserID:    member.ID,
+				Weight:    model.DefaultMembershipWeight,
+				CreatedAt: team.CreatedAt,
+			}
+		}
+	}
+
+	return nil
+}
+
+// ApplyImport checks every reference up front, so a failing plan leaves the store untouched
+// just like a rolled back transaction would.
+func (r *TeamRepositoryMemory) ApplyImport(ctx context.Context, plan *model.ImportPlan) error {
+	defer r.store.lock(ctx)()
+
+	teamIDs := make(map[uuid.UUID]bool)
+	for _, team := range plan.Teams {
+		if team.ParentTeamID != uuid.Nil && !teamIDs[team.ParentTeamID] && !r.store.teamExists(team.ParentTeamID) {
+			return errForeignKeyViolated
+		}
+		teamIDs[uuid.UUID(team.TeamID)] = true
+	}
+	userIDs := make(map[model.UserID]bool)
+	for _, user := range plan.Users {
+		if user.TeamID != uuid.Nil && !teamIDs[user.TeamID] && !r.store.teamExists(user.TeamID) {
+			return errForeignKeyViolated
+		}
+		userIDs[user.ID] = true
+	}
+	for _, membership := range plan.Memberships {
+		_, userExists := r.store.users[membership.UserID]
+		if !teamIDs[uuid.UUID(membership.TeamID)] && !r.store.teamExists(uuid.UUID(membership.TeamID)) ||
+			!userIDs[membership.UserID] && !userExists {
+			return errForeignKeyViolated
+		}
+	}
+
+	for _, team := range plan.Teams {
+		stored, ok := r.store.teams[team.TeamID]
+		if !ok {
+			stored = model.Team{TeamID: team.TeamID, CreatedAt: team.CreatedAt}
+		}
+		stored.Name = team.Name
+		stored.ParentTeamID = team.ParentTeamID
+		r.store.teams[team.TeamID] = stored
+	}
+	for _, user := range plan.Users {
+		if stored, ok := r.store.users[user.ID]; ok {
+			user.CreatedAt = stored.CreatedAt
+		}
+		r.store.users[user.ID] = user
+	}
+	for _, membership := range plan.Memberships {
+		key := membershipKey{teamID: membership.TeamID, userID: membership.UserID}
+		if stored, ok := r.store.memberships[key]; ok {
+			stored.Weight = membership.Weight
+			membership = stored
+		}
+		membership.TeamName = ""
+		r.store.memberships[key] = membership
+	}
+
+	return nil
+}
+
+func (r *TeamRepositoryMemory) GetAll(ctx context.Context) ([]model.Team, error) {
+	defer r.store.rlock(ctx)()
+
+	var teams []model.Team
+	for _, team := range r.store.teams {
+		teams = append(teams, team)
+	}
+	sort.Slice(teams, func(i, j int) bool { return teams[i].Name < teams[j].Name })
+	return teams, nil
+}
+
+// GetAncestors returns the parent chain of the team, nearest parent first.
+func (r *TeamRepositoryMemory) GetAncestors(ctx context.Context, ID model.TeamID) ([]model.Team, error) {
+	defer r.store.rlock(ctx)()
+
+	var ancestors []model.Team
+	team, ok := r.store.teams[ID]
+	for ok && team.ParentTeamID != uuid.Nil && len(ancestors) <= len(r.store.teams) {
+		team, ok = r.store.teams[model.TeamID(team.ParentTeamID)]
+		if ok {
+			ancestors = append(ancestors, team)
+		}
+	}
+	return ancestors, nil
+}
+
+// GetDescendants returns every team below the given one, level by level.
+func (r *TeamRepositoryMemory) GetDescendants(ctx context.Context, ID model.TeamID) ([]model.Team, error) {
+	defer r.store.rlock(ctx)()
+
+	var descendants []model.Team
+	level := []uuid.UUID{uuid.UUID(ID)}
+	for len(level) > 0 && len(descendants) <= len(r.store.teams) {
+		children := r.childrenOf(level)
+		descendants = append(descendants, children...)
+
+		level = level[:0]
+		for _, child := range children {
+			level = append(level, uuid.UUID(child.TeamID))
+		}
+	}
+	return descendants, nil
+}
+
+// StreamAll walks every team, parents before their children.
+func (r *TeamRepositoryMemory) StreamAll(ctx context.Context, fn func(team *model.Team) error) error {
+	unlock := r.store.rlock(ctx)
+	var teams []model.Team
+	level := []uuid.UUID{uuid.Nil}
+	for len(level) > 0 && len(teams) <= len(r.store.teams) {
+		children := r.childrenOf(level)
+		teams = append(teams, children...)
+
+		level = level[:0]
+		for _, child := range children {
+			level = append(level, uuid.UUID(child.TeamID))
+		}
+	}
+	unlock()
+
+	for _, team := range teams {
+		if err := fn(&team); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// childrenOf returns the teams whose parent is one of parentIDs, ordered by name.
+func (r *TeamRepositoryMemory) childrenOf(parentIDs []uuid.UUID) []model.Team {
+	parents := make(map[uuid.UUID]bool, len(parentIDs))
+	for _, ID := range parentIDs {
+		parents[ID] = true
+	}
+
+	var children []model.Team
+	for _, team := range r.store.teams {
+		if parents[team.ParentTeamID] {
+			children = append(children, team)
+		}
+	}
+	sort.Slice(children, func(i, j int) bool { return children[i].Name < children[j].Name })
+	return children
+}
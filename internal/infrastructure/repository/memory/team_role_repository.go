@@ -0,0 +1,85 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"pull-request-review/internal/domain/model"
+	"pull-request-review/internal/domain/ports/repository"
+	"pull-request-review/internal/domain/rules"
+)
+
+type TeamRoleRepositoryMemory struct {
+	store *Store
+}
+
+func NewTeamRoleRepository(store *Store) repository.TeamRoleRepository {
+	return &TeamRoleRepositoryMemory{store: store}
+}
+
+func (r *TeamRoleRepositoryMemory) SetRole(
+	ctx context.Context, teamID model.TeamID, userID model.UserID, role model.TeamRole,
+) error {
+	defer r.store.lock(ctx)()
+
+	if _, ok := r.store.teams[teamID]; !ok {
+		return errForeignKeyViolated
+	}
+	if _, ok := r.store.users[userID]; !ok {
+		return errForeignKeyViolated
+	}
+
+	r.store.teamRoles[membershipKey{teamID: teamID, userID: userID}] = model.TeamRoleGrant{
+		TeamID:    teamID,
+		UserID:    userID,
+		Role:      role,
+		GrantedAt: time.Now(),
+	}
+	return nil
+}
+
+func (r *TeamRoleRepositoryMemory) DeleteRole(ctx context.Context, teamID model.TeamID, userID model.UserID) error {
+	defer r.store.lock(ctx)()
+
+	delete(r.store.teamRoles, membershipKey{teamID: teamID, userID: userID})
+	return nil
+}
+
+func (r *TeamRoleRepositoryMemory) GetRole(
+	ctx context.Context, teamID model.TeamID, userID model.UserID,
+) (model.TeamRole, error) {
+	defer r.store.rlock(ctx)()
+
+	grant, ok := r.store.teamRoles[membershipKey{teamID: teamID, userID: userID}]
+	if !ok {
+		return "", rules.ErrNotFound
+	}
+	return grant.Role, nil
+}
+
+func (r *TeamRoleRepositoryMemory) StreamAll(ctx context.Context, fn func(grant *model.TeamRoleGrant) error) error {
+	unlock := r.store.rlock(ctx)
+	grants := make([]model.TeamRoleGrant, 0, len(r.store.teamRoles))
+	for _, grant := range r.store.teamRoles {
+		grants = append(grants, grant)
+	}
+	unlock()
+
+	sort.Slice(grants, func(i, j int) bool {
+		a, b := grants[i], grants[j]
+		if !a.GrantedAt.Equal(b.GrantedAt) {
+			return a.GrantedAt.Before(b.GrantedAt)
+		}
+		if a.TeamID != b.TeamID {
+			return compareIDs(a.TeamID, b.TeamID) < 0
+		}
+		return compareIDs(a.UserID, b.UserID) < 0
+	})
+	for _, grant := range grants {
+		if err := fn(&grant); err != nil {
+			return err
+		}
+	}
+	return nil
+}
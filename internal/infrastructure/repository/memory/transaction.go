@@ -0,0 +1,106 @@
+package memory
+
+import (
+	"context"
+	"maps"
+	"slices"
+
+	"pull-request-review/internal/domain/model"
+)
+
+type txKey struct{}
+
+// WithinTx holds the write lock of the store while fn runs, so other callers wait for the
+// transaction like they would for row locks, and restores a snapshot of the tables when fn
+// fails. Repositories called with the transaction's context skip locking, the lock is held.
+func (s *Store) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if s.inTx(ctx) {
+		return fn(ctx)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	snapshot := s.snapshot()
+	if err := fn(context.WithValue(ctx, txKey{}, s)); err != nil {
+		s.restore(snapshot)
+		return err
+	}
+
+	return nil
+}
+
+// WithinReadOnlyTx holds the read lock of the store while fn runs, so writers wait until fn has
+// seen everything it reads. Repositories called with the transaction's context skip locking.
+func (s *Store) WithinReadOnlyTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if s.inTx(ctx) {
+		return fn(ctx)
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return fn(context.WithValue(ctx, txKey{}, s))
+}
+
+func (s *Store) inTx(ctx context.Context) bool {
+	store, ok := ctx.Value(txKey{}).(*Store)
+	return ok && store == s
+}
+
+// lock takes the write lock unless ctx belongs to a transaction on this store.
+func (s *Store) lock(ctx context.Context) func() {
+	if s.inTx(ctx) {
+		return func() {}
+	}
+	s.mu.Lock()
+	return s.mu.Unlock
+}
+
+// rlock takes the read lock unless ctx belongs to a transaction on this store.
+func (s *Store) rlock(ctx context.Context) func() {
+	if s.inTx(ctx) {
+		return func() {}
+	}
+	s.mu.RLock()
+	return s.mu.RUnlock
+}
+
+type tables struct {
+	teams        map[model.TeamID]model.Team
+	users        map[model.UserID]model.User
+	memberships  map[membershipKey]model.TeamMembership
+	teamRoles    map[membershipKey]model.TeamRoleGrant
+	pullRequests map[model.PullRequestID]model.PullRequest
+	assignments  map[assignmentKey]model.ReviewAssignment
+	idempotency  map[idempotencyKey]model.IdempotencyRecord
+	audit        []model.MembershipChange
+	declines     []model.ReviewDecline
+}
+
+// snapshot copies the tables. Rows are values, so a shallow copy of each map is enough.
+func (s *Store) snapshot() tables {
+	return tables{
+		teams:        maps.Clone(s.teams),
+		users:        maps.Clone(s.users),
+		memberships:  maps.Clone(s.memberships),
+		teamRoles:    maps.Clone(s.teamRoles),
+		pullRequests: maps.Clone(s.pullRequests),
+		assignments:  maps.Clone(s.assignments),
+		idempotency:  maps.Clone(s.idempotency),
+		audit:        slices.Clone(s.audit),
+		declines:     slices.Clone(s.declines),
+	}
+}
+
+func (s *Store) restore(snapshot tables) {
+	s.teams = snapshot.teams
+	s.users = snapshot.users
+	s.memberships = snapshot.memberships
+	s.teamRoles = snapshot.teamRoles
+	s.pullRequests = snapshot.pullRequests
+	s.assignments = snapshot.assignments
+	s.idempotency = snapshot.idempotency
+	s.audit = snapshot.audit
+	s.declines = snapshot.declines
+}
@@ -0,0 +1,153 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"pull-request-review/internal/domain/model"
+	"pull-request-review/internal/domain/ports/repository"
+	"pull-request-review/internal/domain/rules"
+)
+
+type UserRepositoryMemory struct {
+	store *Store
+}
+
+func NewUserRepository(store *Store) repository.UserRepository {
+	return &UserRepositoryMemory{store: store}
+}
+
+func (r *UserRepositoryMemory) Insert(ctx context.Context, user *model.User) error {
+	defer r.store.lock(ctx)()
+
+	if _, ok := r.store.users[user.ID]; ok {
+		return nil
+	}
+	if user.TeamID != uuid.Nil && !r.store.teamExists(user.TeamID) {
+		return errForeignKeyViolated
+	}
+
+	r.store.users[user.ID] = *user
+	return nil
+}
+
+func (r *UserRepositoryMemory) Update(ctx context.Context, user *model.User) error {
+	defer r.store.lock(ctx)()
+
+	stored, ok := r.store.users[user.ID]
+	if !ok {
+		return rules.ErrUserNotFound
+	}
+	if user.TeamID != uuid.Nil && !r.store.teamExists(user.TeamID) {
+		return errForeignKeyViolated
+	}
+
+	stored.Username = user.Username
+	stored.TeamID = user.TeamID
+	stored.IsActive = user.IsActive
+	stored.UpdatedAt = user.UpdatedAt
+	r.store.users[user.ID] = stored
+	return nil
+}
+
+func (r *UserRepositoryMemory) Upsert(ctx context.Context, user *model.User) error {
+	defer r.store.lock(ctx)()
+
+	if user.TeamID != uuid.Nil && !r.store.teamExists(user.TeamID) {
+		return errForeignKeyViolated
+	}
+
+	upserted := *user
+	if stored, ok := r.store.users[user.ID]; ok {
+		upserted.CreatedAt = stored.CreatedAt
+	}
+	r.store.users[user.ID] = upserted
+	return nil
+}
+
+func (r *UserRepositoryMemory) UpdateActivity(ctx context.Context, ID model.UserID, isActive bool) error {
+	defer r.store.lock(ctx)()
+
+	user, ok := r.store.users[ID]
+	if !ok {
+		return rules.ErrUserNotFound
+	}
+
+	user.IsActive = isActive
+	user.UpdatedAt = time.Now()
+	r.store.users[ID] = user
+	return nil
+}
+
+func (r *UserRepositoryMemory) GetByID(ctx context.Context, ID model.UserID) (*model.User, error) {
+	defer r.store.rlock(ctx)()
+
+	user, ok := r.store.users[ID]
+	if !ok {
+		return nil, rules.ErrUserNotFound
+	}
+	return &user, nil
+}
+
+func (r *UserRepositoryMemory) GetByIDs(ctx context.Context, IDs []model.UserID) ([]model.User, error) {
+	defer r.store.rlock(ctx)()
+
+	var users []model.User
+	for _, ID := range IDs {
+		if user, ok := r.store.users[ID]; ok {
+			users = append(users, user)
+		}
+	}
+	return users, nil
+}
+
+func (r *UserRepositoryMemory) GetByTeam(ctx context.Context, teamID model.TeamID) ([]model.User, error) {
+	defer r.store.rlock(ctx)()
+
+	return r.store.teamMembers(teamID), nil
+}
+
+func (r *UserRepositoryMemory) Exists(ctx context.Context, ID model.UserID) (bool, error) {
+	defer r.store.rlock(ctx)()
+
+	_, ok := r.store.users[ID]
+	return ok, nil
+}
+
+func (r *UserRepositoryMemory) GetActiveByTeamExcluding(
+	ctx context.Context, teamID model.TeamID, excludedUserIDs []model.UserID,
+) ([]model.User, error) {
+	defer r.store.rlock(ctx)()
+
+	excluded := make(map[model.UserID]bool, len(excludedUserIDs))
+	for _, ID := range excludedUserIDs {
+		excluded[ID] = true
+	}
+
+	var users []model.User
+	for _, user := range r.store.teamMembers(teamID) {
+		if user.IsActive && !excluded[user.ID] {
+			users = append(users, user)
+		}
+	}
+	return users, nil
+}
+
+func (r *UserRepositoryMemory) StreamAll(ctx context.Context, fn func(user *model.User) error) error {
+	unlock := r.store.rlock(ctx)
+	users := make([]model.User, 0, len(r.store.users))
+	for _, user := range r.store.users {
+		users = append(users, user)
+	}
+	unlock()
+
+	sortUsers(users)
+	for _, user := range users {
+		if err := fn(&user); err != nil {
+			return err
+		}
+	}
+	return nil
+}
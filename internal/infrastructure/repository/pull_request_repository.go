@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"errors"
+	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	"pull-request-review/internal/domain/model"
 	"pull-request-review/internal/domain/ports/repository"
@@ -19,13 +20,56 @@ func NewPullRequestRepositoryPgx(database *database.Database) repository.PullReq
 	return &PullRequestRepositoryPgx{database: database}
 }
 
+const pullRequestColumns = `pull_request_id, name, author_id, status, created_at, merged_at, version,
+repository, source_branch, target_branch, url, description, labels, changed_paths`
+
+func scanPullRequest(row pgx.Row) (*model.PullRequest, error) {
+	var pr model.PullRequest
+	err := row.Scan(
+		&pr.PullRequestID,
+		&pr.Name,
+		&pr.AuthorID,
+		&pr.Status,
+		&pr.CreatedAt,
+		&pr.MergedAt,
+		&pr.Version,
+		&pr.Repository,
+		&pr.SourceBranch,
+		&pr.TargetBranch,
+		&pr.URL,
+		&pr.Description,
+		&pr.Labels,
+		&pr.ChangedPaths,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &pr, nil
+}
+
+// labels never passes nil to the NOT NULL labels column.
+func labels(metadata model.PullRequestMetadata) []string {
+	if metadata.Labels == nil {
+		return []string{}
+	}
+	return metadata.Labels
+}
+
+// changedPaths never passes nil to the NOT NULL changed_paths column.
+func changedPaths(pr *model.PullRequest) []string {
+	if pr.ChangedPaths == nil {
+		return []string{}
+	}
+	return pr.ChangedPaths
+}
+
 func (r *PullRequestRepositoryPgx) Create(ctx context.Context, pullRequest *model.PullRequest) error {
 	query := `
-INSERT INTO pull_requests (pull_request_id, name, author_id, status, created_at, merged_at)
-VALUES ($1, $2, $3, $4, $5, $6)
+INSERT INTO pull_requests (` + pullRequestColumns + `)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
 ON CONFLICT DO NOTHING;
 `
-	_, err := r.database.GetPool().Exec(
+	_, err := r.database.Querier(ctx).Exec(
 		ctx, query,
 		pullRequest.PullRequestID,
 		pullRequest.Name,
@@ -33,6 +77,14 @@ ON CONFLICT DO NOTHING;
 		pullRequest.Status,
 		pullRequest.CreatedAt,
 		pullRequest.MergedAt,
+		pullRequest.Version,
+		pullRequest.Repository,
+		pullRequest.SourceBranch,
+		pullRequest.TargetBranch,
+		pullRequest.URL,
+		pullRequest.Description,
+		labels(pullRequest.PullRequestMetadata),
+		changedPaths(pullRequest),
 	)
 	if err != nil {
 		return err
@@ -40,23 +92,43 @@ ON CONFLICT DO NOTHING;
 	return nil
 }
 
+// CreateBatch copies the pull requests in with one COPY. Unlike Create it fails on an existing
+// ID, the caller is expected to have filtered those out.
+func (r *PullRequestRepositoryPgx) CreateBatch(ctx context.Context, pullRequests []model.PullRequest) error {
+	if len(pullRequests) == 0 {
+		return nil
+	}
+
+	_, err := r.database.Querier(ctx).CopyFrom(
+		ctx,
+		pgx.Identifier{"pull_requests"},
+		[]string{
+			"pull_request_id", "name", "author_id", "status", "created_at", "merged_at", "version",
+			"repository", "source_branch", "target_branch", "url", "description", "labels", "changed_paths",
+		},
+		pgx.CopyFromSlice(
+			len(pullRequests), func(i int) ([]any, error) {
+				pr := pullRequests[i]
+				return []any{
+					uuid.UUID(pr.PullRequestID), pr.Name, uuid.UUID(pr.AuthorID), string(pr.Status),
+					pr.CreatedAt, pr.MergedAt, pr.Version,
+					pr.Repository, pr.SourceBranch, pr.TargetBranch, pr.URL, pr.Description,
+					labels(pr.PullRequestMetadata), changedPaths(&pr),
+				}, nil
+			},
+		),
+	)
+	return err
+}
+
 func (r *PullRequestRepositoryPgx) GetByID(ctx context.Context, ID model.PullRequestID) (*model.PullRequest, error) {
 	query := `
-SELECT pull_request_id, name, author_id, status, created_at, merged_at
+SELECT ` + pullRequestColumns + `
 FROM pull_requests
 WHERE pull_request_id = $1
 	`
 
-	var pr model.PullRequest
-	err := r.database.GetPool().QueryRow(ctx, query, ID).Scan(
-		&pr.PullRequestID,
-		&pr.Name,
-		&pr.AuthorID,
-		&pr.Status,
-		&pr.CreatedAt,
-		&pr.MergedAt,
-	)
-
+	pr, err := scanPullRequest(r.database.Querier(ctx).QueryRow(ctx, query, ID))
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, rules.ErrNotFound
@@ -64,7 +136,7 @@ WHERE pull_request_id = $1
 		return nil, err
 	}
 
-	return &pr, nil
+	return pr, nil
 }
 
 func (r *PullRequestRepositoryPgx) Exists(ctx context.Context, ID model.PullRequestID) (bool, error) {
@@ -72,7 +144,7 @@ func (r *PullRequestRepositoryPgx) Exists(ctx context.Context, ID model.PullRequ
 SELECT EXISTS (SELECT 1 FROM pull_requests WHERE pull_request_id = $1)
 `
 	var exists bool
-	err := r.database.GetPool().QueryRow(ctx, query, ID).Scan(&exists)
+	err := r.database.Querier(ctx).QueryRow(ctx, query, ID).Scan(&exists)
 	if err != nil {
 		return false, err
 	}
@@ -80,6 +152,35 @@ SELECT EXISTS (SELECT 1 FROM pull_requests WHERE pull_request_id = $1)
 	return exists, nil
 }
 
+func (r *PullRequestRepositoryPgx) GetExistingIDs(
+	ctx context.Context, IDs []model.PullRequestID,
+) ([]model.PullRequestID, error) {
+	query := `
+SELECT pull_request_id FROM pull_requests WHERE pull_request_id = ANY($1)
+`
+
+	rows, err := r.database.Querier(ctx).Query(ctx, query, IDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var existing []model.PullRequestID
+	for rows.Next() {
+		var ID model.PullRequestID
+		if err := rows.Scan(&ID); err != nil {
+			return nil, err
+		}
+		existing = append(existing, ID)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return existing, nil
+}
+
 func (r *PullRequestRepositoryPgx) UpdateStatus(
 	ctx context.Context, ID model.PullRequestID, status model.PullRequestStatus, mergedAt time.Time,
 ) error {
@@ -89,7 +190,54 @@ SET status = $1, merged_at = $2
 WHERE pull_request_id = $3
 	`
 
-	result, err := r.database.GetPool().Exec(ctx, query, status, mergedAt, ID)
+	result, err := r.database.Querier(ctx).Exec(ctx, query, status, mergedAt, ID)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return rules.ErrPullRequestNotFound
+	}
+
+	return nil
+}
+
+// BumpVersion moves the pull request from version to the next one. When another change got
+// there first it returns rules.ErrConcurrentModification. Inside a transaction the row stays
+// locked until the end, so a concurrent bump waits and then sees the new version.
+func (r *PullRequestRepositoryPgx) BumpVersion(ctx context.Context, ID model.PullRequestID, version int64) error {
+	query := `
+UPDATE pull_requests
+SET version = version + 1
+WHERE pull_request_id = $1 AND version = $2
+`
+
+	result, err := r.database.Querier(ctx).Exec(ctx, query, ID, version)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return rules.ErrConcurrentModification
+	}
+
+	return nil
+}
+
+func (r *PullRequestRepositoryPgx) UpdateMetadata(
+	ctx context.Context, ID model.PullRequestID, metadata model.PullRequestMetadata,
+) error {
+	query := `
+UPDATE pull_requests
+SET repository = $1, source_branch = $2, target_branch = $3, url = $4, description = $5, labels = $6
+WHERE pull_request_id = $7
+`
+
+	result, err := r.database.Querier(ctx).Exec(
+		ctx, query,
+		metadata.Repository, metadata.SourceBranch, metadata.TargetBranch, metadata.URL, metadata.Description,
+		labels(metadata), ID,
+	)
 	if err != nil {
 		return err
 	}
@@ -101,15 +249,21 @@ WHERE pull_request_id = $3
 	return nil
 }
 
-func (r *PullRequestRepositoryPgx) GetByReviewer(ctx context.Context, ID model.UserID) ([]model.PullRequest, error) {
+// GetByReviewer lists the pull requests the user reviews, narrowed down by filter.
+func (r *PullRequestRepositoryPgx) GetByReviewer(
+	ctx context.Context, ID model.UserID, filter model.ReviewFilter,
+) ([]model.PullRequest, error) {
 	query := `
-SELECT DISTINCT p.pull_request_id, p.name, p.author_id, p.status, p.created_at, p.merged_at
+SELECT p.pull_request_id, p.name, p.author_id, p.status, p.created_at, p.merged_at, p.version,
+       p.repository, p.source_branch, p.target_branch, p.url, p.description, p.labels, p.changed_paths
 FROM pull_requests p
 INNER JOIN review_assignments ra ON p.pull_request_id = ra.pull_request_id
 WHERE ra.user_id = $1
+  AND ($2 = '' OR p.repository = $2)
+  AND ($3 = '' OR $3 = ANY(p.labels))
 `
 
-	rows, err := r.database.GetPool().Query(ctx, query, ID)
+	rows, err := r.database.Querier(ctx).Query(ctx, query, ID, filter.Repository, filter.Label)
 	if err != nil {
 		return nil, err
 	}
@@ -118,19 +272,11 @@ WHERE ra.user_id = $1
 	var pullRequests []model.PullRequest
 
 	for rows.Next() {
-		var pr model.PullRequest
-		err := rows.Scan(
-			&pr.PullRequestID,
-			&pr.Name,
-			&pr.AuthorID,
-			&pr.Status,
-			&pr.CreatedAt,
-			&pr.MergedAt,
-		)
+		pr, err := scanPullRequest(rows)
 		if err != nil {
 			return nil, err
 		}
-		pullRequests = append(pullRequests, pr)
+		pullRequests = append(pullRequests, *pr)
 	}
 
 	if err := rows.Err(); err != nil {
@@ -147,7 +293,7 @@ FROM pull_requests
 GROUP BY status
 	`
 
-	rows, err := r.database.GetPool().Query(ctx, query)
+	rows, err := r.database.Querier(ctx).Query(ctx, query)
 	if err != nil {
 		return nil, err
 	}
@@ -169,4 +315,32 @@ GROUP BY status
 	}
 
 	return counts, nil
+}
+
+func (r *PullRequestRepositoryPgx) StreamAll(
+	ctx context.Context, fn func(pullRequest *model.PullRequest) error,
+) error {
+	query := `
+SELECT ` + pullRequestColumns + `
+FROM pull_requests
+ORDER BY created_at, pull_request_id
+`
+
+	rows, err := r.database.Querier(ctx).Query(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		pr, err := scanPullRequest(rows)
+		if err != nil {
+			return err
+		}
+		if err := fn(pr); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
 }
\ No newline at end of file
@@ -0,0 +1,20 @@
+package repository
+
+import (
+	"pull-request-review/internal/domain/ports/repository"
+	"pull-request-review/internal/infrastructure/database"
+)
+
+func NewRepositories(database *database.Database) *repository.Repositories {
+	return &repository.Repositories{
+		Team:             NewTeamRepository(database),
+		User:             NewUserRepository(database),
+		PullRequest:      NewPullRequestRepositoryPgx(database),
+		ReviewAssignment: NewReviewAssignmentRepository(database),
+		TeamRole:         NewTeamRoleRepository(database),
+		TeamMembership:   NewTeamMembershipRepository(database),
+		MembershipAudit:  NewMembershipAuditRepository(database),
+		Idempotency:      NewIdempotencyRepository(database),
+		Tx:               database,
+	}
+}
@@ -0,0 +1,891 @@
+// Package repositorytest is the behaviour every repository backend has to share. Each backend
+// runs it from its own tests, so the in-memory implementation cannot drift away from Postgres.
+//
+// The suite only creates rows with fresh ids and never asserts on whole tables, so it can run
+// against a database that already holds data.
+package repositorytest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"pull-request-review/internal/domain/model"
+	"pull-request-review/internal/domain/ports/repository"
+	"pull-request-review/internal/domain/rules"
+)
+
+// Open returns the repositories of the backend under test.
+type Open func(t *testing.T) *repository.Repositories
+
+func Run(t *testing.T, open Open) {
+	t.Run("Team", func(t *testing.T) { testTeams(t, open(t)) })
+	t.Run("TeamHierarchy", func(t *testing.T) { testTeamHierarchy(t, open(t)) })
+	t.Run("User", func(t *testing.T) { testUsers(t, open(t)) })
+	t.Run("TeamMembership", func(t *testing.T) { testMemberships(t, open(t)) })
+	t.Run("TeamRole", func(t *testing.T) { testTeamRoles(t, open(t)) })
+	t.Run("PullRequest", func(t *testing.T) { testPullRequests(t, open(t)) })
+	t.Run("ReviewAssignment", func(t *testing.T) { testReviewAssignments(t, open(t)) })
+	t.Run("Idempotency", func(t *testing.T) { testIdempotency(t, open(t)) })
+	t.Run("Transaction", func(t *testing.T) { testTransactions(t, open(t)) })
+}
+
+// now is rounded to what Postgres keeps, so timestamps survive a round trip unchanged.
+func now() time.Time {
+	return time.Now().UTC().Truncate(time.Microsecond)
+}
+
+func newTeam(t *testing.T, repos *repository.Repositories, parentID model.TeamID) *model.Team {
+	t.Helper()
+
+	team := &model.Team{
+		TeamID:       model.TeamID(uuid.New()),
+		Name:         "team-" + uuid.NewString(),
+		ParentTeamID: uuid.UUID(parentID),
+		CreatedAt:    now(),
+	}
+	if err := repos.Team.Create(context.Background(), team); err != nil {
+		t.Fatalf("create team: %v", err)
+	}
+	return team
+}
+
+func newUser(t *testing.T, repos *repository.Repositories, team *model.Team, active bool) *model.User {
+	t.Helper()
+
+	user := &model.User{
+		ID:        model.UserID(uuid.New()),
+		Username:  "user-" + uuid.NewString()[:8],
+		IsActive:  active,
+		CreatedAt: now(),
+		UpdatedAt: now(),
+	}
+	if team != nil {
+		user.TeamID = uuid.UUID(team.TeamID)
+	}
+
+	ctx := context.Background()
+	if err := repos.User.Insert(ctx, user); err != nil {
+		t.Fatalf("insert user: %v", err)
+	}
+	if team != nil {
+		err := repos.TeamMembership.Add(
+			ctx, &model.TeamMembership{
+				TeamID:    team.TeamID,
+				UserID:    user.ID,
+				Weight:    model.DefaultMembershipWeight,
+				CreatedAt: now(),
+			},
+		)
+		if err != nil {
+			t.Fatalf("add membership: %v", err)
+		}
+	}
+	return user
+}
+
+func newPullRequest(t *testing.T, repos *repository.Repositories, author *model.User) *model.PullRequest {
+	t.Helper()
+
+	pr := &model.PullRequest{
+		PullRequestID: model.PullRequestID(uuid.New()),
+		Name:          "pr-" + uuid.NewString()[:8],
+		AuthorID:      author.ID,
+		Status:        model.PRStatusOpen,
+		CreatedAt:     now(),
+		Version:       model.InitialPullRequestVersion,
+	}
+	if err := repos.PullRequest.Create(context.Background(), pr); err != nil {
+		t.Fatalf("create pull request: %v", err)
+	}
+	return pr
+}
+
+func userIDs(users []model.User) map[model.UserID]bool {
+	IDs := make(map[model.UserID]bool, len(users))
+	for _, user := range users {
+		IDs[user.ID] = true
+	}
+	return IDs
+}
+
+func expectErr(t *testing.T, err error, target error) {
+	t.Helper()
+	if !errors.Is(err, target) {
+		t.Fatalf("expected %v, got %v", target, err)
+	}
+}
+
+func testTeams(t *testing.T, repos *repository.Repositories) {
+	ctx := context.Background()
+	team := newTeam(t, repos, model.TeamID(uuid.Nil))
+
+	got, err := repos.Team.GetByID(ctx, team.TeamID)
+	if err != nil {
+		t.Fatalf("get team: %v", err)
+	}
+	if got.Name != team.Name || !got.CreatedAt.Equal(team.CreatedAt) || got.ParentTeamID != uuid.Nil {
+		t.Fatalf("unexpected team %+v", got)
+	}
+
+	if err := repos.Team.Lock(ctx, team.TeamID); err != nil {
+		t.Fatalf("lock team: %v", err)
+	}
+	expectErr(t, repos.Team.Lock(ctx, model.TeamID(uuid.New())), rules.ErrTeamNotFound)
+	if err := repos.Team.LockName(ctx, "team-"+uuid.NewString()[:8]); err != nil {
+		t.Fatalf("lock team name: %v", err)
+	}
+
+	byName, err := repos.Team.GetByName(ctx, team.Name)
+	if err != nil || byName.TeamID != team.TeamID {
+		t.Fatalf("get team by name: %+v, %v", byName, err)
+	}
+
+	exists, err := repos.Team.ExistsByName(ctx, team.Name)
+	if err != nil || !exists {
+		t.Fatalf("team should exist by name: %v", err)
+	}
+	exists, err = repos.Team.Exists(ctx, model.TeamID(uuid.New()))
+	if err != nil || exists {
+		t.Fatalf("unknown team should not exist: %v", err)
+	}
+
+	_, err = repos.Team.GetByID(ctx, model.TeamID(uuid.New()))
+	expectErr(t, err, rules.ErrTeamNotFound)
+	_, err = repos.Team.GetByName(ctx, "missing-"+uuid.NewString())
+	expectErr(t, err, rules.ErrTeamNotFound)
+
+	maxReviewers := 3
+	team.Name = "renamed-" + uuid.NewString()
+	team.Settings.MaxReviewers = &maxReviewers
+	if err := repos.Team.Update(ctx, team); err != nil {
+		t.Fatalf("update team: %v", err)
+	}
+	got, err = repos.Team.GetByID(ctx, team.TeamID)
+	if err != nil {
+		t.Fatalf("get updated team: %v", err)
+	}
+	if got.Name != team.Name || got.Settings.MaxReviewers == nil || *got.Settings.MaxReviewers != 3 {
+		t.Fatalf("update not applied: %+v", got)
+	}
+
+	active := model.User{
+		ID: model.UserID(uuid.New()), Username: "a", TeamID: uuid.UUID(team.TeamID), IsActive: true,
+		CreatedAt: now(), UpdatedAt: now(),
+	}
+	inactive := active
+	inactive.ID = model.UserID(uuid.New())
+	inactive.IsActive = false
+	withMembers := &model.Team{TeamID: model.TeamID(uuid.New()), Name: "team-" + uuid.NewString(), CreatedAt: now()}
+	active.TeamID = uuid.UUID(withMembers.TeamID)
+	inactive.TeamID = uuid.UUID(withMembers.TeamID)
+	err = repos.Team.CreateWithMembers(ctx, withMembers, []model.User{active, inactive})
+	if err != nil {
+		t.Fatalf("create team with members: %v", err)
+	}
+
+	members, err := repos.Team.GetMembers(ctx, withMembers.TeamID)
+	if err != nil || len(members) != 2 {
+		t.Fatalf("expected 2 members, got %d: %v", len(members), err)
+	}
+	isMember, err := repos.TeamMembership.Exists(ctx, withMembers.TeamID, active.ID)
+	if err != nil || !isMember {
+		t.Fatalf("create with members should add memberships: %v", err)
+	}
+
+	if err := repos.Team.BulkDeactivateTeam(ctx, withMembers.TeamID); err != nil {
+		t.Fatalf("bulk deactivate: %v", err)
+	}
+	got2, err := repos.User.GetByID(ctx, active.ID)
+	if err != nil || got2.IsActive {
+		t.Fatalf("user should be deactivated: %+v, %v", got2, err)
+	}
+
+	err = repos.Team.CreateWithMembers(ctx, withMembers, nil)
+	if err == nil {
+		t.Fatal("creating a team twice should fail")
+	}
+}
+
+func testTeamHierarchy(t *testing.T, repos *repository.Repositories) {
+	ctx := context.Background()
+	root := newTeam(t, repos, model.TeamID(uuid.Nil))
+	child := newTeam(t, repos, root.TeamID)
+	grandchild := newTeam(t, repos, child.TeamID)
+
+	ancestors, err := repos.Team.GetAncestors(ctx, grandchild.TeamID)
+	if err != nil {
+		t.Fatalf("get ancestors: %v", err)
+	}
+	if len(ancestors) != 2 || ancestors[0].TeamID != child.TeamID || ancestors[1].TeamID != root.TeamID {
+		t.Fatalf("ancestors should be nearest first: %+v", ancestors)
+	}
+
+	descendants, err := repos.Team.GetDescendants(ctx, root.TeamID)
+	if err != nil {
+		t.Fatalf("get descendants: %v", err)
+	}
+	if len(descendants) != 2 || descendants[0].TeamID != child.TeamID || descendants[1].TeamID != grandchild.TeamID {
+		t.Fatalf("descendants should be level by level: %+v", descendants)
+	}
+
+	seen := make(map[model.TeamID]bool)
+	err = repos.Team.StreamAll(ctx, func(team *model.Team) error {
+		if team.ParentTeamID != uuid.Nil && (team.TeamID == child.TeamID || team.TeamID == grandchild.TeamID) &&
+			!seen[model.TeamID(team.ParentTeamID)] {
+			t.Errorf("team %s streamed before its parent", team.Name)
+		}
+		seen[team.TeamID] = true
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("stream teams: %v", err)
+	}
+	if !seen[grandchild.TeamID] {
+		t.Fatal("stream should include every team")
+	}
+
+	user := newUser(t, repos, grandchild, true)
+	author := newUser(t, repos, grandchild, true)
+	pr := newPullRequest(t, repos, author)
+	if err := repos.ReviewAssignment.AssignReviewer(ctx, pr.PullRequestID, user.ID); err != nil {
+		t.Fatalf("assign reviewer: %v", err)
+	}
+
+	counts, err := repos.ReviewAssignment.GetTeamAssignmentCounts(ctx)
+	if err != nil {
+		t.Fatalf("team assignment counts: %v", err)
+	}
+	for _, team := range []*model.Team{root, child, grandchild} {
+		if counts[team.Name] != 1 {
+			t.Fatalf("team %s should roll up 1 assignment, got %d", team.Name, counts[team.Name])
+		}
+	}
+
+	// the service refuses parent cycles, but the recursive queries must end even on one
+	root.ParentTeamID = uuid.UUID(grandchild.TeamID)
+	if err := repos.Team.Update(ctx, root); err != nil {
+		t.Fatalf("update team: %v", err)
+	}
+	t.Cleanup(func() {
+		root.ParentTeamID = uuid.Nil
+		if err := repos.Team.Update(ctx, root); err != nil {
+			t.Errorf("break the cycle: %v", err)
+		}
+	})
+	if _, err := repos.Team.GetAncestors(ctx, grandchild.TeamID); err != nil {
+		t.Fatalf("get ancestors in a cycle: %v", err)
+	}
+	if _, err := repos.Team.GetDescendants(ctx, root.TeamID); err != nil {
+		t.Fatalf("get descendants in a cycle: %v", err)
+	}
+	if _, err := repos.ReviewAssignment.GetTeamAssignmentCounts(ctx); err != nil {
+		t.Fatalf("team assignment counts in a cycle: %v", err)
+	}
+}
+
+func testUsers(t *testing.T, repos *repository.Repositories) {
+	ctx := context.Background()
+	team := newTeam(t, repos, model.TeamID(uuid.Nil))
+	user := newUser(t, repos, team, true)
+	other := newUser(t, repos, team, true)
+	inactive := newUser(t, repos, team, false)
+	loner := newUser(t, repos, nil, true)
+
+	got, err := repos.User.GetByID(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("get user: %v", err)
+	}
+	if got.Username != user.Username || got.TeamID != user.TeamID || !got.CreatedAt.Equal(user.CreatedAt) {
+		t.Fatalf("unexpected user %+v", got)
+	}
+
+	got, err = repos.User.GetByID(ctx, loner.ID)
+	if err != nil || got.TeamID != uuid.Nil {
+		t.Fatalf("user without a team should keep a nil team: %+v, %v", got, err)
+	}
+
+	_, err = repos.User.GetByID(ctx, model.UserID(uuid.New()))
+	expectErr(t, err, rules.ErrUserNotFound)
+	expectErr(t, repos.User.Update(ctx, &model.User{ID: model.UserID(uuid.New())}), rules.ErrUserNotFound)
+	expectErr(t, repos.User.UpdateActivity(ctx, model.UserID(uuid.New()), true), rules.ErrUserNotFound)
+
+	exists, err := repos.User.Exists(ctx, user.ID)
+	if err != nil || !exists {
+		t.Fatalf("user should exist: %v", err)
+	}
+
+	byIDs, err := repos.User.GetByIDs(ctx, []model.UserID{user.ID, loner.ID, model.UserID(uuid.New())})
+	if IDs := userIDs(byIDs); err != nil || len(IDs) != 2 || !IDs[user.ID] || !IDs[loner.ID] {
+		t.Fatalf("unexpected users by ids %+v: %v", byIDs, err)
+	}
+
+	duplicate := *user
+	duplicate.Username = "ignored"
+	if err := repos.User.Insert(ctx, &duplicate); err != nil {
+		t.Fatalf("inserting an existing user should be a no-op: %v", err)
+	}
+	got, _ = repos.User.GetByID(ctx, user.ID)
+	if got.Username != user.Username {
+		t.Fatal("insert must not overwrite an existing user")
+	}
+
+	upserted := *user
+	upserted.Username = "upserted"
+	upserted.UpdatedAt = now()
+	if err := repos.User.Upsert(ctx, &upserted); err != nil {
+		t.Fatalf("upsert user: %v", err)
+	}
+	got, _ = repos.User.GetByID(ctx, user.ID)
+	if got.Username != "upserted" {
+		t.Fatal("upsert should overwrite an existing user")
+	}
+
+	if err := repos.User.UpdateActivity(ctx, other.ID, false); err != nil {
+		t.Fatalf("update activity: %v", err)
+	}
+
+	members, err := repos.User.GetByTeam(ctx, team.TeamID)
+	if err != nil {
+		t.Fatalf("get by team: %v", err)
+	}
+	if IDs := userIDs(members); len(IDs) != 3 || !IDs[user.ID] || !IDs[other.ID] || !IDs[inactive.ID] {
+		t.Fatalf("unexpected team members %+v", members)
+	}
+
+	candidates, err := repos.User.GetActiveByTeamExcluding(ctx, team.TeamID, []model.UserID{})
+	if err != nil {
+		t.Fatalf("get active members: %v", err)
+	}
+	if IDs := userIDs(candidates); len(IDs) != 1 || !IDs[user.ID] {
+		t.Fatalf("only active members should be candidates: %+v", candidates)
+	}
+
+	candidates, err = repos.User.GetActiveByTeamExcluding(ctx, team.TeamID, []model.UserID{user.ID})
+	if err != nil || len(candidates) != 0 {
+		t.Fatalf("excluded members should not be candidates: %+v, %v", candidates, err)
+	}
+}
+
+func testMemberships(t *testing.T, repos *repository.Repositories) {
+	ctx := context.Background()
+	first := newTeam(t, repos, model.TeamID(uuid.Nil))
+	second := newTeam(t, repos, model.TeamID(uuid.Nil))
+	user := newUser(t, repos, first, true)
+
+	err := repos.TeamMembership.Add(
+		ctx, &model.TeamMembership{TeamID: second.TeamID, UserID: user.ID, Weight: 0.5, CreatedAt: now().Add(time.Second)},
+	)
+	if err != nil {
+		t.Fatalf("add membership: %v", err)
+	}
+
+	memberships, err := repos.TeamMembership.GetByUser(ctx, user.ID)
+	if err != nil || len(memberships) != 2 {
+		t.Fatalf("expected 2 memberships, got %+v: %v", memberships, err)
+	}
+	if memberships[0].TeamID != first.TeamID || memberships[0].TeamName != first.Name {
+		t.Fatalf("memberships should be oldest first with team names: %+v", memberships)
+	}
+	if memberships[1].Weight != 0.5 {
+		t.Fatalf("unexpected weight %v", memberships[1].Weight)
+	}
+
+	// candidates come from the memberships, not from the primary team
+	member := newUser(t, repos, second, true)
+	newUser(t, repos, second, false)
+	for _, tc := range []struct {
+		team     *model.Team
+		excluded []model.UserID
+		want     []model.UserID
+	}{
+		{second, nil, []model.UserID{user.ID, member.ID}},
+		{second, []model.UserID{user.ID}, []model.UserID{member.ID}},
+		{first, nil, []model.UserID{user.ID}},
+		{first, []model.UserID{member.ID}, []model.UserID{user.ID}},
+	} {
+		candidates, err := repos.User.GetActiveByTeamExcluding(ctx, tc.team.TeamID, tc.excluded)
+		if err != nil {
+			t.Fatalf("get active members: %v", err)
+		}
+		IDs := userIDs(candidates)
+		if len(IDs) != len(tc.want) {
+			t.Fatalf("candidates %+v, want %v", candidates, tc.want)
+		}
+		for _, ID := range tc.want {
+			if !IDs[ID] {
+				t.Fatalf("candidates %+v, want %v", candidates, tc.want)
+			}
+		}
+	}
+
+	err = repos.TeamMembership.Add(
+		ctx, &model.TeamMembership{TeamID: second.TeamID, UserID: user.ID, Weight: 2, CreatedAt: now()},
+	)
+	if err != nil {
+		t.Fatalf("re-adding a membership should update its weight: %v", err)
+	}
+	memberships, _ = repos.TeamMembership.GetByTeam(ctx, second.TeamID)
+	updated := slices.IndexFunc(memberships, func(m model.TeamMembership) bool { return m.UserID == user.ID })
+	if len(memberships) != 3 || updated < 0 || memberships[updated].Weight != 2 {
+		t.Fatalf("weight not updated: %+v", memberships)
+	}
+
+	if err := repos.TeamMembership.Remove(ctx, second.TeamID, user.ID); err != nil {
+		t.Fatalf("remove membership: %v", err)
+	}
+	exists, err := repos.TeamMembership.Exists(ctx, second.TeamID, user.ID)
+	if err != nil || exists {
+		t.Fatalf("membership should be gone: %v", err)
+	}
+	expectErr(t, repos.TeamMembership.Remove(ctx, second.TeamID, user.ID), rules.ErrNotFound)
+}
+
+func testTeamRoles(t *testing.T, repos *repository.Repositories) {
+	ctx := context.Background()
+	team := newTeam(t, repos, model.TeamID(uuid.Nil))
+	user := newUser(t, repos, team, true)
+
+	_, err := repos.TeamRole.GetRole(ctx, team.TeamID, user.ID)
+	expectErr(t, err, rules.ErrNotFound)
+
+	if err := repos.TeamRole.SetRole(ctx, team.TeamID, user.ID, model.TeamRoleMaintainer); err != nil {
+		t.Fatalf("set role: %v", err)
+	}
+	if err := repos.TeamRole.SetRole(ctx, team.TeamID, user.ID, model.TeamRoleAdmin); err != nil {
+		t.Fatalf("overwrite role: %v", err)
+	}
+
+	role, err := repos.TeamRole.GetRole(ctx, team.TeamID, user.ID)
+	if err != nil || role != model.TeamRoleAdmin {
+		t.Fatalf("expected admin, got %q: %v", role, err)
+	}
+
+	if err := repos.TeamRole.DeleteRole(ctx, team.TeamID, user.ID); err != nil {
+		t.Fatalf("delete role: %v", err)
+	}
+	_, err = repos.TeamRole.GetRole(ctx, team.TeamID, user.ID)
+	expectErr(t, err, rules.ErrNotFound)
+}
+
+func testPullRequests(t *testing.T, repos *repository.Repositories) {
+	ctx := context.Background()
+	team := newTeam(t, repos, model.TeamID(uuid.Nil))
+	author := newUser(t, repos, team, true)
+	reviewer := newUser(t, repos, team, true)
+	pr := newPullRequest(t, repos, author)
+
+	got, err := repos.PullRequest.GetByID(ctx, pr.PullRequestID)
+	if err != nil {
+		t.Fatalf("get pull request: %v", err)
+	}
+	if got.Name != pr.Name || got.AuthorID != author.ID || got.Status != model.PRStatusOpen ||
+		got.Version != model.InitialPullRequestVersion {
+		t.Fatalf("unexpected pull request %+v", got)
+	}
+
+	if err := repos.PullRequest.BumpVersion(ctx, pr.PullRequestID, got.Version); err != nil {
+		t.Fatalf("bump version: %v", err)
+	}
+	// the version read before the bump is stale now
+	err = repos.PullRequest.BumpVersion(ctx, pr.PullRequestID, got.Version)
+	expectErr(t, err, rules.ErrConcurrentModification)
+	got, _ = repos.PullRequest.GetByID(ctx, pr.PullRequestID)
+	if got.Version != model.InitialPullRequestVersion+1 {
+		t.Fatalf("version not bumped: %d", got.Version)
+	}
+
+	_, err = repos.PullRequest.GetByID(ctx, model.PullRequestID(uuid.New()))
+	expectErr(t, err, rules.ErrNotFound)
+
+	exists, err := repos.PullRequest.Exists(ctx, pr.PullRequestID)
+	if err != nil || !exists {
+		t.Fatalf("pull request should exist: %v", err)
+	}
+
+	if err := repos.ReviewAssignment.AssignReviewer(ctx, pr.PullRequestID, reviewer.ID); err != nil {
+		t.Fatalf("assign reviewer: %v", err)
+	}
+	reviews, err := repos.PullRequest.GetByReviewer(ctx, reviewer.ID, model.ReviewFilter{})
+	if err != nil || len(reviews) != 1 || reviews[0].PullRequestID != pr.PullRequestID {
+		t.Fatalf("unexpected reviews %+v: %v", reviews, err)
+	}
+	if len(reviews[0].Labels) != 0 {
+		t.Fatalf("pull request without labels got %v", reviews[0].Labels)
+	}
+
+	routed := &model.PullRequest{
+		PullRequestID: model.PullRequestID(uuid.New()), Name: "routed", AuthorID: author.ID,
+		Status: model.PRStatusDraft, CreatedAt: now(), Version: model.InitialPullRequestVersion,
+		ChangedPaths: []string{"web/app.ts", "docs/readme.md"},
+	}
+	if err := repos.PullRequest.Create(ctx, routed); err != nil {
+		t.Fatalf("create pull request: %v", err)
+	}
+	got, _ = repos.PullRequest.GetByID(ctx, routed.PullRequestID)
+	if !slices.Equal(got.ChangedPaths, routed.ChangedPaths) {
+		t.Fatalf("changed paths not stored: %v", got.ChangedPaths)
+	}
+
+	metadata := model.PullRequestMetadata{
+		Repository:   "backend",
+		SourceBranch: "feature/search",
+		TargetBranch: "main",
+		URL:          "https://git.example.com/backend/pull/1",
+		Description:  "Adds search",
+		Labels:       []string{"api", "db"},
+	}
+	if err := repos.PullRequest.UpdateMetadata(ctx, pr.PullRequestID, metadata); err != nil {
+		t.Fatalf("update metadata: %v", err)
+	}
+	got, _ = repos.PullRequest.GetByID(ctx, pr.PullRequestID)
+	if !reflect.DeepEqual(got.PullRequestMetadata, metadata) {
+		t.Fatalf("metadata not stored: %+v", got.PullRequestMetadata)
+	}
+	err = repos.PullRequest.UpdateMetadata(ctx, model.PullRequestID(uuid.New()), metadata)
+	expectErr(t, err, rules.ErrPullRequestNotFound)
+
+	for _, tc := range []struct {
+		filter model.ReviewFilter
+		want   int
+	}{
+		{model.ReviewFilter{Label: "db"}, 1},
+		{model.ReviewFilter{Label: "ui"}, 0},
+		{model.ReviewFilter{Repository: "backend", Label: "api"}, 1},
+		{model.ReviewFilter{Repository: "frontend"}, 0},
+	} {
+		reviews, err := repos.PullRequest.GetByReviewer(ctx, reviewer.ID, tc.filter)
+		if err != nil || len(reviews) != tc.want {
+			t.Fatalf("filter %+v: got %d reviews, want %d: %v", tc.filter, len(reviews), tc.want, err)
+		}
+	}
+
+	mergedAt := now()
+	if err := repos.PullRequest.UpdateStatus(ctx, pr.PullRequestID, model.PRStatusMerged, mergedAt); err != nil {
+		t.Fatalf("update status: %v", err)
+	}
+	got, _ = repos.PullRequest.GetByID(ctx, pr.PullRequestID)
+	if got.Status != model.PRStatusMerged || !got.MergedAt.Equal(mergedAt) {
+		t.Fatalf("status not updated: %+v", got)
+	}
+
+	err = repos.PullRequest.UpdateStatus(ctx, model.PullRequestID(uuid.New()), model.PRStatusMerged, mergedAt)
+	expectErr(t, err, rules.ErrPullRequestNotFound)
+
+	counts, err := repos.PullRequest.GetPullRequestCountsByStatus(ctx)
+	if err != nil || counts[string(model.PRStatusMerged)] < 1 {
+		t.Fatalf("merged pull request should be counted: %v, %v", counts, err)
+	}
+
+	batch := make([]model.PullRequest, 3)
+	batchIDs := make([]model.PullRequestID, len(batch))
+	for i := range batch {
+		batchIDs[i] = model.PullRequestID(uuid.New())
+		batch[i] = model.PullRequest{
+			PullRequestID: batchIDs[i], Name: "batch", AuthorID: author.ID, Status: model.PRStatusOpen,
+			CreatedAt: now(), Version: model.InitialPullRequestVersion,
+		}
+	}
+	if err := repos.PullRequest.CreateBatch(ctx, append(batch[1:], *pr)); err == nil {
+		t.Fatal("a batch with an existing pull request should fail")
+	}
+	existing, err := repos.PullRequest.GetExistingIDs(ctx, append(batchIDs, pr.PullRequestID))
+	if err != nil || len(existing) != 1 || existing[0] != pr.PullRequestID {
+		t.Fatalf("a failed batch should create nothing: %v, %v", existing, err)
+	}
+
+	if err := repos.PullRequest.CreateBatch(ctx, batch); err != nil {
+		t.Fatalf("create batch: %v", err)
+	}
+	existing, err = repos.PullRequest.GetExistingIDs(ctx, batchIDs)
+	if err != nil || len(existing) != len(batch) {
+		t.Fatalf("batch pull requests should exist: %v, %v", existing, err)
+	}
+	got, err = repos.PullRequest.GetByID(ctx, batchIDs[0])
+	if err != nil || got.Name != "batch" || got.Version != model.InitialPullRequestVersion ||
+		!got.CreatedAt.Equal(batch[0].CreatedAt) {
+		t.Fatalf("unexpected batch pull request %+v: %v", got, err)
+	}
+}
+
+func testReviewAssignments(t *testing.T, repos *repository.Repositories) {
+	ctx := context.Background()
+	team := newTeam(t, repos, model.TeamID(uuid.Nil))
+	author := newUser(t, repos, team, true)
+	first := newUser(t, repos, team, true)
+	second := newUser(t, repos, team, true)
+	third := newUser(t, repos, team, true)
+	pr := newPullRequest(t, repos, author)
+
+	err := repos.ReviewAssignment.AssignReviewers(ctx, pr.PullRequestID, []model.UserID{first.ID, second.ID})
+	if err != nil {
+		t.Fatalf("assign reviewers: %v", err)
+	}
+	if err := repos.ReviewAssignment.AssignReviewer(ctx, pr.PullRequestID, first.ID); err == nil {
+		t.Fatal("assigning the same reviewer twice should fail")
+	}
+
+	reviewers, err := repos.ReviewAssignment.GetReviewers(ctx, pr.PullRequestID)
+	if IDs := userIDs(reviewers); err != nil || len(IDs) != 2 || !IDs[first.ID] || !IDs[second.ID] {
+		t.Fatalf("unexpected reviewers %+v: %v", reviewers, err)
+	}
+
+	if err := repos.ReviewAssignment.ReplaceReviewer(ctx, pr.PullRequestID, first.ID, third.ID); err != nil {
+		t.Fatalf("replace reviewer: %v", err)
+	}
+	exists, _ := repos.ReviewAssignment.Exists(ctx, pr.PullRequestID, first.ID)
+	if exists {
+		t.Fatal("replaced reviewer should be unassigned")
+	}
+	exists, _ = repos.ReviewAssignment.Exists(ctx, pr.PullRequestID, third.ID)
+	if !exists {
+		t.Fatal("new reviewer should be assigned")
+	}
+
+	err = repos.ReviewAssignment.ReplaceReviewer(ctx, pr.PullRequestID, first.ID, third.ID)
+	expectErr(t, err, rules.ErrNotAssigned)
+
+	counts, err := repos.ReviewAssignment.GetAssignmentCounts(ctx)
+	if err != nil || counts[uuid.UUID(third.ID).String()] != 1 || counts[uuid.UUID(first.ID).String()] != 0 {
+		t.Fatalf("unexpected assignment counts: %v", err)
+	}
+
+	if err := repos.ReviewAssignment.RemoveReviewer(ctx, pr.PullRequestID, second.ID); err != nil {
+		t.Fatalf("remove reviewer: %v", err)
+	}
+	expectErr(t, repos.ReviewAssignment.RemoveReviewer(ctx, pr.PullRequestID, second.ID), rules.ErrNotAssigned)
+
+	assignedAt := now().Add(-time.Hour)
+	err = repos.ReviewAssignment.Insert(
+		ctx, &model.ReviewAssignment{
+			PullRequestID: pr.PullRequestID, ReviewerID: second.ID, AssignedAt: assignedAt, Rule: "security",
+		},
+	)
+	if err != nil {
+		t.Fatalf("insert assignment: %v", err)
+	}
+
+	var found bool
+	err = repos.ReviewAssignment.StreamAll(ctx, func(assignment *model.ReviewAssignment) error {
+		if assignment.PullRequestID == pr.PullRequestID && assignment.ReviewerID == second.ID {
+			found = assignment.AssignedAt.Equal(assignedAt) && assignment.Rule == "security"
+		}
+		return nil
+	})
+	if err != nil || !found {
+		t.Fatalf("inserted assignment should keep its time and rule: %v", err)
+	}
+
+	open, err := repos.ReviewAssignment.GetOpenReviewCounts(ctx, []model.UserID{first.ID, second.ID, third.ID})
+	if err != nil || open[second.ID] != 1 || open[third.ID] != 1 || open[first.ID] != 0 {
+		t.Fatalf("unexpected open review counts %v: %v", open, err)
+	}
+
+	batch := []model.ReviewAssignment{
+		{PullRequestID: pr.PullRequestID, ReviewerID: first.ID, AssignedAt: assignedAt, Rule: "frontend"},
+		{PullRequestID: pr.PullRequestID, ReviewerID: third.ID, AssignedAt: assignedAt},
+	}
+	if err := repos.ReviewAssignment.InsertBatch(ctx, batch); err == nil {
+		t.Fatal("a batch with an existing assignment should fail")
+	}
+	if exists, _ := repos.ReviewAssignment.Exists(ctx, pr.PullRequestID, first.ID); exists {
+		t.Fatal("a failed batch should insert nothing")
+	}
+	if err := repos.ReviewAssignment.InsertBatch(ctx, batch[:1]); err != nil {
+		t.Fatalf("insert batch: %v", err)
+	}
+	if exists, _ := repos.ReviewAssignment.Exists(ctx, pr.PullRequestID, first.ID); !exists {
+		t.Fatal("batch assignment should be inserted")
+	}
+
+	assignments, err := repos.ReviewAssignment.GetAssignments(ctx, pr.PullRequestID)
+	if err != nil || len(assignments) != 3 {
+		t.Fatalf("unexpected assignments %+v: %v", assignments, err)
+	}
+	assignedRules := make(map[model.UserID]string, len(assignments))
+	for _, assignment := range assignments {
+		assignedRules[assignment.ReviewerID] = assignment.Rule
+	}
+	if assignedRules[first.ID] != "frontend" || assignedRules[second.ID] != "security" || assignedRules[third.ID] != "" {
+		t.Fatalf("assignments should keep the rule that picked the reviewer: %v", assignedRules)
+	}
+
+	if err := repos.PullRequest.UpdateStatus(ctx, pr.PullRequestID, model.PRStatusMerged, now()); err != nil {
+		t.Fatalf("update status: %v", err)
+	}
+	open, err = repos.ReviewAssignment.GetOpenReviewCounts(ctx, []model.UserID{first.ID, second.ID, third.ID})
+	if err != nil || len(open) != 0 {
+		t.Fatalf("merged pull requests should not count as open reviews: %v, %v", open, err)
+	}
+
+	// merged pull requests still count as reviewed, pull requests of other authors do not
+	older := newPullRequest(t, repos, author)
+	err = repos.ReviewAssignment.Insert(
+		ctx, &model.ReviewAssignment{PullRequestID: older.PullRequestID, ReviewerID: third.ID, AssignedAt: assignedAt},
+	)
+	if err != nil {
+		t.Fatalf("insert assignment: %v", err)
+	}
+	other := newPullRequest(t, repos, first)
+	if err := repos.ReviewAssignment.AssignReviewer(ctx, other.PullRequestID, second.ID); err != nil {
+		t.Fatalf("assign reviewer: %v", err)
+	}
+	history, err := repos.ReviewAssignment.GetReviewHistory(
+		ctx, author.ID, []model.UserID{first.ID, second.ID, third.ID, author.ID},
+	)
+	if err != nil || len(history) != 3 {
+		t.Fatalf("unexpected review history %v: %v", history, err)
+	}
+	if history[first.ID].Reviews != 1 || !history[first.ID].LastReviewedAt.Equal(assignedAt) ||
+		history[second.ID].Reviews != 1 || history[third.ID].Reviews != 2 ||
+		!history[third.ID].LastReviewedAt.After(assignedAt) {
+		t.Fatalf("unexpected review history %+v", history)
+	}
+
+	for _, reason := range []string{"not my area", "still not my area"} {
+		decline := &model.ReviewDecline{
+			PullRequestID: pr.PullRequestID, ReviewerID: first.ID, Reason: reason, DeclinedAt: now(),
+		}
+		if err := repos.ReviewAssignment.RecordDecline(ctx, decline); err != nil {
+			t.Fatalf("record decline: %v", err)
+		}
+	}
+	decliners, err := repos.ReviewAssignment.GetDecliners(ctx, pr.PullRequestID)
+	if err != nil || len(decliners) != 1 || decliners[0] != first.ID {
+		t.Fatalf("unexpected decliners %v: %v", decliners, err)
+	}
+	declines, err := repos.ReviewAssignment.GetDeclineCounts(ctx)
+	if err != nil || declines[uuid.UUID(first.ID).String()] != 2 || len(declines) != 1 {
+		t.Fatalf("unexpected decline counts %v: %v", declines, err)
+	}
+	var reasons []string
+	err = repos.ReviewAssignment.StreamDeclines(ctx, func(decline *model.ReviewDecline) error {
+		if decline.PullRequestID != pr.PullRequestID || decline.ReviewerID != first.ID {
+			return fmt.Errorf("unexpected decline %+v", decline)
+		}
+		reasons = append(reasons, decline.Reason)
+		return nil
+	})
+	slices.Sort(reasons)
+	if err != nil || !slices.Equal(reasons, []string{"not my area", "still not my area"}) {
+		t.Fatalf("unexpected streamed declines %v: %v", reasons, err)
+	}
+}
+
+func testIdempotency(t *testing.T, repos *repository.Repositories) {
+	ctx := context.Background()
+	record := &model.IdempotencyRecord{
+		Key:         uuid.NewString(),
+		Route:       "/pullRequest/create",
+		RequestHash: "hash",
+		CreatedAt:   now(),
+	}
+
+	reserved, err := repos.Idempotency.Reserve(ctx, record)
+	if err != nil || !reserved {
+		t.Fatalf("first reserve should win: %v", err)
+	}
+	reserved, err = repos.Idempotency.Reserve(ctx, record)
+	if err != nil || reserved {
+		t.Fatalf("second reserve should lose: %v", err)
+	}
+
+	got, err := repos.Idempotency.Get(ctx, record.Key, record.Route)
+	if err != nil || got.IsCompleted() || got.RequestHash != "hash" {
+		t.Fatalf("unexpected pending record %+v: %v", got, err)
+	}
+
+	if err := repos.Idempotency.Complete(ctx, record.Key, record.Route, 201, []byte(`{"ok":true}`)); err != nil {
+		t.Fatalf("complete: %v", err)
+	}
+	got, err = repos.Idempotency.Get(ctx, record.Key, record.Route)
+	if err != nil || !got.IsCompleted() || got.StatusCode != 201 || string(got.ResponseBody) != `{"ok":true}` {
+		t.Fatalf("unexpected completed record %+v: %v", got, err)
+	}
+
+	expectErr(t, repos.Idempotency.Complete(ctx, "missing", record.Route, 200, nil), rules.ErrNotFound)
+
+	if err := repos.Idempotency.Delete(ctx, record.Key, record.Route); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	_, err = repos.Idempotency.Get(ctx, record.Key, record.Route)
+	expectErr(t, err, rules.ErrNotFound)
+
+	old, fresh := *record, *record
+	old.Key, old.CreatedAt = uuid.NewString(), now().Add(-2*time.Hour)
+	fresh.Key = uuid.NewString()
+	for _, record := range []*model.IdempotencyRecord{&old, &fresh} {
+		if _, err := repos.Idempotency.Reserve(ctx, record); err != nil {
+			t.Fatalf("reserve: %v", err)
+		}
+	}
+	deleted, err := repos.Idempotency.DeleteCreatedBefore(ctx, now().Add(-time.Hour))
+	if err != nil || deleted < 1 {
+		t.Fatalf("delete created before: %d, %v", deleted, err)
+	}
+	_, err = repos.Idempotency.Get(ctx, old.Key, old.Route)
+	expectErr(t, err, rules.ErrNotFound)
+	if _, err := repos.Idempotency.Get(ctx, fresh.Key, fresh.Route); err != nil {
+		t.Fatalf("a fresh key should be kept: %v", err)
+	}
+}
+
+func testTransactions(t *testing.T, repos *repository.Repositories) {
+	ctx := context.Background()
+	team := newTeam(t, repos, model.TeamID(uuid.Nil))
+	author := newUser(t, repos, team, true)
+	reviewer := newUser(t, repos, team, true)
+
+	committed := model.PullRequestID(uuid.New())
+	err := repos.Tx.WithinTx(ctx, func(ctx context.Context) error {
+		err := repos.PullRequest.Create(
+			ctx, &model.PullRequest{
+				PullRequestID: committed, Name: "committed", AuthorID: author.ID, Status: model.PRStatusOpen,
+				CreatedAt: now(), Version: model.InitialPullRequestVersion,
+			},
+		)
+		if err != nil {
+			return err
+		}
+
+		// A nested call joins the outer transaction and sees its writes.
+		return repos.Tx.WithinTx(ctx, func(ctx context.Context) error {
+			return repos.ReviewAssignment.AssignReviewer(ctx, committed, reviewer.ID)
+		})
+	})
+	if err != nil {
+		t.Fatalf("commit transaction: %v", err)
+	}
+	if exists, _ := repos.ReviewAssignment.Exists(ctx, committed, reviewer.ID); !exists {
+		t.Fatal("committed assignment should be visible")
+	}
+
+	rolledBack := model.PullRequestID(uuid.New())
+	failure := errors.New("assignment failed")
+	err = repos.Tx.WithinTx(ctx, func(ctx context.Context) error {
+		err := repos.PullRequest.Create(
+			ctx, &model.PullRequest{
+				PullRequestID: rolledBack, Name: "rolled back", AuthorID: author.ID, Status: model.PRStatusOpen,
+				CreatedAt: now(), Version: model.InitialPullRequestVersion,
+			},
+		)
+		if err != nil {
+			return err
+		}
+		if exists, _ := repos.PullRequest.Exists(ctx, rolledBack); !exists {
+			t.Error("the transaction should see its own writes")
+		}
+		return failure
+	})
+	expectErr(t, err, failure)
+
+	if exists, _ := repos.PullRequest.Exists(ctx, rolledBack); exists {
+		t.Fatal("rolled back pull request should not exist")
+	}
+}
@@ -2,8 +2,8 @@ package repository
 
 import (
 	"context"
-	"fmt"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"pull-request-review/internal/domain/model"
 	"pull-request-review/internal/domain/ports/repository"
 	"pull-request-review/internal/domain/rules"
@@ -32,34 +32,41 @@ func (r *ReviewAssignmentRepository) AssignReviewers(
 		return nil
 	}
 
-	tx, err := r.database.GetPool().Begin(ctx)
-	if err != nil {
-		return err
-	}
-	defer func() {
-		var e error
-		if err == nil {
-			e = tx.Commit(ctx)
-		} else {
-			e = tx.Rollback(ctx)
-		}
+	return r.database.WithinTx(ctx, func(ctx context.Context) error {
+		tx := r.database.Querier(ctx)
 
-		if err == nil && e != nil {
-			err = fmt.Errorf("finishing transaction: %w", e)
-		}
-	}()
-
-	query := `
+		query := `
 INSERT INTO review_assignments (pull_request_id, user_id, assigned_at)
 VALUES ($1, $2, $3)
 	`
 
-	now := time.Now()
-	for _, userID := range reviewerIDs {
-		_, err := tx.Exec(ctx, query, pullRequestID, userID, now)
-		if err != nil {
-			return err
+		now := time.Now()
+		for _, userID := range reviewerIDs {
+			_, err := tx.Exec(ctx, query, pullRequestID, userID, now)
+			if err != nil {
+				return err
+			}
 		}
+
+		return nil
+	})
+}
+
+func (r *ReviewAssignmentRepository) RemoveReviewer(
+	ctx context.Context, pullRequestID model.PullRequestID, reviewerID model.UserID,
+) error {
+	query := `
+DELETE FROM review_assignments
+WHERE pull_request_id = $1 AND user_id = $2
+	`
+
+	result, err := r.database.Querier(ctx).Exec(ctx, query, pullRequestID, reviewerID)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return rules.ErrNotAssigned
 	}
 
 	return nil
@@ -72,7 +79,7 @@ func (r *ReviewAssignmentRepository) GetByReviewer(
 SELECT pull_request_id, user_id, assigned_at FROM review_assignments
 WHERE user_id = $1`
 
-	rows, err := r.database.GetPool().Query(ctx, query, pullRequestID)
+	rows, err := r.database.Querier(ctx).Query(ctx, query, pullRequestID)
 	if err != nil {
 		return nil, err
 	}
@@ -102,7 +109,7 @@ func (r *ReviewAssignmentRepository) Exists(
 SELECT EXISTS (SELECT 1 FROM review_assignments WHERE pull_request_id = $1 AND user_id = $2)
 `
 	var exists bool
-	err := r.database.GetPool().QueryRow(ctx, query, pullRequestID, reviewerID).Scan(&exists)
+	err := r.database.Querier(ctx).QueryRow(ctx, query, pullRequestID, reviewerID).Scan(&exists)
 	if err != nil {
 		return false, err
 	}
@@ -117,7 +124,7 @@ SELECT u.user_id, u.username, u.team_id, u.is_active, u.created_at, u.updated_at
 FROM users u 
 INNER JOIN review_assignments ra ON u.user_id = ra.user_id 
 WHERE ra.pull_request_id = $1`
-	rows, err := r.database.GetPool().Query(ctx, query, pullRequestID)
+	rows, err := r.database.Querier(ctx).Query(ctx, query, pullRequestID)
 	if err != nil {
 		return nil, err
 	}
@@ -138,60 +145,372 @@ WHERE ra.pull_request_id = $1`
 	return users, nil
 }
 
+// GetAssignments lists the assignments of the pull request, telling which rule picked each reviewer.
+func (r *ReviewAssignmentRepository) GetAssignments(
+	ctx context.Context, pullRequestID model.PullRequestID,
+) ([]model.ReviewAssignment, error) {
+	query := `
+SELECT pull_request_id, user_id, assigned_at, rule
+FROM review_assignments
+WHERE pull_request_id = $1
+ORDER BY assigned_at, user_id
+`
+
+	rows, err := r.database.Querier(ctx).Query(ctx, query, pullRequestID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var assignments []model.ReviewAssignment
+	for rows.Next() {
+		var assignment model.ReviewAssignment
+		err := rows.Scan(&assignment.PullRequestID, &assignment.ReviewerID, &assignment.AssignedAt, &assignment.Rule)
+		if err != nil {
+			return nil, err
+		}
+		assignments = append(assignments, assignment)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return assignments, nil
+}
+
 func (r *ReviewAssignmentRepository) ReplaceReviewer(
 	ctx context.Context, pullRequestID model.PullRequestID, oldReviewerID model.UserID, newReviewerID model.UserID,
 ) error {
-	tx, err := r.database.GetPool().Begin(ctx)
+	return r.database.WithinTx(ctx, func(ctx context.Context) error {
+		tx := r.database.Querier(ctx)
+		deleteQuery := `
+DELETE FROM review_assignments 
+WHERE pull_request_id = $1 AND user_id = $2
+	`
+
+		result, err := tx.Exec(ctx, deleteQuery, pullRequestID, oldReviewerID)
+		if err != nil {
+			return err
+		}
+
+		if result.RowsAffected() == 0 {
+			return rules.ErrNotAssigned
+		}
+
+		insertQuery := `
+INSERT INTO review_assignments (pull_request_id, user_id, assigned_at)
+VALUES ($1, $2, $3)
+	`
+
+		_, err = tx.Exec(ctx, insertQuery, pullRequestID, newReviewerID, time.Now())
+		if err != nil {
+			return err
+		}
+
+		return nil
+	})
+}
+
+func (r *ReviewAssignmentRepository) GetAssignmentCounts(ctx context.Context) (map[string]int, error) {
+	query := `
+SELECT user_id, COUNT(*) as count
+FROM review_assignments
+GROUP BY user_id
+	`
+
+	rows, err := r.database.Querier(ctx).Query(ctx, query)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	defer func() {
-		var e error
-		if err == nil {
-			e = tx.Commit(ctx)
-		} else {
-			e = tx.Rollback(ctx)
+	defer rows.Close()
+
+	counts := make(map[string]int)
+	for rows.Next() {
+		var userID model.UserID
+		var count int
+		err := rows.Scan(&userID, &count)
+		if err != nil {
+			return nil, err
 		}
+		counts[uuid.UUID(userID).String()] = count
+	}
 
-		if err == nil && e != nil {
-			err = fmt.Errorf("finishing transaction: %w", e)
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return counts, nil
+}
+
+// GetTeamAssignmentCounts counts the assignments of each team's members, rolled up
+// so that a team also includes everything below it. Keys are team names. The depth bound
+// keeps a parent cycle from recursing forever.
+func (r *ReviewAssignmentRepository) GetTeamAssignmentCounts(ctx context.Context) (map[string]int, error) {
+	query := `
+WITH RECURSIVE subtree AS (
+    SELECT team_id AS root_id, team_id, 0 AS depth
+    FROM teams
+    UNION ALL
+    SELECT s.root_id, t.team_id, s.depth + 1
+    FROM teams t
+    INNER JOIN subtree s ON t.parent_team_id = s.team_id
+    WHERE s.depth < (SELECT COUNT(*) FROM teams)
+)
+SELECT t.name, COUNT(DISTINCT (ra.pull_request_id, ra.user_id)) AS count
+FROM subtree s
+INNER JOIN teams t ON t.team_id = s.root_id
+INNER JOIN team_memberships m ON m.team_id = s.team_id
+INNER JOIN review_assignments ra ON ra.user_id = m.user_id
+GROUP BY t.name
+`
+
+	rows, err := r.database.Querier(ctx).Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int)
+	for rows.Next() {
+		var teamName string
+		var count int
+		err := rows.Scan(&teamName, &count)
+		if err != nil {
+			return nil, err
 		}
-	}()
-	deleteQuery := `
-DELETE FROM review_assignments 
-WHERE pull_request_id = $1 AND user_id = $2
-	`
+		counts[teamName] = count
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
 
-	result, err := tx.Exec(ctx, deleteQuery, pullRequestID, oldReviewerID)
+	return counts, nil
+}
+
+// GetOpenReviewCounts counts how many open pull requests each of the reviewers has to review.
+// Reviewers without any are left out.
+func (r *ReviewAssignmentRepository) GetOpenReviewCounts(
+	ctx context.Context, reviewerIDs []model.UserID,
+) (map[model.UserID]int, error) {
+	query := `
+SELECT ra.user_id, COUNT(*) AS count
+FROM review_assignments ra
+INNER JOIN pull_requests p ON p.pull_request_id = ra.pull_request_id
+WHERE ra.user_id = ANY($1) AND p.status = $2
+GROUP BY ra.user_id
+`
+
+	rows, err := r.database.Querier(ctx).Query(ctx, query, reviewerIDs, model.PRStatusOpen)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[model.UserID]int)
+	for rows.Next() {
+		var userID model.UserID
+		var count int
+		err := rows.Scan(&userID, &count)
+		if err != nil {
+			return nil, err
+		}
+		counts[userID] = count
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return counts, nil
+}
+
+// GetReviewHistory counts the assignments of each reviewer to pull requests of the author and
+// tells when the last one was made. Reviewers who never reviewed the author are left out.
+func (r *ReviewAssignmentRepository) GetReviewHistory(
+	ctx context.Context, authorID model.UserID, reviewerIDs []model.UserID,
+) (map[model.UserID]model.ReviewHistory, error) {
+	query := `
+SELECT ra.user_id, COUNT(*) AS count, MAX(ra.assigned_at) AS last_assigned_at
+FROM review_assignments ra
+INNER JOIN pull_requests p ON p.pull_request_id = ra.pull_request_id
+WHERE p.author_id = $1 AND ra.user_id = ANY($2)
+GROUP BY ra.user_id
+`
+
+	rows, err := r.database.Querier(ctx).Query(ctx, query, authorID, reviewerIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	history := make(map[model.UserID]model.ReviewHistory)
+	for rows.Next() {
+		var userID model.UserID
+		var reviews model.ReviewHistory
+		err := rows.Scan(&userID, &reviews.Reviews, &reviews.LastReviewedAt)
+		if err != nil {
+			return nil, err
+		}
+		history[userID] = reviews
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return history, nil
+}
+
+// Insert stores an assignment as is, keeping its original assignment time.
+func (r *ReviewAssignmentRepository) Insert(ctx context.Context, assignment *model.ReviewAssignment) error {
+	query := `
+INSERT INTO review_assignments (pull_request_id, user_id, assigned_at, rule)
+VALUES ($1, $2, $3, $4)
+`
+
+	_, err := r.database.Querier(ctx).Exec(
+		ctx, query, assignment.PullRequestID, assignment.ReviewerID, assignment.AssignedAt, assignment.Rule,
+	)
+	return err
+}
+
+func (r *ReviewAssignmentRepository) StreamAll(
+	ctx context.Context, fn func(assignment *model.ReviewAssignment) error,
+) error {
+	query := `
+SELECT pull_request_id, user_id, assigned_at, rule
+FROM review_assignments
+ORDER BY assigned_at, pull_request_id, user_id
+`
+
+	rows, err := r.database.Querier(ctx).Query(ctx, query)
 	if err != nil {
 		return err
 	}
+	defer rows.Close()
 
-	if result.RowsAffected() == 0 {
-		return rules.ErrNotAssigned
+	for rows.Next() {
+		var assignment model.ReviewAssignment
+		err := rows.Scan(&assignment.PullRequestID, &assignment.ReviewerID, &assignment.AssignedAt, &assignment.Rule)
+		if err != nil {
+			return err
+		}
+		if err := fn(&assignment); err != nil {
+			return err
+		}
 	}
 
-	insertQuery := `
-INSERT INTO review_assignments (pull_request_id, user_id, assigned_at)
-VALUES ($1, $2, $3)
+	return rows.Err()
+}
+
+// InsertBatch copies the assignments in with one COPY, keeping their assignment times.
+func (r *ReviewAssignmentRepository) InsertBatch(ctx context.Context, assignments []model.ReviewAssignment) error {
+	if len(assignments) == 0 {
+		return nil
+	}
+
+	_, err := r.database.Querier(ctx).CopyFrom(
+		ctx,
+		pgx.Identifier{"review_assignments"},
+		[]string{"pull_request_id", "user_id", "assigned_at", "rule"},
+		pgx.CopyFromSlice(
+			len(assignments), func(i int) ([]any, error) {
+				assignment := assignments[i]
+				return []any{
+					uuid.UUID(assignment.PullRequestID), uuid.UUID(assignment.ReviewerID), assignment.AssignedAt,
+					assignment.Rule,
+				}, nil
+			},
+		),
+	)
+	return err
+}
+
+func (r *ReviewAssignmentRepository) RecordDecline(ctx context.Context, decline *model.ReviewDecline) error {
+	query := `
+INSERT INTO review_declines (pull_request_id, user_id, reason, declined_at)
+VALUES ($1, $2, $3, $4)
 	`
 
-	_, err = tx.Exec(ctx, insertQuery, pullRequestID, newReviewerID, time.Now())
+	_, err := r.database.Querier(ctx).Exec(
+		ctx, query, decline.PullRequestID, decline.ReviewerID, decline.Reason, decline.DeclinedAt,
+	)
+	return err
+}
+
+// StreamDeclines calls fn for every decline, oldest first.
+func (r *ReviewAssignmentRepository) StreamDeclines(
+	ctx context.Context, fn func(decline *model.ReviewDecline) error,
+) error {
+	query := `
+SELECT pull_request_id, user_id, reason, declined_at
+FROM review_declines
+ORDER BY declined_at, pull_request_id, user_id
+`
+
+	rows, err := r.database.Querier(ctx).Query(ctx, query)
 	if err != nil {
 		return err
 	}
+	defer rows.Close()
 
-	return nil
+	for rows.Next() {
+		var decline model.ReviewDecline
+		err := rows.Scan(&decline.PullRequestID, &decline.ReviewerID, &decline.Reason, &decline.DeclinedAt)
+		if err != nil {
+			return err
+		}
+		if err := fn(&decline); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
 }
 
-func (r *ReviewAssignmentRepository) GetAssignmentCounts(ctx context.Context) (map[string]int, error) {
+// GetDecliners lists everybody who declined the pull request, each once.
+func (r *ReviewAssignmentRepository) GetDecliners(
+	ctx context.Context, pullRequestID model.PullRequestID,
+) ([]model.UserID, error) {
+	query := `
+SELECT DISTINCT user_id
+FROM review_declines
+WHERE pull_request_id = $1
+	`
+
+	rows, err := r.database.Querier(ctx).Query(ctx, query, pullRequestID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var decliners []model.UserID
+	for rows.Next() {
+		var userID model.UserID
+		err := rows.Scan(&userID)
+		if err != nil {
+			return nil, err
+		}
+		decliners = append(decliners, userID)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return decliners, nil
+}
+
+func (r *ReviewAssignmentRepository) GetDeclineCounts(ctx context.Context) (map[string]int, error) {
 	query := `
 SELECT user_id, COUNT(*) as count
-FROM review_assignments
+FROM review_declines
 GROUP BY user_id
 	`
 
-	rows, err := r.database.GetPool().Query(ctx, query)
+	rows, err := r.database.Querier(ctx).Query(ctx, query)
 	if err != nil {
 		return nil, err
 	}
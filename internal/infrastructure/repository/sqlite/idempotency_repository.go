@@ -0,0 +1,119 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"pull-request-review/internal/domain/model"
+	"pull-request-review/internal/domain/ports/repository"
+	"pull-request-review/internal/domain/rules"
+	"pull-request-review/internal/infrastructure/database"
+)
+
+type IdempotencyRepositorySQLite struct {
+	database *database.SQLiteDatabase
+}
+
+func NewIdempotencyRepository(database *database.SQLiteDatabase) repository.IdempotencyRepository {
+	return &IdempotencyRepositorySQLite{database: database}
+}
+
+func (r *IdempotencyRepositorySQLite) Reserve(ctx context.Context, record *model.IdempotencyRecord) (bool, error) {
+	query := `
+INSERT INTO idempotency_keys (idempotency_key, route, request_hash, created_at)
+VALUES (?, ?, ?, ?)
+ON CONFLICT (idempotency_key, route) DO NOTHING
+`
+
+	result, err := r.database.Querier(ctx).ExecContext(
+		ctx, query, record.Key, record.Route, record.RequestHash, timestamp(record.CreatedAt),
+	)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected == 1, nil
+}
+
+func (r *IdempotencyRepositorySQLite) Get(ctx context.Context, key string, route string) (
+	*model.IdempotencyRecord, error,
+) {
+	query := `
+SELECT idempotency_key, route, request_hash, status_code, response_body, created_at, completed_at
+FROM idempotency_keys
+WHERE idempotency_key = ? AND route = ?
+`
+
+	var record model.IdempotencyRecord
+	var statusCode sql.NullInt64
+	var completedAt sql.NullTime
+	err := r.database.Querier(ctx).QueryRowContext(ctx, query, key, route).Scan(
+		&record.Key,
+		&record.Route,
+		&record.RequestHash,
+		&statusCode,
+		&record.ResponseBody,
+		&record.CreatedAt,
+		&completedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, rules.ErrNotFound
+		}
+		return nil, err
+	}
+
+	record.StatusCode = int(statusCode.Int64)
+	record.CompletedAt = completedAt.Time
+	return &record, nil
+}
+
+func (r *IdempotencyRepositorySQLite) Complete(
+	ctx context.Context, key string, route string, statusCode int, responseBody []byte,
+) error {
+	query := `
+UPDATE idempotency_keys
+SET status_code = ?, response_body = ?, completed_at = ?
+WHERE idempotency_key = ? AND route = ?
+`
+
+	result, err := r.database.Querier(ctx).ExecContext(
+		ctx, query, statusCode, responseBody, timestamp(time.Now()), key, route,
+	)
+	if err != nil {
+		return err
+	}
+
+	return requireAffected(result, rules.ErrNotFound)
+}
+
+func (r *IdempotencyRepositorySQLite) Delete(ctx context.Context, key string, route string) error {
+	query := `
+DELETE FROM idempotency_keys
+WHERE idempotency_key = ? AND route = ?
+`
+
+	_, err := r.database.Querier(ctx).ExecContext(ctx, query, key, route)
+	return err
+}
+
+func (r *IdempotencyRepositorySQLite) DeleteCreatedBefore(ctx context.Context, before time.Time) (int64, error) {
+	query := `
+DELETE FROM idempotency_keys
+WHERE created_at < ?
+`
+
+	result, err := r.database.Querier(ctx).ExecContext(ctx, query, timestamp(before))
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
@@ -0,0 +1,38 @@
+package sqlite
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"pull-request-review/internal/domain/model"
+	"pull-request-review/internal/domain/ports/repository"
+	"pull-request-review/internal/infrastructure/database"
+)
+
+type MembershipAuditRepositorySQLite struct {
+	database *database.SQLiteDatabase
+}
+
+func NewMembershipAuditRepository(database *database.SQLiteDatabase) repository.MembershipAuditRepository {
+	return &MembershipAuditRepositorySQLite{database: database}
+}
+
+func (r *MembershipAuditRepositorySQLite) Record(ctx context.Context, change *model.MembershipChange) error {
+	query := `
+INSERT INTO team_membership_audit (audit_id, user_id, from_team_id, to_team_id, actor_id, reason, created_at)
+VALUES (?, ?, ?, ?, ?, ?, ?)
+`
+
+	_, err := r.database.Querier(ctx).ExecContext(
+		ctx, query,
+		change.ID.String(),
+		id(change.UserID),
+		nullableUUID(uuid.UUID(change.FromTeamID)),
+		nullableUUID(uuid.UUID(change.ToTeamID)),
+		nullableUUID(uuid.UUID(change.ActorID)),
+		change.Reason,
+		timestamp(change.CreatedAt),
+	)
+	return err
+}
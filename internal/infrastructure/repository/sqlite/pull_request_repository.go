@@ -0,0 +1,335 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+
+	"pull-request-review/internal/domain/model"
+	"pull-request-review/internal/domain/ports/repository"
+	"pull-request-review/internal/domain/rules"
+	"pull-request-review/internal/infrastructure/database"
+)
+
+type PullRequestRepositorySQLite struct {
+	database *database.SQLiteDatabase
+}
+
+func NewPullRequestRepository(database *database.SQLiteDatabase) repository.PullRequestRepository {
+	return &PullRequestRepositorySQLite{database: database}
+}
+
+const pullRequestColumns = `pull_request_id, name, author_id, status, created_at, merged_at, version,
+repository, source_branch, target_branch, url, description, labels, changed_paths`
+
+const pullRequestColumnCount = 14
+
+func scanPullRequest(row interface{ Scan(dest ...any) error }) (*model.PullRequest, error) {
+	var pr model.PullRequest
+	var pullRequestID, authorID uuid.UUID
+	var mergedAt sql.NullTime
+	var labels, changedPaths string
+	err := row.Scan(
+		&pullRequestID, &pr.Name, &authorID, &pr.Status, &pr.CreatedAt, &mergedAt, &pr.Version,
+		&pr.Repository, &pr.SourceBranch, &pr.TargetBranch, &pr.URL, &pr.Description, &labels, &changedPaths,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	pr.PullRequestID = model.PullRequestID(pullRequestID)
+	pr.AuthorID = model.UserID(authorID)
+	pr.MergedAt = mergedAt.Time
+	if err := json.Unmarshal([]byte(labels), &pr.Labels); err != nil {
+		return nil, fmt.Errorf("invalid labels of pull request %s: %w", pullRequestID, err)
+	}
+	if err := json.Unmarshal([]byte(changedPaths), &pr.ChangedPaths); err != nil {
+		return nil, fmt.Errorf("invalid changed paths of pull request %s: %w", pullRequestID, err)
+	}
+	return &pr, nil
+}
+
+// pullRequestArgs are the values of pullRequestColumns for pr.
+func pullRequestArgs(pr *model.PullRequest) ([]any, error) {
+	labels, err := encodeLabels(pr.Labels)
+	if err != nil {
+		return nil, err
+	}
+	changedPaths, err := encodeLabels(pr.ChangedPaths)
+	if err != nil {
+		return nil, err
+	}
+
+	return []any{
+		id(pr.PullRequestID), pr.Name, id(pr.AuthorID), pr.Status, timestamp(pr.CreatedAt),
+		timestamp(pr.MergedAt), pr.Version,
+		pr.Repository, pr.SourceBranch, pr.TargetBranch, pr.URL, pr.Description, labels, changedPaths,
+	}, nil
+}
+
+// encodeLabels stores labels, or changed paths, as a JSON array, an empty one when there are none.
+func encodeLabels(labels []string) (string, error) {
+	if labels == nil {
+		labels = []string{}
+	}
+	encoded, err := json.Marshal(labels)
+	return string(encoded), err
+}
+
+func (r *PullRequestRepositorySQLite) Create(ctx context.Context, pullRequest *model.PullRequest) error {
+	query := `
+INSERT INTO pull_requests (` + pullRequestColumns + `)
+VALUES (` + placeholders(pullRequestColumnCount) + `)
+ON CONFLICT DO NOTHING
+`
+	args, err := pullRequestArgs(pullRequest)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.database.Querier(ctx).ExecContext(ctx, query, args...)
+	return err
+}
+
+// CreateBatch inserts the pull requests with multi-row statements in one transaction. Unlike
+// Create it fails on an existing ID, the caller is expected to have filtered those out.
+func (r *PullRequestRepositorySQLite) CreateBatch(ctx context.Context, pullRequests []model.PullRequest) error {
+	return r.database.WithinTx(ctx, func(ctx context.Context) error {
+		for chunk := range slices.Chunk(pullRequests, batchRows) {
+			query := `INSERT INTO pull_requests (` + pullRequestColumns + `) VALUES ` +
+				rowPlaceholders(len(chunk), pullRequestColumnCount)
+
+			args := make([]any, 0, len(chunk)*pullRequestColumnCount)
+			for _, pr := range chunk {
+				prArgs, err := pullRequestArgs(&pr)
+				if err != nil {
+					return err
+				}
+				args = append(args, prArgs...)
+			}
+
+			_, err := r.database.Querier(ctx).ExecContext(ctx, query, args...)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r *PullRequestRepositorySQLite) GetByID(ctx context.Context, ID model.PullRequestID) (
+	*model.PullRequest, error,
+) {
+	query := `SELECT ` + pullRequestColumns + ` FROM pull_requests WHERE pull_request_id = ?`
+
+	pr, err := scanPullRequest(r.database.Querier(ctx).QueryRowContext(ctx, query, id(ID)))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, rules.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return pr, nil
+}
+
+func (r *PullRequestRepositorySQLite) Exists(ctx context.Context, ID model.PullRequestID) (bool, error) {
+	query := `SELECT EXISTS (SELECT 1 FROM pull_requests WHERE pull_request_id = ?)`
+
+	var exists bool
+	err := r.database.Querier(ctx).QueryRowContext(ctx, query, id(ID)).Scan(&exists)
+	if err != nil {
+		return false, err
+	}
+
+	return exists, nil
+}
+
+func (r *PullRequestRepositorySQLite) GetExistingIDs(
+	ctx context.Context, IDs []model.PullRequestID,
+) ([]model.PullRequestID, error) {
+	var existing []model.PullRequestID
+	for chunk := range slices.Chunk(IDs, batchRows) {
+		query := `SELECT pull_request_id FROM pull_requests WHERE pull_request_id IN (` + placeholders(len(chunk)) + `)`
+
+		rows, err := r.database.Querier(ctx).QueryContext(ctx, query, idArgs(chunk)...)
+		if err != nil {
+			return nil, err
+		}
+
+		for rows.Next() {
+			var ID uuid.UUID
+			if err := rows.Scan(&ID); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			existing = append(existing, model.PullRequestID(ID))
+		}
+		rows.Close()
+
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	return existing, nil
+}
+
+func (r *PullRequestRepositorySQLite) UpdateStatus(
+	ctx context.Context, ID model.PullRequestID, status model.PullRequestStatus, mergedAt time.Time,
+) error {
+	query := `
+UPDATE pull_requests
+SET status = ?, merged_at = ?
+WHERE pull_request_id = ?
+`
+
+	result, err := r.database.Querier(ctx).ExecContext(ctx, query, status, timestamp(mergedAt), id(ID))
+	if err != nil {
+		return err
+	}
+
+	return requireAffected(result, rules.ErrPullRequestNotFound)
+}
+
+// BumpVersion moves the pull request from version to the next one. When another change got
+// there first it returns rules.ErrConcurrentModification.
+func (r *PullRequestRepositorySQLite) BumpVersion(ctx context.Context, ID model.PullRequestID, version int64) error {
+	query := `
+UPDATE pull_requests
+SET version = version + 1
+WHERE pull_request_id = ? AND version = ?
+`
+
+	result, err := r.database.Querier(ctx).ExecContext(ctx, query, id(ID), version)
+	if err != nil {
+		return err
+	}
+
+	return requireAffected(result, rules.ErrConcurrentModification)
+}
+
+func (r *PullRequestRepositorySQLite) UpdateMetadata(
+	ctx context.Context, ID model.PullRequestID, metadata model.PullRequestMetadata,
+) error {
+	query := `
+UPDATE pull_requests
+SET repository = ?, source_branch = ?, target_branch = ?, url = ?, description = ?, labels = ?
+WHERE pull_request_id = ?
+`
+
+	labels, err := encodeLabels(metadata.Labels)
+	if err != nil {
+		return err
+	}
+
+	result, err := r.database.Querier(ctx).ExecContext(
+		ctx, query,
+		metadata.Repository, metadata.SourceBranch, metadata.TargetBranch, metadata.URL, metadata.Description,
+		labels, id(ID),
+	)
+	if err != nil {
+		return err
+	}
+
+	return requireAffected(result, rules.ErrPullRequestNotFound)
+}
+
+// GetByReviewer lists the pull requests the user reviews, narrowed down by filter.
+func (r *PullRequestRepositorySQLite) GetByReviewer(
+	ctx context.Context, ID model.UserID, filter model.ReviewFilter,
+) ([]model.PullRequest, error) {
+	query := `
+SELECT p.pull_request_id, p.name, p.author_id, p.status, p.created_at, p.merged_at, p.version,
+       p.repository, p.source_branch, p.target_branch, p.url, p.description, p.labels, p.changed_paths
+FROM pull_requests p
+INNER JOIN review_assignments ra ON p.pull_request_id = ra.pull_request_id
+WHERE ra.user_id = ?
+  AND (? = '' OR p.repository = ?)
+  AND (? = '' OR EXISTS (SELECT 1 FROM json_each(p.labels) WHERE json_each.value = ?))
+`
+
+	rows, err := r.database.Querier(ctx).QueryContext(
+		ctx, query, id(ID), filter.Repository, filter.Repository, filter.Label, filter.Label,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var pullRequests []model.PullRequest
+	for rows.Next() {
+		pr, err := scanPullRequest(rows)
+		if err != nil {
+			return nil, err
+		}
+		pullRequests = append(pullRequests, *pr)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return pullRequests, nil
+}
+
+func (r *PullRequestRepositorySQLite) GetPullRequestCountsByStatus(ctx context.Context) (map[string]int, error) {
+	query := `
+SELECT status, COUNT(*) AS count
+FROM pull_requests
+GROUP BY status
+`
+
+	rows, err := r.database.Querier(ctx).QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int)
+	for rows.Next() {
+		var status string
+		var count int
+		err := rows.Scan(&status, &count)
+		if err != nil {
+			return nil, err
+		}
+		counts[status] = count
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return counts, nil
+}
+
+func (r *PullRequestRepositorySQLite) StreamAll(
+	ctx context.Context, fn func(pullRequest *model.PullRequest) error,
+) error {
+	query := `SELECT ` + pullRequestColumns + ` FROM pull_requests ORDER BY created_at, pull_request_id`
+
+	rows, err := r.database.Querier(ctx).QueryContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		pr, err := scanPullRequest(rows)
+		if err != nil {
+			return err
+		}
+		if err := fn(pr); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
@@ -0,0 +1,486 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+
+	"pull-request-review/internal/domain/model"
+	"pull-request-review/internal/domain/ports/repository"
+	"pull-request-review/internal/domain/rules"
+	"pull-request-review/internal/infrastructure/database"
+)
+
+type ReviewAssignmentRepositorySQLite struct {
+	database *database.SQLiteDatabase
+}
+
+func NewReviewAssignmentRepository(database *database.SQLiteDatabase) repository.ReviewAssignmentRepository {
+	return &ReviewAssignmentRepositorySQLite{database: database}
+}
+
+const insertAssignmentQuery = `
+INSERT INTO review_assignments (pull_request_id, user_id, assigned_at)
+VALUES (?, ?, ?)
+`
+
+func (r *ReviewAssignmentRepositorySQLite) AssignReviewer(
+	ctx context.Context, pullRequestID model.PullRequestID, reviewerID model.UserID,
+) error {
+	return r.AssignReviewers(ctx, pullRequestID, []model.UserID{reviewerID})
+}
+
+func (r *ReviewAssignmentRepositorySQLite) AssignReviewers(
+	ctx context.Context, pullRequestID model.PullRequestID, reviewerIDs []model.UserID,
+) error {
+	if len(reviewerIDs) == 0 {
+		return nil
+	}
+
+	return r.database.WithinTx(ctx, func(ctx context.Context) error {
+		tx := r.database.Querier(ctx)
+
+		now := timestamp(time.Now())
+		for _, userID := range reviewerIDs {
+			_, err := tx.ExecContext(ctx, insertAssignmentQuery, id(pullRequestID), id(userID), now)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func (r *ReviewAssignmentRepositorySQLite) RemoveReviewer(
+	ctx context.Context, pullRequestID model.PullRequestID, reviewerID model.UserID,
+) error {
+	query := `
+DELETE FROM review_assignments
+WHERE pull_request_id = ? AND user_id = ?
+`
+
+	result, err := r.database.Querier(ctx).ExecContext(ctx, query, id(pullRequestID), id(reviewerID))
+	if err != nil {
+		return err
+	}
+
+	return requireAffected(result, rules.ErrNotAssigned)
+}
+
+// GetByReviewer takes the reviewer's id despite the parameter type, like the pgx implementation.
+func (r *ReviewAssignmentRepositorySQLite) GetByReviewer(
+	ctx context.Context, pullRequestID model.PullRequestID,
+) ([]model.PullRequest, error) {
+	return NewPullRequestRepository(r.database).GetByReviewer(ctx, model.UserID(pullRequestID), model.ReviewFilter{})
+}
+
+func (r *ReviewAssignmentRepositorySQLite) Exists(
+	ctx context.Context, pullRequestID model.PullRequestID, reviewerID model.UserID,
+) (bool, error) {
+	query := `
+SELECT EXISTS (SELECT 1 FROM review_assignments WHERE pull_request_id = ? AND user_id = ?)
+`
+
+	var exists bool
+	err := r.database.Querier(ctx).QueryRowContext(ctx, query, id(pullRequestID), id(reviewerID)).Scan(&exists)
+	if err != nil {
+		return false, err
+	}
+	return exists, nil
+}
+
+func (r *ReviewAssignmentRepositorySQLite) GetReviewers(ctx context.Context, pullRequestID model.PullRequestID) (
+	[]model.User, error,
+) {
+	query := `
+SELECT u.user_id, u.username, u.team_id, u.is_active, u.created_at, u.updated_at
+FROM users u
+INNER JOIN review_assignments ra ON u.user_id = ra.user_id
+WHERE ra.pull_request_id = ?`
+
+	rows, err := r.database.Querier(ctx).QueryContext(ctx, query, id(pullRequestID))
+	if err != nil {
+		return nil, err
+	}
+	return collectUsers(rows)
+}
+
+// ReplaceReviewer swaps the reviewers in one transaction, so the pull request never ends up with neither.
+func (r *ReviewAssignmentRepositorySQLite) ReplaceReviewer(
+	ctx context.Context, pullRequestID model.PullRequestID, oldReviewerID model.UserID, newReviewerID model.UserID,
+) error {
+	return r.database.WithinTx(ctx, func(ctx context.Context) error {
+		tx := r.database.Querier(ctx)
+
+		deleteQuery := `
+DELETE FROM review_assignments
+WHERE pull_request_id = ? AND user_id = ?
+`
+
+		result, err := tx.ExecContext(ctx, deleteQuery, id(pullRequestID), id(oldReviewerID))
+		if err != nil {
+			return err
+		}
+
+		if err := requireAffected(result, rules.ErrNotAssigned); err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, insertAssignmentQuery, id(pullRequestID), id(newReviewerID), timestamp(time.Now()))
+		return err
+	})
+}
+
+func (r *ReviewAssignmentRepositorySQLite) GetAssignmentCounts(ctx context.Context) (map[string]int, error) {
+	query := `
+SELECT user_id, COUNT(*) AS count
+FROM review_assignments
+GROUP BY user_id
+`
+
+	return r.queryCounts(ctx, query)
+}
+
+// GetTeamAssignmentCounts counts the assignments of each team's members, rolled up
+// so that a team also includes everything below it. Keys are team names. The depth bound
+// keeps a parent cycle from recursing forever.
+func (r *ReviewAssignmentRepositorySQLite) GetTeamAssignmentCounts(ctx context.Context) (map[string]int, error) {
+	// SQLite cannot count distinct row values, so the assignment key is concatenated instead.
+	query := `
+WITH RECURSIVE subtree AS (
+    SELECT team_id AS root_id, team_id, 0 AS depth
+    FROM teams
+    UNION ALL
+    SELECT s.root_id, t.team_id, s.depth + 1
+    FROM teams t
+    INNER JOIN subtree s ON t.parent_team_id = s.team_id
+    WHERE s.depth < (SELECT COUNT(*) FROM teams)
+)
+SELECT t.name, COUNT(DISTINCT ra.pull_request_id || ':' || ra.user_id) AS count
+FROM subtree s
+INNER JOIN teams t ON t.team_id = s.root_id
+INNER JOIN team_memberships m ON m.team_id = s.team_id
+INNER JOIN review_assignments ra ON ra.user_id = m.user_id
+GROUP BY t.name
+`
+
+	return r.queryCounts(ctx, query)
+}
+
+func (r *ReviewAssignmentRepositorySQLite) queryCounts(ctx context.Context, query string) (map[string]int, error) {
+	rows, err := r.database.Querier(ctx).QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int)
+	for rows.Next() {
+		var key string
+		var count int
+		err := rows.Scan(&key, &count)
+		if err != nil {
+			return nil, err
+		}
+		counts[key] = count
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return counts, nil
+}
+
+// GetOpenReviewCounts counts how many open pull requests each of the reviewers has to review.
+// Reviewers without any are left out.
+func (r *ReviewAssignmentRepositorySQLite) GetOpenReviewCounts(
+	ctx context.Context, reviewerIDs []model.UserID,
+) (map[model.UserID]int, error) {
+	counts := make(map[model.UserID]int)
+	for chunk := range slices.Chunk(reviewerIDs, batchRows) {
+		query := `
+SELECT ra.user_id, COUNT(*) AS count
+FROM review_assignments ra
+INNER JOIN pull_requests p ON p.pull_request_id = ra.pull_request_id
+WHERE p.status = ? AND ra.user_id IN (` + placeholders(len(chunk)) + `)
+GROUP BY ra.user_id
+`
+
+		args := append([]any{model.PRStatusOpen}, idArgs(chunk)...)
+		rows, err := r.database.Querier(ctx).QueryContext(ctx, query, args...)
+		if err != nil {
+			return nil, err
+		}
+
+		for rows.Next() {
+			var userID uuid.UUID
+			var count int
+			if err := rows.Scan(&userID, &count); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			counts[model.UserID(userID)] = count
+		}
+		rows.Close()
+
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	return counts, nil
+}
+
+// GetReviewHistory counts the assignments of each reviewer to pull requests of the author and
+// tells when the last one was made. Reviewers who never reviewed the author are left out.
+func (r *ReviewAssignmentRepositorySQLite) GetReviewHistory(
+	ctx context.Context, authorID model.UserID, reviewerIDs []model.UserID,
+) (map[model.UserID]model.ReviewHistory, error) {
+	history := make(map[model.UserID]model.ReviewHistory)
+	for chunk := range slices.Chunk(reviewerIDs, batchRows) {
+		// MAX would hand the time back as text, so the assignments are folded here instead
+		query := `
+SELECT ra.user_id, ra.assigned_at
+FROM review_assignments ra
+INNER JOIN pull_requests p ON p.pull_request_id = ra.pull_request_id
+WHERE p.author_id = ? AND ra.user_id IN (` + placeholders(len(chunk)) + `)
+`
+
+		args := append([]any{id(authorID)}, idArgs(chunk)...)
+		rows, err := r.database.Querier(ctx).QueryContext(ctx, query, args...)
+		if err != nil {
+			return nil, err
+		}
+
+		for rows.Next() {
+			var userID uuid.UUID
+			var assignedAt time.Time
+			if err := rows.Scan(&userID, &assignedAt); err != nil {
+				rows.Close()
+				return nil, err
+			}
+
+			reviews := history[model.UserID(userID)]
+			reviews.Reviews++
+			if assignedAt.After(reviews.LastReviewedAt) {
+				reviews.LastReviewedAt = assignedAt
+			}
+			history[model.UserID(userID)] = reviews
+		}
+		rows.Close()
+
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	return history, nil
+}
+
+// Insert stores an assignment as is, keeping its original assignment time.
+func (r *ReviewAssignmentRepositorySQLite) Insert(ctx context.Context, assignment *model.ReviewAssignment) error {
+	query := `
+INSERT INTO review_assignments (pull_request_id, user_id, assigned_at, rule)
+VALUES (?, ?, ?, ?)
+`
+
+	_, err := r.database.Querier(ctx).ExecContext(
+		ctx, query, id(assignment.PullRequestID), id(assignment.ReviewerID), timestamp(assignment.AssignedAt),
+		assignment.Rule,
+	)
+	return err
+}
+
+func (r *ReviewAssignmentRepositorySQLite) StreamAll(
+	ctx context.Context, fn func(assignment *model.ReviewAssignment) error,
+) error {
+	query := `
+SELECT pull_request_id, user_id, assigned_at, rule
+FROM review_assignments
+ORDER BY assigned_at, pull_request_id, user_id
+`
+
+	rows, err := r.database.Querier(ctx).QueryContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		assignment, err := scanAssignment(rows)
+		if err != nil {
+			return err
+		}
+		if err := fn(assignment); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+// GetAssignments lists the assignments of the pull request, telling which rule picked each reviewer.
+func (r *ReviewAssignmentRepositorySQLite) GetAssignments(
+	ctx context.Context, pullRequestID model.PullRequestID,
+) ([]model.ReviewAssignment, error) {
+	query := `
+SELECT pull_request_id, user_id, assigned_at, rule
+FROM review_assignments
+WHERE pull_request_id = ?
+ORDER BY assigned_at, user_id
+`
+
+	rows, err := r.database.Querier(ctx).QueryContext(ctx, query, id(pullRequestID))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var assignments []model.ReviewAssignment
+	for rows.Next() {
+		assignment, err := scanAssignment(rows)
+		if err != nil {
+			return nil, err
+		}
+		assignments = append(assignments, *assignment)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return assignments, nil
+}
+
+func scanAssignment(rows *sql.Rows) (*model.ReviewAssignment, error) {
+	var assignment model.ReviewAssignment
+	var pullRequestID, reviewerID uuid.UUID
+	err := rows.Scan(&pullRequestID, &reviewerID, &assignment.AssignedAt, &assignment.Rule)
+	if err != nil {
+		return nil, err
+	}
+
+	assignment.PullRequestID = model.PullRequestID(pullRequestID)
+	assignment.ReviewerID = model.UserID(reviewerID)
+	return &assignment, nil
+}
+
+// InsertBatch inserts the assignments with multi-row statements in one transaction,
+// keeping their assignment times.
+func (r *ReviewAssignmentRepositorySQLite) InsertBatch(
+	ctx context.Context, assignments []model.ReviewAssignment,
+) error {
+	return r.database.WithinTx(ctx, func(ctx context.Context) error {
+		for chunk := range slices.Chunk(assignments, batchRows) {
+			query := `INSERT INTO review_assignments (pull_request_id, user_id, assigned_at, rule) VALUES ` +
+				rowPlaceholders(len(chunk), 4)
+
+			args := make([]any, 0, len(chunk)*4)
+			for _, assignment := range chunk {
+				args = append(
+					args, id(assignment.PullRequestID), id(assignment.ReviewerID), timestamp(assignment.AssignedAt),
+					assignment.Rule,
+				)
+			}
+
+			_, err := r.database.Querier(ctx).ExecContext(ctx, query, args...)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r *ReviewAssignmentRepositorySQLite) RecordDecline(ctx context.Context, decline *model.ReviewDecline) error {
+	query := `
+INSERT INTO review_declines (pull_request_id, user_id, reason, declined_at)
+VALUES (?, ?, ?, ?)
+`
+
+	_, err := r.database.Querier(ctx).ExecContext(
+		ctx, query, id(decline.PullRequestID), id(decline.ReviewerID), decline.Reason, timestamp(decline.DeclinedAt),
+	)
+	return err
+}
+
+// StreamDeclines calls fn for every decline, oldest first.
+func (r *ReviewAssignmentRepositorySQLite) StreamDeclines(
+	ctx context.Context, fn func(decline *model.ReviewDecline) error,
+) error {
+	query := `
+SELECT pull_request_id, user_id, reason, declined_at
+FROM review_declines
+ORDER BY declined_at, pull_request_id, user_id
+`
+
+	rows, err := r.database.Querier(ctx).QueryContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var decline model.ReviewDecline
+		var pullRequestID, reviewerID uuid.UUID
+		err := rows.Scan(&pullRequestID, &reviewerID, &decline.Reason, &decline.DeclinedAt)
+		if err != nil {
+			return err
+		}
+
+		decline.PullRequestID = model.PullRequestID(pullRequestID)
+		decline.ReviewerID = model.UserID(reviewerID)
+		if err := fn(&decline); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+// GetDecliners lists everybody who declined the pull request, each once.
+func (r *ReviewAssignmentRepositorySQLite) GetDecliners(
+	ctx context.Context, pullRequestID model.PullRequestID,
+) ([]model.UserID, error) {
+	query := `
+SELECT DISTINCT user_id
+FROM review_declines
+WHERE pull_request_id = ?
+`
+
+	rows, err := r.database.Querier(ctx).QueryContext(ctx, query, id(pullRequestID))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var decliners []model.UserID
+	for rows.Next() {
+		var userID uuid.UUID
+		if err := rows.Scan(&userID); err != nil {
+			return nil, err
+		}
+		decliners = append(decliners, model.UserID(userID))
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return decliners, nil
+}
+
+func (r *ReviewAssignmentRepositorySQLite) GetDeclineCounts(ctx context.Context) (map[string]int, error) {
+	query := `
+SELECT user_id, COUNT(*) AS count
+FROM review_declines
+GROUP BY user_id
+`
+
+	return r.queryCounts(ctx, query)
+}
@@ -0,0 +1,107 @@
+package sqlite
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"pull-request-review/internal/domain/model"
+	"pull-request-review/internal/domain/ports/repository"
+	"pull-request-review/internal/infrastructure/database"
+)
+
+func NewRepositories(database *database.SQLiteDatabase) *repository.Repositories {
+	return &repository.Repositories{
+		Team:             NewTeamRepository(database),
+		User:             NewUserRepository(database),
+		PullRequest:      NewPullRequestRepository(database),
+		ReviewAssignment: NewReviewAssignmentRepository(database),
+		TeamRole:         NewTeamRoleRepository(database),
+		TeamMembership:   NewTeamMembershipRepository(database),
+		MembershipAudit:  NewMembershipAuditRepository(database),
+		Idempotency:      NewIdempotencyRepository(database),
+		Tx:               database,
+	}
+}
+
+// database/sql only knows driver values, so the model id types are passed as their text form.
+func id[T ~[16]byte](ID T) string {
+	return uuid.UUID(ID).String()
+}
+
+// nullableUUID stores uuid.Nil as NULL, e.g. for users that are not in any team.
+func nullableUUID(ID uuid.UUID) any {
+	if ID == uuid.Nil {
+		return nil
+	}
+	return ID.String()
+}
+
+// timestamp stores times in UTC so that they compare correctly as text, and the zero time as NULL.
+func timestamp(t time.Time) any {
+	if t.IsZero() {
+		return nil
+	}
+	return t.UTC()
+}
+
+// placeholders returns "?, ?, ..." with n placeholders, for IN lists.
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
+
+// batchRows bounds the rows of one multi-row statement, keeping it well under
+// the number of variables SQLite accepts.
+const batchRows = 500
+
+// rowPlaceholders returns "(?, ?), (?, ?), ..." for rows rows of columns values each.
+func rowPlaceholders(rows int, columns int) string {
+	row := "(" + placeholders(columns) + ")"
+	return strings.TrimSuffix(strings.Repeat(row+", ", rows), ", ")
+}
+
+// idArgs turns ids into query arguments for an IN list.
+func idArgs[T ~[16]byte](IDs []T) []any {
+	args := make([]any, len(IDs))
+	for i, ID := range IDs {
+		args[i] = id(ID)
+	}
+	return args
+}
+
+const userColumns = `user_id, username, team_id, is_active, created_at, updated_at`
+
+func scanUser(row interface{ Scan(dest ...any) error }) (*model.User, error) {
+	var user model.User
+	var userID uuid.UUID
+	var teamID uuid.NullUUID
+	err := row.Scan(&userID, &user.Username, &teamID, &user.IsActive, &user.CreatedAt, &user.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	user.ID = model.UserID(userID)
+	user.TeamID = teamID.UUID
+	return &user, nil
+}
+
+func collectUsers(rows *sql.Rows) ([]model.User, error) {
+	defer rows.Close()
+
+	var users []model.User
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, *user)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return users, nil
+}
@@ -0,0 +1,107 @@
+package sqlite
+
+import (
+	"context"
+	"io/fs"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/google/uuid"
+
+	"pull-request-review/config"
+	"pull-request-review/db/migrations"
+	"pull-request-review/internal/domain/model"
+	"pull-request-review/internal/domain/ports/repository"
+	"pull-request-review/internal/infrastructure/adapters/logger"
+	"pull-request-review/internal/infrastructure/database"
+	"pull-request-review/internal/infrastructure/repository/repositorytest"
+)
+
+func TestContract(t *testing.T) {
+	ctx := context.Background()
+	log := logger.NewZerologLogger()
+	db := database.NewSQLiteDatabase(config.DatabaseConfig{URL: ":memory:"}, log)
+	if err := db.Connect(ctx); err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	t.Cleanup(db.Close)
+
+	if _, err := db.Migrate(ctx, migrations.SQLiteFS); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	repositorytest.Run(t, func(t *testing.T) *repository.Repositories {
+		return NewRepositories(db)
+	})
+}
+
+// TestMigrateRebuildKeepsReferences applies the migrations that rebuild pull_requests to a
+// database with data in it: the rows referencing pull requests must survive the rebuild.
+func TestMigrateRebuildKeepsReferences(t *testing.T) {
+	ctx := context.Background()
+	db := database.NewSQLiteDatabase(config.DatabaseConfig{URL: ":memory:"}, logger.NewZerologLogger())
+	if err := db.Connect(ctx); err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	t.Cleanup(db.Close)
+
+	// the schema as it was before DRAFT was added
+	before := fstest.MapFS{}
+	for _, name := range []string{"001_create_schema", "002_add_pull_request_version", "003_create_review_declines"} {
+		for _, file := range []string{name + ".up.sql", name + ".down.sql"} {
+			data, err := fs.ReadFile(migrations.SQLiteFS, file)
+			if err != nil {
+				t.Fatalf("read migration: %v", err)
+			}
+			before[file] = &fstest.MapFile{Data: data}
+		}
+	}
+	if _, err := db.Migrate(ctx, before); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	repos := NewRepositories(db)
+	now := time.Now()
+	newUser := func(name string) *model.User {
+		return &model.User{ID: model.UserID(uuid.New()), Username: name, IsActive: true, CreatedAt: now, UpdatedAt: now}
+	}
+	author, reviewer := newUser("author"), newUser("reviewer")
+	pr := &model.PullRequest{
+		PullRequestID: model.PullRequestID(uuid.New()), Name: "pr", AuthorID: author.ID,
+		Status: model.PRStatusOpen, CreatedAt: now, Version: model.InitialPullRequestVersion,
+	}
+	for _, user := range []*model.User{author, reviewer} {
+		if err := repos.User.Insert(ctx, user); err != nil {
+			t.Fatalf("insert user: %v", err)
+		}
+	}
+	// inserted by hand: the repository writes the columns of the latest schema
+	_, err := db.GetDB().ExecContext(ctx,
+		`INSERT INTO pull_requests (pull_request_id, name, author_id, status, created_at, version)
+		VALUES (?, ?, ?, ?, ?, ?)`,
+		id(pr.PullRequestID), pr.Name, id(pr.AuthorID), pr.Status, timestamp(pr.CreatedAt), pr.Version,
+	)
+	if err != nil {
+		t.Fatalf("create pull request: %v", err)
+	}
+	if err := repos.ReviewAssignment.AssignReviewer(ctx, pr.PullRequestID, reviewer.ID); err != nil {
+		t.Fatalf("assign reviewer: %v", err)
+	}
+
+	if _, err := db.Migrate(ctx, migrations.SQLiteFS); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	if exists, err := repos.ReviewAssignment.Exists(ctx, pr.PullRequestID, reviewer.ID); err != nil || !exists {
+		t.Fatalf("assignment should survive the rebuild: %v", err)
+	}
+	if err := repos.PullRequest.UpdateStatus(ctx, pr.PullRequestID, model.PRStatusDraft, time.Time{}); err != nil {
+		t.Fatalf("the rebuilt table should accept drafts: %v", err)
+	}
+
+	var foreignKeys bool
+	if err := db.GetDB().QueryRowContext(ctx, `PRAGMA foreign_keys`).Scan(&foreignKeys); err != nil || !foreignKeys {
+		t.Fatalf("foreign keys should be back on after migrating: %v", err)
+	}
+}
@@ -0,0 +1,148 @@
+package sqlite
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"pull-request-review/internal/domain/model"
+	"pull-request-review/internal/domain/ports/repository"
+	"pull-request-review/internal/domain/rules"
+	"pull-request-review/internal/infrastructure/database"
+)
+
+type TeamMembershipRepositorySQLite struct {
+	database *database.SQLiteDatabase
+}
+
+func NewTeamMembershipRepository(database *database.SQLiteDatabase) repository.TeamMembershipRepository {
+	return &TeamMembershipRepositorySQLite{database: database}
+}
+
+func (r *TeamMembershipRepositorySQLite) Add(ctx context.Context, membership *model.TeamMembership) error {
+	query := `
+INSERT INTO team_memberships (team_id, user_id, weight, created_at)
+VALUES (?, ?, ?, ?)
+ON CONFLICT (team_id, user_id) DO UPDATE
+SET weight = excluded.weight
+`
+
+	_, err := r.database.Querier(ctx).ExecContext(
+		ctx, query,
+		id(membership.TeamID),
+		id(membership.UserID),
+		membership.Weight,
+		timestamp(membership.CreatedAt),
+	)
+	return err
+}
+
+func (r *TeamMembershipRepositorySQLite) Remove(ctx context.Context, teamID model.TeamID, userID model.UserID) error {
+	query := `
+DELETE FROM team_memberships
+WHERE team_id = ? AND user_id = ?
+`
+
+	result, err := r.database.Querier(ctx).ExecContext(ctx, query, id(teamID), id(userID))
+	if err != nil {
+		return err
+	}
+
+	return requireAffected(result, rules.ErrNotFound)
+}
+
+func (r *TeamMembershipRepositorySQLite) Exists(ctx context.Context, teamID model.TeamID, userID model.UserID) (
+	bool, error,
+) {
+	query := `SELECT EXISTS(SELECT 1 FROM team_memberships WHERE team_id = ? AND user_id = ?)`
+
+	var exists bool
+	err := r.database.Querier(ctx).QueryRowContext(ctx, query, id(teamID), id(userID)).Scan(&exists)
+	if err != nil {
+		return false, err
+	}
+
+	return exists, nil
+}
+
+func (r *TeamMembershipRepositorySQLite) GetByUser(ctx context.Context, userID model.UserID) (
+	[]model.TeamMembership, error,
+) {
+	query := `
+SELECT m.team_id, m.user_id, t.name, m.weight, m.created_at
+FROM team_memberships m
+INNER JOIN teams t ON t.team_id = m.team_id
+WHERE m.user_id = ?
+ORDER BY m.created_at, t.name
+`
+
+	return r.query(ctx, query, id(userID))
+}
+
+func (r *TeamMembershipRepositorySQLite) GetByTeam(ctx context.Context, teamID model.TeamID) (
+	[]model.TeamMembership, error,
+) {
+	query := `
+SELECT m.team_id, m.user_id, t.name, m.weight, m.created_at
+FROM team_memberships m
+INNER JOIN teams t ON t.team_id = m.team_id
+WHERE m.team_id = ?
+`
+
+	return r.query(ctx, query, id(teamID))
+}
+
+func (r *TeamMembershipRepositorySQLite) query(ctx context.Context, query string, args ...any) (
+	[]model.TeamMembership, error,
+) {
+	var memberships []model.TeamMembership
+	err := r.stream(ctx, query, args, func(membership *model.TeamMembership) error {
+		memberships = append(memberships, *membership)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return memberships, nil
+}
+
+func (r *TeamMembershipRepositorySQLite) StreamAll(
+	ctx context.Context, fn func(membership *model.TeamMembership) error,
+) error {
+	query := `
+SELECT m.team_id, m.user_id, t.name, m.weight, m.created_at
+FROM team_memberships m
+INNER JOIN teams t ON t.team_id = m.team_id
+ORDER BY m.created_at, m.team_id, m.user_id
+`
+
+	return r.stream(ctx, query, nil, fn)
+}
+
+func (r *TeamMembershipRepositorySQLite) stream(
+	ctx context.Context, query string, args []any, fn func(membership *model.TeamMembership) error,
+) error {
+	rows, err := r.database.Querier(ctx).QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var membership model.TeamMembership
+		var teamID, userID uuid.UUID
+		err := rows.Scan(&teamID, &userID, &membership.TeamName, &membership.Weight, &membership.CreatedAt)
+		if err != nil {
+			return err
+		}
+
+		membership.TeamID = model.TeamID(teamID)
+		membership.UserID = model.UserID(userID)
+		if err := fn(&membership); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
@@ -0,0 +1,374 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+
+	"pull-request-review/internal/domain/model"
+	"pull-request-review/internal/domain/ports/repository"
+	"pull-request-review/internal/domain/rules"
+	"pull-request-review/internal/infrastructure/database"
+)
+
+type TeamRepositorySQLite struct {
+	database *database.SQLiteDatabase
+}
+
+func NewTeamRepository(database *database.SQLiteDatabase) repository.TeamRepository {
+	return &TeamRepositorySQLite{database: database}
+}
+
+const teamColumns = `team_id, name, parent_team_id, max_reviewers, reviewer_fallback, created_at`
+
+func scanTeam(row interface{ Scan(dest ...any) error }) (*model.Team, error) {
+	var team model.Team
+	var teamID uuid.UUID
+	var parentTeamID uuid.NullUUID
+	err := row.Scan(
+		&teamID,
+		&team.Name,
+		&parentTeamID,
+		&team.Settings.MaxReviewers,
+		&team.Settings.ReviewerFallback,
+		&team.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	team.TeamID = model.TeamID(teamID)
+	team.ParentTeamID = parentTeamID.UUID
+	return &team, nil
+}
+
+func (r *TeamRepositorySQLite) Create(ctx context.Context, team *model.Team) error {
+	query := `
+INSERT INTO teams (team_id, name, parent_team_id, max_reviewers, reviewer_fallback, created_at)
+VALUES (?, ?, ?, ?, ?, ?)
+ON CONFLICT DO NOTHING
+`
+
+	_, err := r.database.Querier(ctx).ExecContext(
+		ctx, query, id(team.TeamID), team.Name, nullableUUID(team.ParentTeamID), team.Settings.MaxReviewers,
+		team.Settings.ReviewerFallback, timestamp(team.CreatedAt),
+	)
+	return err
+}
+
+func (r *TeamRepositorySQLite) Update(ctx context.Context, team *model.Team) error {
+	query := `
+UPDATE teams
+SET name = ?, parent_team_id = ?, max_reviewers = ?, reviewer_fallback = ?
+WHERE team_id = ?
+`
+
+	_, err := r.database.Querier(ctx).ExecContext(
+		ctx, query, team.Name, nullableUUID(team.ParentTeamID), team.Settings.MaxReviewers,
+		team.Settings.ReviewerFallback, id(team.TeamID),
+	)
+	return err
+}
+
+func (r *TeamRepositorySQLite) GetByID(ctx context.Context, ID model.TeamID) (*model.Team, error) {
+	query := `SELECT ` + teamColumns + ` FROM teams WHERE team_id = ?`
+
+	return r.queryTeam(ctx, query, id(ID))
+}
+
+func (r *TeamRepositorySQLite) GetByName(ctx context.Context, name string) (*model.Team, error) {
+	query := `SELECT ` + teamColumns + ` FROM teams WHERE name = ?`
+
+	return r.queryTeam(ctx, query, name)
+}
+
+func (r *TeamRepositorySQLite) queryTeam(ctx context.Context, query string, args ...any) (*model.Team, error) {
+	team, err := scanTeam(r.database.Querier(ctx).QueryRowContext(ctx, query, args...))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, rules.ErrTeamNotFound
+		}
+		return nil, err
+	}
+
+	return team, nil
+}
+
+func (r *TeamRepositorySQLite) ExistsByName(ctx context.Context, name string) (bool, error) {
+	query := `SELECT EXISTS(SELECT 1 FROM teams WHERE name = ?)`
+
+	var exists bool
+	err := r.database.Querier(ctx).QueryRowContext(ctx, query, name).Scan(&exists)
+	if err != nil {
+		return false, err
+	}
+
+	return exists, nil
+}
+
+func (r *TeamRepositorySQLite) Exists(ctx context.Context, ID model.TeamID) (bool, error) {
+	query := `SELECT EXISTS(SELECT 1 FROM teams WHERE team_id = ?)`
+
+	var exists bool
+	err := r.database.Querier(ctx).QueryRowContext(ctx, query, id(ID)).Scan(&exists)
+	if err != nil {
+		return false, err
+	}
+
+	return exists, nil
+}
+
+func (r *TeamRepositorySQLite) GetMembers(ctx context.Context, ID model.TeamID) ([]*model.User, error) {
+	query := `
+SELECT u.user_id, u.username, u.team_id, u.is_active, u.created_at, u.updated_at
+FROM users u
+INNER JOIN team_memberships m ON m.user_id = u.user_id
+WHERE m.team_id = ?
+`
+
+	rows, err := r.database.Querier(ctx).QueryContext(ctx, query, id(ID))
+	if err != nil {
+		return nil, err
+	}
+
+	users, err := collectUsers(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	members := make([]*model.User, 0, len(users))
+	for i := range users {
+		members = append(members, &users[i])
+	}
+
+	return members, nil
+}
+
+func (r *TeamRepositorySQLite) BulkDeactivateTeam(ctx context.Context, ID model.TeamID) error {
+	query := `
+UPDATE users
+SET is_active = 0, updated_at = ?
+WHERE is_active = 1 AND user_id IN (SELECT user_id FROM team_memberships WHERE team_id = ?)
+`
+
+	_, err := r.database.Querier(ctx).ExecContext(ctx, query, timestamp(time.Now()), id(ID))
+	return err
+}
+
+// Lock only checks that the team exists: transactions already run one at a time on the
+// single connection.
+func (r *TeamRepositorySQLite) Lock(ctx context.Context, ID model.TeamID) error {
+	exists, err := r.Exists(ctx, ID)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return rules.ErrTeamNotFound
+	}
+	return nil
+}
+
+// LockName has nothing to do: transactions already run one at a time on the single connection.
+func (r *TeamRepositorySQLite) LockName(context.Context, string) error {
+	return nil
+}
+
+func (r *TeamRepositorySQLite) CreateWithMembers(ctx context.Context, team *model.Team, members []model.User) error {
+	return r.database.WithinTx(ctx, func(ctx context.Context) error {
+		tx := r.database.Querier(ctx)
+
+		teamQuery := `INSERT INTO teams (team_id, name, parent_team_id, max_reviewers, reviewer_fallback, created_at)
+VALUES (?, ?, ?, ?, ?, ?)`
+
+		_, err := tx.ExecContext(
+			ctx, teamQuery, id(team.TeamID), team.Name, nullableUUID(team.ParentTeamID), team.Settings.MaxReviewers,
+			team.Settings.ReviewerFallback, timestamp(team.CreatedAt),
+		)
+		if err != nil {
+			return err
+		}
+
+		membershipQuery := `INSERT INTO team_memberships (team_id, user_id, weight, created_at)
+VALUES (?, ?, ?, ?)
+ON CONFLICT (team_id, user_id) DO NOTHING`
+
+		for _, member := range members {
+			_, err = tx.ExecContext(
+				ctx, upsertUserQuery, id(member.ID), member.Username, nullableUUID(member.TeamID), member.IsActive,
+				timestamp(member.CreatedAt), timestamp(member.UpdatedAt),
+			)
+			if err != nil {
+				return err
+			}
+
+			_, err = tx.ExecContext(
+				ctx, membershipQuery, id(team.TeamID), id(member.ID), model.DefaultMembershipWeight,
+				timestamp(team.CreatedAt),
+			)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// ApplyImport writes the whole import plan in one transaction. Settings of existing teams are left alone.
+func (r *TeamRepositorySQLite) ApplyImport(ctx context.Context, plan *model.ImportPlan) error {
+	return r.database.WithinTx(ctx, func(ctx context.Context) error {
+		tx := r.database.Querier(ctx)
+
+		teamQuery := `INSERT INTO teams (team_id, name, parent_team_id, created_at)
+VALUES (?, ?, ?, ?)
+ON CONFLICT (team_id) DO UPDATE
+SET name = excluded.name,
+    parent_team_id = excluded.parent_team_id`
+
+		for _, team := range plan.Teams {
+			_, err := tx.ExecContext(
+				ctx, teamQuery, id(team.TeamID), team.Name, nullableUUID(team.ParentTeamID), timestamp(team.CreatedAt),
+			)
+			if err != nil {
+				return err
+			}
+		}
+
+		for _, user := range plan.Users {
+			_, err := tx.ExecContext(
+				ctx, upsertUserQuery, id(user.ID), user.Username, nullableUUID(user.TeamID), user.IsActive,
+				timestamp(user.CreatedAt), timestamp(user.UpdatedAt),
+			)
+			if err != nil {
+				return err
+			}
+		}
+
+		membershipQuery := `INSERT INTO team_memberships (team_id, user_id, weight, created_at)
+VALUES (?, ?, ?, ?)
+ON CONFLICT (team_id, user_id) DO UPDATE
+SET weight = excluded.weight`
+
+		for _, membership := range plan.Memberships {
+			_, err := tx.ExecContext(
+				ctx, membershipQuery, id(membership.TeamID), id(membership.UserID), membership.Weight,
+				timestamp(membership.CreatedAt),
+			)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func (r *TeamRepositorySQLite) GetAll(ctx context.Context) ([]model.Team, error) {
+	query := `SELECT ` + teamColumns + ` FROM teams ORDER BY name`
+
+	return r.queryTeams(ctx, query)
+}
+
+// GetAncestors returns the parent chain of the team, nearest parent first.
+// The query stops at a depth of the number of teams: no chain is longer, so only a parent
+// cycle reaches it, and the query ends instead of recursing forever.
+func (r *TeamRepositorySQLite) GetAncestors(ctx context.Context, ID model.TeamID) ([]model.Team, error) {
+	query := `
+WITH RECURSIVE ancestors AS (
+    SELECT p.team_id, p.name, p.parent_team_id, p.max_reviewers, p.reviewer_fallback, p.created_at, 1 AS depth
+    FROM teams t
+    INNER JOIN teams p ON p.team_id = t.parent_team_id
+    WHERE t.team_id = ?
+    UNION ALL
+    SELECT p.team_id, p.name, p.parent_team_id, p.max_reviewers, p.reviewer_fallback, p.created_at, a.depth + 1
+    FROM teams p
+    INNER JOIN ancestors a ON p.team_id = a.parent_team_id
+    WHERE a.depth < (SELECT COUNT(*) FROM teams)
+)
+SELECT ` + teamColumns + ` FROM ancestors
+ORDER BY depth
+`
+
+	return r.queryTeams(ctx, query, id(ID))
+}
+
+// GetDescendants returns every team below the given one, level by level. Like GetAncestors,
+// it stops at a depth of the number of teams.
+func (r *TeamRepositorySQLite) GetDescendants(ctx context.Context, ID model.TeamID) ([]model.Team, error) {
+	query := `
+WITH RECURSIVE descendants AS (
+    SELECT team_id, name, parent_team_id, max_reviewers, reviewer_fallback, created_at, 1 AS depth
+    FROM teams
+    WHERE parent_team_id = ?
+    UNION ALL
+    SELECT c.team_id, c.name, c.parent_team_id, c.max_reviewers, c.reviewer_fallback, c.created_at, d.depth + 1
+    FROM teams c
+    INNER JOIN descendants d ON c.parent_team_id = d.team_id
+    WHERE d.depth < (SELECT COUNT(*) FROM teams)
+)
+SELECT ` + teamColumns + ` FROM descendants
+ORDER BY depth, name
+`
+
+	return r.queryTeams(ctx, query, id(ID))
+}
+
+func (r *TeamRepositorySQLite) queryTeams(ctx context.Context, query string, args ...any) ([]model.Team, error) {
+	var teams []model.Team
+	err := r.streamTeams(ctx, query, args, func(team *model.Team) error {
+		teams = append(teams, *team)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return teams, nil
+}
+
+// StreamAll walks every team, parents before their children.
+func (r *TeamRepositorySQLite) StreamAll(ctx context.Context, fn func(team *model.Team) error) error {
+	query := `
+WITH RECURSIVE tree AS (
+    SELECT team_id, 0 AS depth
+    FROM teams
+    WHERE parent_team_id IS NULL
+    UNION ALL
+    SELECT t.team_id, tree.depth + 1
+    FROM teams t
+    INNER JOIN tree ON t.parent_team_id = tree.team_id
+    WHERE tree.depth < (SELECT COUNT(*) FROM teams)
+)
+SELECT t.team_id, t.name, t.parent_team_id, t.max_reviewers, t.reviewer_fallback, t.created_at
+FROM teams t
+INNER JOIN tree ON tree.team_id = t.team_id
+ORDER BY tree.depth, t.name
+`
+
+	return r.streamTeams(ctx, query, nil, fn)
+}
+
+func (r *TeamRepositorySQLite) streamTeams(
+	ctx context.Context, query string, args []any, fn func(team *model.Team) error,
+) error {
+	rows, err := r.database.Querier(ctx).QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		team, err := scanTeam(rows)
+		if err != nil {
+			return err
+		}
+		if err := fn(team); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
@@ -0,0 +1,99 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+
+	"pull-request-review/internal/domain/model"
+	"pull-request-review/internal/domain/ports/repository"
+	"pull-request-review/internal/domain/rules"
+	"pull-request-review/internal/infrastructure/database"
+)
+
+type TeamRoleRepositorySQLite struct {
+	database *database.SQLiteDatabase
+}
+
+func NewTeamRoleRepository(database *database.SQLiteDatabase) repository.TeamRoleRepository {
+	return &TeamRoleRepositorySQLite{database: database}
+}
+
+func (r *TeamRoleRepositorySQLite) SetRole(
+	ctx context.Context, teamID model.TeamID, userID model.UserID, role model.TeamRole,
+) error {
+	query := `
+INSERT INTO team_roles (team_id, user_id, role, granted_at)
+VALUES (?, ?, ?, ?)
+ON CONFLICT (team_id, user_id) DO UPDATE
+SET role = excluded.role,
+    granted_at = excluded.granted_at
+`
+
+	_, err := r.database.Querier(ctx).ExecContext(ctx, query, id(teamID), id(userID), role, timestamp(time.Now()))
+	return err
+}
+
+func (r *TeamRoleRepositorySQLite) DeleteRole(ctx context.Context, teamID model.TeamID, userID model.UserID) error {
+	query := `
+DELETE FROM team_roles
+WHERE team_id = ? AND user_id = ?
+`
+
+	_, err := r.database.Querier(ctx).ExecContext(ctx, query, id(teamID), id(userID))
+	return err
+}
+
+func (r *TeamRoleRepositorySQLite) GetRole(
+	ctx context.Context, teamID model.TeamID, userID model.UserID,
+) (model.TeamRole, error) {
+	query := `
+SELECT role FROM team_roles
+WHERE team_id = ? AND user_id = ?
+`
+
+	var role model.TeamRole
+	err := r.database.Querier(ctx).QueryRowContext(ctx, query, id(teamID), id(userID)).Scan(&role)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", rules.ErrNotFound
+		}
+		return "", err
+	}
+
+	return role, nil
+}
+
+func (r *TeamRoleRepositorySQLite) StreamAll(ctx context.Context, fn func(grant *model.TeamRoleGrant) error) error {
+	query := `
+SELECT team_id, user_id, role, granted_at
+FROM team_roles
+ORDER BY granted_at, team_id, user_id
+`
+
+	rows, err := r.database.Querier(ctx).QueryContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var grant model.TeamRoleGrant
+		var teamID, userID uuid.UUID
+		err := rows.Scan(&teamID, &userID, &grant.Role, &grant.GrantedAt)
+		if err != nil {
+			return err
+		}
+
+		grant.TeamID = model.TeamID(teamID)
+		grant.UserID = model.UserID(userID)
+		if err := fn(&grant); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
@@ -0,0 +1,212 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"slices"
+	"time"
+
+	"pull-request-review/internal/domain/model"
+	"pull-request-review/internal/domain/ports/repository"
+	"pull-request-review/internal/domain/rules"
+	"pull-request-review/internal/infrastructure/database"
+)
+
+type UserRepositorySQLite struct {
+	database *database.SQLiteDatabase
+}
+
+func NewUserRepository(database *database.SQLiteDatabase) repository.UserRepository {
+	return &UserRepositorySQLite{database: database}
+}
+
+func (r *UserRepositorySQLite) Insert(ctx context.Context, user *model.User) error {
+	query := `INSERT INTO users (user_id, username, team_id, is_active, created_at, updated_at)
+VALUES (?, ?, ?, ?, ?, ?)
+ON CONFLICT (user_id) DO NOTHING
+`
+
+	_, err := r.database.Querier(ctx).ExecContext(
+		ctx, query,
+		id(user.ID),
+		user.Username,
+		nullableUUID(user.TeamID),
+		user.IsActive,
+		timestamp(user.CreatedAt),
+		timestamp(user.UpdatedAt),
+	)
+	return err
+}
+
+func (r *UserRepositorySQLite) Update(ctx context.Context, user *model.User) error {
+	query := `UPDATE users
+SET username = ?, team_id = ?, is_active = ?, updated_at = ?
+WHERE user_id = ?
+`
+
+	result, err := r.database.Querier(ctx).ExecContext(
+		ctx, query,
+		user.Username,
+		nullableUUID(user.TeamID),
+		user.IsActive,
+		timestamp(user.UpdatedAt),
+		id(user.ID),
+	)
+	if err != nil {
+		return err
+	}
+
+	return requireAffected(result, rules.ErrUserNotFound)
+}
+
+func (r *UserRepositorySQLite) Upsert(ctx context.Context, user *model.User) error {
+	_, err := r.database.Querier(ctx).ExecContext(
+		ctx, upsertUserQuery,
+		id(user.ID),
+		user.Username,
+		nullableUUID(user.TeamID),
+		user.IsActive,
+		timestamp(user.CreatedAt),
+		timestamp(user.UpdatedAt),
+	)
+	return err
+}
+
+const upsertUserQuery = `INSERT INTO users (user_id, username, team_id, is_active, created_at, updated_at)
+VALUES (?, ?, ?, ?, ?, ?)
+ON CONFLICT (user_id) DO UPDATE
+SET username = excluded.username,
+    team_id = excluded.team_id,
+    is_active = excluded.is_active,
+    updated_at = excluded.updated_at`
+
+func (r *UserRepositorySQLite) UpdateActivity(ctx context.Context, ID model.UserID, isActive bool) error {
+	query := `
+UPDATE users
+SET is_active = ?, updated_at = ?
+WHERE user_id = ?`
+
+	result, err := r.database.Querier(ctx).ExecContext(ctx, query, isActive, timestamp(time.Now()), id(ID))
+	if err != nil {
+		return err
+	}
+
+	return requireAffected(result, rules.ErrUserNotFound)
+}
+
+func (r *UserRepositorySQLite) GetByID(ctx context.Context, ID model.UserID) (*model.User, error) {
+	query := `SELECT ` + userColumns + ` FROM users WHERE user_id = ?`
+
+	user, err := scanUser(r.database.Querier(ctx).QueryRowContext(ctx, query, id(ID)))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, rules.ErrUserNotFound
+		}
+		return nil, err
+	}
+	return user, nil
+}
+
+func (r *UserRepositorySQLite) GetByIDs(ctx context.Context, IDs []model.UserID) ([]model.User, error) {
+	var users []model.User
+	for chunk := range slices.Chunk(IDs, batchRows) {
+		query := `SELECT ` + userColumns + ` FROM users WHERE user_id IN (` + placeholders(len(chunk)) + `)`
+
+		rows, err := r.database.Querier(ctx).QueryContext(ctx, query, idArgs(chunk)...)
+		if err != nil {
+			return nil, err
+		}
+		found, err := collectUsers(rows)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, found...)
+	}
+
+	return users, nil
+}
+
+func (r *UserRepositorySQLite) GetByTeam(ctx context.Context, teamID model.TeamID) ([]model.User, error) {
+	query := `
+SELECT u.user_id, u.username, u.team_id, u.is_active, u.created_at, u.updated_at
+FROM users u
+INNER JOIN team_memberships m ON m.user_id = u.user_id
+WHERE m.team_id = ?
+`
+
+	rows, err := r.database.Querier(ctx).QueryContext(ctx, query, id(teamID))
+	if err != nil {
+		return nil, err
+	}
+	return collectUsers(rows)
+}
+
+func (r *UserRepositorySQLite) Exists(ctx context.Context, ID model.UserID) (bool, error) {
+	query := `SELECT EXISTS(SELECT 1 FROM users WHERE user_id = ?)`
+
+	var exists bool
+	err := r.database.Querier(ctx).QueryRowContext(ctx, query, id(ID)).Scan(&exists)
+	if err != nil {
+		return false, err
+	}
+	return exists, nil
+}
+
+func (r *UserRepositorySQLite) GetActiveByTeamExcluding(
+	ctx context.Context, teamID model.TeamID, excludedUserIDs []model.UserID,
+) ([]model.User, error) {
+	query := `
+SELECT u.user_id, u.username, u.team_id, u.is_active, u.created_at, u.updated_at
+FROM users u
+INNER JOIN team_memberships m ON m.user_id = u.user_id
+WHERE m.team_id = ? AND u.is_active = 1`
+
+	args := []any{id(teamID)}
+	if len(excludedUserIDs) > 0 {
+		query += ` AND u.user_id NOT IN (` + placeholders(len(excludedUserIDs)) + `)`
+		for _, excluded := range excludedUserIDs {
+			args = append(args, id(excluded))
+		}
+	}
+
+	rows, err := r.database.Querier(ctx).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	return collectUsers(rows)
+}
+
+func (r *UserRepositorySQLite) StreamAll(ctx context.Context, fn func(user *model.User) error) error {
+	query := `SELECT ` + userColumns + ` FROM users ORDER BY created_at, user_id`
+
+	rows, err := r.database.Querier(ctx).QueryContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return err
+		}
+		if err := fn(user); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+// requireAffected turns an update or delete that matched nothing into notFound.
+func requireAffected(result sql.Result, notFound error) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return notFound
+	}
+	return nil
+}
@@ -0,0 +1,162 @@
+package repository
+
+import (
+	"context"
+	"pull-request-review/internal/domain/model"
+	"pull-request-review/internal/domain/ports/repository"
+	"pull-request-review/internal/domain/rules"
+	"pull-request-review/internal/infrastructure/database"
+)
+
+type TeamMembershipRepositoryPgx struct {
+	database *database.Database
+}
+
+func NewTeamMembershipRepository(database *database.Database) repository.TeamMembershipRepository {
+	return &TeamMembershipRepositoryPgx{database: database}
+}
+
+func (r *TeamMembershipRepositoryPgx) Add(ctx context.Context, membership *model.TeamMembership) error {
+	query := `
+INSERT INTO team_memberships (team_id, user_id, weight, created_at)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (team_id, user_id) DO UPDATE
+SET weight = EXCLUDED.weight
+`
+
+	_, err := r.database.Querier(ctx).Exec(
+		ctx, query,
+		membership.TeamID,
+		membership.UserID,
+		membership.Weight,
+		membership.CreatedAt,
+	)
+	return err
+}
+
+func (r *TeamMembershipRepositoryPgx) Remove(ctx context.Context, teamID model.TeamID, userID model.UserID) error {
+	query := `
+DELETE FROM team_memberships
+WHERE team_id = $1 AND user_id = $2
+`
+
+	result, err := r.database.Querier(ctx).Exec(ctx, query, teamID, userID)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return rules.ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *TeamMembershipRepositoryPgx) Exists(ctx context.Context, teamID model.TeamID, userID model.UserID) (
+	bool, error,
+) {
+	query := `SELECT EXISTS(SELECT 1 FROM team_memberships WHERE team_id = $1 AND user_id = $2)`
+
+	var exists bool
+	err := r.database.Querier(ctx).QueryRow(ctx, query, teamID, userID).Scan(&exists)
+	if err != nil {
+		return false, err
+	}
+
+	return exists, nil
+}
+
+func (r *TeamMembershipRepositoryPgx) GetByUser(ctx context.Context, userID model.UserID) (
+	[]model.TeamMembership, error,
+) {
+	query := `
+SELECT m.team_id, m.user_id, t.name, m.weight, m.created_at
+FROM team_memberships m
+INNER JOIN teams t ON t.team_id = m.team_id
+WHERE m.user_id = $1
+ORDER BY m.created_at, t.name
+`
+
+	return r.query(ctx, query, userID)
+}
+
+func (r *TeamMembershipRepositoryPgx) GetByTeam(ctx context.Context, teamID model.TeamID) (
+	[]model.TeamMembership, error,
+) {
+	query := `
+SELECT m.team_id, m.user_id, t.name, m.weight, m.created_at
+FROM team_memberships m
+INNER JOIN teams t ON t.team_id = m.team_id
+WHERE m.team_id = $1
+`
+
+	return r.query(ctx, query, teamID)
+}
+
+func (r *TeamMembershipRepositoryPgx) query(ctx context.Context, query string, args ...any) (
+	[]model.TeamMembership, error,
+) {
+	rows, err := r.database.Querier(ctx).Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var memberships []model.TeamMembership
+	for rows.Next() {
+		var membership model.TeamMembership
+		err := rows.Scan(
+			&membership.TeamID,
+			&membership.UserID,
+			&membership.TeamName,
+			&membership.Weight,
+			&membership.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		memberships = append(memberships, membership)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return memberships, nil
+}
+
+func (r *TeamMembershipRepositoryPgx) StreamAll(
+	ctx context.Context, fn func(membership *model.TeamMembership) error,
+) error {
+	query := `
+SELECT m.team_id, m.user_id, t.name, m.weight, m.created_at
+FROM team_memberships m
+INNER JOIN teams t ON t.team_id = m.team_id
+ORDER BY m.created_at, m.team_id, m.user_id
+`
+
+	rows, err := r.database.Querier(ctx).Query(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var membership model.TeamMembership
+		err := rows.Scan(
+			&membership.TeamID,
+			&membership.UserID,
+			&membership.TeamName,
+			&membership.Weight,
+			&membership.CreatedAt,
+		)
+		if err != nil {
+			return err
+		}
+		if err := fn(&membership); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
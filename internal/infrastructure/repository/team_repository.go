@@ -3,7 +3,6 @@ package repository
 import (
 	"context"
 	"errors"
-	"fmt"
 	"github.com/jackc/pgx/v5"
 	"pull-request-review/internal/domain/model"
 	"pull-request-review/internal/domain/ports/repository"
@@ -19,14 +18,35 @@ func NewTeamRepository(database *database.Database) repository.TeamRepository {
 	return &TeamRepositoryPgx{database: database}
 }
 
+const teamColumns = `team_id, name, parent_team_id, max_reviewers, reviewer_fallback, created_at`
+
+func scanTeam(row pgx.Row) (*model.Team, error) {
+	var team model.Team
+	err := row.Scan(
+		&team.TeamID,
+		&team.Name,
+		&team.ParentTeamID,
+		&team.Settings.MaxReviewers,
+		&team.Settings.ReviewerFallback,
+		&team.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &team, nil
+}
+
 func (r *TeamRepositoryPgx) Create(ctx context.Context, team *model.Team) error {
 	query := `
-INSERT INTO teams (team_id, name, created_at)
-VALUES ($1, $2, $3)
+INSERT INTO teams (team_id, name, parent_team_id, max_reviewers, reviewer_fallback, created_at)
+VALUES ($1, $2, $3, $4, $5, $6)
 ON CONFLICT DO NOTHING
 `
 
-	_, err := r.database.GetPool().Exec(ctx, query, team.TeamID, team.Name, team.CreatedAt)
+	_, err := r.database.Querier(ctx).Exec(
+		ctx, query, team.TeamID, team.Name, nullableUUID(team.ParentTeamID), team.Settings.MaxReviewers,
+		team.Settings.ReviewerFallback, team.CreatedAt,
+	)
 
 	if err != nil {
 		return err
@@ -37,11 +57,14 @@ ON CONFLICT DO NOTHING
 func (r *TeamRepositoryPgx) Update(ctx context.Context, team *model.Team) error {
 	query := `
 UPDATE teams
-SET name = $1 
-WHERE team_id = $2
+SET name = $1, parent_team_id = $2, max_reviewers = $3, reviewer_fallback = $4
+WHERE team_id = $5
 `
 
-	_, err := r.database.GetPool().Exec(ctx, query, team.Name, team.TeamID)
+	_, err := r.database.Querier(ctx).Exec(
+		ctx, query, team.Name, nullableUUID(team.ParentTeamID), team.Settings.MaxReviewers,
+		team.Settings.ReviewerFallback, team.TeamID,
+	)
 
 	if err != nil {
 		return err
@@ -50,12 +73,10 @@ WHERE team_id = $2
 }
 
 func (r *TeamRepositoryPgx) GetByID(ctx context.Context, ID model.TeamID) (*model.Team, error) {
-	query := `
-SELECT team_id, name, created_at FROM teams
+	query := `SELECT ` + teamColumns + ` FROM teams
 WHERE team_id = $1
 `
-	var team model.Team
-	err := r.database.GetPool().QueryRow(ctx, query, ID).Scan(&team.TeamID, &team.Name, &team.CreatedAt)
+	team, err := scanTeam(r.database.Querier(ctx).QueryRow(ctx, query, ID))
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, rules.ErrTeamNotFound
@@ -64,16 +85,14 @@ WHERE team_id = $1
 		return nil, err
 	}
 
-	return &team, nil
+	return team, nil
 }
 
 func (r *TeamRepositoryPgx) GetByName(ctx context.Context, name string) (*model.Team, error) {
-	query := `
-SELECT team_id, name, created_at FROM teams
+	query := `SELECT ` + teamColumns + ` FROM teams
 WHERE name = $1
 `
-	var team model.Team
-	err := r.database.GetPool().QueryRow(ctx, query, name).Scan(&team.TeamID, &team.Name, &team.CreatedAt)
+	team, err := scanTeam(r.database.Querier(ctx).QueryRow(ctx, query, name))
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, rules.ErrTeamNotFound
@@ -82,14 +101,14 @@ WHERE name = $1
 		return nil, err
 	}
 
-	return &team, nil
+	return team, nil
 }
 
 func (r *TeamRepositoryPgx) ExistsByName(ctx context.Context, name string) (bool, error) {
 	query := `SELECT EXISTS(SELECT 1 FROM teams WHERE name = $1)`
 
 	var exists bool
-	err := r.database.GetPool().QueryRow(ctx, query, name).Scan(&exists)
+	err := r.database.Querier(ctx).QueryRow(ctx, query, name).Scan(&exists)
 	if err != nil {
 		return false, err
 	}
@@ -101,7 +120,7 @@ func (r *TeamRepositoryPgx) Exists(ctx context.Context, ID model.TeamID) (bool,
 	query := `SELECT EXISTS(SELECT 1 FROM teams WHERE team_id = $1)`
 
 	var exists bool
-	err := r.database.GetPool().QueryRow(ctx, query, ID).Scan(&exists)
+	err := r.database.Querier(ctx).QueryRow(ctx, query, ID).Scan(&exists)
 	if err != nil {
 		return false, err
 	}
@@ -111,11 +130,12 @@ func (r *TeamRepositoryPgx) Exists(ctx context.Context, ID model.TeamID) (bool,
 
 func (r *TeamRepositoryPgx) GetMembers(ctx context.Context, ID model.TeamID) ([]*model.User, error) {
 	query := `
-SELECT user_id, username, team_id, is_active, created_at, updated_at 
-FROM users
-WHERE team_id = $1
+SELECT u.user_id, u.username, u.team_id, u.is_active, u.created_at, u.updated_at
+FROM users u
+INNER JOIN team_memberships m ON m.user_id = u.user_id
+WHERE m.team_id = $1
 `
-	rows, err := r.database.GetPool().Query(ctx, query, ID)
+	rows, err := r.database.Querier(ctx).Query(ctx, query, ID)
 	if err != nil {
 		return nil, err
 	}
@@ -142,38 +162,51 @@ func (r *TeamRepositoryPgx) BulkDeactivateTeam(ctx context.Context, ID model.Tea
 	query := `
 UPDATE users
 SET is_active = false, updated_at = now()
-WHERE team_id = $1 AND is_active = true
+WHERE is_active = true AND user_id IN (SELECT user_id FROM team_memberships WHERE team_id = $1)
 `
-	_, err := r.database.GetPool().Exec(ctx, query, ID)
+	_, err := r.database.Querier(ctx).Exec(ctx, query, ID)
 	return err
 }
 
-func (r *TeamRepositoryPgx) CreateWithMembers(ctx context.Context, team *model.Team, members []model.User) error {
-	tx, err := r.database.GetPool().Begin(ctx)
-	if err != nil {
-		return err
+// Lock holds the team row until the transaction ends, so reviewer selection in the team runs
+// one request at a time and each one sees the reviews the previous one assigned. It takes a
+// NO KEY UPDATE lock, which does not block inserts that only reference the team.
+func (r *TeamRepositoryPgx) Lock(ctx context.Context, ID model.TeamID) error {
+	query := `SELECT team_id FROM teams WHERE team_id = $1 FOR NO KEY UPDATE`
+
+	var teamID model.TeamID
+	err := r.database.Querier(ctx).QueryRow(ctx, query, ID).Scan(&teamID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return rules.ErrTeamNotFound
 	}
-	defer func() {
-		var e error
-		if err == nil {
-			e = tx.Commit(ctx)
-		} else {
-			e = tx.Rollback(ctx)
-		}
+	return err
+}
 
-		if err == nil && e != nil {
-			err = fmt.Errorf("finishing transaction: %w", e)
-		}
-	}()
+// LockName holds the team name until the transaction ends, so two transactions that want the
+// same name for a team take turns checking that it is free. The name needs no row to lock.
+func (r *TeamRepositoryPgx) LockName(ctx context.Context, name string) error {
+	query := `SELECT pg_advisory_xact_lock(hashtextextended('teams.name:' || $1, 0))`
 
-	teamQuery := `INSERT INTO teams (team_id, name, created_at) VALUES ($1, $2, $3)`
-	_, err = tx.Exec(ctx, teamQuery, team.TeamID, team.Name, team.CreatedAt)
-	if err != nil {
-		return err
-	}
+	_, err := r.database.Querier(ctx).Exec(ctx, query, name)
+	return err
+}
+
+func (r *TeamRepositoryPgx) CreateWithMembers(ctx context.Context, team *model.Team, members []model.User) error {
+	return r.database.WithinTx(ctx, func(ctx context.Context) error {
+		tx := r.database.Querier(ctx)
+
+		teamQuery := `INSERT INTO teams (team_id, name, parent_team_id, max_reviewers, reviewer_fallback, created_at)
+VALUES ($1, $2, $3, $4, $5, $6)`
+		_, err := tx.Exec(
+			ctx, teamQuery, team.TeamID, team.Name, nullableUUID(team.ParentTeamID), team.Settings.MaxReviewers,
+			team.Settings.ReviewerFallback, team.CreatedAt,
+		)
+		if err != nil {
+			return err
+		}
 
-	for _, member := range members {
-		upsertQuery := `INSERT INTO users (user_id, username, team_id, is_active, created_at, updated_at)
+		for _, member := range members {
+			upsertQuery := `INSERT INTO users (user_id, username, team_id, is_active, created_at, updated_at)
 VALUES ($1, $2, $3, $4, $5, $6)
 ON CONFLICT (user_id) DO UPDATE 
 SET username = EXCLUDED.username, 
@@ -181,14 +214,189 @@ SET username = EXCLUDED.username,
     is_active = EXCLUDED.is_active, 
     updated_at = EXCLUDED.updated_at`
 
-		_, err = tx.Exec(
-			ctx, upsertQuery, member.ID, member.Username, member.TeamID, member.IsActive, member.CreatedAt,
-			member.UpdatedAt,
-		)
+			_, err := tx.Exec(
+				ctx, upsertQuery, member.ID, member.Username, member.TeamID, member.IsActive, member.CreatedAt,
+				member.UpdatedAt,
+			)
+			if err != nil {
+				return err
+			}
+
+			membershipQuery := `INSERT INTO team_memberships (team_id, user_id, weight, created_at)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (team_id, user_id) DO NOTHING`
+
+			_, err = tx.Exec(ctx, membershipQuery, team.TeamID, member.ID, model.DefaultMembershipWeight, team.CreatedAt)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// ApplyImport writes the whole import plan in one transaction. Settings of existing teams are left alone.
+func (r *TeamRepositoryPgx) ApplyImport(ctx context.Context, plan *model.ImportPlan) error {
+	return r.database.WithinTx(ctx, func(ctx context.Context) error {
+		tx := r.database.Querier(ctx)
+
+		teamQuery := `INSERT INTO teams (team_id, name, parent_team_id, created_at)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (team_id) DO UPDATE
+SET name = EXCLUDED.name,
+    parent_team_id = EXCLUDED.parent_team_id`
+
+		for _, team := range plan.Teams {
+			_, err := tx.Exec(ctx, teamQuery, team.TeamID, team.Name, nullableUUID(team.ParentTeamID), team.CreatedAt)
+			if err != nil {
+				return err
+			}
+		}
+
+		userQuery := `INSERT INTO users (user_id, username, team_id, is_active, created_at, updated_at)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (user_id) DO UPDATE
+SET username = EXCLUDED.username,
+    team_id = EXCLUDED.team_id,
+    is_active = EXCLUDED.is_active,
+    updated_at = EXCLUDED.updated_at`
+
+		for _, user := range plan.Users {
+			_, err := tx.Exec(
+				ctx, userQuery, user.ID, user.Username, nullableUUID(user.TeamID), user.IsActive, user.CreatedAt,
+				user.UpdatedAt,
+			)
+			if err != nil {
+				return err
+			}
+		}
+
+		membershipQuery := `INSERT INTO team_memberships (team_id, user_id, weight, created_at)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (team_id, user_id) DO UPDATE
+SET weight = EXCLUDED.weight`
+
+		for _, membership := range plan.Memberships {
+			_, err := tx.Exec(
+				ctx, membershipQuery, membership.TeamID, membership.UserID, membership.Weight, membership.CreatedAt,
+			)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func (r *TeamRepositoryPgx) GetAll(ctx context.Context) ([]model.Team, error) {
+	query := `SELECT ` + teamColumns + ` FROM teams ORDER BY name`
+
+	return r.queryTeams(ctx, query)
+}
+
+// GetAncestors returns the parent chain of the team, nearest parent first.
+// The query stops at a depth of the number of teams: no chain is longer, so only a parent
+// cycle reaches it, and the query ends instead of recursing forever.
+func (r *TeamRepositoryPgx) GetAncestors(ctx context.Context, ID model.TeamID) ([]model.Team, error) {
+	query := `
+WITH RECURSIVE ancestors AS (
+    SELECT p.team_id, p.name, p.parent_team_id, p.max_reviewers, p.reviewer_fallback, p.created_at, 1 AS depth
+    FROM teams t
+    INNER JOIN teams p ON p.team_id = t.parent_team_id
+    WHERE t.team_id = $1
+    UNION ALL
+    SELECT p.team_id, p.name, p.parent_team_id, p.max_reviewers, p.reviewer_fallback, p.created_at, a.depth + 1
+    FROM teams p
+    INNER JOIN ancestors a ON p.team_id = a.parent_team_id
+    WHERE a.depth < (SELECT COUNT(*) FROM teams)
+)
+SELECT ` + teamColumns + ` FROM ancestors
+ORDER BY depth
+`
+
+	return r.queryTeams(ctx, query, ID)
+}
+
+// GetDescendants returns every team below the given one, level by level. Like GetAncestors,
+// it stops at a depth of the number of teams.
+func (r *TeamRepositoryPgx) GetDescendants(ctx context.Context, ID model.TeamID) ([]model.Team, error) {
+	query := `
+WITH RECURSIVE descendants AS (
+    SELECT team_id, name, parent_team_id, max_reviewers, reviewer_fallback, created_at, 1 AS depth
+    FROM teams
+    WHERE parent_team_id = $1
+    UNION ALL
+    SELECT c.team_id, c.name, c.parent_team_id, c.max_reviewers, c.reviewer_fallback, c.created_at, d.depth + 1
+    FROM teams c
+    INNER JOIN descendants d ON c.parent_team_id = d.team_id
+    WHERE d.depth < (SELECT COUNT(*) FROM teams)
+)
+SELECT ` + teamColumns + ` FROM descendants
+ORDER BY depth, name
+`
+
+	return r.queryTeams(ctx, query, ID)
+}
+
+func (r *TeamRepositoryPgx) queryTeams(ctx context.Context, query string, args ...any) ([]model.Team, error) {
+	rows, err := r.database.Querier(ctx).Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var teams []model.Team
+	for rows.Next() {
+		team, err := scanTeam(rows)
+		if err != nil {
+			return nil, err
+		}
+		teams = append(teams, *team)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return teams, nil
+}
+
+// StreamAll walks every team, parents before their children.
+func (r *TeamRepositoryPgx) StreamAll(ctx context.Context, fn func(team *model.Team) error) error {
+	query := `
+WITH RECURSIVE tree AS (
+    SELECT team_id, 0 AS depth
+    FROM teams
+    WHERE parent_team_id IS NULL
+    UNION ALL
+    SELECT t.team_id, tree.depth + 1
+    FROM teams t
+    INNER JOIN tree ON t.parent_team_id = tree.team_id
+    WHERE tree.depth < (SELECT COUNT(*) FROM teams)
+)
+SELECT t.team_id, t.name, t.parent_team_id, t.max_reviewers, t.reviewer_fallback, t.created_at
+FROM teams t
+INNER JOIN tree ON tree.team_id = t.team_id
+ORDER BY tree.depth, t.name
+`
+
+	rows, err := r.database.Querier(ctx).Query(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		team, err := scanTeam(rows)
 		if err != nil {
 			return err
 		}
+		if err := fn(team); err != nil {
+			return err
+		}
 	}
 
-	return nil
+	return rows.Err()
 }
\ No newline at end of file
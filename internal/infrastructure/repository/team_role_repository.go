@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"github.com/jackc/pgx/v5"
+	"pull-request-review/internal/domain/model"
+	"pull-request-review/internal/domain/ports/repository"
+	"pull-request-review/internal/domain/rules"
+	"pull-request-review/internal/infrastructure/database"
+)
+
+type TeamRoleRepositoryPgx struct {
+	database *database.Database
+}
+
+func NewTeamRoleRepository(database *database.Database) repository.TeamRoleRepository {
+	return &TeamRoleRepositoryPgx{database: database}
+}
+
+func (r *TeamRoleRepositoryPgx) SetRole(
+	ctx context.Context, teamID model.TeamID, userID model.UserID, role model.TeamRole,
+) error {
+	query := `
+INSERT INTO team_roles (team_id, user_id, role, granted_at)
+VALUES ($1, $2, $3, now())
+ON CONFLICT (team_id, user_id) DO UPDATE
+SET role = EXCLUDED.role,
+    granted_at = EXCLUDED.granted_at
+`
+
+	_, err := r.database.Querier(ctx).Exec(ctx, query, teamID, userID, role)
+	return err
+}
+
+func (r *TeamRoleRepositoryPgx) DeleteRole(ctx context.Context, teamID model.TeamID, userID model.UserID) error {
+	query := `
+DELETE FROM team_roles
+WHERE team_id = $1 AND user_id = $2
+`
+
+	_, err := r.database.Querier(ctx).Exec(ctx, query, teamID, userID)
+	return err
+}
+
+func (r *TeamRoleRepositoryPgx) GetRole(
+	ctx context.Context, teamID model.TeamID, userID model.UserID,
+) (model.TeamRole, error) {
+	query := `
+SELECT role FROM team_roles
+WHERE team_id = $1 AND user_id = $2
+`
+
+	var role model.TeamRole
+	err := r.database.Querier(ctx).QueryRow(ctx, query, teamID, userID).Scan(&role)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", rules.ErrNotFound
+		}
+		return "", err
+	}
+
+	return role, nil
+}
+
+func (r *TeamRoleRepositoryPgx) StreamAll(ctx context.Context, fn func(grant *model.TeamRoleGrant) error) error {
+	query := `
+SELECT team_id, user_id, role, granted_at
+FROM team_roles
+ORDER BY granted_at, team_id, user_id
+`
+
+	rows, err := r.database.Querier(ctx).Query(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var grant model.TeamRoleGrant
+		err := rows.Scan(&grant.TeamID, &grant.UserID, &grant.Role, &grant.GrantedAt)
+		if err != nil {
+			return err
+		}
+		if err := fn(&grant); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
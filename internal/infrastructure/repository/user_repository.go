@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"errors"
+	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	"pull-request-review/internal/domain/model"
 	"pull-request-review/internal/domain/ports/repository"
@@ -24,11 +25,11 @@ VALUES ($1, $2, $3, $4, $5, $6)
 ON CONFLICT (user_id) DO NOTHING 
 `
 
-	_, err := r.database.GetPool().Exec(
+	_, err := r.database.Querier(ctx).Exec(
 		ctx, query,
 		user.ID,
 		user.Username,
-		user.TeamID,
+		nullableUUID(user.TeamID),
 		user.IsActive,
 		user.CreatedAt,
 		user.UpdatedAt,
@@ -47,10 +48,10 @@ SET username = $1, team_id = $2, is_active = $3, updated_at = $4
 WHERE user_id = $5
 `
 
-	result, err := r.database.GetPool().Exec(
+	result, err := r.database.Querier(ctx).Exec(
 		ctx, query,
 		user.Username,
-		user.TeamID,
+		nullableUUID(user.TeamID),
 		user.IsActive,
 		user.UpdatedAt,
 		user.ID,
@@ -77,11 +78,11 @@ SET username = EXCLUDED.username,
     updated_at = EXCLUDED.updated_at
 `
 
-	_, err := r.database.GetPool().Exec(
+	_, err := r.database.Querier(ctx).Exec(
 		ctx, query,
 		user.ID,
 		user.Username,
-		user.TeamID,
+		nullableUUID(user.TeamID),
 		user.IsActive,
 		user.CreatedAt,
 		user.UpdatedAt,
@@ -100,7 +101,7 @@ UPDATE users
 SET is_active = $1, updated_at = now()
 WHERE user_id = $2`
 
-	result, err := r.database.GetPool().Exec(ctx, query, isActive, ID)
+	result, err := r.database.Querier(ctx).Exec(ctx, query, isActive, ID)
 	if err != nil {
 		return err
 	}
@@ -118,7 +119,7 @@ SELECT user_id, username, team_id, is_active, created_at, updated_at FROM users
 `
 	var user model.User
 
-	err := r.database.GetPool().QueryRow(ctx, query, ID).Scan(
+	err := r.database.Querier(ctx).QueryRow(ctx, query, ID).Scan(
 		&user.ID,
 		&user.Username,
 		&user.TeamID,
@@ -136,12 +137,50 @@ SELECT user_id, username, team_id, is_active, created_at, updated_at FROM users
 	return &user, nil
 }
 
+func (r *UserRepositoryPgx) GetByIDs(ctx context.Context, IDs []model.UserID) ([]model.User, error) {
+	query := `
+SELECT user_id, username, team_id, is_active, created_at, updated_at FROM users WHERE user_id = ANY($1)
+`
+
+	rows, err := r.database.Querier(ctx).Query(ctx, query, IDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var users []model.User
+
+	for rows.Next() {
+		var user model.User
+		err := rows.Scan(
+			&user.ID,
+			&user.Username,
+			&user.TeamID,
+			&user.IsActive,
+			&user.CreatedAt,
+			&user.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return users, nil
+}
+
 func (r *UserRepositoryPgx) GetByTeam(ctx context.Context, teamID model.TeamID) ([]model.User, error) {
 	query := `
-SELECT user_id, username, team_id, is_active, created_at, updated_at FROM users WHERE team_id = $1
+SELECT u.user_id, u.username, u.team_id, u.is_active, u.created_at, u.updated_at
+FROM users u
+INNER JOIN team_memberships m ON m.user_id = u.user_id
+WHERE m.team_id = $1
 `
 
-	rows, err := r.database.GetPool().Query(ctx, query, teamID)
+	rows, err := r.database.Querier(ctx).Query(ctx, query, teamID)
 	if err != nil {
 		return nil, err
 	}
@@ -179,7 +218,7 @@ WHERE user_id = $1)
 `
 
 	var exists bool
-	err := r.database.GetPool().QueryRow(ctx, query, ID).Scan(&exists)
+	err := r.database.Querier(ctx).QueryRow(ctx, query, ID).Scan(&exists)
 	if err != nil {
 		return false, err
 	}
@@ -190,10 +229,13 @@ func (r *UserRepositoryPgx) GetActiveByTeamExcluding(
 	ctx context.Context, teamID model.TeamID, excludedUserIDs []model.UserID,
 ) ([]model.User, error) {
 	query := `
-SELECT user_id, username, team_id, is_active, created_at, updated_at FROM users WHERE team_id = $1 AND is_active = true AND user_id != ALL($2)
+SELECT u.user_id, u.username, u.team_id, u.is_active, u.created_at, u.updated_at
+FROM users u
+INNER JOIN team_memberships m ON m.user_id = u.user_id
+WHERE m.team_id = $1 AND u.is_active = true AND u.user_id != ALL(COALESCE($2, '{}'::uuid[]))
 `
 
-	rows, err := r.database.GetPool().Query(ctx, query, teamID, excludedUserIDs)
+	rows, err := r.database.Querier(ctx).Query(ctx, query, teamID, excludedUserIDs)
 	if err != nil {
 		return nil, err
 	}
@@ -221,4 +263,44 @@ SELECT user_id, username, team_id, is_active, created_at, updated_at FROM users
 	}
 
 	return users, nil
+}
+
+// nullableUUID stores uuid.Nil as NULL, e.g. for users that are not in any team.
+func nullableUUID(id uuid.UUID) any {
+	if id == uuid.Nil {
+		return nil
+	}
+	return id
+}
+
+func (r *UserRepositoryPgx) StreamAll(ctx context.Context, fn func(user *model.User) error) error {
+	query := `
+SELECT user_id, username, team_id, is_active, created_at, updated_at FROM users ORDER BY created_at, user_id
+`
+
+	rows, err := r.database.Querier(ctx).Query(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var user model.User
+		err := rows.Scan(
+			&user.ID,
+			&user.Username,
+			&user.TeamID,
+			&user.IsActive,
+			&user.CreatedAt,
+			&user.UpdatedAt,
+		)
+		if err != nil {
+			return err
+		}
+		if err := fn(&user); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
 }
\ No newline at end of file
@@ -0,0 +1,142 @@
+package roster
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"pull-request-review/internal/domain/model"
+	"pull-request-review/internal/domain/rules"
+)
+
+const (
+	columnTeamName       = "team_name"
+	columnParentTeamName = "parent_team_name"
+	columnUserID         = "user_id"
+	columnUsername       = "username"
+	columnIsActive       = "is_active"
+	columnWeight         = "weight"
+)
+
+// parseCSV reads one membership per row. The header names the columns; team_name, user_id
+// and username are required. A row with an empty user_id declares a team without members.
+func parseCSV(r io.Reader) (*model.Roster, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, &rules.ImportValidationError{Problems: []string{"file is empty"}}
+	}
+	if err != nil {
+		return nil, &rules.ImportValidationError{Problems: []string{err.Error()}}
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+
+	var problems []string
+	for _, required := range []string{columnTeamName, columnUserID, columnUsername} {
+		if _, ok := columns[required]; !ok {
+			problems = append(problems, fmt.Sprintf("header: missing column %q", required))
+		}
+	}
+	if len(problems) > 0 {
+		return nil, &rules.ImportValidationError{Problems: problems}
+	}
+
+	field := func(record []string, column string) string {
+		i, ok := columns[column]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	roster := &model.Roster{}
+	teamIndex := make(map[string]int)
+
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			problems = append(problems, err.Error())
+			continue
+		}
+		line, _ := reader.FieldPos(0)
+
+		teamName := field(record, columnTeamName)
+		parentName := field(record, columnParentTeamName)
+
+		i, ok := teamIndex[teamName]
+		if !ok {
+			i = len(roster.Teams)
+			teamIndex[teamName] = i
+			roster.Teams = append(roster.Teams, model.RosterTeam{Name: teamName, ParentTeamName: parentName})
+		}
+		team := &roster.Teams[i]
+		if parentName != "" && team.ParentTeamName != parentName {
+			if team.ParentTeamName != "" {
+				problems = append(
+					problems, fmt.Sprintf("line %d: team %q already has parent %q", line, teamName, team.ParentTeamName),
+				)
+				continue
+			}
+			team.ParentTeamName = parentName
+		}
+
+		rawUserID := field(record, columnUserID)
+		if rawUserID == "" {
+			continue
+		}
+
+		userUUID, err := uuid.Parse(rawUserID)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("line %d: invalid user_id %q", line, rawUserID))
+			continue
+		}
+
+		isActive := true
+		if raw := field(record, columnIsActive); raw != "" {
+			isActive, err = strconv.ParseBool(raw)
+			if err != nil {
+				problems = append(problems, fmt.Sprintf("line %d: invalid is_active %q", line, raw))
+				continue
+			}
+		}
+
+		var weight float64
+		if raw := field(record, columnWeight); raw != "" {
+			weight, err = strconv.ParseFloat(raw, 64)
+			if err != nil {
+				problems = append(problems, fmt.Sprintf("line %d: invalid weight %q", line, raw))
+				continue
+			}
+		}
+
+		team.Members = append(
+			team.Members, model.RosterMember{
+				UserID:   model.UserID(userUUID),
+				Username: field(record, columnUsername),
+				IsActive: isActive,
+				Weight:   weight,
+			},
+		)
+	}
+
+	if len(problems) > 0 {
+		return nil, &rules.ImportValidationError{Problems: problems}
+	}
+
+	return roster, nil
+}
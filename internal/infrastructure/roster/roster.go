@@ -0,0 +1,51 @@
+package roster
+
+import (
+	"fmt"
+	"io"
+	"mime"
+	"path/filepath"
+	"strings"
+
+	"pull-request-review/internal/domain/model"
+)
+
+type Format string
+
+const (
+	FormatYAML Format = "yaml"
+	FormatCSV  Format = "csv"
+)
+
+// ParseFormat accepts a format name, a file extension or a Content-Type value.
+func ParseFormat(value string) (Format, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if mediaType, _, err := mime.ParseMediaType(value); err == nil {
+		value = mediaType
+	}
+
+	switch value {
+	case "yaml", "yml", ".yaml", ".yml", "application/yaml", "application/x-yaml", "text/yaml", "text/x-yaml":
+		return FormatYAML, nil
+	case "csv", ".csv", "text/csv":
+		return FormatCSV, nil
+	default:
+		return "", fmt.Errorf("unsupported roster format %q", value)
+	}
+}
+
+func FormatFromPath(path string) (Format, error) {
+	return ParseFormat(filepath.Ext(path))
+}
+
+// Parse reads a roster. Malformed entries are reported together as a rules.ImportValidationError.
+func Parse(r io.Reader, format Format) (*model.Roster, error) {
+	switch format {
+	case FormatYAML:
+		return parseYAML(r)
+	case FormatCSV:
+		return parseCSV(r)
+	default:
+		return nil, fmt.Errorf("unsupported roster format %q", format)
+	}
+}
@@ -0,0 +1,139 @@
+package roster_test
+
+import (
+	"errors"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"pull-request-review/internal/domain/model"
+	"pull-request-review/internal/domain/rules"
+	"pull-request-review/internal/infrastructure/roster"
+)
+
+const (
+	firstID  = "0b6f7c1e-3f1a-4f7e-9a57-2f4d7b0f1a01"
+	secondID = "0b6f7c1e-3f1a-4f7e-9a57-2f4d7b0f1a02"
+)
+
+func TestParse(t *testing.T) {
+	want := &model.Roster{
+		Teams: []model.RosterTeam{
+			{
+				Name: "backend", ParentTeamName: "platform",
+				Members: []model.RosterMember{
+					{UserID: model.UserID(uuid.MustParse(firstID)), Username: "alice", IsActive: true, Weight: 2},
+					{UserID: model.UserID(uuid.MustParse(secondID)), Username: "bob", IsActive: false},
+				},
+			},
+			{Name: "platform", Members: []model.RosterMember{}},
+		},
+	}
+
+	for _, tc := range []struct {
+		name   string
+		format roster.Format
+		input  string
+	}{
+		{"csv", roster.FormatCSV, `Team_Name, user_id, username, is_active, weight, parent_team_name
+backend,` + firstID + `,alice,,2,platform
+backend,` + secondID + `,bob,false,,
+platform,,,,,
+`},
+		{"yaml", roster.FormatYAML, `teams:
+  - name: backend
+    parent: platform
+    members:
+      - {user_id: ` + firstID + `, username: alice, weight: 2}
+      - {user_id: ` + secondID + `, username: bob, is_active: false}
+  - name: platform
+`},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := roster.Parse(strings.NewReader(tc.input), tc.format)
+			if err != nil {
+				t.Fatalf("parse: %v", err)
+			}
+			// a team without members may come back with a nil or an empty list
+			for i := range got.Teams {
+				if got.Teams[i].Members == nil {
+					got.Teams[i].Members = []model.RosterMember{}
+				}
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Fatalf("parsed %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestParseMalformed(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		format roster.Format
+		input  string
+		// problems are the substrings every reported problem has to match, in order
+		problems []string
+	}{
+		{"csv empty file", roster.FormatCSV, "", []string{"file is empty"}},
+		{"csv missing columns", roster.FormatCSV, "team_name,weight\n", []string{
+			`header: missing column "user_id"`, `header: missing column "username"`,
+		}},
+		{"csv invalid user_id", roster.FormatCSV, "team_name,user_id,username\nbackend,42,alice\n", []string{
+			`line 2: invalid user_id "42"`,
+		}},
+		{"csv invalid is_active", roster.FormatCSV,
+			"team_name,user_id,username,is_active\nbackend," + firstID + ",alice,maybe\n",
+			[]string{`line 2: invalid is_active "maybe"`}},
+		{"csv invalid weight", roster.FormatCSV,
+			"team_name,user_id,username,weight\nbackend," + firstID + ",alice,heavy\n",
+			[]string{`line 2: invalid weight "heavy"`}},
+		{"csv two parents", roster.FormatCSV,
+			"team_name,parent_team_name,user_id,username\nbackend,platform,,\nbackend,infra,,\n",
+			[]string{`line 3: team "backend" already has parent "platform"`}},
+		{"csv every bad row is reported", roster.FormatCSV,
+			"team_name,user_id,username,weight\nbackend,1,alice,\nbackend," + secondID + ",bob,x\n",
+			[]string{`line 2: invalid user_id "1"`, `line 3: invalid weight "x"`}},
+		{"yaml not a roster", roster.FormatYAML, "teams: [", []string{""}},
+		{"yaml invalid user_id", roster.FormatYAML,
+			"teams:\n  - name: backend\n    members:\n      - {user_id: nope, username: alice}\n",
+			[]string{`teams[0].members[0]: invalid user_id "nope"`}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := roster.Parse(strings.NewReader(tc.input), tc.format)
+
+			var validationErr *rules.ImportValidationError
+			if !errors.As(err, &validationErr) || !errors.Is(err, rules.ErrInvalidImport) {
+				t.Fatalf("expected an import validation error, got %v", err)
+			}
+			matches := slices.EqualFunc(validationErr.Problems, tc.problems, strings.Contains)
+			if !matches {
+				t.Fatalf("problems %q, want %q", validationErr.Problems, tc.problems)
+			}
+		})
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	for _, tc := range []struct {
+		value string
+		want  roster.Format
+	}{
+		{"yaml", roster.FormatYAML},
+		{".YML", roster.FormatYAML},
+		{"application/yaml; charset=utf-8", roster.FormatYAML},
+		{"text/csv", roster.FormatCSV},
+		{".csv", roster.FormatCSV},
+		{"application/json", ""},
+	} {
+		t.Run(tc.value, func(t *testing.T) {
+			got, err := roster.ParseFormat(tc.value)
+			if got != tc.want || (err != nil) != (tc.want == "") {
+				t.Fatalf("got %q, %v, want %q", got, err, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package roster
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/goccy/go-yaml"
+	"github.com/google/uuid"
+
+	"pull-request-review/internal/domain/model"
+	"pull-request-review/internal/domain/rules"
+)
+
+type yamlRoster struct {
+	Teams []yamlTeam `yaml:"teams"`
+}
+
+type yamlTeam struct {
+	Name    string       `yaml:"name"`
+	Parent  string       `yaml:"parent"`
+	Members []yamlMember `yaml:"members"`
+}
+
+type yamlMember struct {
+	UserID   string  `yaml:"user_id"`
+	Username string  `yaml:"username"`
+	IsActive *bool   `yaml:"is_active"`
+	Weight   float64 `yaml:"weight"`
+}
+
+func parseYAML(r io.Reader) (*model.Roster, error) {
+	var doc yamlRoster
+	err := yaml.NewDecoder(r).Decode(&doc)
+	if err != nil {
+		return nil, &rules.ImportValidationError{Problems: []string{err.Error()}}
+	}
+
+	var problems []string
+	roster := &model.Roster{Teams: make([]model.RosterTeam, len(doc.Teams))}
+
+	for i, team := range doc.Teams {
+		roster.Teams[i] = model.RosterTeam{
+			Name:           team.Name,
+			ParentTeamName: team.Parent,
+			Members:        make([]model.RosterMember, len(team.Members)),
+		}
+
+		for j, member := range team.Members {
+			userUUID, err := uuid.Parse(member.UserID)
+			if err != nil {
+				problems = append(problems, fmt.Sprintf("teams[%d].members[%d]: invalid user_id %q", i, j, member.UserID))
+				continue
+			}
+
+			isActive := true
+			if member.IsActive != nil {
+				isActive = *member.IsActive
+			}
+
+			roster.Teams[i].Members[j] = model.RosterMember{
+				UserID:   model.UserID(userUUID),
+				Username: member.Username,
+				IsActive: isActive,
+				Weight:   member.Weight,
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		return nil, &rules.ImportValidationError{Problems: problems}
+	}
+
+	return roster, nil
+}
@@ -0,0 +1,69 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+
+	"pull-request-review/internal/domain/auth"
+	"pull-request-review/internal/domain/model"
+	"pull-request-review/internal/domain/ports/repository"
+	"pull-request-review/internal/domain/rules"
+)
+
+type accessChecker struct {
+	teamRoleRepo   repository.TeamRoleRepository
+	membershipRepo repository.TeamMembershipRepository
+}
+
+func newAccessChecker(
+	teamRoleRepo repository.TeamRoleRepository,
+	membershipRepo repository.TeamMembershipRepository,
+) *accessChecker {
+	return &accessChecker{
+		teamRoleRepo:   teamRoleRepo,
+		membershipRepo: membershipRepo,
+	}
+}
+
+// roleOf resolves the role of userID in teamID. Team members without an explicit grant
+// are plain members, everybody else has no role at all.
+func (c *accessChecker) roleOf(ctx context.Context, teamID model.TeamID, userID model.UserID) (
+	model.TeamRole, error,
+) {
+	role, err := c.teamRoleRepo.GetRole(ctx, teamID, userID)
+	if err == nil {
+		return role, nil
+	}
+	if !errors.Is(err, rules.ErrNotFound) {
+		return "", err
+	}
+
+	isMember, err := c.membershipRepo.Exists(ctx, teamID, userID)
+	if err != nil {
+		return "", err
+	}
+	if isMember {
+		return model.TeamRoleMember, nil
+	}
+
+	return "", nil
+}
+
+func (c *accessChecker) requireRole(ctx context.Context, teamID model.TeamID, required model.TeamRole) error {
+	actorID, ok := auth.ActorFromContext(ctx)
+	if !ok || uuid.UUID(actorID) == uuid.Nil {
+		return rules.ErrForbidden
+	}
+
+	role, err := c.roleOf(ctx, teamID, actorID)
+	if err != nil {
+		return err
+	}
+	if role == "" || !role.Includes(required) {
+		return rules.ErrForbidden
+	}
+
+	return nil
+}
@@ -0,0 +1,214 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"pull-request-review/internal/domain/auth"
+	"pull-request-review/internal/domain/model"
+	"pull-request-review/internal/domain/ports/repository"
+	"pull-request-review/internal/domain/rules"
+)
+
+// TestTeamRoles checks who gets a role when a team is created, who may grant and revoke roles,
+// and who may replace somebody else's review.
+func TestTeamRoles(t *testing.T) {
+	for name, open := range backends {
+		t.Run(name, func(t *testing.T) { testTeamRoles(t, open(t)) })
+	}
+}
+
+func testTeamRoles(t *testing.T, repos *repository.Repositories) {
+	ctx := context.Background()
+	prService, teamService := newServices(repos, nil)
+
+	members := newMembers(6)
+	admin, maintainer, author := members[0].ID, members[1].ID, members[5].ID
+	teamName := "team-" + uuid.NewString()[:8]
+	err := teamService.CreateTeamWithMembers(auth.WithActor(ctx, admin), teamName, members, model.TeamCreateOptions{})
+	if err != nil {
+		t.Fatalf("create team: %v", err)
+	}
+	team, err := repos.Team.GetByName(ctx, teamName)
+	if err != nil {
+		t.Fatalf("get team: %v", err)
+	}
+
+	outsiders := newMembers(2)
+	err = teamService.CreateTeamWithMembers(
+		auth.WithActor(ctx, model.UserID(uuid.New())), "team-"+uuid.NewString()[:8], outsiders,
+		model.TeamCreateOptions{},
+	)
+	if err != nil {
+		t.Fatalf("create outsiders team: %v", err)
+	}
+	outsider := outsiders[0].ID
+
+	roleOf := func(t *testing.T, userID model.UserID) model.TeamRole {
+		t.Helper()
+		role, err := repos.TeamRole.GetRole(ctx, team.TeamID, userID)
+		if errors.Is(err, rules.ErrNotFound) {
+			return ""
+		}
+		if err != nil {
+			t.Fatalf("get role: %v", err)
+		}
+		return role
+	}
+
+	t.Run("creator is the only admin", func(t *testing.T) {
+		if role := roleOf(t, admin); role != model.TeamRoleAdmin {
+			t.Fatalf("creator has role %q", role)
+		}
+		for _, member := range members[1:] {
+			if role := roleOf(t, member.ID); role != "" {
+				t.Fatalf("member %v has role %q", member.ID, role)
+			}
+		}
+	})
+
+	t.Run("unknown creator gets no role", func(t *testing.T) {
+		outsidersTeam, err := repos.Team.GetByID(ctx, model.TeamID(outsiders[0].TeamID))
+		if err != nil {
+			t.Fatalf("get team: %v", err)
+		}
+		for _, member := range outsiders {
+			_, err := repos.TeamRole.GetRole(ctx, outsidersTeam.TeamID, member.ID)
+			if !errors.Is(err, rules.ErrNotFound) {
+				t.Fatalf("member %v of a team created by an unknown user has a role: %v", member.ID, err)
+			}
+		}
+	})
+
+	for _, tc := range []struct {
+		name string
+		ctx  context.Context
+	}{
+		{"anonymous cannot grant", ctx},
+		{"member cannot grant", auth.WithActor(ctx, members[2].ID)},
+		{"outsider cannot grant", auth.WithActor(ctx, outsider)},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := teamService.GrantRole(tc.ctx, teamName, members[2].ID, model.TeamRoleMaintainer)
+			if !errors.Is(err, rules.ErrForbidden) {
+				t.Fatalf("expected %v, got %v", rules.ErrForbidden, err)
+			}
+		})
+	}
+
+	asAdmin := auth.WithActor(ctx, admin)
+	asMaintainer := auth.WithActor(ctx, maintainer)
+
+	t.Run("admin grants and revokes", func(t *testing.T) {
+		if _, err := teamService.GrantRole(asAdmin, teamName, members[2].ID, model.TeamRoleMaintainer); err != nil {
+			t.Fatalf("grant role: %v", err)
+		}
+		if role := roleOf(t, members[2].ID); role != model.TeamRoleMaintainer {
+			t.Fatalf("granted role is %q", role)
+		}
+		if _, err := teamService.RevokeRole(asAdmin, teamName, members[2].ID); err != nil {
+			t.Fatalf("revoke role: %v", err)
+		}
+		if role := roleOf(t, members[2].ID); role != "" {
+			t.Fatalf("revoked role is still %q", role)
+		}
+	})
+
+	t.Run("granting member drops the role", func(t *testing.T) {
+		if _, err := teamService.GrantRole(asAdmin, teamName, members[3].ID, model.TeamRoleAdmin); err != nil {
+			t.Fatalf("grant role: %v", err)
+		}
+		if _, err := teamService.GrantRole(asAdmin, teamName, members[3].ID, model.TeamRoleMember); err != nil {
+			t.Fatalf("grant role: %v", err)
+		}
+		if role := roleOf(t, members[3].ID); role != "" {
+			t.Fatalf("a plain member should have no stored role, has %q", role)
+		}
+	})
+
+	t.Run("invalid role", func(t *testing.T) {
+		_, err := teamService.GrantRole(asAdmin, teamName, members[2].ID, model.TeamRole("owner"))
+		if !errors.Is(err, rules.ErrInvalidRole) {
+			t.Fatalf("expected %v, got %v", rules.ErrInvalidRole, err)
+		}
+	})
+
+	t.Run("roles are for members only", func(t *testing.T) {
+		_, err := teamService.GrantRole(asAdmin, teamName, outsider, model.TeamRoleMaintainer)
+		if !errors.Is(err, rules.ErrUserNotFound) {
+			t.Fatalf("expected %v, got %v", rules.ErrUserNotFound, err)
+		}
+	})
+
+	if _, err := teamService.GrantRole(asAdmin, teamName, maintainer, model.TeamRoleMaintainer); err != nil {
+		t.Fatalf("grant role: %v", err)
+	}
+
+	t.Run("maintainer cannot grant", func(t *testing.T) {
+		_, err := teamService.GrantRole(asMaintainer, teamName, members[2].ID, model.TeamRoleMaintainer)
+		if !errors.Is(err, rules.ErrForbidden) {
+			t.Fatalf("expected %v, got %v", rules.ErrForbidden, err)
+		}
+	})
+
+	pr, _, err := prService.CreatePullRequest(
+		ctx, &model.PullRequest{PullRequestID: model.PullRequestID(uuid.New()), Name: "roles", AuthorID: author},
+	)
+	if err != nil {
+		t.Fatalf("create pull request: %v", err)
+	}
+
+	currentReviewers := func(t *testing.T) []model.UserID {
+		t.Helper()
+		reviewers, err := repos.ReviewAssignment.GetReviewers(ctx, pr.PullRequestID)
+		if err != nil || len(reviewers) == 0 {
+			t.Fatalf("get reviewers: %v, %v", reviewers, err)
+		}
+		return userIDs(reviewers)
+	}
+	// bystander is a plain member of the team who does not review the pull request
+	bystander := func(t *testing.T, reviewers []model.UserID) model.UserID {
+		for _, member := range members[2:5] {
+			if !slices.Contains(reviewers, member.ID) {
+				return member.ID
+			}
+		}
+		t.Fatalf("every plain member reviews the pull request")
+		return model.UserID(uuid.Nil)
+	}
+
+	for _, tc := range []struct {
+		name  string
+		actor func(t *testing.T, reviewers []model.UserID) model.UserID
+		want  error
+	}{
+		{"reviewer hands off own review", func(t *testing.T, reviewers []model.UserID) model.UserID {
+			return reviewers[0]
+		}, nil},
+		{"maintainer replaces a reviewer", func(*testing.T, []model.UserID) model.UserID { return maintainer }, nil},
+		{"admin replaces a reviewer", func(*testing.T, []model.UserID) model.UserID { return admin }, nil},
+		{"member cannot replace a reviewer", bystander, rules.ErrForbidden},
+		{"outsider cannot replace a reviewer", func(*testing.T, []model.UserID) model.UserID {
+			return outsider
+		}, rules.ErrForbidden},
+		{"anonymous cannot replace a reviewer", func(*testing.T, []model.UserID) model.UserID {
+			return model.UserID(uuid.Nil)
+		}, rules.ErrForbidden},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			reviewers := currentReviewers(t)
+			actorCtx := auth.WithActor(ctx, tc.actor(t, reviewers))
+			_, _, err := prService.ReassignPullRequest(actorCtx, pr.PullRequestID, reviewers[0], model.UserID(uuid.Nil))
+			if !errors.Is(err, tc.want) {
+				t.Fatalf("expected %v, got %v", tc.want, err)
+			}
+			if replaced := !slices.Contains(currentReviewers(t), reviewers[0]); replaced != (tc.want == nil) {
+				t.Fatalf("reviewer replaced: %v", replaced)
+			}
+		})
+	}
+}
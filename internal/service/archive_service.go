@@ -0,0 +1,242 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"pull-request-review/internal/domain/model"
+	"pull-request-review/internal/domain/ports/archive"
+	"pull-request-review/internal/domain/ports/repository"
+	"pull-request-review/internal/domain/rules"
+	"pull-request-review/internal/infrastructure/adapters/logger"
+)
+
+// errStopStream ends a StreamAll early once the callback has seen what it needs.
+var errStopStream = errors.New("stop stream")
+
+type ArchiveService struct {
+	teamRepo             repository.TeamRepository
+	userRepo             repository.UserRepository
+	membershipRepo       repository.TeamMembershipRepository
+	teamRoleRepo         repository.TeamRoleRepository
+	prRepo               repository.PullRequestRepository
+	reviewAssignmentRepo repository.ReviewAssignmentRepository
+	tx                   repository.TxManager
+	logger               logger.Logger
+}
+
+func NewArchiveService(
+	teamRepo repository.TeamRepository,
+	userRepo repository.UserRepository,
+	membershipRepo repository.TeamMembershipRepository,
+	teamRoleRepo repository.TeamRoleRepository,
+	prRepo repository.PullRequestRepository,
+	reviewAssignmentRepo repository.ReviewAssignmentRepository,
+	tx repository.TxManager,
+	logger logger.Logger,
+) *ArchiveService {
+	return &ArchiveService{
+		teamRepo:             teamRepo,
+		userRepo:             userRepo,
+		membershipRepo:       membershipRepo,
+		teamRoleRepo:         teamRoleRepo,
+		prRepo:               prRepo,
+		reviewAssignmentRepo: reviewAssignmentRepo,
+		tx:                   tx,
+		logger:               logger,
+	}
+}
+
+// Export streams every table into the writer, in an order that restore can replay as is:
+// parent teams before their children, and everything a row references before the row itself.
+// All tables are read from one snapshot, so rows written meanwhile cannot leave the archive
+// with references to rows it does not contain.
+func (s *ArchiveService) Export(ctx context.Context, writer archive.Writer) (map[model.ArchiveRecordKind]int, error) {
+	counts := make(map[model.ArchiveRecordKind]int)
+
+	err := writer.WriteHeader(
+		model.ArchiveHeader{
+			Format:    model.ArchiveFormat,
+			Version:   model.ArchiveVersion,
+			CreatedAt: time.Now().UTC(),
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	write := func(record *model.ArchiveRecord) error {
+		counts[record.Kind]++
+		return writer.Write(record)
+	}
+
+	steps := []struct {
+		kind   model.ArchiveRecordKind
+		stream func(ctx context.Context) error
+	}{
+		{model.ArchiveRecordTeam, func(ctx context.Context) error {
+			return s.teamRepo.StreamAll(ctx, func(team *model.Team) error {
+				return write(&model.ArchiveRecord{Kind: model.ArchiveRecordTeam, Team: team})
+			})
+		}},
+		{model.ArchiveRecordUser, func(ctx context.Context) error {
+			return s.userRepo.StreamAll(ctx, func(user *model.User) error {
+				return write(&model.ArchiveRecord{Kind: model.ArchiveRecordUser, User: user})
+			})
+		}},
+		{model.ArchiveRecordMembership, func(ctx context.Context) error {
+			return s.membershipRepo.StreamAll(ctx, func(membership *model.TeamMembership) error {
+				return write(&model.ArchiveRecord{Kind: model.ArchiveRecordMembership, Membership: membership})
+			})
+		}},
+		{model.ArchiveRecordTeamRole, func(ctx context.Context) error {
+			return s.teamRoleRepo.StreamAll(ctx, func(grant *model.TeamRoleGrant) error {
+				return write(&model.ArchiveRecord{Kind: model.ArchiveRecordTeamRole, TeamRole: grant})
+			})
+		}},
+		{model.ArchiveRecordPullRequest, func(ctx context.Context) error {
+			return s.prRepo.StreamAll(ctx, func(pr *model.PullRequest) error {
+				return write(&model.ArchiveRecord{Kind: model.ArchiveRecordPullRequest, PullRequest: pr})
+			})
+		}},
+		{model.ArchiveRecordReviewAssignment, func(ctx context.Context) error {
+			return s.reviewAssignmentRepo.StreamAll(ctx, func(assignment *model.ReviewAssignment) error {
+				return write(&model.ArchiveRecord{Kind: model.ArchiveRecordReviewAssignment, ReviewAssignment: assignment})
+			})
+		}},
+		{model.ArchiveRecordReviewDecline, func(ctx context.Context) error {
+			return s.reviewAssignmentRepo.StreamDeclines(ctx, func(decline *model.ReviewDecline) error {
+				return write(&model.ArchiveRecord{Kind: model.ArchiveRecordReviewDecline, ReviewDecline: decline})
+			})
+		}},
+	}
+
+	err = s.tx.WithinReadOnlyTx(ctx, func(ctx context.Context) error {
+		for _, step := range steps {
+			err := step.stream(ctx)
+			if err != nil {
+				s.logger.Error(err, fmt.Sprintf("cannot export %s records", step.kind))
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = writer.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	return counts, nil
+}
+
+// Restore replays an archive into an empty database. Records are inserted in archive order,
+// so an archive produced by Export never references a row that has not been restored yet.
+// The restore runs in one transaction: when a record fails, nothing is left behind and the
+// restore can be retried.
+func (s *ArchiveService) Restore(ctx context.Context, reader archive.Reader) (map[model.ArchiveRecordKind]int, error) {
+	header, err := reader.ReadHeader()
+	if err != nil {
+		return nil, err
+	}
+	if header.Format != model.ArchiveFormat || header.Version != model.ArchiveVersion {
+		return nil, fmt.Errorf("%w: %s version %d", rules.ErrUnsupportedArchive, header.Format, header.Version)
+	}
+
+	var counts map[model.ArchiveRecordKind]int
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		counts, err = s.restore(ctx, reader)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return counts, nil
+}
+
+func (s *ArchiveService) restore(ctx context.Context, reader archive.Reader) (map[model.ArchiveRecordKind]int, error) {
+	empty, err := s.isEmpty(ctx)
+	if err != nil {
+		s.logger.Error(err, "cannot check restore target")
+		return nil, err
+	}
+	if !empty {
+		return nil, rules.ErrRestoreTargetNotEmpty
+	}
+
+	counts := make(map[model.ArchiveRecordKind]int)
+	for {
+		record, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			return counts, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		err = s.restoreRecord(ctx, record)
+		if err != nil {
+			s.logger.Error(err, fmt.Sprintf("cannot restore %s record", record.Kind))
+			return nil, err
+		}
+		counts[record.Kind]++
+	}
+}
+
+func (s *ArchiveService) restoreRecord(ctx context.Context, record *model.ArchiveRecord) error {
+	switch record.Kind {
+	case model.ArchiveRecordTeam:
+		return s.teamRepo.Create(ctx, record.Team)
+	case model.ArchiveRecordUser:
+		return s.userRepo.Insert(ctx, record.User)
+	case model.ArchiveRecordMembership:
+		return s.membershipRepo.Add(ctx, record.Membership)
+	case model.ArchiveRecordTeamRole:
+		grant := record.TeamRole
+		return s.teamRoleRepo.SetRole(ctx, grant.TeamID, grant.UserID, grant.Role)
+	case model.ArchiveRecordPullRequest:
+		return s.prRepo.Create(ctx, record.PullRequest)
+	case model.ArchiveRecordReviewAssignment:
+		return s.reviewAssignmentRepo.Insert(ctx, record.ReviewAssignment)
+	case model.ArchiveRecordReviewDecline:
+		return s.reviewAssignmentRepo.RecordDecline(ctx, record.ReviewDecline)
+	default:
+		return fmt.Errorf("unknown archive record kind %q", record.Kind)
+	}
+}
+
+// isEmpty reports whether there are no teams, users or pull requests yet. Every other table
+// references one of these, so checking them is enough.
+func (s *ArchiveService) isEmpty(ctx context.Context) (bool, error) {
+	checks := []func() error{
+		func() error {
+			return s.teamRepo.StreamAll(ctx, func(*model.Team) error { return errStopStream })
+		},
+		func() error {
+			return s.userRepo.StreamAll(ctx, func(*model.User) error { return errStopStream })
+		},
+		func() error {
+			return s.prRepo.StreamAll(ctx, func(*model.PullRequest) error { return errStopStream })
+		},
+	}
+
+	for _, check := range checks {
+		err := check()
+		if errors.Is(err, errStopStream) {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+	}
+
+	return true, nil
+}
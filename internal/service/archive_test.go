@@ -0,0 +1,224 @@
+package service_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"pull-request-review/internal/domain/auth"
+	"pull-request-review/internal/domain/model"
+	archiveport "pull-request-review/internal/domain/ports/archive"
+	"pull-request-review/internal/domain/ports/repository"
+	"pull-request-review/internal/infrastructure/adapters/logger"
+	"pull-request-review/internal/infrastructure/archive"
+	"pull-request-review/internal/infrastructure/repository/memory"
+	"pull-request-review/internal/service"
+)
+
+// TestArchiveRoundTrip exports a populated store in each format, restores it into an empty one
+// and expects the restored store to export the same records.
+func TestArchiveRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	source := memory.NewRepositories(memory.NewStore())
+	populateArchive(t, source)
+	want := exportRecords(t, source)
+	for _, kind := range []model.ArchiveRecordKind{
+		model.ArchiveRecordTeam, model.ArchiveRecordUser, model.ArchiveRecordMembership, model.ArchiveRecordTeamRole,
+		model.ArchiveRecordPullRequest, model.ArchiveRecordReviewAssignment, model.ArchiveRecordReviewDecline,
+	} {
+		if !slices.ContainsFunc(want, func(line string) bool { return strings.Contains(line, `"type":"`+string(kind)+`"`) }) {
+			t.Fatalf("the source store has no %s record", kind)
+		}
+	}
+
+	for _, format := range []archive.Format{archive.FormatJSON, archive.FormatNDJSON} {
+		t.Run(string(format), func(t *testing.T) {
+			var buf bytes.Buffer
+			writer, err := archive.NewWriter(&buf, format)
+			if err != nil {
+				t.Fatalf("new writer: %v", err)
+			}
+			if _, err := newArchiveService(source).Export(ctx, writer); err != nil {
+				t.Fatalf("export: %v", err)
+			}
+
+			target := memory.NewRepositories(memory.NewStore())
+			reader, err := archive.NewReader(&buf, format)
+			if err != nil {
+				t.Fatalf("new reader: %v", err)
+			}
+			counts, err := newArchiveService(target).Restore(ctx, reader)
+			if err != nil {
+				t.Fatalf("restore: %v", err)
+			}
+
+			restored := 0
+			for _, count := range counts {
+				restored += count
+			}
+			if restored != len(want) {
+				t.Fatalf("restored %d records of %d: %v", restored, len(want), counts)
+			}
+			if got := exportRecords(t, target); !slices.Equal(got, want) {
+				t.Fatalf("restored store differs:\n got %v\nwant %v", got, want)
+			}
+		})
+	}
+}
+
+// TestArchiveExportSnapshot writes while an export runs and expects the write to land after it.
+func TestArchiveExportSnapshot(t *testing.T) {
+	ctx := context.Background()
+	repos := memory.NewRepositories(memory.NewStore())
+	populateArchive(t, repos)
+
+	var buf bytes.Buffer
+	ndjson, err := archive.NewWriter(&buf, archive.FormatNDJSON)
+	if err != nil {
+		t.Fatalf("new writer: %v", err)
+	}
+
+	// the first record starts a write, which has to wait until the last record is out
+	written := make(chan error, 1)
+	writer := &interceptingWriter{
+		Writer: ndjson,
+		first: func() {
+			go func() {
+				late := model.User{ID: model.UserID(uuid.New()), Username: "late", IsActive: true}
+				written <- repos.User.Insert(ctx, &late)
+			}()
+		},
+		next: func() {
+			select {
+			case err := <-written:
+				t.Errorf("a write finished during the export: %v", err)
+				written <- err
+			case <-time.After(5 * time.Millisecond):
+			}
+		},
+	}
+
+	counts, err := newArchiveService(repos).Export(ctx, writer)
+	if err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	if err := <-written; err != nil {
+		t.Fatalf("insert user: %v", err)
+	}
+	if strings.Contains(buf.String(), `"late"`) {
+		t.Fatalf("the export contains a user written after it started: %v", counts)
+	}
+}
+
+// populateArchive fills repos with a record of every kind: a team nested under another, an
+// admin, a weighted membership, and an open and a merged pull request with their reviewers,
+// one of whom declined.
+func populateArchive(t *testing.T, repos *repository.Repositories) {
+	t.Helper()
+
+	ctx := context.Background()
+	prService, teamService := newServices(repos, nil)
+
+	parent := newMembers(3)
+	asAdmin := auth.WithActor(ctx, parent[0].ID)
+	if err := teamService.CreateTeamWithMembers(asAdmin, "platform", parent, model.TeamCreateOptions{}); err != nil {
+		t.Fatalf("create team: %v", err)
+	}
+
+	members := newMembers(4)
+	err := teamService.CreateTeamWithMembers(
+		auth.WithActor(ctx, parent[0].ID), "backend", members, model.TeamCreateOptions{
+			ParentTeamName: "platform",
+			Weights:        map[model.UserID]float64{members[1].ID: 2},
+		},
+	)
+	if err != nil {
+		t.Fatalf("create child team: %v", err)
+	}
+
+	for i, name := range []string{"open", "merged"} {
+		pr, reviewers, err := prService.CreatePullRequest(
+			ctx, &model.PullRequest{
+				PullRequestID: model.PullRequestID(uuid.New()), Name: name, AuthorID: members[i].ID,
+				PullRequestMetadata: model.PullRequestMetadata{Repository: "backend", Labels: []string{"api"}},
+			},
+		)
+		if err != nil {
+			t.Fatalf("create pull request: %v", err)
+		}
+		if name == "open" {
+			_, _, err := prService.DeclineReview(auth.WithActor(ctx, reviewers[0]), pr.PullRequestID, "on leave")
+			if err != nil {
+				t.Fatalf("decline review: %v", err)
+			}
+		}
+		if name == "merged" {
+			if _, err := prService.MergePullRequest(auth.WithActor(ctx, pr.AuthorID), pr.PullRequestID); err != nil {
+				t.Fatalf("merge pull request: %v", err)
+			}
+		}
+	}
+}
+
+// exportRecords exports repos as NDJSON and returns its record lines, sorted and without the
+// header. Role grants are restored with the time of the restore, so their time is left out.
+func exportRecords(t *testing.T, repos *repository.Repositories) []string {
+	t.Helper()
+
+	var buf bytes.Buffer
+	writer, err := archive.NewWriter(&buf, archive.FormatNDJSON)
+	if err != nil {
+		t.Fatalf("new writer: %v", err)
+	}
+	if _, err := newArchiveService(repos).Export(context.Background(), writer); err != nil {
+		t.Fatalf("export: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")[1:]
+	for i, line := range lines {
+		var record map[string]any
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("decode %q: %v", line, err)
+		}
+		if data, ok := record["data"].(map[string]any); ok {
+			delete(data, "granted_at")
+		}
+		normalized, err := json.Marshal(record)
+		if err != nil {
+			t.Fatalf("encode record: %v", err)
+		}
+		lines[i] = string(normalized)
+	}
+	slices.Sort(lines)
+	return lines
+}
+
+func newArchiveService(repos *repository.Repositories) *service.ArchiveService {
+	return service.NewArchiveService(
+		repos.Team, repos.User, repos.TeamMembership, repos.TeamRole, repos.PullRequest,
+		repos.ReviewAssignment, repos.Tx, logger.NewZerologLogger(),
+	)
+}
+
+// interceptingWriter calls first before the first record and next before every other one.
+type interceptingWriter struct {
+	archiveport.Writer
+	first, next func()
+	started     bool
+}
+
+func (w *interceptingWriter) Write(record *model.ArchiveRecord) error {
+	if w.started {
+		w.next()
+	} else {
+		w.started = true
+		w.first()
+	}
+	return w.Writer.Write(record)
+}
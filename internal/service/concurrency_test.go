@@ -0,0 +1,241 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"pull-request-review/config"
+	"pull-request-review/db/migrations"
+	"pull-request-review/internal/domain/auth"
+	"pull-request-review/internal/domain/model"
+	"pull-request-review/internal/domain/ports/repository"
+	"pull-request-review/internal/domain/rules"
+	"pull-request-review/internal/infrastructure/adapters/logger"
+	"pull-request-review/internal/infrastructure/database"
+	pgrepository "pull-request-review/internal/infrastructure/repository"
+	"pull-request-review/internal/infrastructure/repository/memory"
+	"pull-request-review/internal/infrastructure/repository/sqlite"
+	"pull-request-review/internal/service"
+)
+
+const (
+	stressTeamSize  = 6
+	stressReviewers = 2
+	stressWorkers   = 8
+	stressRounds    = 10
+)
+
+// backends are the storages the service tests run against.
+var backends = map[string]func(t *testing.T) *repository.Repositories{
+	"memory": func(t *testing.T) *repository.Repositories {
+		return memory.NewRepositories(memory.NewStore())
+	},
+	"sqlite":   openSQLite,
+	"postgres": openPostgres,
+}
+
+// TestConcurrentReassign fires parallel reassigns at one pull request and checks that every
+// backend keeps its reviewers distinct and its version in step with the changes that won.
+func TestConcurrentReassign(t *testing.T) {
+	for name, open := range backends {
+		t.Run(name, func(t *testing.T) { testConcurrentReassign(t, open(t)) })
+	}
+}
+
+func testConcurrentReassign(t *testing.T, repos *repository.Repositories) {
+	ctx := context.Background()
+	prService, members := newTeamWithPullRequests(t, repos, stressTeamSize)
+
+	author := members[0].ID
+	pr, reviewers, err := prService.CreatePullRequest(
+		ctx, &model.PullRequest{PullRequestID: model.PullRequestID(uuid.New()), Name: "stress", AuthorID: author},
+	)
+	if err != nil || len(reviewers) != stressReviewers {
+		t.Fatalf("create pull request: %v, %v", reviewers, err)
+	}
+
+	var (
+		wg        sync.WaitGroup
+		mu        sync.Mutex
+		succeeded int64
+		failures  []error
+	)
+	for range stressWorkers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range stressRounds {
+				current, err := repos.ReviewAssignment.GetReviewers(ctx, pr.PullRequestID)
+				if err != nil || len(current) == 0 {
+					mu.Lock()
+					failures = append(failures, errors.Join(errors.New("no reviewers to reassign"), err))
+					mu.Unlock()
+					return
+				}
+
+				// every worker asks to drop the same reviewer, so most of them race for one change
+				old := current[0].ID
+				_, _, err = prService.ReassignPullRequest(
+					auth.WithActor(ctx, old), pr.PullRequestID, old, model.UserID(uuid.Nil),
+				)
+
+				mu.Lock()
+				switch {
+				case err == nil:
+					succeeded++
+				case errors.Is(err, rules.ErrNotAssigned), errors.Is(err, rules.ErrNoCandidates):
+					// someone else already replaced this reviewer
+				default:
+					failures = append(failures, err)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	for _, err := range failures {
+		t.Errorf("unexpected reassign error: %v", err)
+	}
+	if succeeded == 0 {
+		t.Fatal("no reassign succeeded")
+	}
+
+	final, err := repos.ReviewAssignment.GetReviewers(ctx, pr.PullRequestID)
+	if err != nil {
+		t.Fatalf("get reviewers: %v", err)
+	}
+	seen := make(map[model.UserID]bool, len(final))
+	for _, reviewer := range final {
+		if reviewer.ID == author || seen[reviewer.ID] {
+			t.Fatalf("invalid reviewers %v", final)
+		}
+		seen[reviewer.ID] = true
+	}
+	if len(final) != stressReviewers {
+		t.Fatalf("expected %d reviewers, got %v", stressReviewers, final)
+	}
+
+	got, err := repos.PullRequest.GetByID(ctx, pr.PullRequestID)
+	if err != nil {
+		t.Fatalf("get pull request: %v", err)
+	}
+	if got.Version != model.InitialPullRequestVersion+succeeded {
+		t.Fatalf("version %d after %d reassigns", got.Version, succeeded)
+	}
+}
+
+// newTeamWithPullRequests sets up a pull request service over repos and a team of size
+// active members to write and review pull requests.
+func newTeamWithPullRequests(t *testing.T, repos *repository.Repositories, size int) (
+	*service.PullRequestService, []model.User,
+) {
+	t.Helper()
+	return newTeamWithRoutingRules(t, repos, size, nil)
+}
+
+// newTeamWithRoutingRules is newTeamWithPullRequests with a service that routes by routingRules.
+func newTeamWithRoutingRules(
+	t *testing.T, repos *repository.Repositories, size int, routingRules []model.RoutingRule,
+) (*service.PullRequestService, []model.User) {
+	t.Helper()
+
+	prService, teamService := newServices(repos, routingRules)
+	members := newMembers(size)
+	err := teamService.CreateTeamWithMembers(
+		context.Background(), "team-"+uuid.NewString()[:8], members, model.TeamCreateOptions{},
+	)
+	if err != nil {
+		t.Fatalf("create team: %v", err)
+	}
+
+	return prService, members
+}
+
+// createPullRequest stores an open pull request of authorID and fails unless it gets all its reviewers.
+func createPullRequest(t *testing.T, prService *service.PullRequestService, authorID model.UserID) (
+	model.PullRequestID, []model.UserID,
+) {
+	t.Helper()
+
+	pr, reviewers, err := prService.CreatePullRequest(
+		context.Background(),
+		&model.PullRequest{PullRequestID: model.PullRequestID(uuid.New()), Name: "pull request", AuthorID: authorID},
+	)
+	if err != nil || len(reviewers) != stressReviewers {
+		t.Fatalf("create pull request: %v, %v", reviewers, err)
+	}
+	return pr.PullRequestID, reviewers
+}
+
+// newServices wires the pull request and team services over repos like the application does.
+func newServices(repos *repository.Repositories, routingRules []model.RoutingRule) (
+	*service.PullRequestService, *service.TeamService,
+) {
+	log := logger.NewZerologLogger()
+	prService := service.NewPullRequestService(
+		repos.PullRequest, repos.User, repos.ReviewAssignment, repos.TeamMembership,
+		repos.Team, repos.TeamRole, repos.Tx, log,
+		service.PullRequestServiceOptions{MaxReviewers: stressReviewers, MaxBatchSize: 100, RoutingRules: routingRules},
+	)
+	teamService := service.NewTeamService(
+		repos.Team, repos.User, repos.TeamMembership, repos.TeamRole,
+		repos.MembershipAudit, prService, repos.Tx, log,
+	)
+	return prService, teamService
+}
+
+// newMembers makes size active users that are not stored yet.
+func newMembers(size int) []model.User {
+	members := make([]model.User, size)
+	for i := range members {
+		members[i] = model.User{
+			ID:       model.UserID(uuid.New()),
+			Username: "member-" + uuid.NewString()[:8],
+			IsActive: true,
+		}
+	}
+	return members
+}
+
+func openSQLite(t *testing.T) *repository.Repositories {
+	ctx := context.Background()
+	db := database.NewSQLiteDatabase(config.DatabaseConfig{URL: ":memory:"}, logger.NewZerologLogger())
+	if err := db.Connect(ctx); err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	t.Cleanup(db.Close)
+
+	if _, err := db.Migrate(ctx, migrations.SQLiteFS); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	return sqlite.NewRepositories(db)
+}
+
+// openPostgres uses the database in DATABASE_URL, the only backend where the race is real.
+func openPostgres(t *testing.T) *repository.Repositories {
+	url := os.Getenv("DATABASE_URL")
+	if url == "" {
+		t.Skip("DATABASE_URL is not set")
+	}
+
+	ctx := context.Background()
+	log := logger.NewZerologLogger()
+	db := database.NewDatabase(config.DatabaseConfig{URL: url, MaxConns: stressWorkers}, log)
+	if err := db.Connect(ctx); err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	t.Cleanup(db.Close)
+
+	if _, err := database.NewMigrator(db, migrations.FS, log).Up(ctx); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	return pgrepository.NewRepositories(db)
+}
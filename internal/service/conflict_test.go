@@ -0,0 +1,151 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"pull-request-review/internal/domain/auth"
+	"pull-request-review/internal/domain/model"
+	"pull-request-review/internal/domain/ports/repository"
+	"pull-request-review/internal/domain/rules"
+)
+
+// TestMemberConflicts creates teams with members that already belong to another team, once per
+// conflict mode, and checks the reported conflicts, the memberships and the audit trail.
+func TestMemberConflicts(t *testing.T) {
+	for name, open := range backends {
+		t.Run(name, func(t *testing.T) { testMemberConflicts(t, open(t)) })
+	}
+}
+
+func testMemberConflicts(t *testing.T, repos *repository.Repositories) {
+	ctx := context.Background()
+	audit := &auditLog{MembershipAuditRepository: repos.MembershipAudit}
+	_, teamService, _ := newMembershipServices(repos, audit)
+
+	home := newMembers(6)
+	admin := home[0].ID
+	asAdmin := auth.WithActor(ctx, admin)
+	homeName := "team-" + uuid.NewString()[:8]
+	if err := teamService.CreateTeamWithMembers(asAdmin, homeName, home, model.TeamCreateOptions{}); err != nil {
+		t.Fatalf("create team: %v", err)
+	}
+	homeTeam, err := repos.Team.GetByName(ctx, homeName)
+	if err != nil {
+		t.Fatalf("get team: %v", err)
+	}
+
+	// create adds a new team with a fresh member and the given existing ones
+	create := func(ctx context.Context, mode model.MemberConflictMode, existing ...model.User) (string, error) {
+		name := "team-" + uuid.NewString()[:8]
+		members := append(newMembers(1), existing...)
+		return name, teamService.CreateTeamWithMembers(ctx, name, members, model.TeamCreateOptions{ConflictMode: mode})
+	}
+	memberOf := func(t *testing.T, userID model.UserID) []model.TeamID {
+		t.Helper()
+		memberships, err := repos.TeamMembership.GetByUser(ctx, userID)
+		if err != nil {
+			t.Fatalf("get memberships: %v", err)
+		}
+		teams := make([]model.TeamID, 0, len(memberships))
+		for _, membership := range memberships {
+			teams = append(teams, membership.TeamID)
+		}
+		return teams
+	}
+	primaryTeam := func(t *testing.T, userID model.UserID) model.TeamID {
+		t.Helper()
+		user, err := repos.User.GetByID(ctx, userID)
+		if err != nil {
+			t.Fatalf("get user: %v", err)
+		}
+		return model.TeamID(user.TeamID)
+	}
+
+	t.Run("reject", func(t *testing.T) {
+		for _, mode := range []model.MemberConflictMode{"", model.MemberConflictReject} {
+			name, err := create(asAdmin, mode, home[1], home[2])
+
+			var conflictErr *rules.MemberConflictError
+			if !errors.As(err, &conflictErr) || !errors.Is(err, rules.ErrMemberConflict) {
+				t.Fatalf("mode %q: expected a member conflict, got %v", mode, err)
+			}
+			want := []model.MemberConflict{
+				{UserID: home[1].ID, TeamID: homeTeam.TeamID, TeamName: homeName},
+				{UserID: home[2].ID, TeamID: homeTeam.TeamID, TeamName: homeName},
+			}
+			if !slices.Equal(conflictErr.Conflicts, want) {
+				t.Fatalf("mode %q: conflicts %+v, want %+v", mode, conflictErr.Conflicts, want)
+			}
+
+			if exists, err := repos.Team.ExistsByName(ctx, name); err != nil || exists {
+				t.Fatalf("mode %q: a rejected team should not be created: %v, %v", mode, exists, err)
+			}
+			for _, member := range home[1:3] {
+				if teams := memberOf(t, member.ID); !slices.Equal(teams, []model.TeamID{homeTeam.TeamID}) {
+					t.Fatalf("mode %q: memberships changed to %v", mode, teams)
+				}
+				if audit.recorded(member.ID) != 0 {
+					t.Fatalf("mode %q: a rejected team should not record membership changes", mode)
+				}
+			}
+		}
+	})
+
+	t.Run("move", func(t *testing.T) {
+		name, err := create(asAdmin, model.MemberConflictMove, home[1])
+		if err != nil {
+			t.Fatalf("create team: %v", err)
+		}
+		team, err := repos.Team.GetByName(ctx, name)
+		if err != nil {
+			t.Fatalf("get team: %v", err)
+		}
+
+		if primaryTeam(t, home[1].ID) != team.TeamID {
+			t.Fatalf("a moved member should get the new team as primary team")
+		}
+		if teams := memberOf(t, home[1].ID); !slices.Equal(teams, []model.TeamID{team.TeamID}) {
+			t.Fatalf("a moved member should only belong to the new team, belongs to %v", teams)
+		}
+		if audit.recorded(home[1].ID) != 1 {
+			t.Fatalf("a move should record exactly one membership change")
+		}
+		audit.expect(t, home[1].ID, model.MembershipChangeTeamAdd, homeTeam.TeamID, team.TeamID, admin)
+
+		// only maintainers of the old team may take its members away
+		_, err = create(auth.WithActor(ctx, home[3].ID), model.MemberConflictMove, home[2])
+		if !errors.Is(err, rules.ErrForbidden) {
+			t.Fatalf("expected %v, got %v", rules.ErrForbidden, err)
+		}
+		if primaryTeam(t, home[2].ID) != homeTeam.TeamID || audit.recorded(home[2].ID) != 0 {
+			t.Fatalf("a refused move should leave the member alone")
+		}
+	})
+
+	t.Run("multi", func(t *testing.T) {
+		name, err := create(asAdmin, model.MemberConflictMulti, home[4])
+		if err != nil {
+			t.Fatalf("create team: %v", err)
+		}
+		team, err := repos.Team.GetByName(ctx, name)
+		if err != nil {
+			t.Fatalf("get team: %v", err)
+		}
+
+		if primaryTeam(t, home[4].ID) != homeTeam.TeamID {
+			t.Fatalf("a member in several teams should keep the primary team")
+		}
+		teams := memberOf(t, home[4].ID)
+		if len(teams) != 2 || !slices.Contains(teams, homeTeam.TeamID) || !slices.Contains(teams, team.TeamID) {
+			t.Fatalf("the member should belong to both teams, belongs to %v", teams)
+		}
+		if audit.recorded(home[4].ID) != 0 {
+			t.Fatalf("joining another team without leaving one should not be recorded")
+		}
+	})
+}
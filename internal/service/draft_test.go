@@ -0,0 +1,123 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"pull-request-review/internal/domain/auth"
+	"pull-request-review/internal/domain/model"
+	"pull-request-review/internal/domain/ports/repository"
+	"pull-request-review/internal/domain/rules"
+	"pull-request-review/internal/service"
+)
+
+// TestDraftPullRequest follows a draft from creation, where nobody is asked to review it,
+// to markReady, where it gets its reviewers.
+func TestDraftPullRequest(t *testing.T) {
+	for name, open := range backends {
+		t.Run(name, func(t *testing.T) { testDraftPullRequest(t, open(t)) })
+	}
+}
+
+func testDraftPullRequest(t *testing.T, repos *repository.Repositories) {
+	ctx := context.Background()
+
+	type fixture struct {
+		prService *service.PullRequestService
+		members   []model.User
+		ID        model.PullRequestID
+	}
+	newDraft := func(t *testing.T) *fixture {
+		t.Helper()
+		prService, members := newTeamWithPullRequests(t, repos, 4)
+		draft, reviewers, err := prService.CreatePullRequest(
+			ctx, &model.PullRequest{
+				PullRequestID: model.PullRequestID(uuid.New()), Name: "draft", AuthorID: members[0].ID,
+				Status: model.PRStatusDraft,
+			},
+		)
+		if err != nil || draft.Status != model.PRStatusDraft || len(reviewers) != 0 {
+			t.Fatalf("create draft: %+v, %v, %v", draft, reviewers, err)
+		}
+		return &fixture{prService: prService, members: members, ID: draft.PullRequestID}
+	}
+	// drafts hold no reviews, so they add nothing to anybody's load
+	expectNoLoad := func(t *testing.T, members []model.User) {
+		t.Helper()
+		counts, err := repos.ReviewAssignment.GetOpenReviewCounts(ctx, userIDs(members))
+		if err != nil || len(counts) != 0 {
+			t.Fatalf("drafts should not count as open reviews: %v, %v", counts, err)
+		}
+	}
+
+	t.Run("a draft gets no reviewers", func(t *testing.T) {
+		expectNoLoad(t, newDraft(t).members)
+	})
+
+	t.Run("a batch draft gets no reviewers", func(t *testing.T) {
+		prService, members := newTeamWithPullRequests(t, repos, 4)
+		results, err := prService.BatchCreatePullRequests(
+			ctx, []*model.PullRequest{
+				{
+					PullRequestID: model.PullRequestID(uuid.New()), Name: "batch draft", AuthorID: members[0].ID,
+					Status: model.PRStatusDraft,
+				},
+			},
+		)
+		if err != nil || results[0].Err != nil || len(results[0].ReviewerIDs) != 0 {
+			t.Fatalf("batch draft: %+v, %v", results, err)
+		}
+		expectNoLoad(t, members)
+	})
+
+	for _, tc := range []struct {
+		name string
+		call func(f *fixture) error
+		want error
+	}{
+		{"merge", func(f *fixture) error {
+			_, err := f.prService.MergePullRequest(ctx, f.ID)
+			return err
+		}, rules.ErrPullRequestDraft},
+		{"add reviewer", func(f *fixture) error {
+			_, err := f.prService.AddReviewer(auth.WithActor(ctx, f.members[0].ID), f.ID, f.members[1].ID)
+			return err
+		}, rules.ErrPullRequestDraft},
+		{"mark ready by another member", func(f *fixture) error {
+			_, _, err := f.prService.MarkReady(auth.WithActor(ctx, f.members[1].ID), f.ID)
+			return err
+		}, rules.ErrForbidden},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := tc.call(newDraft(t)); !errors.Is(err, tc.want) {
+				t.Fatalf("expected %v, got %v", tc.want, err)
+			}
+		})
+	}
+
+	t.Run("mark ready", func(t *testing.T) {
+		f := newDraft(t)
+		ready, reviewers, err := f.prService.MarkReady(auth.WithActor(ctx, f.members[0].ID), f.ID)
+		if err != nil || ready.Status != model.PRStatusOpen || len(reviewers) != stressReviewers {
+			t.Fatalf("mark ready: %+v, %v, %v", ready, reviewers, err)
+		}
+		if ready.Version != model.InitialPullRequestVersion+1 || !ready.MergedAt.IsZero() {
+			t.Fatalf("unexpected ready pull request %+v", ready)
+		}
+	})
+
+	t.Run("mark ready twice", func(t *testing.T) {
+		f := newDraft(t)
+		asAuthor := auth.WithActor(ctx, f.members[0].ID)
+		if _, _, err := f.prService.MarkReady(asAuthor, f.ID); err != nil {
+			t.Fatalf("mark ready: %v", err)
+		}
+		_, _, err := f.prService.MarkReady(asAuthor, f.ID)
+		if !errors.Is(err, rules.ErrPullRequestNotDraft) {
+			t.Fatalf("expected %v, got %v", rules.ErrPullRequestNotDraft, err)
+		}
+	})
+}
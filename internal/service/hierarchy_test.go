@@ -0,0 +1,119 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"pull-request-review/internal/domain/auth"
+	"pull-request-review/internal/domain/model"
+	"pull-request-review/internal/domain/ports/repository"
+	"pull-request-review/internal/domain/rules"
+)
+
+// TestTeamHierarchy nests teams, refuses cycles even when two teams are moved under each other
+// at once, and merges settings updates into the overrides a team already has.
+func TestTeamHierarchy(t *testing.T) {
+	for name, open := range backends {
+		t.Run(name, func(t *testing.T) { testTeamHierarchy(t, open(t)) })
+	}
+}
+
+func testTeamHierarchy(t *testing.T, repos *repository.Repositories) {
+	ctx := context.Background()
+	_, teamService := newServices(repos, nil)
+
+	admin := newMembers(1)
+	adminTeam := "team-" + uuid.NewString()[:8]
+	err := teamService.CreateTeamWithMembers(auth.WithActor(ctx, admin[0].ID), adminTeam, admin, model.TeamCreateOptions{})
+	if err != nil {
+		t.Fatalf("create team: %v", err)
+	}
+	asAdmin := auth.WithActor(ctx, admin[0].ID)
+
+	// newTeam creates a team the admin may move around
+	newTeam := func(t *testing.T) string {
+		t.Helper()
+		name := "team-" + uuid.NewString()[:8]
+		if err := teamService.CreateTeamWithMembers(asAdmin, name, newMembers(1), model.TeamCreateOptions{}); err != nil {
+			t.Fatalf("create team: %v", err)
+		}
+		return name
+	}
+
+	t.Run("cycles are refused", func(t *testing.T) {
+		root, child := newTeam(t), newTeam(t)
+		if _, err := teamService.SetParentTeam(asAdmin, child, root); err != nil {
+			t.Fatalf("set parent: %v", err)
+		}
+		for _, parent := range []string{child, root} {
+			_, err := teamService.SetParentTeam(asAdmin, root, parent)
+			if !errors.Is(err, rules.ErrTeamCycle) {
+				t.Fatalf("moving %s under %s: expected %v, got %v", root, parent, rules.ErrTeamCycle, err)
+			}
+		}
+
+		team, err := teamService.SetParentTeam(asAdmin, child, "")
+		if err != nil || team.ParentTeamID != uuid.Nil {
+			t.Fatalf("an empty parent should make a root team: %+v, %v", team, err)
+		}
+	})
+
+	t.Run("concurrent moves under each other", func(t *testing.T) {
+		for range stressRounds {
+			first, second := newTeam(t), newTeam(t)
+
+			var wg sync.WaitGroup
+			errs := make([]error, 2)
+			for i, move := range [][2]string{{first, second}, {second, first}} {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					_, errs[i] = teamService.SetParentTeam(asAdmin, move[0], move[1])
+				}()
+			}
+			wg.Wait()
+
+			if (errs[0] == nil) == (errs[1] == nil) {
+				t.Fatalf("exactly one move should win: %v", errs)
+			}
+			for _, err := range errs {
+				if err != nil && !errors.Is(err, rules.ErrTeamCycle) {
+					t.Fatalf("expected %v, got %v", rules.ErrTeamCycle, err)
+				}
+			}
+		}
+	})
+
+	t.Run("settings updates merge", func(t *testing.T) {
+		name := newTeam(t)
+		maxReviewers, fallback := 3, false
+		if _, err := teamService.UpdateSettings(asAdmin, name, model.TeamSettings{MaxReviewers: &maxReviewers}); err != nil {
+			t.Fatalf("update settings: %v", err)
+		}
+		team, err := teamService.UpdateSettings(asAdmin, name, model.TeamSettings{ReviewerFallback: &fallback})
+		if err != nil {
+			t.Fatalf("update settings: %v", err)
+		}
+
+		stored, err := repos.Team.GetByID(ctx, team.TeamID)
+		if err != nil {
+			t.Fatalf("get team: %v", err)
+		}
+		for _, settings := range []model.TeamSettings{team.Settings, stored.Settings} {
+			if settings.MaxReviewers == nil || *settings.MaxReviewers != maxReviewers ||
+				settings.ReviewerFallback == nil || *settings.ReviewerFallback {
+				t.Fatalf("the second update should keep the first: %+v", settings)
+			}
+		}
+
+		invalid := 0
+		_, err = teamService.UpdateSettings(asAdmin, name, model.TeamSettings{MaxReviewers: &invalid})
+		if !errors.Is(err, rules.ErrInvalidTeamSettings) {
+			t.Fatalf("expected %v, got %v", rules.ErrInvalidTeamSettings, err)
+		}
+	})
+}
@@ -0,0 +1,442 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"pull-request-review/internal/domain/model"
+	"pull-request-review/internal/domain/ports/repository"
+	"pull-request-review/internal/domain/rules"
+	"pull-request-review/internal/infrastructure/adapters/logger"
+)
+
+type ImportService struct {
+	teamRepo       repository.TeamRepository
+	userRepo       repository.UserRepository
+	membershipRepo repository.TeamMembershipRepository
+	tx             repository.TxManager
+	logger         logger.Logger
+}
+
+func NewImportService(
+	teamRepo repository.TeamRepository,
+	userRepo repository.UserRepository,
+	membershipRepo repository.TeamMembershipRepository,
+	tx repository.TxManager,
+	logger logger.Logger,
+) *ImportService {
+	return &ImportService{
+		teamRepo:       teamRepo,
+		userRepo:       userRepo,
+		membershipRepo: membershipRepo,
+		tx:             tx,
+		logger:         logger,
+	}
+}
+
+// Import validates the roster and diffs it against the stored teams and users. Unless dryRun
+// is set, the resulting plan is applied in the transaction it was planned in, so nothing
+// changes between the diff and the writes. Imports only add or update, nobody is removed
+// from a team.
+func (s *ImportService) Import(ctx context.Context, roster *model.Roster, dryRun bool) (*model.ImportPlan, error) {
+	// a dry run only reads, so it plans over a snapshot
+	within := s.tx.WithinTx
+	if dryRun {
+		within = s.tx.WithinReadOnlyTx
+	}
+
+	var plan *model.ImportPlan
+	err := within(ctx, func(ctx context.Context) error {
+		var err error
+		plan, err = s.importRoster(ctx, roster, dryRun)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return plan, nil
+}
+
+func (s *ImportService) importRoster(
+	ctx context.Context, roster *model.Roster, dryRun bool,
+) (*model.ImportPlan, error) {
+	existingTeams, err := s.teamRepo.GetAll(ctx)
+	if err != nil {
+		s.logger.Error(err, "cannot get teams")
+		return nil, err
+	}
+
+	problems := validateRoster(roster, existingTeams)
+	if len(problems) > 0 {
+		return nil, &rules.ImportValidationError{Problems: problems}
+	}
+
+	plan, err := s.buildImportPlan(ctx, roster, existingTeams)
+	if err != nil {
+		return nil, err
+	}
+
+	if dryRun || plan.IsEmpty() {
+		return plan, nil
+	}
+
+	err = s.teamRepo.ApplyImport(ctx, plan)
+	if err != nil {
+		s.logger.Error(err, "cannot apply import")
+		return nil, err
+	}
+
+	return plan, nil
+}
+
+func validateRoster(roster *model.Roster, existingTeams []model.Team) []string {
+	var problems []string
+	if len(roster.Teams) == 0 {
+		return []string{"roster has no teams"}
+	}
+
+	existingNames := make(map[uuid.UUID]string, len(existingTeams))
+	for _, team := range existingTeams {
+		existingNames[uuid.UUID(team.TeamID)] = team.Name
+	}
+
+	// parents maps every known team name to its parent name, with the roster taking precedence
+	parents := make(map[string]string)
+	for _, team := range existingTeams {
+		parents[team.Name] = existingNames[team.ParentTeamID]
+	}
+
+	seenTeams := make(map[string]bool)
+	for i, team := range roster.Teams {
+		if strings.TrimSpace(team.Name) == "" {
+			problems = append(problems, fmt.Sprintf("team #%d: name is required", i+1))
+			continue
+		}
+		if seenTeams[team.Name] {
+			problems = append(problems, fmt.Sprintf("team %q is listed more than once", team.Name))
+			continue
+		}
+		seenTeams[team.Name] = true
+
+		if _, ok := parents[team.Name]; !ok || team.ParentTeamName != "" {
+			parents[team.Name] = team.ParentTeamName
+		}
+	}
+
+	type userEntry struct {
+		member   model.RosterMember
+		teamName string
+	}
+	users := make(map[model.UserID]userEntry)
+
+	for _, team := range roster.Teams {
+		if strings.TrimSpace(team.Name) == "" {
+			continue
+		}
+
+		if team.ParentTeamName == team.Name {
+			problems = append(problems, fmt.Sprintf("team %q cannot be its own parent", team.Name))
+		} else if team.ParentTeamName != "" {
+			if _, ok := parents[team.ParentTeamName]; !ok {
+				problems = append(
+					problems, fmt.Sprintf("team %q: parent team %q does not exist", team.Name, team.ParentTeamName),
+				)
+			} else if formsCycle(team.Name, parents) {
+				problems = append(problems, fmt.Sprintf("team %q: parent chain forms a cycle", team.Name))
+			}
+		}
+
+		seenMembers := make(map[model.UserID]bool)
+		for _, member := range team.Members {
+			userID := uuid.UUID(member.UserID).String()
+			if seenMembers[member.UserID] {
+				problems = append(problems, fmt.Sprintf("team %q: user %s is listed more than once", team.Name, userID))
+				continue
+			}
+			seenMembers[member.UserID] = true
+
+			if strings.TrimSpace(member.Username) == "" {
+				problems = append(problems, fmt.Sprintf("team %q: user %s has no username", team.Name, userID))
+			}
+			if member.Weight < 0 {
+				problems = append(problems, fmt.Sprintf("team %q: user %s has a negative weight", team.Name, userID))
+			}
+
+			entry, ok := users[member.UserID]
+			if !ok {
+				users[member.UserID] = userEntry{member: member, teamName: team.Name}
+				continue
+			}
+			if entry.member.Username != member.Username || entry.member.IsActive != member.IsActive {
+				problems = append(
+					problems, fmt.Sprintf(
+						"user %s has conflicting entries in teams %q and %q", userID, entry.teamName, team.Name,
+					),
+				)
+			}
+		}
+	}
+
+	return problems
+}
+
+func formsCycle(teamName string, parents map[string]string) bool {
+	visited := map[string]bool{teamName: true}
+	for current := parents[teamName]; current != ""; current = parents[current] {
+		if visited[current] {
+			return true
+		}
+		visited[current] = true
+	}
+	return false
+}
+
+func (s *ImportService) buildImportPlan(
+	ctx context.Context, roster *model.Roster, existingTeams []model.Team,
+) (*model.ImportPlan, error) {
+	plan := &model.ImportPlan{}
+	now := time.Now()
+
+	teamsByName := make(map[string]model.Team, len(existingTeams))
+	existingNames := make(map[uuid.UUID]string, len(existingTeams))
+	for _, team := range existingTeams {
+		teamsByName[team.Name] = team
+		existingNames[uuid.UUID(team.TeamID)] = team.Name
+	}
+	newTeams := make(map[string]bool)
+
+	for _, rosterTeam := range orderRosterTeams(roster.Teams) {
+		var parentID uuid.UUID
+		if rosterTeam.ParentTeamName != "" {
+			parentID = uuid.UUID(teamsByName[rosterTeam.ParentTeamName].TeamID)
+		}
+
+		team, exists := teamsByName[rosterTeam.Name]
+		switch {
+		case !exists:
+			team = model.Team{
+				TeamID:       model.TeamID(uuid.New()),
+				Name:         rosterTeam.Name,
+				ParentTeamID: parentID,
+				CreatedAt:    now,
+			}
+			newTeams[team.Name] = true
+
+			change := model.ImportChange{
+				Action:  model.ImportActionCreate,
+				Subject: model.ImportSubjectTeam,
+				Key:     team.Name,
+			}
+			if rosterTeam.ParentTeamName != "" {
+				change.Details = []string{"parent: " + rosterTeam.ParentTeamName}
+			}
+			plan.Teams = append(plan.Teams, team)
+			plan.Changes = append(plan.Changes, change)
+		case rosterTeam.ParentTeamName != "" && team.ParentTeamID != parentID:
+			oldParent := existingNames[team.ParentTeamID]
+			team.ParentTeamID = parentID
+
+			plan.Teams = append(plan.Teams, team)
+			plan.Changes = append(
+				plan.Changes, model.ImportChange{
+					Action:  model.ImportActionUpdate,
+					Subject: model.ImportSubjectTeam,
+					Key:     team.Name,
+					Details: []string{fmt.Sprintf("parent: %q -> %q", oldParent, rosterTeam.ParentTeamName)},
+				},
+			)
+		}
+
+		teamsByName[team.Name] = team
+	}
+
+	planned := make(map[model.UserID]bool)
+	for _, rosterTeam := range roster.Teams {
+		team := teamsByName[rosterTeam.Name]
+
+		for _, member := range rosterTeam.Members {
+			memberships, err := s.planUser(ctx, plan, team, member, planned[member.UserID], now)
+			if err != nil {
+				return nil, err
+			}
+			planned[member.UserID] = true
+
+			s.planMembership(plan, team, member, memberships, newTeams[team.Name], now)
+		}
+	}
+
+	return plan, nil
+}
+
+// orderRosterTeams puts every roster team after its parent, so parents are written first.
+func orderRosterTeams(teams []model.RosterTeam) []model.RosterTeam {
+	byName := make(map[string]model.RosterTeam, len(teams))
+	for _, team := range teams {
+		byName[team.Name] = team
+	}
+
+	ordered := make([]model.RosterTeam, 0, len(teams))
+	placed := make(map[string]bool, len(teams))
+
+	var place func(team model.RosterTeam)
+	place = func(team model.RosterTeam) {
+		if placed[team.Name] {
+			return
+		}
+		placed[team.Name] = true
+
+		if parent, ok := byName[team.ParentTeamName]; ok {
+			place(parent)
+		}
+		ordered = append(ordered, team)
+	}
+
+	for _, team := range teams {
+		place(team)
+	}
+
+	return ordered
+}
+
+// planUser adds the user record to the plan when it is new or differs from the roster, and
+// returns the user's current memberships keyed by team.
+func (s *ImportService) planUser(
+	ctx context.Context, plan *model.ImportPlan, team model.Team, member model.RosterMember, planned bool,
+	now time.Time,
+) (map[model.TeamID]float64, error) {
+	memberships := make(map[model.TeamID]float64)
+	userKey := uuid.UUID(member.UserID).String()
+
+	user, err := s.userRepo.GetByID(ctx, member.UserID)
+	if errors.Is(err, rules.ErrUserNotFound) {
+		if !planned {
+			plan.Users = append(
+				plan.Users, model.User{
+					ID:        member.UserID,
+					Username:  member.Username,
+					TeamID:    uuid.UUID(team.TeamID),
+					IsActive:  member.IsActive,
+					CreatedAt: now,
+					UpdatedAt: now,
+				},
+			)
+			plan.Changes = append(
+				plan.Changes, model.ImportChange{
+					Action:  model.ImportActionCreate,
+					Subject: model.ImportSubjectUser,
+					Key:     userKey,
+					Details: []string{"username: " + member.Username, fmt.Sprintf("is_active: %t", member.IsActive)},
+				},
+			)
+		}
+		return memberships, nil
+	}
+	if err != nil {
+		s.logger.Error(err, "cannot get user")
+		return nil, err
+	}
+
+	current, err := s.membershipRepo.GetByUser(ctx, member.UserID)
+	if err != nil {
+		s.logger.Error(err, "cannot get user teams")
+		return nil, err
+	}
+	for _, membership := range current {
+		memberships[membership.TeamID] = membership.Weight
+	}
+
+	if planned {
+		return memberships, nil
+	}
+
+	var details []string
+	if user.Username != member.Username {
+		details = append(details, fmt.Sprintf("username: %q -> %q", user.Username, member.Username))
+		user.Username = member.Username
+	}
+	if user.IsActive != member.IsActive {
+		details = append(details, fmt.Sprintf("is_active: %t -> %t", user.IsActive, member.IsActive))
+		user.IsActive = member.IsActive
+	}
+	if user.TeamID == uuid.Nil {
+		details = append(details, "primary team: "+team.Name)
+		user.TeamID = uuid.UUID(team.TeamID)
+	}
+
+	if len(details) > 0 {
+		user.UpdatedAt = now
+		plan.Users = append(plan.Users, *user)
+		plan.Changes = append(
+			plan.Changes, model.ImportChange{
+				Action:  model.ImportActionUpdate,
+				Subject: model.ImportSubjectUser,
+				Key:     userKey,
+				Details: details,
+			},
+		)
+	}
+
+	return memberships, nil
+}
+
+func (s *ImportService) planMembership(
+	plan *model.ImportPlan, team model.Team, member model.RosterMember, memberships map[model.TeamID]float64,
+	newTeam bool, now time.Time,
+) {
+	key := team.Name + ": " + uuid.UUID(member.UserID).String()
+
+	currentWeight, isMember := memberships[team.TeamID]
+	if newTeam {
+		isMember = false
+	}
+
+	switch {
+	case !isMember:
+		weight := member.Weight
+		if weight == 0 {
+			weight = model.DefaultMembershipWeight
+		}
+
+		plan.Memberships = append(
+			plan.Memberships, model.TeamMembership{
+				TeamID:    team.TeamID,
+				UserID:    member.UserID,
+				TeamName:  team.Name,
+				Weight:    weight,
+				CreatedAt: now,
+			},
+		)
+		plan.Changes = append(
+			plan.Changes, model.ImportChange{
+				Action:  model.ImportActionCreate,
+				Subject: model.ImportSubjectMembership,
+				Key:     key,
+				Details: []string{fmt.Sprintf("weight: %g", weight)},
+			},
+		)
+	case member.Weight != 0 && member.Weight != currentWeight:
+		plan.Memberships = append(
+			plan.Memberships, model.TeamMembership{
+				TeamID:    team.TeamID,
+				UserID:    member.UserID,
+				TeamName:  team.Name,
+				Weight:    member.Weight,
+				CreatedAt: now,
+			},
+		)
+		plan.Changes = append(
+			plan.Changes, model.ImportChange{
+				Action:  model.ImportActionUpdate,
+				Subject: model.ImportSubjectMembership,
+				Key:     key,
+				Details: []string{fmt.Sprintf("weight: %g -> %g", currentWeight, member.Weight)},
+			},
+		)
+	}
+}
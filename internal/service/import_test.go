@@ -0,0 +1,217 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"pull-request-review/internal/domain/model"
+	"pull-request-review/internal/domain/ports/repository"
+	"pull-request-review/internal/domain/rules"
+	"pull-request-review/internal/infrastructure/adapters/logger"
+	"pull-request-review/internal/service"
+)
+
+// TestImport checks the roster validation, the dry-run diff and that applying a roster twice
+// changes nothing the second time.
+func TestImport(t *testing.T) {
+	for name, open := range backends {
+		t.Run(name, func(t *testing.T) { testImport(t, open(t)) })
+	}
+}
+
+func testImport(t *testing.T, repos *repository.Repositories) {
+	ctx := context.Background()
+	importService := service.NewImportService(
+		repos.Team, repos.User, repos.TeamMembership, repos.Tx, logger.NewZerologLogger(),
+	)
+
+	newName := func() string { return "team-" + uuid.NewString()[:8] }
+	member := func(username string) model.RosterMember {
+		return model.RosterMember{UserID: model.UserID(uuid.New()), Username: username, IsActive: true}
+	}
+
+	t.Run("validation", func(t *testing.T) {
+		// upper is stored under lower, so moving lower under upper closes a cycle through the stored teams
+		lower, upper := newName(), newName()
+		lowerTeam := &model.Team{TeamID: model.TeamID(uuid.New()), Name: lower, CreatedAt: time.Now()}
+		upperTeam := &model.Team{
+			TeamID: model.TeamID(uuid.New()), Name: upper, ParentTeamID: uuid.UUID(lowerTeam.TeamID), CreatedAt: time.Now(),
+		}
+		for _, team := range []*model.Team{lowerTeam, upperTeam} {
+			if err := repos.Team.Create(ctx, team); err != nil {
+				t.Fatalf("create team: %v", err)
+			}
+		}
+
+		alice := member("alice")
+		renamed, inactive, heavy := alice, alice, alice
+		renamed.Username = "alicia"
+		inactive.IsActive = false
+		heavy.Weight = -1
+		nameless := member(" ")
+
+		for _, tc := range []struct {
+			name  string
+			teams []model.RosterTeam
+			// problems are the substrings the reported problems have to contain, in order
+			problems []string
+		}{
+			{"no teams", nil, []string{"roster has no teams"}},
+			{"team without a name", []model.RosterTeam{{Name: " "}}, []string{"team #1: name is required"}},
+			{"team listed twice", []model.RosterTeam{{Name: "a"}, {Name: "a"}}, []string{`team "a" is listed more than once`}},
+			{"unknown parent", []model.RosterTeam{{Name: "a", ParentTeamName: "missing-" + uuid.NewString()}},
+				[]string{`team "a": parent team "missing-`}},
+			{"own parent", []model.RosterTeam{{Name: "a", ParentTeamName: "a"}}, []string{`team "a" cannot be its own parent`}},
+			{"cycle in the roster", []model.RosterTeam{{Name: "a", ParentTeamName: "b"}, {Name: "b", ParentTeamName: "a"}},
+				[]string{`team "a": parent chain forms a cycle`, `team "b": parent chain forms a cycle`}},
+			{"cycle through stored teams", []model.RosterTeam{{Name: lower, ParentTeamName: upper}},
+				[]string{`parent chain forms a cycle`}},
+			{"member listed twice", []model.RosterTeam{{Name: "a", Members: []model.RosterMember{alice, alice}}},
+				[]string{"is listed more than once"}},
+			{"member without a username", []model.RosterTeam{{Name: "a", Members: []model.RosterMember{nameless}}},
+				[]string{"has no username"}},
+			{"negative weight", []model.RosterTeam{{Name: "a", Members: []model.RosterMember{heavy}}},
+				[]string{"has a negative weight"}},
+			{"member renamed in another team", []model.RosterTeam{
+				{Name: "a", Members: []model.RosterMember{alice}}, {Name: "b", Members: []model.RosterMember{renamed}},
+			}, []string{`has conflicting entries in teams "a" and "b"`}},
+			{"member deactivated in another team", []model.RosterTeam{
+				{Name: "a", Members: []model.RosterMember{alice}}, {Name: "b", Members: []model.RosterMember{inactive}},
+			}, []string{`has conflicting entries in teams "a" and "b"`}},
+		} {
+			t.Run(tc.name, func(t *testing.T) {
+				_, err := importService.Import(ctx, &model.Roster{Teams: tc.teams}, true)
+
+				var validationErr *rules.ImportValidationError
+				if !errors.As(err, &validationErr) || !errors.Is(err, rules.ErrInvalidImport) {
+					t.Fatalf("expected an import validation error, got %v", err)
+				}
+				if len(validationErr.Problems) != len(tc.problems) {
+					t.Fatalf("problems %q, want %q", validationErr.Problems, tc.problems)
+				}
+				for i, problem := range tc.problems {
+					if !strings.Contains(validationErr.Problems[i], problem) {
+						t.Fatalf("problems %q, want %q", validationErr.Problems, tc.problems)
+					}
+				}
+			})
+		}
+	})
+
+	t.Run("dry run and apply", func(t *testing.T) {
+		parent, child := newName(), newName()
+		alice := member("alice")
+		alice.Weight = 2
+		aliceID := uuid.UUID(alice.UserID).String()
+
+		// the child comes first, the plan still creates the parent before it
+		roster := &model.Roster{Teams: []model.RosterTeam{
+			{Name: child, ParentTeamName: parent, Members: []model.RosterMember{alice}},
+			{Name: parent, Members: []model.RosterMember{{UserID: alice.UserID, Username: "alice", IsActive: true}}},
+		}}
+		want := []model.ImportChange{
+			{Action: model.ImportActionCreate, Subject: model.ImportSubjectTeam, Key: parent},
+			{Action: model.ImportActionCreate, Subject: model.ImportSubjectTeam, Key: child,
+				Details: []string{"parent: " + parent}},
+			{Action: model.ImportActionCreate, Subject: model.ImportSubjectUser, Key: aliceID,
+				Details: []string{"username: alice", "is_active: true"}},
+			{Action: model.ImportActionCreate, Subject: model.ImportSubjectMembership, Key: child + ": " + aliceID,
+				Details: []string{"weight: 2"}},
+			{Action: model.ImportActionCreate, Subject: model.ImportSubjectMembership, Key: parent + ": " + aliceID,
+				Details: []string{"weight: 1"}},
+		}
+
+		plan, err := importService.Import(ctx, roster, true)
+		if err != nil {
+			t.Fatalf("dry run: %v", err)
+		}
+		if !reflect.DeepEqual(plan.Changes, want) {
+			t.Fatalf("dry run changes %+v, want %+v", plan.Changes, want)
+		}
+		if exists, err := repos.Team.ExistsByName(ctx, child); err != nil || exists {
+			t.Fatalf("a dry run should not write anything: %v, %v", exists, err)
+		}
+		if _, err := repos.User.GetByID(ctx, alice.UserID); !errors.Is(err, rules.ErrUserNotFound) {
+			t.Fatalf("a dry run should not create users: %v", err)
+		}
+
+		plan, err = importService.Import(ctx, roster, false)
+		if err != nil || !reflect.DeepEqual(plan.Changes, want) {
+			t.Fatalf("apply: %+v, %v", plan, err)
+		}
+		childTeam, err := repos.Team.GetByName(ctx, child)
+		if err != nil {
+			t.Fatalf("get team: %v", err)
+		}
+		user, err := repos.User.GetByID(ctx, alice.UserID)
+		if err != nil || model.TeamID(user.TeamID) != childTeam.TeamID {
+			t.Fatalf("the user should get the first roster team as primary team: %+v, %v", user, err)
+		}
+
+		plan, err = importService.Import(ctx, roster, true)
+		if err != nil || !plan.IsEmpty() {
+			t.Fatalf("importing the same roster again should change nothing: %+v, %v", plan, err)
+		}
+
+		// a changed username and weight show up as updates, a zero weight keeps the stored one
+		roster.Teams[0].Members[0].Username = "alicia"
+		roster.Teams[0].Members[0].Weight = 3
+		roster.Teams[1].Members[0].Username = "alicia"
+		plan, err = importService.Import(ctx, roster, true)
+		if err != nil {
+			t.Fatalf("dry run: %v", err)
+		}
+		want = []model.ImportChange{
+			{Action: model.ImportActionUpdate, Subject: model.ImportSubjectUser, Key: aliceID,
+				Details: []string{`username: "alice" -> "alicia"`}},
+			{Action: model.ImportActionUpdate, Subject: model.ImportSubjectMembership, Key: child + ": " + aliceID,
+				Details: []string{"weight: 2 -> 3"}},
+		}
+		if !reflect.DeepEqual(plan.Changes, want) {
+			t.Fatalf("dry run changes %+v, want %+v", plan.Changes, want)
+		}
+	})
+
+	t.Run("one transaction per import", func(t *testing.T) {
+		tx := &txCounter{TxManager: repos.Tx}
+		importService := service.NewImportService(
+			repos.Team, repos.User, repos.TeamMembership, tx, logger.NewZerologLogger(),
+		)
+		roster := &model.Roster{Teams: []model.RosterTeam{{Name: newName(), Members: []model.RosterMember{member("bob")}}}}
+
+		if _, err := importService.Import(ctx, roster, true); err != nil {
+			t.Fatalf("dry run: %v", err)
+		}
+		if tx.reads != 1 || tx.writes != 0 {
+			t.Fatalf("a dry run should read in one snapshot: %d reads, %d writes", tx.reads, tx.writes)
+		}
+		if _, err := importService.Import(ctx, roster, false); err != nil {
+			t.Fatalf("apply: %v", err)
+		}
+		if tx.reads != 1 || tx.writes != 1 {
+			t.Fatalf("an import should plan and apply in one transaction: %d reads, %d writes", tx.reads, tx.writes)
+		}
+	})
+}
+
+// txCounter counts the transactions opened through it.
+type txCounter struct {
+	repository.TxManager
+	reads, writes int
+}
+
+func (c *txCounter) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	c.writes++
+	return c.TxManager.WithinTx(ctx, fn)
+}
+
+func (c *txCounter) WithinReadOnlyTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	c.reads++
+	return c.TxManager.WithinReadOnlyTx(ctx, fn)
+}
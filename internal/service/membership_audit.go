@@ -0,0 +1,31 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"pull-request-review/internal/domain/auth"
+	"pull-request-review/internal/domain/model"
+)
+
+func newMembershipChange(
+	ctx context.Context,
+	userID model.UserID,
+	fromTeamID model.TeamID,
+	toTeamID model.TeamID,
+	reason model.MembershipChangeReason,
+) *model.MembershipChange {
+	actorID, _ := auth.ActorFromContext(ctx)
+
+	return &model.MembershipChange{
+		ID:         uuid.New(),
+		UserID:     userID,
+		FromTeamID: fromTeamID,
+		ToTeamID:   toTeamID,
+		ActorID:    actorID,
+		Reason:     reason,
+		CreatedAt:  time.Now(),
+	}
+}
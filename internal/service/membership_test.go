@@ -0,0 +1,356 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"pull-request-review/internal/domain/auth"
+	"pull-request-review/internal/domain/model"
+	"pull-request-review/internal/domain/ports/repository"
+	"pull-request-review/internal/domain/rules"
+	"pull-request-review/internal/infrastructure/adapters/logger"
+	"pull-request-review/internal/service"
+)
+
+// TestTeamMembership adds, removes and moves members, renames teams, and checks what happens
+// to the reviews of a member who moves away under each review policy.
+func TestTeamMembership(t *testing.T) {
+	for name, open := range backends {
+		t.Run(name, func(t *testing.T) { testTeamMembership(t, open(t)) })
+	}
+}
+
+func testTeamMembership(t *testing.T, repos *repository.Repositories) {
+	ctx := context.Background()
+	audit := &auditLog{MembershipAuditRepository: repos.MembershipAudit}
+	prService, teamService, userService := newMembershipServices(repos, audit)
+
+	alpha, beta := newMembers(6), newMembers(3)
+	admin := alpha[0].ID
+	asAdmin := auth.WithActor(ctx, admin)
+	alphaName, betaName := "team-"+uuid.NewString()[:8], "team-"+uuid.NewString()[:8]
+	// alpha goes first: the admin only gets a role in teams created once the admin is stored
+	for _, team := range []struct {
+		name    string
+		members []model.User
+	}{{alphaName, alpha}, {betaName, beta}} {
+		if err := teamService.CreateTeamWithMembers(asAdmin, team.name, team.members, model.TeamCreateOptions{}); err != nil {
+			t.Fatalf("create team: %v", err)
+		}
+	}
+	betaTeam, err := repos.Team.GetByName(ctx, betaName)
+	if err != nil {
+		t.Fatalf("get team: %v", err)
+	}
+
+	primaryTeam := func(t *testing.T, userID model.UserID) model.TeamID {
+		t.Helper()
+		user, err := repos.User.GetByID(ctx, userID)
+		if err != nil {
+			t.Fatalf("get user: %v", err)
+		}
+		return model.TeamID(user.TeamID)
+	}
+
+	t.Run("AddMember", func(t *testing.T) {
+		newcomer := newMembers(1)[0]
+		team, members, err := teamService.AddMember(asAdmin, alphaName, newcomer, 0)
+		if err != nil || !slices.Contains(userIDs(members), newcomer.ID) {
+			t.Fatalf("add member: %v, %v", userIDs(members), err)
+		}
+		if primaryTeam(t, newcomer.ID) != team.TeamID {
+			t.Fatalf("a user without a team should get the team as primary team")
+		}
+
+		// a member of another team keeps it as primary team
+		if _, _, err := teamService.AddMember(asAdmin, alphaName, beta[1], 2); err != nil {
+			t.Fatalf("add member: %v", err)
+		}
+		if primaryTeam(t, beta[1].ID) != betaTeam.TeamID {
+			t.Fatalf("an existing user should keep the primary team")
+		}
+		audit.expect(t, beta[1].ID, model.MembershipChangeAddMember, model.TeamID(uuid.Nil), team.TeamID, admin)
+
+		for _, tc := range []struct {
+			name   string
+			ctx    context.Context
+			member model.User
+			weight float64
+			want   error
+		}{
+			{"already a member", asAdmin, newcomer, 0, rules.ErrUserExists},
+			{"negative weight", asAdmin, newMembers(1)[0], -1, rules.ErrInvalidWeight},
+			{"plain member", auth.WithActor(ctx, alpha[1].ID), newMembers(1)[0], 0, rules.ErrForbidden},
+		} {
+			t.Run(tc.name, func(t *testing.T) {
+				_, _, err := teamService.AddMember(tc.ctx, alphaName, tc.member, tc.weight)
+				if !errors.Is(err, tc.want) {
+					t.Fatalf("expected %v, got %v", tc.want, err)
+				}
+			})
+		}
+	})
+
+	t.Run("RemoveMember", func(t *testing.T) {
+		// beta[1] belongs to both teams now, leaving beta makes alpha the primary team
+		team, members, err := teamService.RemoveMember(asAdmin, betaName, beta[1].ID, model.ReviewPolicyKeep)
+		if err != nil || slices.Contains(userIDs(members), beta[1].ID) {
+			t.Fatalf("remove member: %v, %v", userIDs(members), err)
+		}
+		if primary := primaryTeam(t, beta[1].ID); primary == team.TeamID || primary == model.TeamID(uuid.Nil) {
+			t.Fatalf("the primary team should move to the remaining membership, is %v", primary)
+		}
+		audit.expect(t, beta[1].ID, model.MembershipChangeRemoveMember, team.TeamID, model.TeamID(uuid.Nil), admin)
+
+		for _, tc := range []struct {
+			name   string
+			userID model.UserID
+			policy model.ReviewPolicy
+			want   error
+		}{
+			{"not a member", beta[1].ID, model.ReviewPolicyKeep, rules.ErrUserNotFound},
+			{"unknown user", model.UserID(uuid.New()), model.ReviewPolicyKeep, rules.ErrUserNotFound},
+			{"invalid policy", beta[2].ID, model.ReviewPolicy("drop"), rules.ErrInvalidReviewPolicy},
+		} {
+			t.Run(tc.name, func(t *testing.T) {
+				_, _, err := teamService.RemoveMember(asAdmin, betaName, tc.userID, tc.policy)
+				if !errors.Is(err, tc.want) {
+					t.Fatalf("expected %v, got %v", tc.want, err)
+				}
+			})
+		}
+	})
+
+	t.Run("RenameTeam", func(t *testing.T) {
+		newName := "team-" + uuid.NewString()[:8]
+		team, _, err := teamService.RenameTeam(asAdmin, betaName, newName)
+		if err != nil || team.Name != newName {
+			t.Fatalf("rename team: %+v, %v", team, err)
+		}
+		betaName = newName
+		if _, err := repos.Team.GetByName(ctx, newName); err != nil {
+			t.Fatalf("the renamed team should be found by its new name: %v", err)
+		}
+
+		if _, _, err := teamService.RenameTeam(asAdmin, betaName, betaName); err != nil {
+			t.Fatalf("keeping the name should be a no-op: %v", err)
+		}
+		_, _, err = teamService.RenameTeam(asAdmin, betaName, alphaName)
+		if !errors.Is(err, rules.ErrTeamExists) {
+			t.Fatalf("expected %v, got %v", rules.ErrTeamExists, err)
+		}
+		_, _, err = teamService.RenameTeam(auth.WithActor(ctx, beta[2].ID), betaName, "team-"+uuid.NewString()[:8])
+		if !errors.Is(err, rules.ErrForbidden) {
+			t.Fatalf("expected %v, got %v", rules.ErrForbidden, err)
+		}
+	})
+
+	t.Run("concurrent renames to one name", func(t *testing.T) {
+		for range stressRounds {
+			names := []string{"team-" + uuid.NewString()[:8], "team-" + uuid.NewString()[:8]}
+			for _, name := range names {
+				err := teamService.CreateTeamWithMembers(asAdmin, name, newMembers(1), model.TeamCreateOptions{})
+				if err != nil {
+					t.Fatalf("create team: %v", err)
+				}
+			}
+
+			newName := "team-" + uuid.NewString()[:8]
+			var wg sync.WaitGroup
+			errs := make([]error, len(names))
+			for i, name := range names {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					_, _, errs[i] = teamService.RenameTeam(asAdmin, name, newName)
+				}()
+			}
+			wg.Wait()
+
+			if (errs[0] == nil) == (errs[1] == nil) {
+				t.Fatalf("exactly one rename should win: %v", errs)
+			}
+			for _, err := range errs {
+				if err != nil && !errors.Is(err, rules.ErrTeamExists) {
+					t.Fatalf("expected %v, got %v", rules.ErrTeamExists, err)
+				}
+			}
+		}
+	})
+
+	t.Run("a review nobody can take over keeps its version", func(t *testing.T) {
+		// the author and the two reviewers are the whole team, so nobody is left to take over
+		members := newMembers(stressReviewers + 1)
+		name := "team-" + uuid.NewString()[:8]
+		if err := teamService.CreateTeamWithMembers(asAdmin, name, members, model.TeamCreateOptions{}); err != nil {
+			t.Fatalf("create team: %v", err)
+		}
+		prID, reviewers := createPullRequest(t, prService, members[0].ID)
+		before, err := prService.GetPullRequest(ctx, prID)
+		if err != nil {
+			t.Fatalf("get pull request: %v", err)
+		}
+
+		if _, err := userService.MoveToTeam(asAdmin, reviewers[0], "", betaName, model.ReviewPolicyReassign); err != nil {
+			t.Fatalf("move to team: %v", err)
+		}
+
+		currentIDs, err := prService.GetPullRequestReviewers(ctx, prID)
+		if err != nil || !slices.Contains(currentIDs, reviewers[0]) {
+			t.Fatalf("the moved reviewer should keep the review: %v, %v", currentIDs, err)
+		}
+		after, err := prService.GetPullRequest(ctx, prID)
+		if err != nil || after.Version != before.Version {
+			t.Fatalf("version %d after an unchanged review, was %d: %v", after.Version, before.Version, err)
+		}
+	})
+
+	t.Run("MoveToTeam", func(t *testing.T) {
+		author := alpha[5].ID
+		for _, tc := range []struct {
+			policy model.ReviewPolicy
+			// keeps is whether the moved reviewer still reviews, replaced whether somebody took over
+			keeps, replaced bool
+		}{
+			{model.ReviewPolicyKeep, true, false},
+			{model.ReviewPolicyReassign, false, true},
+			{model.ReviewPolicyUnassign, false, false},
+		} {
+			t.Run(string(tc.policy), func(t *testing.T) {
+				pr, reviewers, err := prService.CreatePullRequest(
+					ctx, &model.PullRequest{
+						PullRequestID: model.PullRequestID(uuid.New()), Name: "move", AuthorID: author,
+					},
+				)
+				if err != nil || len(reviewers) != stressReviewers {
+					t.Fatalf("create pull request: %v, %v", reviewers, err)
+				}
+				// the admin would lose the role by leaving the team
+				moved := reviewers[0]
+				if moved == admin {
+					moved = reviewers[1]
+				}
+				from := primaryTeam(t, moved)
+
+				user, err := userService.MoveToTeam(asAdmin, moved, "", betaName, tc.policy)
+				if err != nil || model.TeamID(user.TeamID) != betaTeam.TeamID {
+					t.Fatalf("move to team: %+v, %v", user, err)
+				}
+				audit.expect(t, moved, model.MembershipChangeMoveTeam, from, betaTeam.TeamID, admin)
+
+				currentIDs, err := prService.GetPullRequestReviewers(ctx, pr.PullRequestID)
+				if err != nil {
+					t.Fatalf("get reviewers: %v", err)
+				}
+				if slices.Contains(currentIDs, moved) != tc.keeps {
+					t.Fatalf("moved reviewer kept: %v, want %v", !tc.keeps, tc.keeps)
+				}
+				if replaced := len(currentIDs) == stressReviewers && !tc.keeps; replaced != tc.replaced {
+					t.Fatalf("reviewers %v after moving %v", currentIDs, moved)
+				}
+
+				// moving back keeps the following cases independent
+				_, err = userService.MoveToTeam(asAdmin, moved, betaName, alphaName, model.ReviewPolicyKeep)
+				if err != nil {
+					t.Fatalf("move back: %v", err)
+				}
+			})
+		}
+
+		if _, _, err := teamService.AddMember(asAdmin, betaName, alpha[1], 0); err != nil {
+			t.Fatalf("add member: %v", err)
+		}
+		for _, tc := range []struct {
+			name   string
+			ctx    context.Context
+			userID model.UserID
+			to     string
+			policy model.ReviewPolicy
+			want   error
+		}{
+			{"current team is a no-op", asAdmin, alpha[2].ID, alphaName, model.ReviewPolicyKeep, nil},
+			{"already in the target team", asAdmin, alpha[1].ID, betaName, model.ReviewPolicyKeep, rules.ErrUserExists},
+			{"invalid policy", asAdmin, alpha[2].ID, betaName, model.ReviewPolicy("drop"), rules.ErrInvalidReviewPolicy},
+			{"unknown team", asAdmin, alpha[2].ID, "missing-" + uuid.NewString(), model.ReviewPolicyKeep,
+				rules.ErrTeamNotFound},
+			{"plain member", auth.WithActor(ctx, alpha[3].ID), alpha[2].ID, betaName, model.ReviewPolicyKeep,
+				rules.ErrForbidden},
+		} {
+			t.Run(tc.name, func(t *testing.T) {
+				_, err := userService.MoveToTeam(tc.ctx, tc.userID, "", tc.to, tc.policy)
+				if !errors.Is(err, tc.want) {
+					t.Fatalf("expected %v, got %v", tc.want, err)
+				}
+			})
+		}
+	})
+}
+
+func newMembershipServices(repos *repository.Repositories, audit repository.MembershipAuditRepository) (
+	*service.PullRequestService, *service.TeamService, *service.UserService,
+) {
+	log := logger.NewZerologLogger()
+	prService, _ := newServices(repos, nil)
+	teamService := service.NewTeamService(
+		repos.Team, repos.User, repos.TeamMembership, repos.TeamRole, audit, prService, repos.Tx, log,
+	)
+	userService := service.NewUserService(
+		repos.User, repos.Team, repos.TeamMembership, repos.TeamRole, audit, prService, repos.Tx, log,
+	)
+	return prService, teamService, userService
+}
+
+// auditLog keeps the membership changes recorded through it, for the tests to look at.
+type auditLog struct {
+	repository.MembershipAuditRepository
+	mu      sync.Mutex
+	changes []model.MembershipChange
+}
+
+func (l *auditLog) Record(ctx context.Context, change *model.MembershipChange) error {
+	l.mu.Lock()
+	l.changes = append(l.changes, *change)
+	l.mu.Unlock()
+	return l.MembershipAuditRepository.Record(ctx, change)
+}
+
+// expect fails unless the last change recorded for userID is the given one.
+func (l *auditLog) expect(
+	t *testing.T, userID model.UserID, reason model.MembershipChangeReason, from, to model.TeamID, actor model.UserID,
+) {
+	t.Helper()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for i := len(l.changes) - 1; i >= 0; i-- {
+		change := l.changes[i]
+		if change.UserID != userID {
+			continue
+		}
+		if change.Reason != reason || change.FromTeamID != from || change.ToTeamID != to || change.ActorID != actor {
+			t.Fatalf("unexpected membership change %+v, want %s from %v to %v by %v", change, reason, from, to, actor)
+		}
+		return
+	}
+	t.Fatalf("no membership change recorded for %v", userID)
+}
+
+// recorded returns how many changes were recorded for userID.
+func (l *auditLog) recorded(userID model.UserID) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	count := 0
+	for _, change := range l.changes {
+		if change.UserID == userID {
+			count++
+		}
+	}
+	return count
+}
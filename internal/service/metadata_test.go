@@ -0,0 +1,143 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"pull-request-review/internal/domain/auth"
+	"pull-request-review/internal/domain/model"
+	"pull-request-review/internal/domain/ports/repository"
+	"pull-request-review/internal/domain/rules"
+	"pull-request-review/internal/service"
+)
+
+// TestPullRequestMetadata creates a pull request with metadata, edits it and filters a
+// reviewer's queue by it.
+func TestPullRequestMetadata(t *testing.T) {
+	for name, open := range backends {
+		t.Run(name, func(t *testing.T) { testPullRequestMetadata(t, open(t)) })
+	}
+}
+
+func testPullRequestMetadata(t *testing.T, repos *repository.Repositories) {
+	ctx := context.Background()
+
+	type fixture struct {
+		prService *service.PullRequestService
+		created   *model.PullRequest
+		asAuthor  context.Context
+		reviewers []model.UserID
+	}
+	newFixture := func(t *testing.T) *fixture {
+		t.Helper()
+		prService, members := newTeamWithPullRequests(t, repos, 3)
+		created, reviewers, err := prService.CreatePullRequest(
+			ctx, &model.PullRequest{
+				PullRequestID: model.PullRequestID(uuid.New()), Name: "search", AuthorID: members[0].ID,
+				Status: model.PRStatusOpen,
+				PullRequestMetadata: model.PullRequestMetadata{
+					Repository: " backend ", SourceBranch: "feature/search", TargetBranch: "main",
+					URL: "https://git.example.com/backend/pull/7", Labels: []string{"api", " db", "api"},
+				},
+			},
+		)
+		if err != nil || len(reviewers) == 0 {
+			t.Fatalf("create pull request: %v, %v", reviewers, err)
+		}
+		return &fixture{
+			prService: prService, created: created, asAuthor: auth.WithActor(ctx, members[0].ID), reviewers: reviewers,
+		}
+	}
+	// update replaces the metadata of the pull request of f with a repository and a ui label
+	update := func(t *testing.T, f *fixture) *model.PullRequest {
+		t.Helper()
+		updated, err := f.prService.UpdatePullRequest(
+			f.asAuthor, f.created.PullRequestID, model.PullRequestMetadata{Repository: "backend", Labels: []string{"ui"}},
+		)
+		if err != nil {
+			t.Fatalf("update pull request: %v", err)
+		}
+		return updated
+	}
+
+	t.Run("metadata is normalized", func(t *testing.T) {
+		created := newFixture(t).created
+		if created.Repository != "backend" || !slices.Equal(created.Labels, []string{"api", "db"}) {
+			t.Fatalf("metadata not normalized: %+v", created.PullRequestMetadata)
+		}
+	})
+
+	for _, tc := range []struct {
+		name string
+		call func(f *fixture) error
+		want error
+	}{
+		{"non-http url", func(f *fixture) error {
+			_, _, err := f.prService.CreatePullRequest(
+				ctx, &model.PullRequest{
+					PullRequestID: model.PullRequestID(uuid.New()), Name: "bad url", AuthorID: f.created.AuthorID,
+					Status:              model.PRStatusOpen,
+					PullRequestMetadata: model.PullRequestMetadata{URL: "ftp://git.example.com/pull/8"},
+				},
+			)
+			return err
+		}, rules.ErrInvalidMetadata},
+		{"blank label", func(f *fixture) error {
+			_, err := f.prService.UpdatePullRequest(
+				f.asAuthor, f.created.PullRequestID, model.PullRequestMetadata{Labels: []string{" "}},
+			)
+			return err
+		}, rules.ErrInvalidMetadata},
+		{"edited by a reviewer", func(f *fixture) error {
+			_, err := f.prService.UpdatePullRequest(
+				auth.WithActor(ctx, f.reviewers[0]), f.created.PullRequestID, model.PullRequestMetadata{},
+			)
+			return err
+		}, rules.ErrForbidden},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := tc.call(newFixture(t)); !errors.Is(err, tc.want) {
+				t.Fatalf("expected %v, got %v", tc.want, err)
+			}
+		})
+	}
+
+	t.Run("update replaces the metadata", func(t *testing.T) {
+		f := newFixture(t)
+		updated := update(t, f)
+		if updated.Version != f.created.Version+1 || updated.SourceBranch != "" ||
+			!slices.Equal(updated.Labels, []string{"ui"}) {
+			t.Fatalf("unexpected update %+v", updated)
+		}
+	})
+
+	for _, tc := range []struct {
+		name   string
+		filter model.ReviewFilter
+		want   bool
+	}{
+		{"without a filter", model.ReviewFilter{}, true},
+		{"by the current label", model.ReviewFilter{Label: "ui"}, true},
+		{"by a removed label", model.ReviewFilter{Label: "api"}, false},
+		{"by another repository", model.ReviewFilter{Repository: "frontend"}, false},
+	} {
+		t.Run("reviews "+tc.name, func(t *testing.T) {
+			f := newFixture(t)
+			update(t, f)
+			reviews, err := f.prService.GetUserReviews(ctx, f.reviewers[0], tc.filter)
+			if err != nil {
+				t.Fatalf("get reviews: %v", err)
+			}
+			found := slices.ContainsFunc(
+				reviews, func(pr model.PullRequest) bool { return pr.PullRequestID == f.created.PullRequestID },
+			)
+			if found != tc.want {
+				t.Fatalf("filter %+v: found %v, want %v", tc.filter, found, tc.want)
+			}
+		})
+	}
+}
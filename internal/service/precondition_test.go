@@ -0,0 +1,72 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"pull-request-review/internal/domain/auth"
+	"pull-request-review/internal/domain/model"
+	"pull-request-review/internal/domain/ports/repository"
+	"pull-request-review/internal/domain/precondition"
+	"pull-request-review/internal/domain/rules"
+	"pull-request-review/internal/service"
+)
+
+// TestStaleVersion changes a pull request with the version the client saw before its last change
+// and checks that the service refuses it and leaves the pull request as it was.
+func TestStaleVersion(t *testing.T) {
+	for name, open := range backends {
+		t.Run(name, func(t *testing.T) { testStaleVersion(t, open(t)) })
+	}
+}
+
+func testStaleVersion(t *testing.T, repos *repository.Repositories) {
+	ctx := context.Background()
+
+	// change touches the pull request ID, reviewer being one of its reviewers
+	type change func(ctx context.Context, prService *service.PullRequestService, ID model.PullRequestID,
+		reviewer model.UserID) error
+
+	for _, tc := range []struct {
+		name   string
+		change change
+	}{
+		{"merge", func(ctx context.Context, prService *service.PullRequestService, ID model.PullRequestID,
+			_ model.UserID) error {
+			_, err := prService.MergePullRequest(ctx, ID)
+			return err
+		}},
+		{"reassign", func(ctx context.Context, prService *service.PullRequestService, ID model.PullRequestID,
+			reviewer model.UserID) error {
+			_, _, err := prService.ReassignPullRequest(auth.WithActor(ctx, reviewer), ID, reviewer, model.UserID(uuid.Nil))
+			return err
+		}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			// one spare member leaves a candidate for the reassign
+			prService, members := newTeamWithPullRequests(t, repos, stressReviewers+2)
+			prID, reviewers := createPullRequest(t, prService, members[0].ID)
+			pr, err := prService.GetPullRequest(ctx, prID)
+			if err != nil {
+				t.Fatalf("get pull request: %v", err)
+			}
+
+			stale := precondition.WithVersions(ctx, []int64{pr.Version - 1})
+			if err := tc.change(stale, prService, prID, reviewers[0]); !errors.Is(err, rules.ErrPreconditionFailed) {
+				t.Fatalf("expected %v, got %v", rules.ErrPreconditionFailed, err)
+			}
+			after, err := prService.GetPullRequest(ctx, prID)
+			if err != nil || after.Version != pr.Version || after.Status != model.PRStatusOpen {
+				t.Fatalf("a refused change should leave the pull request alone: %+v, %v", after, err)
+			}
+
+			current := precondition.WithVersions(ctx, []int64{pr.Version})
+			if err := tc.change(current, prService, prID, reviewers[0]); err != nil {
+				t.Fatalf("the current version should pass: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,430 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"math/rand"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+
+	"pull-request-review/internal/domain/model"
+	"pull-request-review/internal/domain/rules"
+)
+
+// BatchCreatePullRequests creates many pull requests in one transaction with bulk inserts,
+// instead of a round trip per pull request. Items that cannot be created are reported in
+// their result and skipped, the rest of the batch is created regardless.
+func (s *PullRequestService) BatchCreatePullRequests(
+	ctx context.Context,
+	pullRequests []*model.PullRequest,
+) ([]model.BatchCreateResult, error) {
+	if len(pullRequests) > s.maxBatchSize {
+		return nil, rules.ErrBatchTooLarge
+	}
+
+	var results []model.BatchCreateResult
+	err := withinRetriedTx(ctx, s.tx, func(ctx context.Context) error {
+		var err error
+		results, err = s.batchCreatePullRequests(ctx, pullRequests)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+func (s *PullRequestService) batchCreatePullRequests(
+	ctx context.Context,
+	pullRequests []*model.PullRequest,
+) ([]model.BatchCreateResult, error) {
+	results := make([]model.BatchCreateResult, len(pullRequests))
+	IDs := make([]model.PullRequestID, len(pullRequests))
+	authorIDs := make([]model.UserID, len(pullRequests))
+	for i, pr := range pullRequests {
+		results[i].PullRequest = pr
+		IDs[i] = pr.PullRequestID
+		authorIDs[i] = pr.AuthorID
+	}
+
+	existing, err := s.pullRequestRepo.GetExistingIDs(ctx, IDs)
+	if err != nil {
+		s.logger.Error(err, "failed to check PR existence")
+		return nil, err
+	}
+	taken := make(map[model.PullRequestID]bool, len(pullRequests))
+	for _, ID := range existing {
+		taken[ID] = true
+	}
+
+	authors, err := s.userRepo.GetByIDs(ctx, authorIDs)
+	if err != nil {
+		s.logger.Error(err, "failed to get authors")
+		return nil, err
+	}
+	authorTeams := make(map[model.UserID]model.TeamID, len(authors))
+	for _, author := range authors {
+		authorTeams[author.ID] = model.TeamID(author.TeamID)
+	}
+
+	now := time.Now()
+	var accepted []int
+	for i, pr := range pullRequests {
+		if taken[pr.PullRequestID] {
+			results[i].Err = rules.ErrPullRequestExists
+			continue
+		}
+		if _, ok := authorTeams[pr.AuthorID]; !ok {
+			results[i].Err = rules.ErrUserNotFound
+			continue
+		}
+		metadata, err := normalizeMetadata(pr.PullRequestMetadata)
+		if err != nil {
+			results[i].Err = err
+			continue
+		}
+		pr.PullRequestMetadata = metadata
+
+		taken[pr.PullRequestID] = true
+		if pr.Status != model.PRStatusDraft {
+			pr.Status = model.PRStatusOpen
+		}
+		pr.CreatedAt = now
+		pr.Version = model.InitialPullRequestVersion
+		accepted = append(accepted, i)
+	}
+
+	if len(accepted) == 0 {
+		return results, nil
+	}
+
+	balancer := newReviewBalancer(s)
+	err = balancer.lockTeams(ctx, accepted, pullRequests, authorTeams)
+	if err != nil {
+		return nil, err
+	}
+	router := newReviewerRouter(s)
+
+	created := make([]model.PullRequest, 0, len(accepted))
+	var assignments []model.ReviewAssignment
+	for _, i := range accepted {
+		pr := pullRequests[i]
+		created = append(created, *pr)
+		if pr.Status == model.PRStatusDraft {
+			results[i].ReviewerIDs = []model.UserID{}
+			continue
+		}
+
+		routed, err := router.route(ctx, pr, []model.UserID{pr.AuthorID})
+		if err != nil {
+			return nil, err
+		}
+
+		reviewerIDs, err := balancer.pick(ctx, pr, authorTeams[pr.AuthorID], routed)
+		if err != nil {
+			return nil, err
+		}
+
+		results[i].ReviewerIDs = make([]model.UserID, 0, len(routed)+len(reviewerIDs))
+		for _, assignment := range routed {
+			assignment.AssignedAt = now
+			assignments = append(assignments, assignment)
+			results[i].ReviewerIDs = append(results[i].ReviewerIDs, assignment.ReviewerID)
+		}
+		for _, reviewerID := range reviewerIDs {
+			assignments = append(
+				assignments, model.ReviewAssignment{
+					PullRequestID: pr.PullRequestID,
+					ReviewerID:    reviewerID,
+					AssignedAt:    now,
+				},
+			)
+			results[i].ReviewerIDs = append(results[i].ReviewerIDs, reviewerID)
+		}
+	}
+
+	err = s.pullRequestRepo.CreateBatch(ctx, created)
+	if err != nil {
+		s.logger.Error(err, "failed to create pull requests")
+		return nil, err
+	}
+
+	err = s.reviewAssignmentRepo.InsertBatch(ctx, assignments)
+	if err != nil {
+		s.logger.Error(err, "failed to assign reviewers to pull requests")
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// reviewBalancer spreads the reviews of a batch by load: each pull request gets the candidates
+// with the fewest open reviews for their membership weight, counting the reviews handed out
+// earlier in the same batch. Teams and their members are loaded once per batch.
+type reviewBalancer struct {
+	service *PullRequestService
+	teams   map[model.TeamID]*balancedTeam
+	members map[model.TeamID][]model.User
+	weights map[model.TeamID]map[model.UserID]float64
+	load    map[model.UserID]int
+}
+
+type balancedTeam struct {
+	settings  model.TeamSettings
+	ancestors []model.Team
+}
+
+func newReviewBalancer(service *PullRequestService) *reviewBalancer {
+	return &reviewBalancer{
+		service: service,
+		teams:   make(map[model.TeamID]*balancedTeam),
+		members: make(map[model.TeamID][]model.User),
+		weights: make(map[model.TeamID]map[model.UserID]float64),
+		load:    make(map[model.UserID]int),
+	}
+}
+
+// lockTeams takes the lock of every author team of the batch, in a fixed order so that two
+// batches cannot wait on each other.
+func (b *reviewBalancer) lockTeams(
+	ctx context.Context, accepted []int, pullRequests []*model.PullRequest, authorTeams map[model.UserID]model.TeamID,
+) error {
+	var teamIDs []model.TeamID
+	seen := make(map[model.TeamID]bool)
+	for _, i := range accepted {
+		teamID := authorTeams[pullRequests[i].AuthorID]
+		if uuid.UUID(teamID) == uuid.Nil || seen[teamID] {
+			continue
+		}
+		seen[teamID] = true
+		teamIDs = append(teamIDs, teamID)
+	}
+	sort.Slice(
+		teamIDs, func(i, j int) bool {
+			return bytes.Compare(teamIDs[i][:], teamIDs[j][:]) < 0
+		},
+	)
+
+	for _, teamID := range teamIDs {
+		err := b.service.teamRepo.Lock(ctx, teamID)
+		if err != nil {
+			b.service.logger.Error(err, "failed to lock author team")
+			return err
+		}
+	}
+
+	return nil
+}
+
+// pick chooses the reviewers of pr the way assignInitialReviewers does, only by load instead
+// of at random: the author's team fills the places the routed reviewers leave. The expertise
+// strategy still ranks by score, with the load of the batch as availability.
+func (b *reviewBalancer) pick(
+	ctx context.Context, pr *model.PullRequest, authorTeamID model.TeamID, routed []model.ReviewAssignment,
+) ([]model.UserID, error) {
+	team, err := b.team(ctx, authorTeamID)
+	if err != nil {
+		return nil, err
+	}
+
+	maxCount := b.service.maxReviewersCount
+	if team.settings.MaxReviewers != nil {
+		maxCount = *team.settings.MaxReviewers
+	}
+
+	isRouted := make(map[model.UserID]bool, len(routed))
+	routedIDs := make([]model.UserID, len(routed))
+	for i, assignment := range routed {
+		isRouted[assignment.ReviewerID] = true
+		routedIDs[i] = assignment.ReviewerID
+	}
+	// routed reviews add to the load like the picked ones
+	if err := b.countLoad(ctx, routedIDs); err != nil {
+		return nil, err
+	}
+	for _, ID := range routedIDs {
+		b.load[ID]++
+	}
+	if maxCount <= len(routed) {
+		return []model.UserID{}, nil
+	}
+
+	candidates, teamID, err := b.candidates(ctx, authorTeamID, team, pr.AuthorID)
+	if err != nil {
+		return nil, err
+	}
+	candidates = slices.DeleteFunc(candidates, func(user model.User) bool { return isRouted[user.ID] })
+
+	weights, err := b.teamWeights(ctx, teamID)
+	if err != nil {
+		return nil, err
+	}
+
+	count := min(maxCount-len(routed), len(candidates))
+	if b.service.strategy == model.ReviewerStrategyExpertise {
+		candidates, err = b.rankByExpertise(ctx, pr.AuthorID, candidates, weights)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		b.sortByLoad(candidates, weights)
+	}
+
+	reviewerIDs := make([]model.UserID, count)
+	for i, reviewer := range candidates[:count] {
+		reviewerIDs[i] = reviewer.ID
+		b.load[reviewer.ID]++
+	}
+
+	return reviewerIDs, nil
+}
+
+// rankByExpertise orders the candidates like the expertise strategy does, with the load the
+// batch has assigned so far standing in for the stored open reviews.
+func (b *reviewBalancer) rankByExpertise(
+	ctx context.Context, authorID model.UserID, candidates []model.User, weights map[model.UserID]float64,
+) ([]model.User, error) {
+	suggestions, err := b.service.rankCandidates(ctx, authorID, candidates, b.load, weights)
+	if err != nil {
+		return nil, err
+	}
+
+	ranked := make([]model.User, len(suggestions))
+	for i, suggestion := range suggestions {
+		ranked[i] = suggestion.User
+	}
+	return ranked, nil
+}
+
+// sortByLoad puts the least loaded candidates for their weight first.
+func (b *reviewBalancer) sortByLoad(candidates []model.User, weights map[model.UserID]float64) {
+	// shuffled first, so that equally loaded candidates take turns
+	rand.Shuffle(len(candidates), func(i, j int) { candidates[i], candidates[j] = candidates[j], candidates[i] })
+	score := func(user model.User) float64 {
+		weight, ok := weights[user.ID]
+		if !ok || weight <= 0 {
+			weight = model.DefaultMembershipWeight
+		}
+		return float64(b.load[user.ID]+1) / weight
+	}
+	sort.SliceStable(
+		candidates, func(i, j int) bool {
+			return score(candidates[i]) < score(candidates[j])
+		},
+	)
+}
+
+func (b *reviewBalancer) team(ctx context.Context, teamID model.TeamID) (*balancedTeam, error) {
+	if team, ok := b.teams[teamID]; ok {
+		return team, nil
+	}
+
+	settings, ancestors, err := b.service.hierarchy.resolve(ctx, teamID)
+	if err != nil {
+		b.service.logger.Error(err, "failed to resolve author team settings")
+		return nil, err
+	}
+
+	team := &balancedTeam{settings: settings, ancestors: ancestors}
+	b.teams[teamID] = team
+	return team, nil
+}
+
+// candidates mirrors findCandidates on the members loaded for the batch: the active members
+// of the team but the author, or of the nearest ancestor that has some when fallback is on.
+func (b *reviewBalancer) candidates(
+	ctx context.Context, teamID model.TeamID, team *balancedTeam, authorID model.UserID,
+) ([]model.User, model.TeamID, error) {
+	candidates, err := b.activeMembers(ctx, teamID, authorID)
+	if err != nil {
+		return nil, teamID, err
+	}
+	if len(candidates) > 0 || !team.settings.FallbackEnabled() {
+		return candidates, teamID, nil
+	}
+
+	for _, ancestor := range team.ancestors {
+		candidates, err = b.activeMembers(ctx, ancestor.TeamID, authorID)
+		if err != nil {
+			return nil, teamID, err
+		}
+		if len(candidates) > 0 {
+			return candidates, ancestor.TeamID, nil
+		}
+	}
+
+	return candidates, teamID, nil
+}
+
+func (b *reviewBalancer) activeMembers(ctx context.Context, teamID model.TeamID, authorID model.UserID) (
+	[]model.User, error,
+) {
+	members, ok := b.members[teamID]
+	if !ok {
+		var err error
+		members, err = b.service.userRepo.GetActiveByTeamExcluding(ctx, teamID, nil)
+		if err != nil {
+			b.service.logger.Error(err, "failed to get active team members for reviewer assignment")
+			return nil, err
+		}
+
+		memberIDs := make([]model.UserID, len(members))
+		for i, member := range members {
+			memberIDs[i] = member.ID
+		}
+		if err := b.countLoad(ctx, memberIDs); err != nil {
+			return nil, err
+		}
+
+		b.members[teamID] = members
+	}
+
+	candidates := make([]model.User, 0, len(members))
+	for _, member := range members {
+		if member.ID != authorID {
+			candidates = append(candidates, member)
+		}
+	}
+	return candidates, nil
+}
+
+// countLoad loads the open reviews of the users whose load is not known yet.
+func (b *reviewBalancer) countLoad(ctx context.Context, userIDs []model.UserID) error {
+	var uncounted []model.UserID
+	for _, ID := range userIDs {
+		if _, counted := b.load[ID]; !counted {
+			uncounted = append(uncounted, ID)
+		}
+	}
+	if len(uncounted) == 0 {
+		return nil
+	}
+
+	counts, err := b.service.reviewAssignmentRepo.GetOpenReviewCounts(ctx, uncounted)
+	if err != nil {
+		b.service.logger.Error(err, "failed to get open review counts")
+		return err
+	}
+	for _, ID := range uncounted {
+		b.load[ID] = counts[ID]
+	}
+	return nil
+}
+
+func (b *reviewBalancer) teamWeights(ctx context.Context, teamID model.TeamID) (map[model.UserID]float64, error) {
+	if weights, ok := b.weights[teamID]; ok {
+		return weights, nil
+	}
+
+	weights, err := b.service.membershipWeights(ctx, teamID)
+	if err != nil {
+		return nil, err
+	}
+
+	b.weights[teamID] = weights
+	return weights, nil
+}
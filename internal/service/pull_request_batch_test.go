@@ -0,0 +1,128 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"pull-request-review/internal/domain/model"
+	"pull-request-review/internal/domain/ports/repository"
+	"pull-request-review/internal/domain/rules"
+)
+
+// TestBatchCreate checks that a batch skips the pull requests it cannot create and spreads
+// the reviews of the rest evenly over the team.
+func TestBatchCreate(t *testing.T) {
+	for name, open := range backends {
+		t.Run(name, func(t *testing.T) { testBatchCreate(t, open(t)) })
+	}
+}
+
+func testBatchCreate(t *testing.T, repos *repository.Repositories) {
+	ctx := context.Background()
+	newPullRequest := func(authorID model.UserID) *model.PullRequest {
+		return &model.PullRequest{PullRequestID: model.PullRequestID(uuid.New()), Name: "batch", AuthorID: authorID}
+	}
+
+	// every refused item comes after a valid one, which is created regardless
+	for _, tc := range []struct {
+		name string
+		// refused returns the item to refuse, given the valid item and a stored pull request
+		refused func(valid, stored *model.PullRequest) *model.PullRequest
+		want    error
+	}{
+		{"existing pull request", func(valid, stored *model.PullRequest) *model.PullRequest {
+			existing := *stored
+			return &existing
+		}, rules.ErrPullRequestExists},
+		{"duplicate in the batch", func(valid, stored *model.PullRequest) *model.PullRequest {
+			duplicate := *valid
+			return &duplicate
+		}, rules.ErrPullRequestExists},
+		{"unknown author", func(valid, stored *model.PullRequest) *model.PullRequest {
+			return newPullRequest(model.UserID(uuid.New()))
+		}, rules.ErrUserNotFound},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			prService, members := newTeamWithPullRequests(t, repos, 3)
+			stored, _, err := prService.CreatePullRequest(ctx, newPullRequest(members[0].ID))
+			if err != nil {
+				t.Fatalf("create pull request: %v", err)
+			}
+			valid := newPullRequest(members[0].ID)
+
+			results, err := prService.BatchCreatePullRequests(ctx, []*model.PullRequest{valid, tc.refused(valid, stored)})
+			if err != nil || len(results) != 2 {
+				t.Fatalf("batch create: %+v, %v", results, err)
+			}
+			if !errors.Is(results[1].Err, tc.want) {
+				t.Fatalf("expected %v, got %v", tc.want, results[1].Err)
+			}
+			if results[0].Err != nil || len(results[0].ReviewerIDs) != stressReviewers {
+				t.Fatalf("the valid item should be created: %+v", results[0])
+			}
+			if exists, _ := repos.PullRequest.Exists(ctx, valid.PullRequestID); !exists {
+				t.Fatalf("pull request %v was not created", valid.PullRequestID)
+			}
+		})
+	}
+
+	t.Run("reviews are spread evenly", func(t *testing.T) {
+		prService, members := newTeamWithPullRequests(t, repos, 5)
+		author := members[0].ID
+
+		// the reviewers of the existing pull request are busier, the batch has to make up for it
+		if _, _, err := prService.CreatePullRequest(ctx, newPullRequest(author)); err != nil {
+			t.Fatalf("create pull request: %v", err)
+		}
+		var batch []*model.PullRequest
+		for range 7 {
+			batch = append(batch, newPullRequest(author))
+		}
+		results, err := prService.BatchCreatePullRequests(ctx, batch)
+		if err != nil {
+			t.Fatalf("batch create: %v", err)
+		}
+		for _, result := range results {
+			if result.Err != nil || len(result.ReviewerIDs) != stressReviewers {
+				t.Fatalf("unexpected result %+v", result)
+			}
+		}
+
+		// 7 pull requests with 2 reviewers plus the 2 existing reviews make 16 reviews for 4 reviewers
+		counts, err := repos.ReviewAssignment.GetOpenReviewCounts(ctx, userIDs(members))
+		if err != nil {
+			t.Fatalf("open review counts: %v", err)
+		}
+		for _, member := range members[1:] {
+			if counts[member.ID] != 4 {
+				t.Fatalf("reviews should be spread evenly: %v", counts)
+			}
+		}
+		if counts[author] != 0 {
+			t.Fatalf("the author should not review their own pull requests: %v", counts)
+		}
+	})
+
+	t.Run("batch too large", func(t *testing.T) {
+		prService, members := newTeamWithPullRequests(t, repos, 3)
+		oversized := make([]*model.PullRequest, 101)
+		for i := range oversized {
+			oversized[i] = newPullRequest(members[0].ID)
+		}
+		_, err := prService.BatchCreatePullRequests(ctx, oversized)
+		if !errors.Is(err, rules.ErrBatchTooLarge) {
+			t.Fatalf("expected %v, got %v", rules.ErrBatchTooLarge, err)
+		}
+	})
+}
+
+func userIDs(users []model.User) []model.UserID {
+	IDs := make([]model.UserID, len(users))
+	for i, user := range users {
+		IDs[i] = user.ID
+	}
+	return IDs
+}
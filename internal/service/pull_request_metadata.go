@@ -0,0 +1,92 @@
+package service
+
+import (
+	"context"
+	"net/url"
+	"slices"
+	"strings"
+
+	"pull-request-review/internal/domain/model"
+	"pull-request-review/internal/domain/rules"
+)
+
+// UpdatePullRequest replaces the metadata of a pull request. Like picking reviewers by hand,
+// it is left to the author and the maintainers of the author's team.
+func (s *PullRequestService) UpdatePullRequest(
+	ctx context.Context,
+	ID model.PullRequestID,
+	metadata model.PullRequestMetadata,
+) (*model.PullRequest, error) {
+	metadata, err := normalizeMetadata(metadata)
+	if err != nil {
+		return nil, err
+	}
+
+	var updated *model.PullRequest
+	err = withinRetriedTx(ctx, s.tx, func(ctx context.Context) error {
+		var err error
+		updated, err = s.updatePullRequest(ctx, ID, metadata)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return updated, nil
+}
+
+func (s *PullRequestService) updatePullRequest(
+	ctx context.Context,
+	ID model.PullRequestID,
+	metadata model.PullRequestMetadata,
+) (*model.PullRequest, error) {
+	pullRequest, _, err := s.getForReviewerChange(ctx, ID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.pullRequestRepo.BumpVersion(ctx, ID, pullRequest.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.pullRequestRepo.UpdateMetadata(ctx, ID, metadata)
+	if err != nil {
+		s.logger.Error(err, "failed to update pull request metadata")
+		return nil, err
+	}
+
+	return s.pullRequestRepo.GetByID(ctx, ID)
+}
+
+// normalizeMetadata trims every field and drops repeated labels. The URL, when there is one,
+// has to be an absolute http(s) URL, and labels cannot be blank.
+func normalizeMetadata(metadata model.PullRequestMetadata) (model.PullRequestMetadata, error) {
+	normalized := model.PullRequestMetadata{
+		Repository:   strings.TrimSpace(metadata.Repository),
+		SourceBranch: strings.TrimSpace(metadata.SourceBranch),
+		TargetBranch: strings.TrimSpace(metadata.TargetBranch),
+		URL:          strings.TrimSpace(metadata.URL),
+		Description:  strings.TrimSpace(metadata.Description),
+		Labels:       make([]string, 0, len(metadata.Labels)),
+	}
+
+	if normalized.URL != "" {
+		parsed, err := url.Parse(normalized.URL)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return model.PullRequestMetadata{}, rules.ErrInvalidMetadata
+		}
+	}
+
+	for _, label := range metadata.Labels {
+		label = strings.TrimSpace(label)
+		if label == "" {
+			return model.PullRequestMetadata{}, rules.ErrInvalidMetadata
+		}
+		if !slices.Contains(normalized.Labels, label) {
+			normalized.Labels = append(normalized.Labels, label)
+		}
+	}
+
+	return normalized, nil
+}
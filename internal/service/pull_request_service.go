@@ -2,13 +2,19 @@ package service
 
 import (
 	"context"
+	"errors"
+	"math"
 	"math/rand"
+	"slices"
+	"sort"
 	"time"
 
 	"github.com/google/uuid"
 
+	"pull-request-review/internal/domain/auth"
 	"pull-request-review/internal/domain/model"
 	"pull-request-review/internal/domain/ports/repository"
+	"pull-request-review/internal/domain/precondition"
 	"pull-request-review/internal/domain/rules"
 	"pull-request-review/internal/infrastructure/adapters/logger"
 )
@@ -17,29 +23,94 @@ type PullRequestService struct {
 	pullRequestRepo      repository.PullRequestRepository
 	userRepo             repository.UserRepository
 	reviewAssignmentRepo repository.ReviewAssignmentRepository
+	membershipRepo       repository.TeamMembershipRepository
+	teamRepo             repository.TeamRepository
+	tx                   repository.TxManager
+	access               *accessChecker
+	hierarchy            *teamHierarchy
 	logger               logger.Logger
 	maxReviewersCount    int
+	maxBatchSize         int
+	routingRules         []model.RoutingRule
+	strategy             model.ReviewerStrategy
+	scoringWeights       model.ScoringWeights
+	// random draws the numbers behind reviewer selection, tests replace it to get a fixed sequence
+	random func() float64
+}
+
+// PullRequestServiceOptions tunes how the service assigns reviewers.
+type PullRequestServiceOptions struct {
+	// MaxReviewers is how many reviewers a pull request gets unless its team says otherwise.
+	MaxReviewers int
+	// MaxBatchSize caps the pull requests of one BatchCreatePullRequests call.
+	MaxBatchSize int
+	// RoutingRules add reviewers from other teams by the paths a pull request changes.
+	RoutingRules []model.RoutingRule
+	// Strategy picks reviewers among the candidates of a team, at random when empty.
+	Strategy model.ReviewerStrategy
+	// ScoringWeights rank candidates for suggestions and the expertise strategy. The zero
+	// value means model.DefaultScoringWeights.
+	ScoringWeights model.ScoringWeights
 }
 
 func NewPullRequestService(
 	pullRequestRepo repository.PullRequestRepository,
 	userRepo repository.UserRepository,
 	reviewAssignmentRepo repository.ReviewAssignmentRepository,
+	membershipRepo repository.TeamMembershipRepository,
+	teamRepo repository.TeamRepository,
+	teamRoleRepo repository.TeamRoleRepository,
+	tx repository.TxManager,
 	logger logger.Logger,
-	maxReviewersCount int,
+	opts PullRequestServiceOptions,
 ) *PullRequestService {
+	if opts.ScoringWeights == (model.ScoringWeights{}) {
+		opts.ScoringWeights = model.DefaultScoringWeights
+	}
+
 	return &PullRequestService{
 		pullRequestRepo:      pullRequestRepo,
 		userRepo:             userRepo,
 		reviewAssignmentRepo: reviewAssignmentRepo,
+		membershipRepo:       membershipRepo,
+		teamRepo:             teamRepo,
+		tx:                   tx,
+		access:               newAccessChecker(teamRoleRepo, membershipRepo),
+		hierarchy:            newTeamHierarchy(teamRepo),
 		logger:               logger,
-		maxReviewersCount:    maxReviewersCount,
+		maxReviewersCount:    opts.MaxReviewers,
+		maxBatchSize:         opts.MaxBatchSize,
+		routingRules:         opts.RoutingRules,
+		strategy:             opts.Strategy,
+		scoringWeights:       opts.ScoringWeights,
+		random:               rand.Float64,
 	}
 }
 
+// CreatePullRequest stores the pull request together with its reviewers, so a failed
+// assignment does not leave a pull request nobody reviews. A pull request created with
+// the draft status gets no reviewers until MarkReady.
 func (s *PullRequestService) CreatePullRequest(
 	ctx context.Context,
 	pullRequest *model.PullRequest,
+) (*model.PullRequest, []model.UserID, error) {
+	var created *model.PullRequest
+	var reviewerIDs []model.UserID
+	err := withinRetriedTx(ctx, s.tx, func(ctx context.Context) error {
+		var err error
+		created, reviewerIDs, err = s.createPullRequest(ctx, pullRequest)
+		return err
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return created, reviewerIDs, nil
+}
+
+func (s *PullRequestService) createPullRequest(
+	ctx context.Context,
+	pullRequest *model.PullRequest,
 ) (*model.PullRequest, []model.UserID, error) {
 	exists, err := s.pullRequestRepo.Exists(ctx, pullRequest.PullRequestID)
 	if err != nil {
@@ -56,14 +127,26 @@ func (s *PullRequestService) CreatePullRequest(
 		return nil, nil, err
 	}
 
-	pullRequest.Status = model.PRStatusOpen
+	pullRequest.PullRequestMetadata, err = normalizeMetadata(pullRequest.PullRequestMetadata)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if pullRequest.Status != model.PRStatusDraft {
+		pullRequest.Status = model.PRStatusOpen
+	}
 	pullRequest.CreatedAt = time.Now()
+	pullRequest.Version = model.InitialPullRequestVersion
 	err = s.pullRequestRepo.Create(ctx, pullRequest)
 	if err != nil {
 		s.logger.Error(err, "failed to create pull request")
 		return nil, nil, err
 	}
 
+	if pullRequest.Status == model.PRStatusDraft {
+		return pullRequest, []model.UserID{}, nil
+	}
+
 	reviewerIDs, err := s.assignInitialReviewers(ctx, pullRequest, uuid.UUID(author.TeamID))
 	if err != nil {
 		s.logger.Error(err, "failed to assign reviewers")
@@ -99,14 +182,29 @@ func (s *PullRequestService) GetPullRequestReviewers(ctx context.Context, ID mod
 	return reviewerIDs, nil
 }
 
-func (s *PullRequestService) GetUserReviews(ctx context.Context, userID model.UserID) ([]model.PullRequest, error) {
+// GetReviewAssignments lists the assignments of the pull request with the rule that picked each reviewer.
+func (s *PullRequestService) GetReviewAssignments(ctx context.Context, ID model.PullRequestID) (
+	[]model.ReviewAssignment, error,
+) {
+	assignments, err := s.reviewAssignmentRepo.GetAssignments(ctx, ID)
+	if err != nil {
+		s.logger.Error(err, "failed to get review assignments for pull request")
+		return nil, err
+	}
+	return assignments, nil
+}
+
+// GetUserReviews lists the pull requests the user reviews that match filter.
+func (s *PullRequestService) GetUserReviews(
+	ctx context.Context, userID model.UserID, filter model.ReviewFilter,
+) ([]model.PullRequest, error) {
 	_, err := s.userRepo.GetByID(ctx, userID)
 	if err != nil {
 		s.logger.Error(err, "failed to get user")
 		return nil, err
 	}
 
-	pullRequests, err := s.pullRequestRepo.GetByReviewer(ctx, userID)
+	pullRequests, err := s.pullRequestRepo.GetByReviewer(ctx, userID, filter)
 	if err != nil {
 		s.logger.Error(err, "cannot get pull requests for reviewer")
 		return nil, err
@@ -114,10 +212,34 @@ func (s *PullRequestService) GetUserReviews(ctx context.Context, userID model.Us
 	return pullRequests, nil
 }
 
+// ReassignPullRequest runs its checks and the replacement in one transaction, so the
+// reviewers it decided on are still the ones it replaces. The replacement is newReviewerID
+// when it is set, otherwise it is drawn at random.
 func (s *PullRequestService) ReassignPullRequest(
 	ctx context.Context,
 	ID model.PullRequestID,
 	oldReviewerID model.UserID,
+	newReviewerID model.UserID,
+) (*model.PullRequest, model.UserID, error) {
+	var updatedPR *model.PullRequest
+	var replacedBy model.UserID
+	err := withinRetriedTx(ctx, s.tx, func(ctx context.Context) error {
+		var err error
+		updatedPR, replacedBy, err = s.reassignPullRequest(ctx, ID, oldReviewerID, newReviewerID)
+		return err
+	})
+	if err != nil {
+		return nil, model.UserID(uuid.Nil), err
+	}
+
+	return updatedPR, replacedBy, nil
+}
+
+func (s *PullRequestService) reassignPullRequest(
+	ctx context.Context,
+	ID model.PullRequestID,
+	oldReviewerID model.UserID,
+	newReviewerID model.UserID,
 ) (*model.PullRequest, model.UserID, error) {
 	pullRequest, err := s.pullRequestRepo.GetByID(ctx, ID)
 	if err != nil {
@@ -125,6 +247,11 @@ func (s *PullRequestService) ReassignPullRequest(
 		return nil, model.UserID(uuid.Nil), err
 	}
 
+	err = precondition.CheckVersion(ctx, pullRequest.Version)
+	if err != nil {
+		return nil, model.UserID(uuid.Nil), err
+	}
+
 	if pullRequest.Status == model.PRStatusMerged {
 		return nil, model.UserID(uuid.Nil), rules.ErrPullRequestMerged
 	}
@@ -138,7 +265,21 @@ func (s *PullRequestService) ReassignPullRequest(
 		return nil, model.UserID(uuid.Nil), rules.ErrNotAssigned
 	}
 
-	newReviewerID, err := s.reassignReviewer(ctx, pullRequest, oldReviewerID)
+	err = s.checkForceReassign(ctx, pullRequest, oldReviewerID)
+	if err != nil {
+		return nil, model.UserID(uuid.Nil), err
+	}
+
+	teamID, err := s.replacementTeam(ctx, pullRequest, oldReviewerID)
+	if err != nil {
+		return nil, model.UserID(uuid.Nil), err
+	}
+
+	if uuid.UUID(newReviewerID) == uuid.Nil {
+		newReviewerID, err = s.reassignReviewer(ctx, pullRequest, oldReviewerID, teamID)
+	} else {
+		err = s.replaceWithChosenReviewer(ctx, pullRequest, oldReviewerID, newReviewerID, teamID)
+	}
 	if err != nil {
 		s.logger.Error(err, "failed to reassign reviewer")
 		return nil, model.UserID(uuid.Nil), err
@@ -153,16 +294,406 @@ func (s *PullRequestService) ReassignPullRequest(
 	return updatedPR, newReviewerID, nil
 }
 
+// DeclineReview lets the acting reviewer turn down their review with a reason. They are replaced
+// the way a reassign would, and never picked for the pull request again. When nobody can take
+// the review over, the reviewer is only removed and the returned replacement is uuid.Nil.
+func (s *PullRequestService) DeclineReview(
+	ctx context.Context,
+	ID model.PullRequestID,
+	reason string,
+) (*model.PullRequest, model.UserID, error) {
+	var updatedPR *model.PullRequest
+	var replacedBy model.UserID
+	err := withinRetriedTx(ctx, s.tx, func(ctx context.Context) error {
+		var err error
+		updatedPR, replacedBy, err = s.declineReview(ctx, ID, reason)
+		return err
+	})
+	if err != nil {
+		return nil, model.UserID(uuid.Nil), err
+	}
+
+	return updatedPR, replacedBy, nil
+}
+
+func (s *PullRequestService) declineReview(
+	ctx context.Context,
+	ID model.PullRequestID,
+	reason string,
+) (*model.PullRequest, model.UserID, error) {
+	reviewerID, ok := auth.ActorFromContext(ctx)
+	if !ok || uuid.UUID(reviewerID) == uuid.Nil {
+		return nil, model.UserID(uuid.Nil), rules.ErrForbidden
+	}
+
+	pullRequest, err := s.pullRequestRepo.GetByID(ctx, ID)
+	if err != nil {
+		s.logger.Error(err, "failed to get pull request")
+		return nil, model.UserID(uuid.Nil), err
+	}
+
+	err = precondition.CheckVersion(ctx, pullRequest.Version)
+	if err != nil {
+		return nil, model.UserID(uuid.Nil), err
+	}
+
+	if pullRequest.Status == model.PRStatusMerged {
+		return nil, model.UserID(uuid.Nil), rules.ErrPullRequestMerged
+	}
+
+	isAssigned, err := s.reviewAssignmentRepo.Exists(ctx, ID, reviewerID)
+	if err != nil {
+		s.logger.Error(err, "failed to check reviewer assignment")
+		return nil, model.UserID(uuid.Nil), err
+	}
+	if !isAssigned {
+		return nil, model.UserID(uuid.Nil), rules.ErrNotAssigned
+	}
+
+	err = s.reviewAssignmentRepo.RecordDecline(
+		ctx, &model.ReviewDecline{PullRequestID: ID, ReviewerID: reviewerID, Reason: reason, DeclinedAt: time.Now()},
+	)
+	if err != nil {
+		s.logger.Error(err, "failed to record decline")
+		return nil, model.UserID(uuid.Nil), err
+	}
+
+	teamID, err := s.replacementTeam(ctx, pullRequest, reviewerID)
+	if err != nil {
+		return nil, model.UserID(uuid.Nil), err
+	}
+
+	newReviewerID, err := s.reassignReviewer(ctx, pullRequest, reviewerID, teamID)
+	if errors.Is(err, rules.ErrNoCandidates) {
+		// nobody takes over, the decliner just leaves
+		err = s.pullRequestRepo.BumpVersion(ctx, ID, pullRequest.Version)
+		if err == nil {
+			err = s.reviewAssignmentRepo.RemoveReviewer(ctx, ID, reviewerID)
+		}
+	}
+	if err != nil {
+		s.logger.Error(err, "failed to replace declining reviewer")
+		return nil, model.UserID(uuid.Nil), err
+	}
+
+	updatedPR, err := s.pullRequestRepo.GetByID(ctx, ID)
+	if err != nil {
+		s.logger.Error(err, "failed to get updated pull request")
+		return nil, model.UserID(uuid.Nil), err
+	}
+
+	return updatedPR, newReviewerID, nil
+}
+
+// MarkReady turns a draft into an open pull request and assigns its reviewers the way
+// CreatePullRequest does for pull requests that are ready from the start. Only the author,
+// or a maintainer of the author's team, can do it.
+func (s *PullRequestService) MarkReady(ctx context.Context, ID model.PullRequestID) (
+	*model.PullRequest, []model.UserID, error,
+) {
+	var updated *model.PullRequest
+	var reviewerIDs []model.UserID
+	err := withinRetriedTx(ctx, s.tx, func(ctx context.Context) error {
+		var err error
+		updated, reviewerIDs, err = s.markReady(ctx, ID)
+		return err
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return updated, reviewerIDs, nil
+}
+
+func (s *PullRequestService) markReady(ctx context.Context, ID model.PullRequestID) (
+	*model.PullRequest, []model.UserID, error,
+) {
+	pullRequest, author, err := s.getForReviewerChange(ctx, ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if pullRequest.Status != model.PRStatusDraft {
+		return nil, nil, rules.ErrPullRequestNotDraft
+	}
+
+	err = s.pullRequestRepo.BumpVersion(ctx, ID, pullRequest.Version)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	err = s.pullRequestRepo.UpdateStatus(ctx, ID, model.PRStatusOpen, time.Time{})
+	if err != nil {
+		s.logger.Error(err, "failed to update pull request status")
+		return nil, nil, err
+	}
+
+	reviewerIDs, err := s.assignInitialReviewers(ctx, pullRequest, uuid.UUID(author.TeamID))
+	if err != nil {
+		s.logger.Error(err, "failed to assign reviewers")
+		return nil, nil, err
+	}
+
+	updatedPR, err := s.pullRequestRepo.GetByID(ctx, ID)
+	if err != nil {
+		s.logger.Error(err, "failed to get updated pull request")
+		return nil, nil, err
+	}
+
+	return updatedPR, reviewerIDs, nil
+}
+
+// AddReviewer lets the author, or a maintainer of the author's team, request a review from a
+// specific active member of that team, on top of the reviewers assigned so far.
+func (s *PullRequestService) AddReviewer(
+	ctx context.Context, ID model.PullRequestID, reviewerID model.UserID,
+) (*model.PullRequest, error) {
+	var updated *model.PullRequest
+	err := withinRetriedTx(ctx, s.tx, func(ctx context.Context) error {
+		var err error
+		updated, err = s.addReviewer(ctx, ID, reviewerID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return updated, nil
+}
+
+func (s *PullRequestService) addReviewer(
+	ctx context.Context, ID model.PullRequestID, reviewerID model.UserID,
+) (*model.PullRequest, error) {
+	pullRequest, author, err := s.getForReviewerChange(ctx, ID)
+	if err != nil {
+		return nil, err
+	}
+	if pullRequest.Status == model.PRStatusDraft {
+		return nil, rules.ErrPullRequestDraft
+	}
+
+	currentReviewers, err := s.reviewAssignmentRepo.GetReviewers(ctx, ID)
+	if err != nil {
+		s.logger.Error(err, "failed to get current reviewers")
+		return nil, err
+	}
+
+	settings, err := s.checkChosenReviewer(ctx, pullRequest, reviewerID, model.TeamID(author.TeamID), currentReviewers)
+	if err != nil {
+		return nil, err
+	}
+
+	maxCount := s.maxReviewersCount
+	if settings.MaxReviewers != nil {
+		maxCount = *settings.MaxReviewers
+	}
+	if len(currentReviewers) >= maxCount {
+		return nil, rules.ErrTooManyReviewers
+	}
+
+	err = s.pullRequestRepo.BumpVersion(ctx, ID, pullRequest.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.reviewAssignmentRepo.AssignReviewer(ctx, ID, reviewerID)
+	if err != nil {
+		s.logger.Error(err, "failed to assign reviewer")
+		return nil, err
+	}
+
+	return s.pullRequestRepo.GetByID(ctx, ID)
+}
+
+// RemoveReviewer lets the author, or a maintainer of the author's team, drop a reviewer
+// without asking anybody else instead.
+func (s *PullRequestService) RemoveReviewer(
+	ctx context.Context, ID model.PullRequestID, reviewerID model.UserID,
+) (*model.PullRequest, error) {
+	var updated *model.PullRequest
+	err := withinRetriedTx(ctx, s.tx, func(ctx context.Context) error {
+		var err error
+		updated, err = s.removeReviewer(ctx, ID, reviewerID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return updated, nil
+}
+
+func (s *PullRequestService) removeReviewer(
+	ctx context.Context, ID model.PullRequestID, reviewerID model.UserID,
+) (*model.PullRequest, error) {
+	pullRequest, _, err := s.getForReviewerChange(ctx, ID)
+	if err != nil {
+		return nil, err
+	}
+
+	isAssigned, err := s.reviewAssignmentRepo.Exists(ctx, ID, reviewerID)
+	if err != nil {
+		s.logger.Error(err, "failed to check reviewer assignment")
+		return nil, err
+	}
+	if !isAssigned {
+		return nil, rules.ErrNotAssigned
+	}
+
+	err = s.pullRequestRepo.BumpVersion(ctx, ID, pullRequest.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.reviewAssignmentRepo.RemoveReviewer(ctx, ID, reviewerID)
+	if err != nil {
+		s.logger.Error(err, "failed to remove reviewer")
+		return nil, err
+	}
+
+	return s.pullRequestRepo.GetByID(ctx, ID)
+}
+
+// checkChosenReviewer tells why reviewerID cannot join the reviewers of pr, if it cannot: it has
+// to be an active member of teamID, or of the ancestors the team falls back to, other than the
+// author, the current reviewers and whoever declined pr. It returns the settings of teamID.
+func (s *PullRequestService) checkChosenReviewer(
+	ctx context.Context,
+	pr *model.PullRequest,
+	reviewerID model.UserID,
+	teamID model.TeamID,
+	currentReviewers []model.User,
+) (model.TeamSettings, error) {
+	if reviewerID == pr.AuthorID {
+		return model.TeamSettings{}, rules.ErrReviewerIsAuthor
+	}
+
+	reviewer, err := s.userRepo.GetByID(ctx, reviewerID)
+	if err != nil {
+		return model.TeamSettings{}, err
+	}
+	if !reviewer.IsActive {
+		return model.TeamSettings{}, rules.ErrReviewerInactive
+	}
+
+	settings, ancestors, err := s.hierarchy.resolve(ctx, teamID)
+	if err != nil {
+		s.logger.Error(err, "failed to resolve reviewer team settings")
+		return model.TeamSettings{}, err
+	}
+
+	teamIDs := []model.TeamID{teamID}
+	if settings.FallbackEnabled() {
+		for _, ancestor := range ancestors {
+			teamIDs = append(teamIDs, ancestor.TeamID)
+		}
+	}
+	inTeam := false
+	for _, ID := range teamIDs {
+		inTeam, err = s.membershipRepo.Exists(ctx, ID, reviewerID)
+		if err != nil {
+			s.logger.Error(err, "failed to check reviewer membership")
+			return model.TeamSettings{}, err
+		}
+		if inTeam {
+			break
+		}
+	}
+	if !inTeam {
+		return model.TeamSettings{}, rules.ErrReviewerNotInTeam
+	}
+
+	for _, current := range currentReviewers {
+		if current.ID == reviewerID {
+			return model.TeamSettings{}, rules.ErrAlreadyAssigned
+		}
+	}
+
+	decliners, err := s.reviewAssignmentRepo.GetDecliners(ctx, pr.PullRequestID)
+	if err != nil {
+		s.logger.Error(err, "failed to get decliners")
+		return model.TeamSettings{}, err
+	}
+	if slices.Contains(decliners, reviewerID) {
+		return model.TeamSettings{}, rules.ErrReviewerDeclined
+	}
+
+	return settings, nil
+}
+
+// getForReviewerChange loads an unmerged pull request whose reviewers the actor may pick by
+// hand: the author, or a maintainer of the author's team.
+func (s *PullRequestService) getForReviewerChange(ctx context.Context, ID model.PullRequestID) (
+	*model.PullRequest, *model.User, error,
+) {
+	pullRequest, err := s.pullRequestRepo.GetByID(ctx, ID)
+	if err != nil {
+		s.logger.Error(err, "failed to get pull request")
+		return nil, nil, err
+	}
+
+	err = precondition.CheckVersion(ctx, pullRequest.Version)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if pullRequest.Status == model.PRStatusMerged {
+		return nil, nil, rules.ErrPullRequestMerged
+	}
+
+	author, err := s.userRepo.GetByID(ctx, pullRequest.AuthorID)
+	if err != nil {
+		s.logger.Error(err, "failed to get author")
+		return nil, nil, err
+	}
+
+	actorID, ok := auth.ActorFromContext(ctx)
+	if !ok || actorID != pullRequest.AuthorID {
+		err = s.access.requireRole(ctx, model.TeamID(author.TeamID), model.TeamRoleMaintainer)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return pullRequest, author, nil
+}
+
 func (s *PullRequestService) MergePullRequest(ctx context.Context, ID model.PullRequestID) (*model.PullRequest, error) {
+	var merged *model.PullRequest
+	err := withinRetriedTx(ctx, s.tx, func(ctx context.Context) error {
+		var err error
+		merged, err = s.mergePullRequest(ctx, ID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return merged, nil
+}
+
+func (s *PullRequestService) mergePullRequest(ctx context.Context, ID model.PullRequestID) (*model.PullRequest, error) {
 	pullRequest, err := s.pullRequestRepo.GetByID(ctx, ID)
 	if err != nil {
 		s.logger.Error(err, "failed to get pull request")
 		return nil, err
 	}
 
+	err = precondition.CheckVersion(ctx, pullRequest.Version)
+	if err != nil {
+		return nil, err
+	}
+
 	if pullRequest.Status == model.PRStatusMerged {
 		return pullRequest, nil
 	}
+	if pullRequest.Status == model.PRStatusDraft {
+		return nil, rules.ErrPullRequestDraft
+	}
+
+	err = s.pullRequestRepo.BumpVersion(ctx, ID, pullRequest.Version)
+	if err != nil {
+		return nil, err
+	}
 
 	now := time.Now()
 	err = s.pullRequestRepo.UpdateStatus(ctx, ID, model.PRStatusMerged, now)
@@ -180,33 +711,165 @@ func (s *PullRequestService) MergePullRequest(ctx context.Context, ID model.Pull
 	return updatedPR, nil
 }
 
+// ReleaseReviews applies policy to the open reviews the reviewer holds on pull requests
+// authored in teamID. It has to run while the reviewer still belongs to that team,
+// so replacements are picked among their current teammates. Called inside a membership
+// change, it joins that change's transaction.
+func (s *PullRequestService) ReleaseReviews(
+	ctx context.Context,
+	reviewerID model.UserID,
+	teamID model.TeamID,
+	policy model.ReviewPolicy,
+) error {
+	return s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		return s.releaseReviews(ctx, reviewerID, teamID, policy)
+	})
+}
+
+func (s *PullRequestService) releaseReviews(
+	ctx context.Context,
+	reviewerID model.UserID,
+	teamID model.TeamID,
+	policy model.ReviewPolicy,
+) error {
+	if !policy.IsValid() {
+		return rules.ErrInvalidReviewPolicy
+	}
+	if policy == model.ReviewPolicyKeep {
+		return nil
+	}
+
+	pullRequests, err := s.pullRequestRepo.GetByReviewer(ctx, reviewerID, model.ReviewFilter{})
+	if err != nil {
+		s.logger.Error(err, "cannot get pull requests for reviewer")
+		return err
+	}
+
+	for _, pr := range pullRequests {
+		if pr.Status != model.PRStatusOpen {
+			continue
+		}
+
+		inTeam, err := s.membershipRepo.Exists(ctx, teamID, pr.AuthorID)
+		if err != nil {
+			s.logger.Error(err, "failed to check author membership")
+			return err
+		}
+		if !inTeam {
+			continue
+		}
+
+		switch policy {
+		case model.ReviewPolicyReassign:
+			_, err = s.reassignReviewer(ctx, &pr, reviewerID, teamID)
+			if errors.Is(err, rules.ErrNoCandidates) {
+				s.logger.Warn(
+					"no replacement for released review, keeping reviewer",
+					logger.F("pull_request_id", uuid.UUID(pr.PullRequestID).String()),
+				)
+				continue
+			}
+		case model.ReviewPolicyUnassign:
+			err = s.pullRequestRepo.BumpVersion(ctx, pr.PullRequestID, pr.Version)
+			if err == nil {
+				err = s.reviewAssignmentRepo.RemoveReviewer(ctx, pr.PullRequestID, reviewerID)
+			}
+		}
+		if err != nil {
+			s.logger.Error(err, "failed to release review")
+			return err
+		}
+	}
+
+	return nil
+}
+
+// checkForceReassign lets reviewers hand off their own review, while replacing
+// somebody else requires a maintainer of the author's team.
+func (s *PullRequestService) checkForceReassign(
+	ctx context.Context,
+	pr *model.PullRequest,
+	oldReviewerID model.UserID,
+) error {
+	actorID, ok := auth.ActorFromContext(ctx)
+	if ok && actorID == oldReviewerID {
+		return nil
+	}
+
+	author, err := s.userRepo.GetByID(ctx, pr.AuthorID)
+	if err != nil {
+		s.logger.Error(err, "failed to get author")
+		return err
+	}
+
+	return s.access.requireRole(ctx, model.TeamID(author.TeamID), model.TeamRoleMaintainer)
+}
+
 func (s *PullRequestService) assignInitialReviewers(
 	ctx context.Context,
 	pr *model.PullRequest,
 	authorTeamID uuid.UUID,
 ) ([]model.UserID, error) {
-	teamID := model.TeamID(authorTeamID)
 	excludedUserIDs := []model.UserID{pr.AuthorID}
 
-	candidates, err := s.userRepo.GetActiveByTeamExcluding(ctx, teamID, excludedUserIDs)
+	// pull requests of one team pick their reviewers one at a time
+	if authorTeamID != uuid.Nil {
+		err := s.teamRepo.Lock(ctx, model.TeamID(authorTeamID))
+		if err != nil {
+			s.logger.Error(err, "failed to lock author team")
+			return nil, err
+		}
+	}
+
+	settings, ancestors, err := s.hierarchy.resolve(ctx, model.TeamID(authorTeamID))
 	if err != nil {
-		s.logger.Error(err, "failed to get active team members for reviewer assignment")
+		s.logger.Error(err, "failed to resolve author team settings")
 		return nil, err
 	}
 
-	if len(candidates) == 0 {
-		return []model.UserID{}, nil
+	maxCount := s.maxReviewersCount
+	if settings.MaxReviewers != nil {
+		maxCount = *settings.MaxReviewers
 	}
 
-	selectedReviewers := s.randomSelectReviewers(candidates, s.maxReviewersCount)
+	// the routing rules pick first, the author's team fills the places they leave
+	assignments, err := newReviewerRouter(s).route(ctx, pr, excludedUserIDs)
+	if err != nil {
+		return nil, err
+	}
+	for _, assignment := range assignments {
+		excludedUserIDs = append(excludedUserIDs, assignment.ReviewerID)
+	}
 
-	reviewerIDs := make([]model.UserID, len(selectedReviewers))
-	for i, reviewer := range selectedReviewers {
-		reviewerIDs[i] = reviewer.ID
+	if remaining := maxCount - len(assignments); remaining > 0 {
+		candidates, teamID, err := s.findCandidates(
+			ctx, model.TeamID(authorTeamID), settings, ancestors, excludedUserIDs,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		selectedReviewers, err := s.selectReviewers(ctx, pr.AuthorID, candidates, remaining, teamID)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, reviewer := range selectedReviewers {
+			assignments = append(
+				assignments, model.ReviewAssignment{PullRequestID: pr.PullRequestID, ReviewerID: reviewer.ID},
+			)
+		}
+	}
+
+	now := time.Now()
+	reviewerIDs := make([]model.UserID, len(assignments))
+	for i := range assignments {
+		assignments[i].AssignedAt = now
+		reviewerIDs[i] = assignments[i].ReviewerID
 	}
 
-	if len(reviewerIDs) > 0 {
-		err = s.reviewAssignmentRepo.AssignReviewers(ctx, pr.PullRequestID, reviewerIDs)
+	if len(assignments) > 0 {
+		err = s.reviewAssignmentRepo.InsertBatch(ctx, assignments)
 		if err != nil {
 			s.logger.Error(err, "failed to assign reviewers to pull request")
 			return nil, err
@@ -216,31 +879,70 @@ func (s *PullRequestService) assignInitialReviewers(
 	return reviewerIDs, nil
 }
 
-func (s *PullRequestService) reassignReviewer(
+// replacementTeam picks the team a substitute reviewer is drawn from: the author's team
+// when the old reviewer belongs to it, otherwise the old reviewer's primary team.
+func (s *PullRequestService) replacementTeam(
 	ctx context.Context,
 	pr *model.PullRequest,
 	oldReviewerID model.UserID,
-) (model.UserID, error) {
+) (model.TeamID, error) {
+	author, err := s.userRepo.GetByID(ctx, pr.AuthorID)
+	if err != nil {
+		s.logger.Error(err, "failed to get author")
+		return model.TeamID(uuid.Nil), err
+	}
+
+	authorTeamID := model.TeamID(author.TeamID)
+	inAuthorTeam, err := s.membershipRepo.Exists(ctx, authorTeamID, oldReviewerID)
+	if err != nil {
+		s.logger.Error(err, "failed to check reviewer membership")
+		return model.TeamID(uuid.Nil), err
+	}
+	if inAuthorTeam {
+		return authorTeamID, nil
+	}
+
 	oldReviewer, err := s.userRepo.GetByID(ctx, oldReviewerID)
 	if err != nil {
 		s.logger.Error(err, "failed to get old reviewer")
-		return model.UserID(uuid.Nil), err
+		return model.TeamID(uuid.Nil), err
 	}
 
+	return model.TeamID(oldReviewer.TeamID), nil
+}
+
+func (s *PullRequestService) reassignReviewer(
+	ctx context.Context,
+	pr *model.PullRequest,
+	oldReviewerID model.UserID,
+	teamID model.TeamID,
+) (model.UserID, error) {
 	currentReviewers, err := s.reviewAssignmentRepo.GetReviewers(ctx, pr.PullRequestID)
 	if err != nil {
 		s.logger.Error(err, "failed to get current reviewers")
 		return model.UserID(uuid.Nil), err
 	}
 
-	excludedUserIDs := []model.UserID{pr.AuthorID}
+	// whoever declined the pull request is not asked again
+	decliners, err := s.reviewAssignmentRepo.GetDecliners(ctx, pr.PullRequestID)
+	if err != nil {
+		s.logger.Error(err, "failed to get decliners")
+		return model.UserID(uuid.Nil), err
+	}
+
+	excludedUserIDs := append([]model.UserID{pr.AuthorID}, decliners...)
 	for _, reviewer := range currentReviewers {
 		excludedUserIDs = append(excludedUserIDs, reviewer.ID)
 	}
 
-	candidates, err := s.userRepo.GetActiveByTeamExcluding(ctx, model.TeamID(oldReviewer.TeamID), excludedUserIDs)
+	settings, ancestors, err := s.hierarchy.resolve(ctx, teamID)
+	if err != nil {
+		s.logger.Error(err, "failed to resolve reviewer team settings")
+		return model.UserID(uuid.Nil), err
+	}
+
+	candidates, teamID, err := s.findCandidates(ctx, teamID, settings, ancestors, excludedUserIDs)
 	if err != nil {
-		s.logger.Error(err, "failed to get candidate reviewers for reassignment")
 		return model.UserID(uuid.Nil), err
 	}
 
@@ -248,13 +950,24 @@ func (s *PullRequestService) reassignReviewer(
 		return model.UserID(uuid.Nil), rules.ErrNoCandidates
 	}
 
-	selectedReviewers := s.randomSelectReviewers(candidates, 1)
+	selectedReviewers, err := s.selectReviewers(ctx, pr.AuthorID, candidates, 1, teamID)
+	if err != nil {
+		return model.UserID(uuid.Nil), err
+	}
 	if len(selectedReviewers) == 0 {
 		return model.UserID(uuid.Nil), rules.ErrNoCandidates
 	}
 
 	newReviewerID := selectedReviewers[0].ID
 
+	// claim the pull request only once there is a replacement: a concurrent change since it was
+	// read makes this one start over instead of trusting a stale list, and a pull request
+	// nobody can take over keeps its version
+	err = s.pullRequestRepo.BumpVersion(ctx, pr.PullRequestID, pr.Version)
+	if err != nil {
+		return model.UserID(uuid.Nil), err
+	}
+
 	err = s.reviewAssignmentRepo.ReplaceReviewer(ctx, pr.PullRequestID, oldReviewerID, newReviewerID)
 	if err != nil {
 		s.logger.Error(err, "failed to replace reviewer")
@@ -264,20 +977,129 @@ func (s *PullRequestService) reassignReviewer(
 	return newReviewerID, nil
 }
 
-func (s *PullRequestService) randomSelectReviewers(candidates []model.User, maxCount int) []model.User {
+// replaceWithChosenReviewer replaces oldReviewerID by newReviewerID, provided it is somebody the
+// random pick from teamID could have chosen.
+func (s *PullRequestService) replaceWithChosenReviewer(
+	ctx context.Context,
+	pr *model.PullRequest,
+	oldReviewerID model.UserID,
+	newReviewerID model.UserID,
+	teamID model.TeamID,
+) error {
+	err := s.pullRequestRepo.BumpVersion(ctx, pr.PullRequestID, pr.Version)
+	if err != nil {
+		return err
+	}
+
+	currentReviewers, err := s.reviewAssignmentRepo.GetReviewers(ctx, pr.PullRequestID)
+	if err != nil {
+		s.logger.Error(err, "failed to get current reviewers")
+		return err
+	}
+
+	// the old reviewer leaves as the new one joins, so the number of reviewers stays in capacity
+	_, err = s.checkChosenReviewer(ctx, pr, newReviewerID, teamID, currentReviewers)
+	if err != nil {
+		return err
+	}
+
+	err = s.reviewAssignmentRepo.ReplaceReviewer(ctx, pr.PullRequestID, oldReviewerID, newReviewerID)
+	if err != nil {
+		s.logger.Error(err, "failed to replace reviewer")
+		return err
+	}
+
+	return nil
+}
+
+// findCandidates looks for active reviewers in the team and, when it has nobody left and the
+// team allows it, in its ancestors from the nearest one up. It also returns the team the
+// candidates were found in.
+func (s *PullRequestService) findCandidates(
+	ctx context.Context,
+	teamID model.TeamID,
+	settings model.TeamSettings,
+	ancestors []model.Team,
+	excludedUserIDs []model.UserID,
+) ([]model.User, model.TeamID, error) {
+	candidates, err := s.userRepo.GetActiveByTeamExcluding(ctx, teamID, excludedUserIDs)
+	if err != nil {
+		s.logger.Error(err, "failed to get active team members for reviewer assignment")
+		return nil, teamID, err
+	}
+	if len(candidates) > 0 || !settings.FallbackEnabled() {
+		return candidates, teamID, nil
+	}
+
+	for _, ancestor := range ancestors {
+		candidates, err = s.userRepo.GetActiveByTeamExcluding(ctx, ancestor.TeamID, excludedUserIDs)
+		if err != nil {
+			s.logger.Error(err, "failed to get active members of parent team")
+			return nil, teamID, err
+		}
+		if len(candidates) > 0 {
+			return candidates, ancestor.TeamID, nil
+		}
+	}
+
+	return candidates, teamID, nil
+}
+
+func (s *PullRequestService) membershipWeights(ctx context.Context, teamID model.TeamID) (
+	map[model.UserID]float64, error,
+) {
+	memberships, err := s.membershipRepo.GetByTeam(ctx, teamID)
+	if err != nil {
+		s.logger.Error(err, "failed to get team membership weights")
+		return nil, err
+	}
+
+	weights := make(map[model.UserID]float64, len(memberships))
+	for _, membership := range memberships {
+		weights[membership.UserID] = membership.Weight
+	}
+
+	return weights, nil
+}
+
+// randomSelectReviewers draws up to maxCount distinct candidates, each with a chance
+// proportional to their membership weight (weighted sampling without replacement).
+func (s *PullRequestService) randomSelectReviewers(
+	candidates []model.User, maxCount int, weights map[model.UserID]float64,
+) []model.User {
 	if len(candidates) == 0 {
 		return []model.User{}
 	}
 
 	selectCount := min(maxCount, len(candidates))
 
-	shuffled := make([]model.User, len(candidates))
-	copy(shuffled, candidates)
+	type keyedCandidate struct {
+		user model.User
+		key  float64
+	}
+
+	keyed := make([]keyedCandidate, len(candidates))
+	for i, candidate := range candidates {
+		weight, ok := weights[candidate.ID]
+		if !ok || weight <= 0 {
+			weight = model.DefaultMembershipWeight
+		}
+		keyed[i] = keyedCandidate{
+			user: candidate,
+			key:  math.Pow(s.random(), 1/weight),
+		}
+	}
+
+	sort.Slice(
+		keyed, func(i, j int) bool {
+			return keyed[i].key > keyed[j].key
+		},
+	)
 
-	for i := len(shuffled) - 1; i > 0; i-- {
-		j := rand.Intn(i + 1)
-		shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
+	selected := make([]model.User, selectCount)
+	for i := range selected {
+		selected[i] = keyed[i].user
 	}
 
-	return shuffled[:selectCount]
+	return selected
 }
\ No newline at end of file
@@ -0,0 +1,123 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+
+	"pull-request-review/internal/domain/model"
+	"pull-request-review/internal/domain/rules"
+	"pull-request-review/internal/infrastructure/adapters/logger"
+)
+
+// reviewerRouter applies the routing rules to pull requests. The pool of a rule is loaded the
+// first time the rule matches, so a batch looks it up only once.
+type reviewerRouter struct {
+	service *PullRequestService
+	pools   map[string][]model.User
+}
+
+func newReviewerRouter(service *PullRequestService) *reviewerRouter {
+	return &reviewerRouter{
+		service: service,
+		pools:   make(map[string][]model.User),
+	}
+}
+
+// route picks the reviewers asked for by the rules matching pr, rule by rule in order. Nobody in
+// excludedUserIDs is picked, nor anybody an earlier rule picked. A rule whose pool has nobody
+// left is skipped, the author's team makes up for it. The assignment times are left to the caller.
+func (r *reviewerRouter) route(
+	ctx context.Context, pr *model.PullRequest, excludedUserIDs []model.UserID,
+) ([]model.ReviewAssignment, error) {
+	excluded := make(map[model.UserID]bool, len(excludedUserIDs))
+	for _, ID := range excludedUserIDs {
+		excluded[ID] = true
+	}
+
+	var routed []model.ReviewAssignment
+	for _, rule := range r.service.routingRules {
+		if !rule.Matches(pr.Labels, pr.ChangedPaths) {
+			continue
+		}
+
+		pool, err := r.pool(ctx, rule)
+		if err != nil {
+			return nil, err
+		}
+
+		candidates := make([]model.User, 0, len(pool))
+		for _, user := range pool {
+			if !excluded[user.ID] {
+				candidates = append(candidates, user)
+			}
+		}
+		if len(candidates) == 0 {
+			r.service.logger.Warn(
+				"no reviewer left in routing rule pool, skipping rule",
+				logger.F("rule", rule.Name),
+				logger.F("pull_request_id", uuid.UUID(pr.PullRequestID).String()),
+			)
+			continue
+		}
+
+		for _, reviewer := range r.service.randomSelectReviewers(candidates, rule.Reviewers, nil) {
+			excluded[reviewer.ID] = true
+			routed = append(
+				routed, model.ReviewAssignment{
+					PullRequestID: pr.PullRequestID,
+					ReviewerID:    reviewer.ID,
+					Rule:          rule.Name,
+				},
+			)
+		}
+	}
+
+	return routed, nil
+}
+
+// pool lists the active users of the rule and the active members of its teams, each once. A
+// team that does not exist is left out, so a rule can be configured before its team is created.
+func (r *reviewerRouter) pool(ctx context.Context, rule model.RoutingRule) ([]model.User, error) {
+	if pool, ok := r.pools[rule.Name]; ok {
+		return pool, nil
+	}
+
+	users, err := r.service.userRepo.GetByIDs(ctx, rule.UserIDs)
+	if err != nil {
+		r.service.logger.Error(err, "failed to get routing rule users")
+		return nil, err
+	}
+
+	for _, name := range rule.TeamNames {
+		team, err := r.service.teamRepo.GetByName(ctx, name)
+		if errors.Is(err, rules.ErrTeamNotFound) {
+			r.service.logger.Warn("routing rule team not found", logger.F("rule", rule.Name), logger.F("team", name))
+			continue
+		}
+		if err != nil {
+			r.service.logger.Error(err, "failed to get routing rule team")
+			return nil, err
+		}
+
+		members, err := r.service.userRepo.GetActiveByTeamExcluding(ctx, team.TeamID, nil)
+		if err != nil {
+			r.service.logger.Error(err, "failed to get routing rule team members")
+			return nil, err
+		}
+		users = append(users, members...)
+	}
+
+	seen := make(map[model.UserID]bool, len(users))
+	pool := make([]model.User, 0, len(users))
+	for _, user := range users {
+		if user.IsActive && !seen[user.ID] {
+			seen[user.ID] = true
+			pool = append(pool, user)
+		}
+	}
+
+	r.pools[rule.Name] = pool
+	return pool, nil
+}
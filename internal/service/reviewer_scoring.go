@@ -0,0 +1,233 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"pull-request-review/internal/domain/model"
+	"pull-request-review/internal/domain/rules"
+)
+
+// recencyHalfLife is how long it takes for a past review of the author to count half as much as
+// one made today.
+const recencyHalfLife = 30 * 24 * time.Hour
+
+// SuggestReviewers ranks the candidates who could review the pull request on top of its current
+// reviewers, best first, without assigning anybody. The candidates are the ones a reassignment
+// would draw from. limit caps the suggestions when it is positive.
+func (s *PullRequestService) SuggestReviewers(ctx context.Context, ID model.PullRequestID, limit int) (
+	[]model.ReviewerSuggestion, error,
+) {
+	pullRequest, err := s.pullRequestRepo.GetByID(ctx, ID)
+	if err != nil {
+		s.logger.Error(err, "failed to get pull request")
+		return nil, err
+	}
+	if pullRequest.Status == model.PRStatusMerged {
+		return nil, rules.ErrPullRequestMerged
+	}
+
+	author, err := s.userRepo.GetByID(ctx, pullRequest.AuthorID)
+	if err != nil {
+		s.logger.Error(err, "failed to get author")
+		return nil, err
+	}
+
+	currentReviewers, err := s.reviewAssignmentRepo.GetReviewers(ctx, ID)
+	if err != nil {
+		s.logger.Error(err, "failed to get current reviewers")
+		return nil, err
+	}
+
+	decliners, err := s.reviewAssignmentRepo.GetDecliners(ctx, ID)
+	if err != nil {
+		s.logger.Error(err, "failed to get decliners")
+		return nil, err
+	}
+
+	excludedUserIDs := append([]model.UserID{pullRequest.AuthorID}, decliners...)
+	for _, reviewer := range currentReviewers {
+		excludedUserIDs = append(excludedUserIDs, reviewer.ID)
+	}
+
+	authorTeamID := model.TeamID(author.TeamID)
+	settings, ancestors, err := s.hierarchy.resolve(ctx, authorTeamID)
+	if err != nil {
+		s.logger.Error(err, "failed to resolve author team settings")
+		return nil, err
+	}
+
+	candidates, teamID, err := s.findCandidates(ctx, authorTeamID, settings, ancestors, excludedUserIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	suggestions, err := s.scoreCandidates(ctx, pullRequest.AuthorID, candidates, teamID)
+	if err != nil {
+		return nil, err
+	}
+
+	if limit > 0 && len(suggestions) > limit {
+		suggestions = suggestions[:limit]
+	}
+	return suggestions, nil
+}
+
+// selectReviewers picks up to count of the candidates found in teamID for a pull request of
+// authorID, with the configured strategy.
+func (s *PullRequestService) selectReviewers(
+	ctx context.Context, authorID model.UserID, candidates []model.User, count int, teamID model.TeamID,
+) ([]model.User, error) {
+	if len(candidates) == 0 || count <= 0 {
+		return []model.User{}, nil
+	}
+
+	if s.strategy == model.ReviewerStrategyExpertise {
+		suggestions, err := s.scoreCandidates(ctx, authorID, candidates, teamID)
+		if err != nil {
+			return nil, err
+		}
+
+		selected := make([]model.User, min(count, len(suggestions)))
+		for i := range selected {
+			selected[i] = suggestions[i].User
+		}
+		return selected, nil
+	}
+
+	weights, err := s.membershipWeights(ctx, teamID)
+	if err != nil {
+		return nil, err
+	}
+	return s.randomSelectReviewers(candidates, count, weights), nil
+}
+
+// scoreCandidates ranks the candidates found in teamID for a pull request of authorID, best first.
+// Expertise grows with the number of the author's pull requests a candidate reviewed, relative to
+// the most experienced candidate, and with how recent the last of them is. Availability shrinks
+// with the open reviews of the candidate for their membership weight, like the batch balancing.
+func (s *PullRequestService) scoreCandidates(
+	ctx context.Context, authorID model.UserID, candidates []model.User, teamID model.TeamID,
+) ([]model.ReviewerSuggestion, error) {
+	if len(candidates) == 0 {
+		return []model.ReviewerSuggestion{}, nil
+	}
+
+	candidateIDs := make([]model.UserID, len(candidates))
+	for i, candidate := range candidates {
+		candidateIDs[i] = candidate.ID
+	}
+
+	openReviews, err := s.reviewAssignmentRepo.GetOpenReviewCounts(ctx, candidateIDs)
+	if err != nil {
+		s.logger.Error(err, "failed to get open review counts")
+		return nil, err
+	}
+
+	weights, err := s.membershipWeights(ctx, teamID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.rankCandidates(ctx, authorID, candidates, openReviews, weights)
+}
+
+// rankCandidates scores the candidates with the given open reviews and membership weights, so a
+// batch can rank them by the load it has assigned so far.
+func (s *PullRequestService) rankCandidates(
+	ctx context.Context, authorID model.UserID, candidates []model.User, openReviews map[model.UserID]int,
+	weights map[model.UserID]float64,
+) ([]model.ReviewerSuggestion, error) {
+	candidateIDs := make([]model.UserID, len(candidates))
+	for i, candidate := range candidates {
+		candidateIDs[i] = candidate.ID
+	}
+
+	history, err := s.reviewAssignmentRepo.GetReviewHistory(ctx, authorID, candidateIDs)
+	if err != nil {
+		s.logger.Error(err, "failed to get review history")
+		return nil, err
+	}
+
+	mostReviews := 0
+	for _, reviews := range history {
+		mostReviews = max(mostReviews, reviews.Reviews)
+	}
+
+	now := time.Now()
+	suggestions := make([]model.ReviewerSuggestion, len(candidates))
+	for i, candidate := range candidates {
+		reviews := history[candidate.ID]
+
+		var familiarity, recency float64
+		if reviews.Reviews > 0 {
+			familiarity = float64(reviews.Reviews) / float64(mostReviews)
+			age := max(now.Sub(reviews.LastReviewedAt), 0)
+			recency = math.Pow(0.5, float64(age)/float64(recencyHalfLife))
+		}
+
+		weight, ok := weights[candidate.ID]
+		if !ok || weight <= 0 {
+			weight = model.DefaultMembershipWeight
+		}
+
+		suggestion := model.ReviewerSuggestion{
+			User:         candidate,
+			Expertise:    (familiarity + recency) / 2,
+			Availability: 1 / (1 + float64(openReviews[candidate.ID])/weight),
+			History:      reviews,
+			OpenReviews:  openReviews[candidate.ID],
+			Reasons:      explainSuggestion(reviews, openReviews[candidate.ID], now),
+		}
+		suggestion.Score = s.scoringWeights.Expertise*suggestion.Expertise +
+			s.scoringWeights.Load*suggestion.Availability +
+			s.scoringWeights.Randomness*s.random()
+		suggestions[i] = suggestion
+	}
+
+	sort.SliceStable(
+		suggestions, func(i, j int) bool {
+			return suggestions[i].Score > suggestions[j].Score
+		},
+	)
+
+	return suggestions, nil
+}
+
+// explainSuggestion puts the facts behind a candidate's score into words.
+func explainSuggestion(reviews model.ReviewHistory, openReviews int, now time.Time) []string {
+	var reasons []string
+	switch reviews.Reviews {
+	case 0:
+		reasons = append(reasons, "has not reviewed any pull request of the author")
+	case 1:
+		reasons = append(reasons, "reviewed 1 pull request of the author")
+	default:
+		reasons = append(reasons, fmt.Sprintf("reviewed %d pull requests of the author", reviews.Reviews))
+	}
+
+	if reviews.Reviews > 0 {
+		switch days := int(max(now.Sub(reviews.LastReviewedAt), 0) / (24 * time.Hour)); days {
+		case 0:
+			reasons = append(reasons, "last reviewed one today")
+		case 1:
+			reasons = append(reasons, "last reviewed one yesterday")
+		default:
+			reasons = append(reasons, fmt.Sprintf("last reviewed one %d days ago", days))
+		}
+	}
+
+	switch openReviews {
+	case 0:
+		reasons = append(reasons, "has no open reviews")
+	case 1:
+		reasons = append(reasons, "has 1 open review")
+	default:
+		reasons = append(reasons, fmt.Sprintf("has %d open reviews", openReviews))
+	}
+
+	return reasons
+}
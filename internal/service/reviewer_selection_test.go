@@ -0,0 +1,86 @@
+package service
+
+import (
+	"math"
+	"math/rand"
+	"slices"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"pull-request-review/internal/domain/model"
+)
+
+// sequence returns a random source that hands out values in order.
+func sequence(values ...float64) func() float64 {
+	return func() float64 {
+		value := values[0]
+		values = values[1:]
+		return value
+	}
+}
+
+func TestRandomSelectReviewers(t *testing.T) {
+	a, b, c := newCandidate("a"), newCandidate("b"), newCandidate("c")
+
+	for _, tc := range []struct {
+		name     string
+		random   func() float64
+		maxCount int
+		weights  map[model.UserID]float64
+		want     []model.User
+	}{
+		{"heavier weight wins equal draws", sequence(0.5, 0.5, 0.5), 3,
+			map[model.UserID]float64{a.ID: 1, b.ID: 3, c.ID: 0.5}, []model.User{b, a, c}},
+		{"a high draw beats a heavier weight", sequence(0.99, 0.2, 0.5), 1,
+			map[model.UserID]float64{a.ID: 0.25, b.ID: 4}, []model.User{a}},
+		{"missing and non-positive weights count as the default", sequence(0.3, 0.6, 0.45), 3,
+			map[model.UserID]float64{a.ID: 0, b.ID: -1}, []model.User{b, c, a}},
+		{"count is capped by the candidates", sequence(0.1, 0.2, 0.3), 5, nil, []model.User{c, b, a}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			s := &PullRequestService{random: tc.random}
+			got := s.randomSelectReviewers([]model.User{a, b, c}, tc.maxCount, tc.weights)
+			if !slices.Equal(got, tc.want) {
+				t.Fatalf("selected %v, want %v", usernames(got), usernames(tc.want))
+			}
+		})
+	}
+
+	t.Run("no candidates", func(t *testing.T) {
+		s := &PullRequestService{random: sequence()}
+		if got := s.randomSelectReviewers(nil, 2, nil); len(got) != 0 {
+			t.Fatalf("selected %v", usernames(got))
+		}
+	})
+
+	t.Run("picks follow the weights", func(t *testing.T) {
+		const draws = 20000
+		s := &PullRequestService{random: rand.New(rand.NewSource(1)).Float64}
+		weights := map[model.UserID]float64{a.ID: 1, b.ID: 2, c.ID: 1}
+
+		picked := make(map[model.UserID]int)
+		for range draws {
+			picked[s.randomSelectReviewers([]model.User{a, b, c}, 1, weights)[0].ID]++
+		}
+		for _, candidate := range []model.User{a, b, c} {
+			share := float64(picked[candidate.ID]) / draws
+			want := weights[candidate.ID] / 4
+			if math.Abs(share-want) > 0.02 {
+				t.Fatalf("%s was picked %.3f of the time, want %.3f", candidate.Username, share, want)
+			}
+		}
+	})
+}
+
+func newCandidate(username string) model.User {
+	return model.User{ID: model.UserID(uuid.New()), Username: username, IsActive: true}
+}
+
+func usernames(users []model.User) []string {
+	names := make([]string, len(users))
+	for i, user := range users {
+		names[i] = user.Username
+	}
+	return names
+}
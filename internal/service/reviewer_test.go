@@ -0,0 +1,339 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"pull-request-review/internal/domain/auth"
+	"pull-request-review/internal/domain/model"
+	"pull-request-review/internal/domain/ports/repository"
+	"pull-request-review/internal/domain/rules"
+	"pull-request-review/internal/infrastructure/adapters/logger"
+	"pull-request-review/internal/service"
+)
+
+// TestManualReviewers walks a pull request through every rule of addReviewer and removeReviewer.
+func TestManualReviewers(t *testing.T) {
+	for name, open := range backends {
+		t.Run(name, func(t *testing.T) { testManualReviewers(t, open(t)) })
+	}
+}
+
+func testManualReviewers(t *testing.T, repos *repository.Repositories) {
+	ctx := context.Background()
+
+	type fixture struct {
+		prService *service.PullRequestService
+		ID        model.PullRequestID
+		author    model.UserID
+		asAuthor  context.Context
+		reviewers []model.UserID
+		// spare is a team member who does not review the pull request
+		spare model.UserID
+	}
+	newFixture := func(t *testing.T) *fixture {
+		t.Helper()
+		prService, members := newTeamWithPullRequests(t, repos, 4)
+		f := &fixture{prService: prService, author: members[0].ID, asAuthor: auth.WithActor(ctx, members[0].ID)}
+		f.ID, f.reviewers = createPullRequest(t, prService, f.author)
+		for _, member := range members[1:] {
+			if !slices.Contains(f.reviewers, member.ID) {
+				f.spare = member.ID
+			}
+		}
+		return f
+	}
+
+	now := time.Now()
+	outsider := &model.User{
+		ID: model.UserID(uuid.New()), Username: "outsider", IsActive: true, CreatedAt: now, UpdatedAt: now,
+	}
+	if err := repos.User.Insert(ctx, outsider); err != nil {
+		t.Fatalf("insert user: %v", err)
+	}
+
+	for _, tc := range []struct {
+		name string
+		// add returns the actor and the reviewer to add
+		add  func(f *fixture) (context.Context, model.UserID)
+		want error
+	}{
+		{"actor without access", func(f *fixture) (context.Context, model.UserID) {
+			return auth.WithActor(ctx, f.spare), f.spare
+		}, rules.ErrForbidden},
+		{"author", func(f *fixture) (context.Context, model.UserID) {
+			return f.asAuthor, f.author
+		}, rules.ErrReviewerIsAuthor},
+		{"not in the team", func(f *fixture) (context.Context, model.UserID) {
+			return f.asAuthor, outsider.ID
+		}, rules.ErrReviewerNotInTeam},
+		{"already assigned", func(f *fixture) (context.Context, model.UserID) {
+			return f.asAuthor, f.reviewers[0]
+		}, rules.ErrAlreadyAssigned},
+		{"too many reviewers", func(f *fixture) (context.Context, model.UserID) {
+			return f.asAuthor, f.spare
+		}, rules.ErrTooManyReviewers},
+	} {
+		t.Run("add refused: "+tc.name, func(t *testing.T) {
+			f := newFixture(t)
+			actorCtx, reviewerID := tc.add(f)
+			_, err := f.prService.AddReviewer(actorCtx, f.ID, reviewerID)
+			if !errors.Is(err, tc.want) {
+				t.Fatalf("expected %v, got %v", tc.want, err)
+			}
+		})
+	}
+
+	t.Run("add refused: inactive", func(t *testing.T) {
+		f := newFixture(t)
+		if _, err := f.prService.RemoveReviewer(f.asAuthor, f.ID, f.reviewers[0]); err != nil {
+			t.Fatalf("remove reviewer: %v", err)
+		}
+		if err := repos.User.UpdateActivity(ctx, f.spare, false); err != nil {
+			t.Fatalf("deactivate user: %v", err)
+		}
+		_, err := f.prService.AddReviewer(f.asAuthor, f.ID, f.spare)
+		if !errors.Is(err, rules.ErrReviewerInactive) {
+			t.Fatalf("expected %v, got %v", rules.ErrReviewerInactive, err)
+		}
+	})
+
+	t.Run("add into a free place", func(t *testing.T) {
+		f := newFixture(t)
+		if _, err := f.prService.RemoveReviewer(f.asAuthor, f.ID, f.reviewers[0]); err != nil {
+			t.Fatalf("remove reviewer: %v", err)
+		}
+		updated, err := f.prService.AddReviewer(f.asAuthor, f.ID, f.spare)
+		if err != nil || updated.Version != model.InitialPullRequestVersion+2 {
+			t.Fatalf("add reviewer: %+v, %v", updated, err)
+		}
+		current, err := f.prService.GetPullRequestReviewers(ctx, f.ID)
+		if err != nil || len(current) != stressReviewers || !slices.Contains(current, f.spare) {
+			t.Fatalf("unexpected reviewers %v: %v", current, err)
+		}
+	})
+
+	t.Run("remove refused: not assigned", func(t *testing.T) {
+		f := newFixture(t)
+		_, err := f.prService.RemoveReviewer(f.asAuthor, f.ID, f.spare)
+		if !errors.Is(err, rules.ErrNotAssigned) {
+			t.Fatalf("expected %v, got %v", rules.ErrNotAssigned, err)
+		}
+	})
+
+	t.Run("remove refused: merged", func(t *testing.T) {
+		f := newFixture(t)
+		if _, err := f.prService.MergePullRequest(ctx, f.ID); err != nil {
+			t.Fatalf("merge: %v", err)
+		}
+		_, err := f.prService.RemoveReviewer(f.asAuthor, f.ID, f.reviewers[0])
+		if !errors.Is(err, rules.ErrPullRequestMerged) {
+			t.Fatalf("expected %v, got %v", rules.ErrPullRequestMerged, err)
+		}
+	})
+
+	t.Run("remove bumps the version", func(t *testing.T) {
+		f := newFixture(t)
+		updated, err := f.prService.RemoveReviewer(f.asAuthor, f.ID, f.reviewers[0])
+		if err != nil {
+			t.Fatalf("remove reviewer: %v", err)
+		}
+		if updated.Version != model.InitialPullRequestVersion+1 {
+			t.Fatalf("removing a reviewer should bump the version, got %d", updated.Version)
+		}
+	})
+}
+
+// TestChosenReplacement checks that a reassign to a chosen reviewer tells why the choice is refused.
+func TestChosenReplacement(t *testing.T) {
+	for name, open := range backends {
+		t.Run(name, func(t *testing.T) { testChosenReplacement(t, open(t)) })
+	}
+}
+
+func testChosenReplacement(t *testing.T, repos *repository.Repositories) {
+	ctx := context.Background()
+
+	type fixture struct {
+		prService *service.PullRequestService
+		ID        model.PullRequestID
+		author    model.UserID
+		reviewers []model.UserID
+		spare     model.UserID
+	}
+	newFixture := func(t *testing.T) *fixture {
+		t.Helper()
+		prService, members := newTeamWithPullRequests(t, repos, 4)
+		f := &fixture{prService: prService, author: members[0].ID}
+		f.ID, f.reviewers = createPullRequest(t, prService, f.author)
+		for _, member := range members[1:] {
+			if !slices.Contains(f.reviewers, member.ID) {
+				f.spare = member.ID
+			}
+		}
+		return f
+	}
+
+	for _, tc := range []struct {
+		name   string
+		choose func(t *testing.T, f *fixture) model.UserID
+		want   error
+	}{
+		{"author", func(t *testing.T, f *fixture) model.UserID { return f.author }, rules.ErrReviewerIsAuthor},
+		{"other reviewer", func(t *testing.T, f *fixture) model.UserID { return f.reviewers[1] },
+			rules.ErrAlreadyAssigned},
+		{"replaced reviewer", func(t *testing.T, f *fixture) model.UserID { return f.reviewers[0] },
+			rules.ErrAlreadyAssigned},
+		{"unknown user", func(t *testing.T, f *fixture) model.UserID { return model.UserID(uuid.New()) },
+			rules.ErrUserNotFound},
+		{"inactive user", func(t *testing.T, f *fixture) model.UserID {
+			if err := repos.User.UpdateActivity(ctx, f.spare, false); err != nil {
+				t.Fatalf("deactivate user: %v", err)
+			}
+			return f.spare
+		}, rules.ErrReviewerInactive},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			f := newFixture(t)
+			old := f.reviewers[0]
+			_, _, err := f.prService.ReassignPullRequest(auth.WithActor(ctx, old), f.ID, old, tc.choose(t, f))
+			if !errors.Is(err, tc.want) {
+				t.Fatalf("expected %v, got %v", tc.want, err)
+			}
+			// a refused choice leaves the pull request as it was
+			pr, err := repos.PullRequest.GetByID(ctx, f.ID)
+			if err != nil || pr.Version != model.InitialPullRequestVersion {
+				t.Fatalf("a refused reassign should not bump the version: %+v, %v", pr, err)
+			}
+		})
+	}
+
+	t.Run("accepted choice", func(t *testing.T) {
+		f := newFixture(t)
+		old := f.reviewers[0]
+		updated, replacedBy, err := f.prService.ReassignPullRequest(auth.WithActor(ctx, old), f.ID, old, f.spare)
+		if err != nil || replacedBy != f.spare {
+			t.Fatalf("reassign to %v: got %v, %v", f.spare, replacedBy, err)
+		}
+		if updated.Version != model.InitialPullRequestVersion+1 {
+			t.Fatalf("the accepted reassign should bump the version once, got %d", updated.Version)
+		}
+	})
+}
+
+// TestDeclineReview declines a review until nobody is left to take it over.
+func TestDeclineReview(t *testing.T) {
+	for name, open := range backends {
+		t.Run(name, func(t *testing.T) { testDeclineReview(t, open(t)) })
+	}
+}
+
+func testDeclineReview(t *testing.T, repos *repository.Repositories) {
+	ctx := context.Background()
+
+	// newPullRequest sets up a pull request in a team of size, so size-3 members are left to take over
+	newPullRequest := func(t *testing.T, size int) (
+		*service.PullRequestService, model.PullRequestID, model.UserID, []model.UserID,
+	) {
+		t.Helper()
+		prService, members := newTeamWithPullRequests(t, repos, size)
+		ID, reviewers := createPullRequest(t, prService, members[0].ID)
+		return prService, ID, members[0].ID, reviewers
+	}
+	decline := func(
+		t *testing.T, prService *service.PullRequestService, ID model.PullRequestID, reviewerID model.UserID,
+	) (*model.PullRequest, model.UserID) {
+		t.Helper()
+		updated, replacedBy, err := prService.DeclineReview(auth.WithActor(ctx, reviewerID), ID, "not my area")
+		if err != nil {
+			t.Fatalf("decline: %v", err)
+		}
+		return updated, replacedBy
+	}
+
+	t.Run("only assigned reviewers decline", func(t *testing.T) {
+		prService, ID, author, _ := newPullRequest(t, 3)
+		_, _, err := prService.DeclineReview(auth.WithActor(ctx, author), ID, "not mine")
+		if !errors.Is(err, rules.ErrNotAssigned) {
+			t.Fatalf("expected %v, got %v", rules.ErrNotAssigned, err)
+		}
+	})
+
+	t.Run("a replacement takes over", func(t *testing.T) {
+		prService, ID, _, reviewers := newPullRequest(t, 4)
+		updated, replacedBy := decline(t, prService, ID, reviewers[0])
+		if uuid.UUID(replacedBy) == uuid.Nil || slices.Contains(reviewers, replacedBy) {
+			t.Fatalf("the last member should take over, got %v", replacedBy)
+		}
+		if updated.Version != model.InitialPullRequestVersion+1 {
+			t.Fatalf("a decline should bump the version once, got %d", updated.Version)
+		}
+	})
+
+	t.Run("a decliner is not picked again", func(t *testing.T) {
+		prService, ID, _, reviewers := newPullRequest(t, 4)
+		_, replacedBy := decline(t, prService, ID, reviewers[0])
+		_, _, err := prService.ReassignPullRequest(auth.WithActor(ctx, replacedBy), ID, replacedBy, model.UserID(uuid.Nil))
+		if !errors.Is(err, rules.ErrNoCandidates) {
+			t.Fatalf("expected %v, got %v", rules.ErrNoCandidates, err)
+		}
+	})
+
+	t.Run("nobody is left", func(t *testing.T) {
+		prService, ID, _, reviewers := newPullRequest(t, 3)
+		updated, replacedBy := decline(t, prService, ID, reviewers[0])
+		if uuid.UUID(replacedBy) != uuid.Nil {
+			t.Fatalf("nobody should take over, got %v", replacedBy)
+		}
+		current, err := repos.ReviewAssignment.GetReviewers(ctx, ID)
+		if err != nil || len(current) != 1 || current[0].ID != reviewers[1] {
+			t.Fatalf("the decliner should only be removed, got %v: %v", current, err)
+		}
+		if updated.Version != model.InitialPullRequestVersion+1 {
+			t.Fatalf("a decline should bump the version once, got %d", updated.Version)
+		}
+	})
+
+	// nor can a decliner be chosen by hand
+	t.Run("a decliner is not added back", func(t *testing.T) {
+		prService, ID, author, reviewers := newPullRequest(t, 3)
+		decline(t, prService, ID, reviewers[0])
+		_, err := prService.AddReviewer(auth.WithActor(ctx, author), ID, reviewers[0])
+		if !errors.Is(err, rules.ErrReviewerDeclined) {
+			t.Fatalf("expected %v, got %v", rules.ErrReviewerDeclined, err)
+		}
+	})
+
+	t.Run("a decliner is not chosen as replacement", func(t *testing.T) {
+		prService, ID, _, reviewers := newPullRequest(t, 3)
+		decline(t, prService, ID, reviewers[0])
+		_, _, err := prService.ReassignPullRequest(auth.WithActor(ctx, reviewers[1]), ID, reviewers[1], reviewers[0])
+		if !errors.Is(err, rules.ErrReviewerDeclined) {
+			t.Fatalf("expected %v, got %v", rules.ErrReviewerDeclined, err)
+		}
+	})
+
+	t.Run("statistics count declines", func(t *testing.T) {
+		prService, ID, _, reviewers := newPullRequest(t, 3)
+		for _, reviewerID := range reviewers {
+			decline(t, prService, ID, reviewerID)
+		}
+
+		stats, err := service.NewStatisticsService(repos.ReviewAssignment, repos.PullRequest, logger.NewZerologLogger()).
+			GetStatistics(ctx)
+		if err != nil {
+			t.Fatalf("get statistics: %v", err)
+		}
+		declines := stats["user_declines"].(map[string]int)
+		for _, reviewerID := range reviewers {
+			if declines[uuid.UUID(reviewerID).String()] != 1 {
+				t.Fatalf("unexpected declines %v", declines)
+			}
+		}
+	})
+}
@@ -0,0 +1,179 @@
+package service_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"pull-request-review/internal/domain/auth"
+	"pull-request-review/internal/domain/model"
+	"pull-request-review/internal/domain/ports/repository"
+	"pull-request-review/internal/service"
+)
+
+// TestReviewerRouting routes pull requests by label and by changed path, and checks that the
+// assignments remember the rule that picked each reviewer.
+func TestReviewerRouting(t *testing.T) {
+	for name, open := range backends {
+		t.Run(name, func(t *testing.T) { testReviewerRouting(t, open(t)) })
+	}
+}
+
+func testReviewerRouting(t *testing.T, repos *repository.Repositories) {
+	ctx := context.Background()
+
+	// fixture is a team routed to a security engineer by label and to a guild by changed path
+	type fixture struct {
+		prService *service.PullRequestService
+		author    model.UserID
+		security  model.UserID
+		inGuild   func(ID model.UserID) bool
+		inTeam    func(ID model.UserID) bool
+	}
+	newFixture := func(t *testing.T) *fixture {
+		t.Helper()
+		_, guild := newTeamWithPullRequests(t, repos, 3)
+		guildTeam, err := repos.Team.GetByID(ctx, model.TeamID(guild[0].TeamID))
+		if err != nil {
+			t.Fatalf("get guild team: %v", err)
+		}
+		now := time.Now()
+		securityEngineer := &model.User{
+			ID: model.UserID(uuid.New()), Username: "security", IsActive: true, CreatedAt: now, UpdatedAt: now,
+		}
+		if err := repos.User.Insert(ctx, securityEngineer); err != nil {
+			t.Fatalf("insert user: %v", err)
+		}
+
+		prService, members := newTeamWithRoutingRules(
+			t, repos, 4, []model.RoutingRule{
+				{Name: "security", Label: "security", UserIDs: []model.UserID{securityEngineer.ID}, Reviewers: 1},
+				{Name: "frontend", PathPattern: "web/**", TeamNames: []string{guildTeam.Name}, Reviewers: 1},
+				{Name: "migrations", PathPattern: "*.sql", TeamNames: []string{"no-such-team"}, Reviewers: 1},
+			},
+		)
+		return &fixture{
+			prService: prService,
+			author:    members[0].ID,
+			security:  securityEngineer.ID,
+			inGuild:   func(ID model.UserID) bool { return slices.Contains(userIDs(guild), ID) },
+			inTeam:    func(ID model.UserID) bool { return slices.Contains(userIDs(members), ID) },
+		}
+	}
+	create := func(t *testing.T, f *fixture, labels []string, paths []string) map[model.UserID]string {
+		t.Helper()
+		pr := &model.PullRequest{
+			PullRequestID: model.PullRequestID(uuid.New()), Name: "routed", AuthorID: f.author,
+			Status: model.PRStatusOpen, PullRequestMetadata: model.PullRequestMetadata{Labels: labels},
+			ChangedPaths: paths,
+		}
+		_, reviewers, err := f.prService.CreatePullRequest(ctx, pr)
+		if err != nil || len(reviewers) != stressReviewers {
+			t.Fatalf("create pull request: %v, %v", reviewers, err)
+		}
+		return assignedRules(t, repos, pr.PullRequestID)
+	}
+	// expectFrontend fails unless the guild got exactly one of the places
+	expectFrontend := func(t *testing.T, f *fixture, routed map[model.UserID]string) {
+		t.Helper()
+		var frontend int
+		for reviewerID, rule := range routed {
+			if rule == "frontend" && f.inGuild(reviewerID) {
+				frontend++
+			}
+		}
+		if frontend != 1 || len(routed) != stressReviewers {
+			t.Fatalf("the frontend rule should pick one reviewer: %v", routed)
+		}
+	}
+
+	t.Run("matching rules take every place", func(t *testing.T) {
+		f := newFixture(t)
+		for reviewerID, rule := range create(t, f, []string{"security"}, []string{"web/app/page.tsx"}) {
+			switch {
+			case reviewerID == f.security && rule == "security":
+			case f.inGuild(reviewerID) && rule == "frontend":
+			default:
+				t.Fatalf("reviewer %v should not be routed by %q", reviewerID, rule)
+			}
+		}
+	})
+
+	t.Run("the team fills the places left", func(t *testing.T) {
+		f := newFixture(t)
+		routed := create(t, f, nil, []string{"./web/index.html", "go.mod"})
+		for reviewerID, rule := range routed {
+			if !(f.inGuild(reviewerID) && rule == "frontend") && !(f.inTeam(reviewerID) && rule == "") {
+				t.Fatalf("reviewer %v should not be routed by %q", reviewerID, rule)
+			}
+		}
+		expectFrontend(t, f, routed)
+	})
+
+	for _, tc := range []struct {
+		name  string
+		paths []string
+	}{
+		{"no rule matches", []string{"website/index.html"}},
+		{"the matching rule has no candidates", []string{"db/001_init.sql"}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			f := newFixture(t)
+			for reviewerID, rule := range create(t, f, []string{"docs"}, tc.paths) {
+				if !f.inTeam(reviewerID) || rule != "" {
+					t.Fatalf("reviewer %v should not be routed by %q", reviewerID, rule)
+				}
+			}
+		})
+	}
+
+	t.Run("a ready draft is routed by its paths", func(t *testing.T) {
+		f := newFixture(t)
+		draft, reviewers, err := f.prService.CreatePullRequest(
+			ctx, &model.PullRequest{
+				PullRequestID: model.PullRequestID(uuid.New()), Name: "draft", AuthorID: f.author,
+				Status: model.PRStatusDraft, ChangedPaths: []string{"web/app/page.tsx"},
+			},
+		)
+		if err != nil || len(reviewers) != 0 {
+			t.Fatalf("create draft: %v, %v", reviewers, err)
+		}
+		if _, _, err := f.prService.MarkReady(auth.WithActor(ctx, f.author), draft.PullRequestID); err != nil {
+			t.Fatalf("mark ready: %v", err)
+		}
+		expectFrontend(t, f, assignedRules(t, repos, draft.PullRequestID))
+	})
+
+	t.Run("batch pull requests are routed", func(t *testing.T) {
+		f := newFixture(t)
+		pr := &model.PullRequest{
+			PullRequestID: model.PullRequestID(uuid.New()), Name: "batch", AuthorID: f.author,
+			PullRequestMetadata: model.PullRequestMetadata{Labels: []string{"security"}},
+		}
+		results, err := f.prService.BatchCreatePullRequests(ctx, []*model.PullRequest{pr})
+		if err != nil || results[0].Err != nil || len(results[0].ReviewerIDs) != stressReviewers {
+			t.Fatalf("batch create: %+v, %v", results, err)
+		}
+		routed := assignedRules(t, repos, pr.PullRequestID)
+		if routed[f.security] != "security" || len(routed) != stressReviewers {
+			t.Fatalf("batch pull requests should be routed too: %v", routed)
+		}
+	})
+}
+
+func assignedRules(t *testing.T, repos *repository.Repositories, ID model.PullRequestID) map[model.UserID]string {
+	t.Helper()
+
+	assignments, err := repos.ReviewAssignment.GetAssignments(context.Background(), ID)
+	if err != nil {
+		t.Fatalf("get assignments: %v", err)
+	}
+	assignedRules := make(map[model.UserID]string, len(assignments))
+	for _, assignment := range assignments {
+		assignedRules[assignment.ReviewerID] = assignment.Rule
+	}
+	return assignedRules
+}
@@ -0,0 +1,181 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"pull-request-review/internal/domain/model"
+	"pull-request-review/internal/domain/ports/repository"
+	"pull-request-review/internal/domain/rules"
+	"pull-request-review/internal/infrastructure/adapters/logger"
+	"pull-request-review/internal/service"
+)
+
+// TestReviewerScoring ranks candidates by what they reviewed of the author and by their load,
+// both as suggestions and with the expertise strategy.
+func TestReviewerScoring(t *testing.T) {
+	for name, open := range backends {
+		t.Run(name, func(t *testing.T) { testReviewerScoring(t, open(t)) })
+	}
+}
+
+func testReviewerScoring(t *testing.T, repos *repository.Repositories) {
+	ctx := context.Background()
+
+	type fixture struct {
+		prService                          *service.PullRequestService
+		author, expert, former, idle, busy model.UserID
+		// target is a pull request of the author without reviewers
+		target model.PullRequestID
+	}
+	newFixture := func(t *testing.T) *fixture {
+		t.Helper()
+		prService, members := newTeamWithPullRequests(t, repos, 5)
+		f := &fixture{
+			prService: prService,
+			author:    members[0].ID, expert: members[1].ID, former: members[2].ID, idle: members[3].ID, busy: members[4].ID,
+		}
+
+		now := time.Now()
+		newPullRequest := func(authorID model.UserID, reviewerID model.UserID, assignedAt time.Time, merged bool) {
+			t.Helper()
+			pr := &model.PullRequest{
+				PullRequestID: model.PullRequestID(uuid.New()), Name: "history", AuthorID: authorID,
+				Status: model.PRStatusOpen, CreatedAt: assignedAt, Version: model.InitialPullRequestVersion,
+			}
+			if err := repos.PullRequest.Create(ctx, pr); err != nil {
+				t.Fatalf("create pull request: %v", err)
+			}
+			assignment := &model.ReviewAssignment{
+				PullRequestID: pr.PullRequestID, ReviewerID: reviewerID, AssignedAt: assignedAt,
+			}
+			if err := repos.ReviewAssignment.Insert(ctx, assignment); err != nil {
+				t.Fatalf("insert assignment: %v", err)
+			}
+			if merged {
+				if err := repos.PullRequest.UpdateStatus(ctx, pr.PullRequestID, model.PRStatusMerged, now); err != nil {
+					t.Fatalf("merge pull request: %v", err)
+				}
+			}
+		}
+
+		// the expert reviewed the author a lot and lately, the former reviewer once long ago,
+		// and the busy one has open reviews of somebody else
+		for range 3 {
+			newPullRequest(f.author, f.expert, now.Add(-time.Hour), true)
+		}
+		newPullRequest(f.author, f.former, now.Add(-90*24*time.Hour), true)
+		for range 2 {
+			newPullRequest(f.former, f.busy, now, false)
+		}
+
+		target := &model.PullRequest{
+			PullRequestID: model.PullRequestID(uuid.New()), Name: "target", AuthorID: f.author,
+			Status: model.PRStatusOpen, CreatedAt: now, Version: model.InitialPullRequestVersion,
+		}
+		if err := repos.PullRequest.Create(ctx, target); err != nil {
+			t.Fatalf("create pull request: %v", err)
+		}
+		f.target = target.PullRequestID
+		return f
+	}
+	// newExpertiseService assigns the most familiar reviewers, with nothing but expertise in the score
+	newExpertiseService := func() *service.PullRequestService {
+		return service.NewPullRequestService(
+			repos.PullRequest, repos.User, repos.ReviewAssignment, repos.TeamMembership,
+			repos.Team, repos.TeamRole, repos.Tx, logger.NewZerologLogger(), service.PullRequestServiceOptions{
+				MaxReviewers: stressReviewers, MaxBatchSize: 100,
+				Strategy: model.ReviewerStrategyExpertise, ScoringWeights: model.ScoringWeights{Expertise: 1},
+			},
+		)
+	}
+
+	t.Run("suggestions rank by expertise and load", func(t *testing.T) {
+		f := newFixture(t)
+		suggestions, err := f.prService.SuggestReviewers(ctx, f.target, 0)
+		if err != nil {
+			t.Fatalf("suggest reviewers: %v", err)
+		}
+		ranked := make([]model.UserID, len(suggestions))
+		for i, suggestion := range suggestions {
+			ranked[i] = suggestion.User.ID
+		}
+		want := []model.UserID{f.expert, f.former, f.idle, f.busy}
+		if !slices.Equal(ranked, want) {
+			t.Fatalf("unexpected ranking %v of %v", ranked, want)
+		}
+		top := suggestions[0]
+		if top.History.Reviews != 3 || top.OpenReviews != 0 || len(top.Reasons) != 3 ||
+			top.Reasons[0] != "reviewed 3 pull requests of the author" {
+			t.Fatalf("unexpected top suggestion %+v", top)
+		}
+		if suggestions[3].OpenReviews != 2 || suggestions[3].Availability >= suggestions[2].Availability {
+			t.Fatalf("open reviews should lower availability: %+v", suggestions[3])
+		}
+	})
+
+	t.Run("suggesting assigns nobody", func(t *testing.T) {
+		f := newFixture(t)
+		if _, err := f.prService.SuggestReviewers(ctx, f.target, 0); err != nil {
+			t.Fatalf("suggest reviewers: %v", err)
+		}
+		reviewers, err := f.prService.GetPullRequestReviewers(ctx, f.target)
+		if err != nil || len(reviewers) != 0 {
+			t.Fatalf("suggestions should not be assigned: %v, %v", reviewers, err)
+		}
+	})
+
+	t.Run("limit caps the suggestions", func(t *testing.T) {
+		f := newFixture(t)
+		suggestions, err := f.prService.SuggestReviewers(ctx, f.target, 2)
+		if err != nil || len(suggestions) != 2 {
+			t.Fatalf("limit should cap the suggestions: %d, %v", len(suggestions), err)
+		}
+	})
+
+	t.Run("merged pull requests get no suggestions", func(t *testing.T) {
+		f := newFixture(t)
+		if err := repos.PullRequest.UpdateStatus(ctx, f.target, model.PRStatusMerged, time.Now()); err != nil {
+			t.Fatalf("merge pull request: %v", err)
+		}
+		_, err := f.prService.SuggestReviewers(ctx, f.target, 0)
+		if !errors.Is(err, rules.ErrPullRequestMerged) {
+			t.Fatalf("expected %v, got %v", rules.ErrPullRequestMerged, err)
+		}
+	})
+
+	t.Run("expertise strategy", func(t *testing.T) {
+		f := newFixture(t)
+		_, reviewers, err := newExpertiseService().CreatePullRequest(
+			ctx, &model.PullRequest{PullRequestID: model.PullRequestID(uuid.New()), Name: "scored", AuthorID: f.author},
+		)
+		if want := []model.UserID{f.expert, f.former}; err != nil || !slices.Equal(reviewers, want) {
+			t.Fatalf("expertise strategy should pick %v, got %v: %v", want, reviewers, err)
+		}
+	})
+
+	t.Run("expertise strategy in batches", func(t *testing.T) {
+		// batches rank by expertise as well instead of spreading the reviews by load
+		f := newFixture(t)
+		results, err := newExpertiseService().BatchCreatePullRequests(
+			ctx, []*model.PullRequest{
+				{PullRequestID: model.PullRequestID(uuid.New()), Name: "first", AuthorID: f.author},
+				{PullRequestID: model.PullRequestID(uuid.New()), Name: "second", AuthorID: f.author},
+			},
+		)
+		if err != nil {
+			t.Fatalf("batch create: %v", err)
+		}
+		want := []model.UserID{f.expert, f.former}
+		for _, result := range results {
+			if result.Err != nil || !slices.Equal(result.ReviewerIDs, want) {
+				t.Fatalf("batches should pick %v, got %v: %v", want, result.ReviewerIDs, result.Err)
+			}
+		}
+	})
+}
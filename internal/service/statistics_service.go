@@ -38,6 +38,18 @@ func (s *StatisticsService) GetStatistics(ctx context.Context) (map[string]any,
 		prCounts = make(map[string]int)
 	}
 
+	teamAssignments, err := s.reviewAssignmentRepo.GetTeamAssignmentCounts(ctx)
+	if err != nil {
+		s.logger.Warn("Failed to get team assignment counts")
+		teamAssignments = make(map[string]int)
+	}
+
+	userDeclines, err := s.reviewAssignmentRepo.GetDeclineCounts(ctx)
+	if err != nil {
+		s.logger.Warn("Failed to get decline counts")
+		userDeclines = make(map[string]int)
+	}
+
 	totalAssignments := 0
 	for _, count := range userAssignments {
 		totalAssignments += count
@@ -45,6 +57,8 @@ func (s *StatisticsService) GetStatistics(ctx context.Context) (map[string]any,
 
 	return map[string]any{
 		"user_assignments":  userAssignments,
+		"user_declines":     userDeclines,
+		"team_assignments":  teamAssignments,
 		"pr_counts":         prCounts,
 		"total_assignments": totalAssignments,
 	}, nil
@@ -0,0 +1,85 @@
+package service
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"pull-request-review/internal/domain/model"
+	"pull-request-review/internal/domain/ports/repository"
+)
+
+type teamHierarchy struct {
+	teamRepo repository.TeamRepository
+}
+
+func newTeamHierarchy(teamRepo repository.TeamRepository) *teamHierarchy {
+	return &teamHierarchy{teamRepo: teamRepo}
+}
+
+// resolve returns the effective settings of the team together with its ancestors, nearest first.
+func (h *teamHierarchy) resolve(ctx context.Context, teamID model.TeamID) (model.TeamSettings, []model.Team, error) {
+	if uuid.UUID(teamID) == uuid.Nil {
+		return model.TeamSettings{}, nil, nil
+	}
+
+	team, err := h.teamRepo.GetByID(ctx, teamID)
+	if err != nil {
+		return model.TeamSettings{}, nil, err
+	}
+
+	ancestors, err := h.teamRepo.GetAncestors(ctx, teamID)
+	if err != nil {
+		return model.TeamSettings{}, nil, err
+	}
+
+	settings := team.Settings
+	for _, ancestor := range ancestors {
+		settings = settings.Inherit(ancestor.Settings)
+	}
+
+	return settings, ancestors, nil
+}
+
+// isAncestorOrSelf reports whether candidateID is teamID itself or one of its ancestors.
+func (h *teamHierarchy) isAncestorOrSelf(ctx context.Context, teamID model.TeamID, candidateID model.TeamID) (
+	bool, error,
+) {
+	if teamID == candidateID {
+		return true, nil
+	}
+
+	ancestors, err := h.teamRepo.GetAncestors(ctx, teamID)
+	if err != nil {
+		return false, err
+	}
+	for _, ancestor := range ancestors {
+		if ancestor.TeamID == candidateID {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func buildTeamTree(team model.Team, inherited model.TeamSettings, children map[uuid.UUID][]model.Team) *model.TeamNode {
+	node := &model.TeamNode{
+		Team:              team,
+		EffectiveSettings: team.Settings.Inherit(inherited),
+		Children:          []*model.TeamNode{},
+	}
+
+	for _, child := range children[uuid.UUID(team.TeamID)] {
+		node.Children = append(node.Children, buildTeamTree(child, node.EffectiveSettings, children))
+	}
+
+	return node
+}
+
+func groupByParent(teams []model.Team) map[uuid.UUID][]model.Team {
+	children := make(map[uuid.UUID][]model.Team)
+	for _, team := range teams {
+		children[team.ParentTeamID] = append(children[team.ParentTeamID], team)
+	}
+	return children
+}
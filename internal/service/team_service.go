@@ -1,32 +1,60 @@
 package service
 
 import (
+	"bytes"
 	"context"
+	"errors"
+	"slices"
 	"time"
 
 	"github.com/google/uuid"
 
+	"pull-request-review/internal/domain/auth"
 	"pull-request-review/internal/domain/model"
 	"pull-request-review/internal/domain/ports/repository"
 	"pull-request-review/internal/domain/rules"
 	"pull-request-review/internal/infrastructure/adapters/logger"
 )
 
+// ReviewReleaser hands off the open reviews of a user leaving a team.
+type ReviewReleaser interface {
+	ReleaseReviews(ctx context.Context, reviewerID model.UserID, teamID model.TeamID, policy model.ReviewPolicy) error
+}
+
 type TeamService struct {
-	teamRepo repository.TeamRepository
-	userRepo repository.UserRepository
-	logger   logger.Logger
+	teamRepo       repository.TeamRepository
+	userRepo       repository.UserRepository
+	membershipRepo repository.TeamMembershipRepository
+	teamRoleRepo   repository.TeamRoleRepository
+	auditRepo      repository.MembershipAuditRepository
+	reviewReleaser ReviewReleaser
+	tx             repository.TxManager
+	access         *accessChecker
+	hierarchy      *teamHierarchy
+	logger         logger.Logger
 }
 
 func NewTeamService(
 	teamRepo repository.TeamRepository,
 	userRepo repository.UserRepository,
+	membershipRepo repository.TeamMembershipRepository,
+	teamRoleRepo repository.TeamRoleRepository,
+	auditRepo repository.MembershipAuditRepository,
+	reviewReleaser ReviewReleaser,
+	tx repository.TxManager,
 	logger logger.Logger,
 ) *TeamService {
 	return &TeamService{
-		teamRepo: teamRepo,
-		userRepo: userRepo,
-		logger:   logger,
+		teamRepo:       teamRepo,
+		userRepo:       userRepo,
+		membershipRepo: membershipRepo,
+		teamRoleRepo:   teamRoleRepo,
+		auditRepo:      auditRepo,
+		reviewReleaser: reviewReleaser,
+		tx:             tx,
+		access:         newAccessChecker(teamRoleRepo, membershipRepo),
+		hierarchy:      newTeamHierarchy(teamRepo),
+		logger:         logger,
 	}
 }
 
@@ -55,26 +83,87 @@ func (s *TeamService) GetTeam(ctx context.Context, ID model.TeamID) (*model.Team
 	return team, users, nil
 }
 
-func (s *TeamService) CreateTeamWithMembers(ctx context.Context, teamName string, members []model.User) error {
-	exists, err := s.teamRepo.ExistsByName(ctx, teamName)
+// CreateTeamWithMembers creates the team and resolves member conflicts in one transaction,
+// members never end up half moved.
+func (s *TeamService) CreateTeamWithMembers(
+	ctx context.Context, teamName string, members []model.User, opts model.TeamCreateOptions,
+) error {
+	return withinRetriedTx(ctx, s.tx, func(ctx context.Context) error {
+		return s.createTeamWithMembers(ctx, teamName, members, opts)
+	})
+}
+
+func (s *TeamService) createTeamWithMembers(
+	ctx context.Context, teamName string, members []model.User, opts model.TeamCreateOptions,
+) error {
+	if opts.ConflictMode == "" {
+		opts.ConflictMode = model.MemberConflictReject
+	}
+	if !opts.ConflictMode.IsValid() {
+		return rules.ErrInvalidConflictMode
+	}
+	if opts.ReviewPolicy == "" {
+		opts.ReviewPolicy = model.ReviewPolicyReassign
+	}
+	if !opts.ReviewPolicy.IsValid() {
+		return rules.ErrInvalidReviewPolicy
+	}
+	for _, weight := range opts.Weights {
+		if weight <= 0 {
+			return rules.ErrInvalidWeight
+		}
+	}
+
+	exists, err := s.nameTaken(ctx, teamName)
 	if err != nil {
-		s.logger.Error(err, "cannot check team existence")
 		return err
 	}
 	if exists {
 		return rules.ErrTeamExists
 	}
 
+	var parentTeamID uuid.UUID
+	if opts.ParentTeamName != "" {
+		parent, err := s.getParentTeam(ctx, opts.ParentTeamName)
+		if err != nil {
+			return err
+		}
+		parentTeamID = uuid.UUID(parent.TeamID)
+	}
+
+	conflicts, err := s.findMemberConflicts(ctx, members)
+	if err != nil {
+		return err
+	}
+	if len(conflicts) > 0 {
+		err = s.resolveMemberConflicts(ctx, conflicts, opts)
+		if err != nil {
+			return err
+		}
+	}
+
 	teamID := model.TeamID(uuid.New())
 	now := time.Now()
 	team := &model.Team{
-		TeamID:    teamID,
-		Name:      teamName,
-		CreatedAt: now,
+		TeamID:       teamID,
+		Name:         teamName,
+		ParentTeamID: parentTeamID,
+		CreatedAt:    now,
+	}
+
+	// in multi mode existing members keep their primary team and only gain a membership
+	primaryTeams := make(map[model.UserID]model.TeamID)
+	if opts.ConflictMode == model.MemberConflictMulti {
+		for _, conflict := range conflicts {
+			primaryTeams[conflict.UserID] = conflict.TeamID
+		}
 	}
 
 	for i := range members {
 		members[i].TeamID = uuid.UUID(teamID)
+		if primaryTeamID, ok := primaryTeams[members[i].ID]; ok {
+			members[i].TeamID = uuid.UUID(primaryTeamID)
+		}
 		if members[i].CreatedAt.IsZero() {
 			members[i].CreatedAt = now
 		}
@@ -89,9 +178,145 @@ func (s *TeamService) CreateTeamWithMembers(ctx context.Context, teamName string
 		return err
 	}
 
+	for userID, weight := range opts.Weights {
+		if weight == model.DefaultMembershipWeight {
+			continue
+		}
+		err = s.membershipRepo.Add(
+			ctx, &model.TeamMembership{
+				TeamID:    teamID,
+				UserID:    userID,
+				Weight:    weight,
+				CreatedAt: now,
+			},
+		)
+		if err != nil {
+			s.logger.Error(err, "cannot set membership weight")
+			return err
+		}
+	}
+
+	for _, conflict := range conflicts {
+		if opts.ConflictMode == model.MemberConflictMulti {
+			continue
+		}
+
+		err = s.membershipRepo.Remove(ctx, conflict.TeamID, conflict.UserID)
+		if err != nil && !errors.Is(err, rules.ErrNotFound) {
+			s.logger.Error(err, "cannot drop membership in previous team")
+			return err
+		}
+
+		err = s.teamRoleRepo.DeleteRole(ctx, conflict.TeamID, conflict.UserID)
+		if err != nil {
+			s.logger.Error(err, "cannot drop team role in previous team")
+			return err
+		}
+
+		change := newMembershipChange(ctx, conflict.UserID, conflict.TeamID, teamID, model.MembershipChangeTeamAdd)
+		err = s.auditRepo.Record(ctx, change)
+		if err != nil {
+			s.logger.Error(err, "cannot record membership change")
+			return err
+		}
+	}
+
+	return s.grantCreatorAdmin(ctx, teamID)
+}
+
+// grantCreatorAdmin makes the actor creating a team its admin, every other role goes through
+// GrantRole. Anonymous callers and actors that are not users yet get nothing.
+func (s *TeamService) grantCreatorAdmin(ctx context.Context, teamID model.TeamID) error {
+	actorID, ok := auth.ActorFromContext(ctx)
+	if !ok || uuid.UUID(actorID) == uuid.Nil {
+		return nil
+	}
+
+	_, err := s.userRepo.GetByID(ctx, actorID)
+	if errors.Is(err, rules.ErrUserNotFound) {
+		return nil
+	}
+	if err != nil {
+		s.logger.Error(err, "cannot get team creator")
+		return err
+	}
+
+	err = s.teamRoleRepo.SetRole(ctx, teamID, actorID, model.TeamRoleAdmin)
+	if err != nil {
+		s.logger.Error(err, "cannot make the creator team admin")
+		return err
+	}
 	return nil
 }
 
+// findMemberConflicts returns the members that already belong to some team,
+// reported against their primary team.
+func (s *TeamService) findMemberConflicts(ctx context.Context, members []model.User) ([]model.MemberConflict, error) {
+	var conflicts []model.MemberConflict
+
+	for _, member := range members {
+		existing, err := s.userRepo.GetByID(ctx, member.ID)
+		if errors.Is(err, rules.ErrUserNotFound) {
+			continue
+		}
+		if err != nil {
+			s.logger.Error(err, "cannot get existing member")
+			return nil, err
+		}
+		if existing.TeamID == uuid.Nil {
+			continue
+		}
+
+		memberships, err := s.membershipRepo.GetByUser(ctx, member.ID)
+		if err != nil {
+			s.logger.Error(err, "cannot get memberships of member")
+			return nil, err
+		}
+
+		for _, membership := range memberships {
+			if membership.TeamID != model.TeamID(existing.TeamID) {
+				continue
+			}
+			conflicts = append(
+				conflicts, model.MemberConflict{
+					UserID:   member.ID,
+					TeamID:   membership.TeamID,
+					TeamName: membership.TeamName,
+				},
+			)
+		}
+	}
+
+	return conflicts, nil
+}
+
+func (s *TeamService) resolveMemberConflicts(
+	ctx context.Context, conflicts []model.MemberConflict, opts model.TeamCreateOptions,
+) error {
+	switch opts.ConflictMode {
+	case model.MemberConflictMulti:
+		return nil
+	case model.MemberConflictMove:
+		// taking people away from a team is up to that team's maintainers
+		for _, conflict := range conflicts {
+			err := s.access.requireRole(ctx, conflict.TeamID, model.TeamRoleMaintainer)
+			if err != nil {
+				return err
+			}
+		}
+
+		for _, conflict := range conflicts {
+			err := s.reviewReleaser.ReleaseReviews(ctx, conflict.UserID, conflict.TeamID, opts.ReviewPolicy)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return &rules.MemberConflictError{Conflicts: conflicts}
+	}
+}
+
 func (s *TeamService) GetTeamWithMembers(ctx context.Context, teamName string) (*model.Team, []model.User, error) {
 	team, err := s.teamRepo.GetByName(ctx, teamName)
 	if err != nil {
@@ -109,10 +334,574 @@ func (s *TeamService) GetTeamWithMembers(ctx context.Context, teamName string) (
 }
 
 func (s *TeamService) BulkDeactivateTeam(ctx context.Context, ID model.TeamID) error {
-	err := s.teamRepo.BulkDeactivateTeam(ctx, ID)
+	err := s.access.requireRole(ctx, ID, model.TeamRoleAdmin)
+	if err != nil {
+		return err
+	}
+
+	err = s.teamRepo.BulkDeactivateTeam(ctx, ID)
 	if err != nil {
 		s.logger.Error(err, "cannot bulk deactivate team")
 		return err
 	}
 	return nil
-}
\ No newline at end of file
+}
+
+// AddMember adds a membership in the team. Users without a team get it as their primary
+// team, users that already belong elsewhere keep their primary team.
+func (s *TeamService) AddMember(ctx context.Context, teamName string, member model.User, weight float64) (
+	*model.Team, []model.User, error,
+) {
+	var team *model.Team
+	var members []model.User
+	err := withinRetriedTx(ctx, s.tx, func(ctx context.Context) error {
+		var err error
+		team, members, err = s.addMember(ctx, teamName, member, weight)
+		return err
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return team, members, nil
+}
+
+func (s *TeamService) addMember(ctx context.Context, teamName string, member model.User, weight float64) (
+	*model.Team, []model.User, error,
+) {
+	if weight == 0 {
+		weight = model.DefaultMembershipWeight
+	}
+	if weight < 0 {
+		return nil, nil, rules.ErrInvalidWeight
+	}
+
+	team, err := s.getTeamForMemberChange(ctx, teamName)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	isMember, err := s.membershipRepo.Exists(ctx, team.TeamID, member.ID)
+	if err != nil {
+		s.logger.Error(err, "cannot check team membership")
+		return nil, nil, err
+	}
+	if isMember {
+		return nil, nil, rules.ErrUserExists
+	}
+
+	now := time.Now()
+	existing, err := s.userRepo.GetByID(ctx, member.ID)
+	switch {
+	case err == nil && existing.TeamID != uuid.Nil:
+	case err == nil:
+		existing.TeamID = uuid.UUID(team.TeamID)
+		existing.IsActive = member.IsActive
+		existing.UpdatedAt = now
+		err = s.userRepo.Update(ctx, existing)
+	case errors.Is(err, rules.ErrUserNotFound):
+		member.TeamID = uuid.UUID(team.TeamID)
+		member.CreatedAt = now
+		member.UpdatedAt = now
+		err = s.userRepo.Insert(ctx, &member)
+	}
+	if err == nil {
+		err = s.membershipRepo.Add(
+			ctx, &model.TeamMembership{
+				TeamID:    team.TeamID,
+				UserID:    member.ID,
+				Weight:    weight,
+				CreatedAt: now,
+			},
+		)
+	}
+	if err == nil && existing != nil {
+		change := newMembershipChange(
+			ctx, member.ID, model.TeamID(uuid.Nil), team.TeamID, model.MembershipChangeAddMember,
+		)
+		err = s.auditRepo.Record(ctx, change)
+	}
+	if err != nil {
+		s.logger.Error(err, "cannot add team member")
+		return nil, nil, err
+	}
+
+	return s.getTeamMembers(ctx, team)
+}
+
+// RemoveMember releases the member's reviews and drops the membership in one transaction.
+func (s *TeamService) RemoveMember(
+	ctx context.Context, teamName string, userID model.UserID, policy model.ReviewPolicy,
+) (*model.Team, []model.User, error) {
+	var team *model.Team
+	var members []model.User
+	err := withinRetriedTx(ctx, s.tx, func(ctx context.Context) error {
+		var err error
+		team, members, err = s.removeMember(ctx, teamName, userID, policy)
+		return err
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return team, members, nil
+}
+
+func (s *TeamService) removeMember(
+	ctx context.Context, teamName string, userID model.UserID, policy model.ReviewPolicy,
+) (*model.Team, []model.User, error) {
+	if !policy.IsValid() {
+		return nil, nil, rules.ErrInvalidReviewPolicy
+	}
+
+	team, err := s.getTeamForMemberChange(ctx, teamName)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		s.logger.Error(err, "cannot get user")
+		return nil, nil, err
+	}
+
+	isMember, err := s.membershipRepo.Exists(ctx, team.TeamID, userID)
+	if err != nil {
+		s.logger.Error(err, "cannot check team membership")
+		return nil, nil, err
+	}
+	if !isMember {
+		return nil, nil, rules.ErrUserNotFound
+	}
+
+	err = s.reviewReleaser.ReleaseReviews(ctx, userID, team.TeamID, policy)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	err = s.membershipRepo.Remove(ctx, team.TeamID, userID)
+	if errors.Is(err, rules.ErrNotFound) {
+		return nil, nil, rules.ErrUserNotFound
+	}
+	if err != nil {
+		s.logger.Error(err, "cannot remove team member")
+		return nil, nil, err
+	}
+
+	if model.TeamID(user.TeamID) == team.TeamID {
+		err = s.reassignPrimaryTeam(ctx, user)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	err = s.teamRoleRepo.DeleteRole(ctx, team.TeamID, userID)
+	if err != nil {
+		s.logger.Error(err, "cannot drop team role of removed member")
+		return nil, nil, err
+	}
+
+	change := newMembershipChange(ctx, userID, team.TeamID, model.TeamID(uuid.Nil), model.MembershipChangeRemoveMember)
+	err = s.auditRepo.Record(ctx, change)
+	if err != nil {
+		s.logger.Error(err, "cannot record membership change")
+		return nil, nil, err
+	}
+
+	return s.getTeamMembers(ctx, team)
+}
+
+// reassignPrimaryTeam points the user's primary team at one of the remaining memberships,
+// or clears it when there are none left.
+func (s *TeamService) reassignPrimaryTeam(ctx context.Context, user *model.User) error {
+	memberships, err := s.membershipRepo.GetByUser(ctx, user.ID)
+	if err != nil {
+		s.logger.Error(err, "cannot get remaining memberships")
+		return err
+	}
+
+	user.TeamID = uuid.Nil
+	if len(memberships) > 0 {
+		user.TeamID = uuid.UUID(memberships[0].TeamID)
+	}
+	user.UpdatedAt = time.Now()
+
+	err = s.userRepo.Update(ctx, user)
+	if err != nil {
+		s.logger.Error(err, "cannot update primary team")
+		return err
+	}
+	return nil
+}
+
+func (s *TeamService) RenameTeam(ctx context.Context, teamName string, newName string) (
+	*model.Team, []model.User, error,
+) {
+	var (
+		team    *model.Team
+		members []model.User
+	)
+	err := withinRetriedTx(ctx, s.tx, func(ctx context.Context) error {
+		var err error
+		team, members, err = s.renameTeam(ctx, teamName, newName)
+		return err
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return team, members, nil
+}
+
+// renameTeam checks the new name in the transaction that writes it, so of two teams renamed to
+// the same name at once the later one gets rules.ErrTeamExists.
+func (s *TeamService) renameTeam(ctx context.Context, teamName string, newName string) (
+	*model.Team, []model.User, error,
+) {
+	team, err := s.getTeamForMemberChange(ctx, teamName)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	err = s.lockTeams(ctx, team)
+	if err != nil {
+		return nil, nil, err
+	}
+	// the team may have been renamed while waiting for the lock
+	team, err = s.teamRepo.GetByID(ctx, team.TeamID)
+	if err != nil {
+		s.logger.Error(err, "cannot get team")
+		return nil, nil, err
+	}
+
+	if newName == team.Name {
+		return s.getTeamMembers(ctx, team)
+	}
+
+	exists, err := s.nameTaken(ctx, newName)
+	if err != nil {
+		return nil, nil, err
+	}
+	if exists {
+		return nil, nil, rules.ErrTeamExists
+	}
+
+	team.Name = newName
+	err = s.teamRepo.Update(ctx, team)
+	if err != nil {
+		s.logger.Error(err, "cannot rename team")
+		return nil, nil, err
+	}
+
+	return s.getTeamMembers(ctx, team)
+}
+
+// nameTaken locks name for the rest of the transaction before checking it, so a team created or
+// renamed meanwhile under the same name cannot slip in between the check and the write.
+func (s *TeamService) nameTaken(ctx context.Context, name string) (bool, error) {
+	err := s.teamRepo.LockName(ctx, name)
+	if err != nil {
+		s.logger.Error(err, "cannot lock team name")
+		return false, err
+	}
+
+	exists, err := s.teamRepo.ExistsByName(ctx, name)
+	if err != nil {
+		s.logger.Error(err, "cannot check team existence")
+		return false, err
+	}
+	return exists, nil
+}
+
+func (s *TeamService) getTeamForMemberChange(ctx context.Context, teamName string) (*model.Team, error) {
+	team, err := s.teamRepo.GetByName(ctx, teamName)
+	if err != nil {
+		s.logger.Error(err, "cannot get team by name")
+		return nil, err
+	}
+
+	err = s.access.requireRole(ctx, team.TeamID, model.TeamRoleMaintainer)
+	if err != nil {
+		return nil, err
+	}
+
+	return team, nil
+}
+
+func (s *TeamService) getTeamMembers(ctx context.Context, team *model.Team) (*model.Team, []model.User, error) {
+	users, err := s.userRepo.GetByTeam(ctx, team.TeamID)
+	if err != nil {
+		s.logger.Error(err, "cannot get team members")
+		return nil, nil, err
+	}
+
+	return team, users, nil
+}
+
+func (s *TeamService) GrantRole(
+	ctx context.Context, teamName string, userID model.UserID, role model.TeamRole,
+) (*model.Team, error) {
+	if !role.IsValid() {
+		return nil, rules.ErrInvalidRole
+	}
+
+	team, err := s.getTeamForRoleChange(ctx, teamName, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if role == model.TeamRoleMember {
+		err = s.teamRoleRepo.DeleteRole(ctx, team.TeamID, userID)
+	} else {
+		err = s.teamRoleRepo.SetRole(ctx, team.TeamID, userID, role)
+	}
+	if err != nil {
+		s.logger.Error(err, "cannot grant team role")
+		return nil, err
+	}
+
+	return team, nil
+}
+
+func (s *TeamService) RevokeRole(ctx context.Context, teamName string, userID model.UserID) (*model.Team, error) {
+	team, err := s.getTeamForRoleChange(ctx, teamName, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.teamRoleRepo.DeleteRole(ctx, team.TeamID, userID)
+	if err != nil {
+		s.logger.Error(err, "cannot revoke team role")
+		return nil, err
+	}
+
+	return team, nil
+}
+
+func (s *TeamService) getTeamForRoleChange(
+	ctx context.Context, teamName string, userID model.UserID,
+) (*model.Team, error) {
+	team, err := s.teamRepo.GetByName(ctx, teamName)
+	if err != nil {
+		s.logger.Error(err, "cannot get team by name")
+		return nil, err
+	}
+
+	err = s.access.requireRole(ctx, team.TeamID, model.TeamRoleAdmin)
+	if err != nil {
+		return nil, err
+	}
+
+	isMember, err := s.membershipRepo.Exists(ctx, team.TeamID, userID)
+	if err != nil {
+		s.logger.Error(err, "cannot check team membership")
+		return nil, err
+	}
+	if !isMember {
+		return nil, rules.ErrUserNotFound
+	}
+
+	return team, nil
+}
+
+// SetParentTeam nests the team under another one, or makes it a root team when parentTeamName is empty.
+func (s *TeamService) SetParentTeam(ctx context.Context, teamName string, parentTeamName string) (*model.Team, error) {
+	var team *model.Team
+	err := withinRetriedTx(ctx, s.tx, func(ctx context.Context) error {
+		var err error
+		team, err = s.setParentTeam(ctx, teamName, parentTeamName)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return team, nil
+}
+
+// setParentTeam locks the team and its new parent before looking for a cycle, so two teams
+// moved under each other at the same time cannot both pass the check.
+func (s *TeamService) setParentTeam(ctx context.Context, teamName string, parentTeamName string) (*model.Team, error) {
+	team, err := s.teamRepo.GetByName(ctx, teamName)
+	if err != nil {
+		s.logger.Error(err, "cannot get team by name")
+		return nil, err
+	}
+
+	err = s.access.requireRole(ctx, team.TeamID, model.TeamRoleAdmin)
+	if err != nil {
+		return nil, err
+	}
+
+	var parent *model.Team
+	if parentTeamName != "" {
+		parent, err = s.getParentTeam(ctx, parentTeamName)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = s.lockTeams(ctx, team, parent)
+	if err != nil {
+		return nil, err
+	}
+	// the team may have changed while waiting for the lock
+	team, err = s.teamRepo.GetByID(ctx, team.TeamID)
+	if err != nil {
+		s.logger.Error(err, "cannot get team")
+		return nil, err
+	}
+
+	team.ParentTeamID = uuid.Nil
+	if parent != nil {
+		cycle, err := s.hierarchy.isAncestorOrSelf(ctx, parent.TeamID, team.TeamID)
+		if err != nil {
+			s.logger.Error(err, "cannot get team ancestors")
+			return nil, err
+		}
+		if cycle {
+			return nil, rules.ErrTeamCycle
+		}
+
+		team.ParentTeamID = uuid.UUID(parent.TeamID)
+	}
+
+	err = s.teamRepo.Update(ctx, team)
+	if err != nil {
+		s.logger.Error(err, "cannot set parent team")
+		return nil, err
+	}
+
+	return team, nil
+}
+
+// lockTeams locks the given teams in the order of their ids, which keeps two transactions that
+// lock the same pair from deadlocking. Nil teams are skipped.
+func (s *TeamService) lockTeams(ctx context.Context, teams ...*model.Team) error {
+	IDs := make([]model.TeamID, 0, len(teams))
+	for _, team := range teams {
+		if team != nil && !slices.Contains(IDs, team.TeamID) {
+			IDs = append(IDs, team.TeamID)
+		}
+	}
+	slices.SortFunc(IDs, func(a, b model.TeamID) int { return bytes.Compare(a[:], b[:]) })
+
+	for _, ID := range IDs {
+		err := s.teamRepo.Lock(ctx, ID)
+		if err != nil {
+			s.logger.Error(err, "cannot lock team")
+			return err
+		}
+	}
+
+	return nil
+}
+
+// getParentTeam loads a team that another one is about to be attached to. Growing a team's
+// subtree is up to its maintainers.
+func (s *TeamService) getParentTeam(ctx context.Context, parentTeamName string) (*model.Team, error) {
+	parent, err := s.teamRepo.GetByName(ctx, parentTeamName)
+	if err != nil {
+		s.logger.Error(err, "cannot get parent team by name")
+		return nil, err
+	}
+
+	err = s.access.requireRole(ctx, parent.TeamID, model.TeamRoleMaintainer)
+	if err != nil {
+		return nil, err
+	}
+
+	return parent, nil
+}
+
+// UpdateSettings overrides the settings given, leaving the fields that are nil as they are.
+func (s *TeamService) UpdateSettings(ctx context.Context, teamName string, settings model.TeamSettings) (
+	*model.Team, error,
+) {
+	if settings.MaxReviewers != nil && *settings.MaxReviewers <= 0 {
+		return nil, rules.ErrInvalidTeamSettings
+	}
+
+	var team *model.Team
+	err := withinRetriedTx(ctx, s.tx, func(ctx context.Context) error {
+		var err error
+		team, err = s.updateSettings(ctx, teamName, settings)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return team, nil
+}
+
+func (s *TeamService) updateSettings(ctx context.Context, teamName string, settings model.TeamSettings) (
+	*model.Team, error,
+) {
+	team, err := s.getTeamForMemberChange(ctx, teamName)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.lockTeams(ctx, team)
+	if err != nil {
+		return nil, err
+	}
+	team, err = s.teamRepo.GetByID(ctx, team.TeamID)
+	if err != nil {
+		s.logger.Error(err, "cannot get team")
+		return nil, err
+	}
+
+	if settings.MaxReviewers != nil {
+		team.Settings.MaxReviewers = settings.MaxReviewers
+	}
+	if settings.ReviewerFallback != nil {
+		team.Settings.ReviewerFallback = settings.ReviewerFallback
+	}
+	err = s.teamRepo.Update(ctx, team)
+	if err != nil {
+		s.logger.Error(err, "cannot update team settings")
+		return nil, err
+	}
+
+	return team, nil
+}
+
+// GetTeamTree returns the subtree rooted at the named team, or the whole forest when teamName is empty.
+func (s *TeamService) GetTeamTree(ctx context.Context, teamName string) ([]*model.TeamNode, error) {
+	if teamName == "" {
+		teams, err := s.teamRepo.GetAll(ctx)
+		if err != nil {
+			s.logger.Error(err, "cannot get teams")
+			return nil, err
+		}
+
+		children := groupByParent(teams)
+		roots := make([]*model.TeamNode, 0, len(children[uuid.Nil]))
+		for _, team := range children[uuid.Nil] {
+			roots = append(roots, buildTeamTree(team, model.TeamSettings{}, children))
+		}
+		return roots, nil
+	}
+
+	team, err := s.teamRepo.GetByName(ctx, teamName)
+	if err != nil {
+		s.logger.Error(err, "cannot get team by name")
+		return nil, err
+	}
+
+	settings, _, err := s.hierarchy.resolve(ctx, team.TeamID)
+	if err != nil {
+		s.logger.Error(err, "cannot resolve team settings")
+		return nil, err
+	}
+
+	descendants, err := s.teamRepo.GetDescendants(ctx, team.TeamID)
+	if err != nil {
+		s.logger.Error(err, "cannot get team descendants")
+		return nil, err
+	}
+
+	return []*model.TeamNode{buildTeamTree(*team, settings, groupByParent(descendants))}, nil
+}
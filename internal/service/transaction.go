@@ -0,0 +1,37 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"time"
+
+	"pull-request-review/internal/domain/ports/repository"
+	"pull-request-review/internal/domain/rules"
+)
+
+// maxTxAttempts bounds how many times an operation that lost a race is replayed.
+const maxTxAttempts = 5
+
+// withinRetriedTx runs fn in a transaction and replays it from scratch when a concurrent change
+// to the same pull request won the race, so the losing request decides again on fresh data
+// instead of failing. Only top-level operations retry: a replay inside an outer transaction
+// would repeat the writes fn already made in it.
+func withinRetriedTx(ctx context.Context, tx repository.TxManager, fn func(ctx context.Context) error) error {
+	var err error
+	for attempt := 1; attempt <= maxTxAttempts; attempt++ {
+		err = tx.WithinTx(ctx, fn)
+		if !errors.Is(err, rules.ErrConcurrentModification) {
+			return err
+		}
+
+		// a little jitter keeps the losers from colliding again right away
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(time.Duration(rand.Intn(attempt*5)+1) * time.Millisecond):
+		}
+	}
+
+	return err
+}
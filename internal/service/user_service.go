@@ -2,6 +2,9 @@ package service
 
 import (
 	"context"
+	"time"
+
+	"github.com/google/uuid"
 
 	"pull-request-review/internal/domain/model"
 	"pull-request-review/internal/domain/ports/repository"
@@ -10,18 +13,37 @@ import (
 )
 
 type UserService struct {
-	userRepo repository.UserRepository
-	teamRepo repository.TeamRepository
-	logger   logger.Logger
+	userRepo       repository.UserRepository
+	teamRepo       repository.TeamRepository
+	membershipRepo repository.TeamMembershipRepository
+	teamRoleRepo   repository.TeamRoleRepository
+	auditRepo      repository.MembershipAuditRepository
+	reviewReleaser ReviewReleaser
+	tx             repository.TxManager
+	access         *accessChecker
+	logger         logger.Logger
 }
 
 func NewUserService(
-	userRepo repository.UserRepository, teamRepo repository.TeamRepository, logger logger.Logger,
+	userRepo repository.UserRepository,
+	teamRepo repository.TeamRepository,
+	membershipRepo repository.TeamMembershipRepository,
+	teamRoleRepo repository.TeamRoleRepository,
+	auditRepo repository.MembershipAuditRepository,
+	reviewReleaser ReviewReleaser,
+	tx repository.TxManager,
+	logger logger.Logger,
 ) *UserService {
 	return &UserService{
-		userRepo: userRepo,
-		teamRepo: teamRepo,
-		logger:   logger,
+		userRepo:       userRepo,
+		teamRepo:       teamRepo,
+		membershipRepo: membershipRepo,
+		teamRoleRepo:   teamRoleRepo,
+		auditRepo:      auditRepo,
+		reviewReleaser: reviewReleaser,
+		tx:             tx,
+		access:         newAccessChecker(teamRoleRepo, membershipRepo),
+		logger:         logger,
 	}
 }
 
@@ -57,6 +79,10 @@ func (s *UserService) GetUserWithTeamName(ctx context.Context, ID model.UserID)
 		return nil, "", rules.ErrUserNotFound
 	}
 
+	if user.TeamID == uuid.Nil {
+		return user, "", nil
+	}
+
 	team, err := s.teamRepo.GetByID(ctx, model.TeamID(user.TeamID))
 	if err != nil {
 		s.logger.Error(err, "cannot get team for user")
@@ -64,4 +90,174 @@ func (s *UserService) GetUserWithTeamName(ctx context.Context, ID model.UserID)
 	}
 
 	return user, team.Name, nil
+}
+
+func (s *UserService) GetUserTeams(ctx context.Context, ID model.UserID) ([]model.TeamMembership, error) {
+	memberships, err := s.membershipRepo.GetByUser(ctx, ID)
+	if err != nil {
+		s.logger.Error(err, "cannot get user teams")
+		return nil, err
+	}
+	return memberships, nil
+}
+
+// MoveToTeam transfers the user's membership from one team to another, by default from the
+// primary team. Maintainers of both teams have to agree, and the open reviews in the old team
+// are handled according to policy before the move, all in one transaction.
+func (s *UserService) MoveToTeam(
+	ctx context.Context, ID model.UserID, fromTeamName string, teamName string, policy model.ReviewPolicy,
+) (*model.User, error) {
+	var user *model.User
+	err := withinRetriedTx(ctx, s.tx, func(ctx context.Context) error {
+		var err error
+		user, err = s.moveToTeam(ctx, ID, fromTeamName, teamName, policy)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+func (s *UserService) moveToTeam(
+	ctx context.Context, ID model.UserID, fromTeamName string, teamName string, policy model.ReviewPolicy,
+) (*model.User, error) {
+	if !policy.IsValid() {
+		return nil, rules.ErrInvalidReviewPolicy
+	}
+
+	user, err := s.userRepo.GetByID(ctx, ID)
+	if err != nil {
+		s.logger.Error(err, "cannot get user")
+		return nil, err
+	}
+
+	team, err := s.teamRepo.GetByName(ctx, teamName)
+	if err != nil {
+		s.logger.Error(err, "cannot get team by name")
+		return nil, err
+	}
+
+	oldTeamID := model.TeamID(user.TeamID)
+	if fromTeamName != "" {
+		fromTeam, err := s.teamRepo.GetByName(ctx, fromTeamName)
+		if err != nil {
+			s.logger.Error(err, "cannot get team by name")
+			return nil, err
+		}
+		oldTeamID = fromTeam.TeamID
+	}
+	if oldTeamID == team.TeamID {
+		return user, nil
+	}
+
+	if oldTeamID != model.TeamID(uuid.Nil) {
+		isMember, err := s.membershipRepo.Exists(ctx, oldTeamID, ID)
+		if err != nil {
+			s.logger.Error(err, "cannot check team membership")
+			return nil, err
+		}
+		if !isMember {
+			return nil, rules.ErrUserNotFound
+		}
+	}
+
+	alreadyMember, err := s.membershipRepo.Exists(ctx, team.TeamID, ID)
+	if err != nil {
+		s.logger.Error(err, "cannot check team membership")
+		return nil, err
+	}
+	if alreadyMember {
+		return nil, rules.ErrUserExists
+	}
+
+	err = s.access.requireRole(ctx, team.TeamID, model.TeamRoleMaintainer)
+	if err != nil {
+		return nil, err
+	}
+
+	if oldTeamID != model.TeamID(uuid.Nil) {
+		err = s.access.requireRole(ctx, oldTeamID, model.TeamRoleMaintainer)
+		if err != nil {
+			return nil, err
+		}
+
+		err = s.reviewReleaser.ReleaseReviews(ctx, ID, oldTeamID, policy)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	now := time.Now()
+	weight := model.DefaultMembershipWeight
+	if oldTeamID != model.TeamID(uuid.Nil) {
+		weight, err = s.membershipWeight(ctx, oldTeamID, ID)
+		if err != nil {
+			return nil, err
+		}
+
+		err = s.membershipRepo.Remove(ctx, oldTeamID, ID)
+		if err != nil {
+			s.logger.Error(err, "cannot remove membership in previous team")
+			return nil, err
+		}
+	}
+
+	err = s.membershipRepo.Add(
+		ctx, &model.TeamMembership{
+			TeamID:    team.TeamID,
+			UserID:    ID,
+			Weight:    weight,
+			CreatedAt: now,
+		},
+	)
+	if err != nil {
+		s.logger.Error(err, "cannot add membership in new team")
+		return nil, err
+	}
+
+	if model.TeamID(user.TeamID) == oldTeamID {
+		user.TeamID = uuid.UUID(team.TeamID)
+		user.UpdatedAt = now
+		err = s.userRepo.Update(ctx, user)
+		if err != nil {
+			s.logger.Error(err, "cannot move user to team")
+			return nil, err
+		}
+	}
+
+	if oldTeamID != model.TeamID(uuid.Nil) {
+		err = s.teamRoleRepo.DeleteRole(ctx, oldTeamID, ID)
+		if err != nil {
+			s.logger.Error(err, "cannot drop team role in previous team")
+			return nil, err
+		}
+	}
+
+	change := newMembershipChange(ctx, ID, oldTeamID, team.TeamID, model.MembershipChangeMoveTeam)
+	err = s.auditRepo.Record(ctx, change)
+	if err != nil {
+		s.logger.Error(err, "cannot record membership change")
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// membershipWeight keeps the user's weight when a membership moves to another team.
+func (s *UserService) membershipWeight(ctx context.Context, teamID model.TeamID, ID model.UserID) (float64, error) {
+	memberships, err := s.membershipRepo.GetByUser(ctx, ID)
+	if err != nil {
+		s.logger.Error(err, "cannot get user teams")
+		return 0, err
+	}
+
+	for _, membership := range memberships {
+		if membership.TeamID == teamID {
+			return membership.Weight, nil
+		}
+	}
+
+	return model.DefaultMembershipWeight, nil
 }
\ No newline at end of file
@@ -42,7 +42,7 @@ func TestMain(m *testing.M) {
 		log.Error(err, "Failed to cleanup test data")
 	}
 
-	go app.Run(cfg, db, log)
+	go app.Run(cfg, app.NewPostgresStorage(db), log)
 
 	baseURL = fmt.Sprintf("http://localhost:%s", cfg.Server.Port)
 
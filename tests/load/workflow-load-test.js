@@ -1,6 +1,9 @@
 import http from 'k6/http';
 import { check, sleep } from 'k6';
 
+// 20 virtual users from one address send far more than the 50 requests per second the rate
+// limiter allows an address by default, so run the service without it:
+//   RATE_LIMIT_ENABLED=false make docker-up
 const BASE_URL = __ENV.BASE_URL || 'http://localhost:8080';
 const NUM_USERS = parseInt(__ENV.NUM_USERS || '10');
 
@@ -10,11 +13,13 @@ export const options = {
 };
 
 export function setup() {
+    // /team/add rejects users that already belong to another team, so every run gets its own users
+    const runId = Date.now().toString(16).padStart(10, '0').slice(-10);
     const members = [];
     for (let i = 1; i <= NUM_USERS; i++) {
         const paddedId = String(i).padStart(2, '0');
         members.push({
-            user_id: `550e8400-e29b-41d4-a716-4466554400${paddedId}`,
+            user_id: `550e8400-e29b-41d4-a716-${runId}${paddedId}`,
             username: `User${i}`,
             is_active: true
         });
@@ -29,6 +34,9 @@ export function setup() {
             headers: { 'Content-Type': 'application/json' },
         }
     );
+    if (teamRes.status === 429) {
+        throw new Error('rate limited, start the service with RATE_LIMIT_ENABLED=false');
+    }
     check(teamRes, { 'team created': (r) => r.status === 201 });
 
     return { teamName: teamName, userIds: members.map(m => m.user_id) };